@@ -0,0 +1,16 @@
+// Package api embeds the OpenAPI description of the HTTP API so it ships in
+// the binary and can be served alongside the routes it documents.
+package api
+
+import _ "embed"
+
+// Spec is the OpenAPI 3 document describing every route, kept in sync with
+// the handlers by hand
+//
+//go:embed openapi.json
+var Spec []byte
+
+// SwaggerUI is an HTML page rendering Spec with Swagger UI
+//
+//go:embed swagger.html
+var SwaggerUI []byte
@@ -4,17 +4,25 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/Tarifsiz/go-currency-api/internal/config"
 	"github.com/Tarifsiz/go-currency-api/internal/database"
 	"github.com/Tarifsiz/go-currency-api/internal/handler"
+	"github.com/Tarifsiz/go-currency-api/internal/logging"
+	"github.com/Tarifsiz/go-currency-api/internal/metrics"
+	"github.com/Tarifsiz/go-currency-api/internal/middleware"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/rates"
 	"github.com/Tarifsiz/go-currency-api/internal/repository"
 	"github.com/Tarifsiz/go-currency-api/internal/service"
+	"github.com/Tarifsiz/go-currency-api/migrations"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
@@ -27,6 +35,13 @@ func main() {
 	if err != nil {
 		log.Fatal("Failed to load config:", err)
 	}
+	if err := cfg.Auth.Validate(); err != nil {
+		log.Fatal("Invalid auth config:", err)
+	}
+
+	// Initialize structured logging; the standard log package is routed through it too
+	logger := logging.New(cfg.Log.Level)
+	slog.SetDefault(logger)
 
 	// Initialize database
 	db, err := database.NewPostgresConnection(cfg.Database)
@@ -34,6 +49,21 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	// Initialize metrics
+	if cfg.Metrics.Enabled {
+		metrics.Enable()
+		if err := metrics.InstrumentGORM(db); err != nil {
+			log.Fatal("Failed to instrument database metrics:", err)
+		}
+	}
+
+	// Apply schema migrations
+	if cfg.Database.RunMigrations {
+		if err := database.RunMigrations(db, migrations.FS, &model.Currency{}, &model.ExchangeRate{}, &model.CurrencyTranslation{}, &model.ChangeLog{}); err != nil {
+			log.Fatal("Failed to run database migrations:", err)
+		}
+	}
+
 	// Initialize Redis
 	redisClient := redis.NewClient(&redis.Options{
 		Addr:     cfg.Redis.Addr,
@@ -50,16 +80,55 @@ func main() {
 	}
 
 	// Initialize repositories
-	currencyRepo := repository.NewCurrencyRepository(db)
+	currencyRepo := repository.NewCurrencyRepository(db, database.RetryPolicy{
+		MaxRetries: cfg.Database.TxMaxRetries,
+		Backoff:    cfg.Database.TxRetryBackoff,
+	})
+	rateRepo := repository.NewExchangeRateRepository(db)
+	translationRepo := repository.NewTranslationRepository(db)
 
 	// Initialize services
-	currencyService := service.NewCurrencyService(currencyRepo, redisClient)
+	currencyValidator, err := service.NewCurrencyValidator(cfg.Currency.ValidationMode)
+	if err != nil {
+		log.Fatal("Failed to create currency validator:", err)
+	}
+	currencyService := service.NewCurrencyService(currencyRepo, redisClient, cfg.Currency, cfg.Cache, cfg.Pagination, currencyValidator)
+	conversionService := service.NewConversionService(currencyRepo, rateRepo, cfg.Rates.PivotCurrency)
+	healthService := service.NewHealthService(db, redisClient)
+	rateService := service.NewRateService(rateRepo, currencyRepo)
+	translationService := service.NewTranslationService(translationRepo)
+
+	// Start the exchange rate refresh worker; it is skipped when no provider is configured
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	var jobs sync.WaitGroup
+	var rateRefresher service.RateRefresherInterface
+	if cfg.Rates.ProviderURL != "" {
+		provider, err := rates.NewHTTPProvider(cfg.Rates.ProviderURL, cfg.Rates.APIKey, cfg.Rates.Timeout)
+		if err != nil {
+			log.Fatal("Failed to create rate provider:", err)
+		}
+		refresher := service.NewRateRefresher(provider, rateRepo, cfg.Rates.BaseCurrencies, cfg.Rates.RefreshInterval, cfg.Rates.Timeout, cfg.Rates.MaxBackoff, logger)
+		rateRefresher = refresher
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			refresher.Run(jobsCtx)
+		}()
+	} else {
+		logger.Info("RATE_PROVIDER_URL not set, exchange rate refresh disabled")
+	}
+	adminService := service.NewAdminService(currencyRepo, rateRepo, currencyService, rateRefresher, db, cfg.Cache.HotCodes)
 
 	// Initialize handlers
-	currencyHandler := handler.NewCurrencyHandler(currencyService)
+	currencyHandler := handler.NewCurrencyHandler(currencyService, translationService, cfg.Pagination)
+	conversionHandler := handler.NewConversionHandler(conversionService)
+	adminHandler := handler.NewAdminHandler(adminService)
+	rateHandler := handler.NewRateHandler(rateService, rateRefresher, cfg.Pagination)
+	healthHandler := handler.NewHealthHandler(healthService, cfg.Health.DetailsToken)
 
 	// Setup router
-	router := setupRouter(currencyHandler)
+	router := setupRouter(cfg, logger, redisClient, currencyHandler, conversionHandler, adminHandler, rateHandler, healthHandler)
 
 	// Start server
 	srv := &http.Server{
@@ -81,60 +150,160 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	
+	// Stop background jobs first so none is left mid-write
+	stopJobs()
+	jobs.Wait()
 
 	// Graceful shutdown with timeout
 	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+		log.Println("Server forced to shutdown:", err)
 	}
 
+	// Release connections only once in-flight requests are done with them
+	closeAll(
+		closer{"database", func() error { return database.CloseConnection(db) }},
+		closer{"redis", redisClient.Close},
+	)
+
 	log.Println("Server exiting")
 }
 
-func setupRouter(currencyHandler *handler.CurrencyHandler) *gin.Engine {
-	// Set gin mode based on environment
-	gin.SetMode(gin.ReleaseMode) // Change to gin.DebugMode for development
+// closer is a named cleanup function run during shutdown
+type closer struct {
+	name  string
+	close func() error
+}
+
+// closeAll runs every closer in order, logging failures without stopping,
+// so one failing close doesn't leak the remaining connections
+func closeAll(closers ...closer) {
+	for _, c := range closers {
+		if err := c.close(); err != nil {
+			log.Printf("Failed to close %s: %v", c.name, err)
+		}
+	}
+}
+
+func setupRouter(cfg *config.Config, logger *slog.Logger, redisClient *redis.Client, currencyHandler *handler.CurrencyHandler, conversionHandler *handler.ConversionHandler, adminHandler *handler.AdminHandler, rateHandler *handler.RateHandler, healthHandler *handler.HealthHandler) *gin.Engine {
+	// Set gin mode from GIN_MODE; debug logs every registered route
+	gin.SetMode(cfg.Server.Mode)
 
 	router := gin.New()
 	
 	// Global middleware
-	router.Use(gin.Logger())
+	router.Use(middleware.RequestID())
+	router.Use(middleware.RequestLogger(logger))
+	if cfg.Metrics.Enabled {
+		router.Use(middleware.Metrics())
+	}
 	router.Use(gin.Recovery())
-	router.Use(corsMiddleware())
-
-	// Health check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":    "healthy",
-			"timestamp": time.Now().UTC(),
-			"service":   "currency-api",
-		})
+	router.Use(middleware.CORS(cfg.CORS.AllowedOrigins, cfg.CORS.AllowedMethods, cfg.CORS.AllowedHeaders))
+	// Probes and scrapers are small and frequent, so they skip compression
+	router.Use(middleware.Gzip(cfg.Server.GzipLevel, cfg.Server.GzipMinLength, "/health", "/metrics"))
+	router.Use(middleware.ConcurrencyLimit(cfg.Server.MaxConcurrentRequests, cfg.Server.QueueTimeout))
+
+	// Health check endpoints: /health/live for liveness probes, /health/ready
+	// (and /health) for readiness probes, and /health/details with dependency
+	// latencies for operators
+	health := router.Group("/health")
+	{
+		health.GET("", healthHandler.Ready)
+		health.GET("/live", healthHandler.Live)
+		health.GET("/ready", healthHandler.Ready)
+		health.GET("/details", healthHandler.Details)
+	}
+
+	// API description, as OpenAPI JSON and rendered with Swagger UI
+	router.GET("/openapi.json", handler.OpenAPISpec)
+	router.GET("/swagger/*any", handler.SwaggerUI)
+	router.GET("/docs", func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, "/swagger/")
 	})
 
+	// Prometheus metrics endpoint
+	if cfg.Metrics.Enabled {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+
 	// API routes
+	requireAuth := middleware.JWTAuth(cfg.Auth.JWTSecret)
+	// Identifies callers with a valid token ahead of the rate limiter so
+	// authenticated users get their own bucket instead of their IP's
+	identify := middleware.OptionalJWTAuth(cfg.Auth.JWTSecret)
+	rateLimit := middleware.RateLimit(redisClient, "api", cfg.Server.RateLimitRPM)
+	idempotent := middleware.Idempotency(redisClient, cfg.Server.IdempotencyTTL)
+
 	v1 := router.Group("/api/v1")
+	v1.Use(identify, rateLimit, middleware.RequireJSON())
 	{
-		// Currency endpoints
-		v1.GET("/currencies", currencyHandler.GetCurrencies)
-	}
+		// Currency endpoints; reads are public, writes require a bearer token
+		currencies := v1.Group("/currencies")
+		currencies.Use(middleware.CacheControl(cfg.HTTPCache.CurrenciesMaxAge))
+		currencies.GET("", currencyHandler.GetCurrencies)
+		currencies.POST("", requireAuth, idempotent, currencyHandler.CreateCurrency)
+		currencies.GET("/schema", currencyHandler.GetCurrencySchema)
+		currencies.GET("/changes", currencyHandler.GetCurrencyChanges)
+		currencies.GET("/count", currencyHandler.CountCurrencies)
+		currencies.GET("/grouped", currencyHandler.GetCurrenciesGrouped)
+		currencies.GET("/without-rates", currencyHandler.GetCurrenciesWithoutRates)
+		currencies.POST("/batch", requireAuth, idempotent, currencyHandler.CreateCurrenciesBatch)
+		currencies.PATCH("/batch", requireAuth, currencyHandler.PatchCurrencies)
+		currencies.GET("/numeric/:numericCode", currencyHandler.GetCurrencyByNumericCode)
+		currencies.GET("/:code", currencyHandler.GetCurrencyByCode)
+		// HEAD checks that a code exists; net/http drops the body but keeps GET's headers
+		currencies.HEAD("/:code", currencyHandler.GetCurrencyByCode)
+		currencies.GET("/:code/symbol", currencyHandler.GetCurrencySymbol)
+		currencies.GET("/:code/audit", requireAuth, middleware.NoStore(), currencyHandler.GetCurrencyAudit)
+		currencies.POST("/:code/format", currencyHandler.FormatAmount)
+		currencies.POST("/:code/format-preview", currencyHandler.PreviewFormat)
+		currencies.PUT("/:code", requireAuth, currencyHandler.UpdateCurrency)
+		currencies.PATCH("/:code", requireAuth, currencyHandler.PatchCurrency)
+		currencies.DELETE("/:code", requireAuth, currencyHandler.DeleteCurrency)
+		currencies.POST("/:code/restore", requireAuth, currencyHandler.RestoreCurrency)
+		currencies.POST("/:code/deactivate", requireAuth, currencyHandler.DeactivateCurrency)
+		currencies.POST("/:code/activate", requireAuth, currencyHandler.ActivateCurrency)
 
-	return router
-}
+		// Conversion endpoints
+		v1.GET("/convert", conversionHandler.Convert)
 
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		// Admin endpoints expose operational data, so all of them require a token
+		admin := v1.Group("/admin")
+		admin.Use(requireAuth, middleware.NoStore())
+		admin.GET("/report", adminHandler.GetReport)
+		admin.POST("/cache/rebuild", adminHandler.RebuildCache)
+		admin.POST("/cache/flush", adminHandler.FlushCache)
+		admin.POST("/rates/:from/:to/pin", rateHandler.PinRate)
+		admin.DELETE("/rates/:from/:to/pin", rateHandler.UnpinRate)
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+		// Exchange rate endpoints; lookups are public, refreshes require a token
+		rates := v1.Group("/rates")
+		rates.GET("", rateHandler.GetRate)
+		rates.GET("/pairs", rateHandler.ListPairs)
+		rates.GET("/matrix", conversionHandler.GetRateMatrix)
+		rates.POST("/refresh", requireAuth, middleware.NoStore(), rateHandler.RefreshRates)
+	}
 
-		c.Next()
+	// API v2 answers in a JSON:API style envelope; endpoints move here as
+	// they are ported, and v1 keeps serving the APIResponse envelope
+	v2 := router.Group("/api/v2")
+	v2.Use(identify, rateLimit, handler.APIVersion(2))
+	{
+		currencies := v2.Group("/currencies")
+		currencies.Use(middleware.CacheControl(cfg.HTTPCache.CurrenciesMaxAge))
+		currencies.GET("", currencyHandler.GetCurrenciesV2)
 	}
+	
+	// File uploads are multipart, so they are registered outside the JSON-only v1 group
+	uploads := router.Group("/api/v1/currencies")
+	uploads.Use(identify, rateLimit, middleware.NoStore())
+	uploads.POST("/import", requireAuth, currencyHandler.ImportCurrencies)
+	rateUploads := router.Group("/api/v1/rates")
+	rateUploads.Use(identify, rateLimit, middleware.NoStore())
+	rateUploads.POST("/import", requireAuth, rateHandler.ImportRates)
+
+	return router
 }
\ No newline at end of file
@@ -0,0 +1,498 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/config"
+	"github.com/Tarifsiz/go-currency-api/internal/database"
+	"github.com/Tarifsiz/go-currency-api/internal/handler"
+	"github.com/Tarifsiz/go-currency-api/internal/metrics"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// buildRouter sets up the router for cfg with handlers that have no services
+// behind them, which is enough to inspect how it was configured
+func buildRouter(t *testing.T, cfg *config.Config) *gin.Engine {
+	t.Helper()
+	return buildRouterWithRedis(t, cfg, "localhost:0")
+}
+
+func buildRouterWithRedis(t *testing.T, cfg *config.Config, redisAddr string) *gin.Engine {
+	t.Helper()
+	return buildRouterWithCurrencies(t, cfg, redisAddr, nil, nil)
+}
+
+// buildRouterWithCurrencies sets up the router for cfg with the given
+// currency and translation services behind the currency handler
+func buildRouterWithCurrencies(t *testing.T, cfg *config.Config, redisAddr string, currencies service.CurrencyServiceInterface, translations service.TranslationServiceInterface) *gin.Engine {
+	t.Helper()
+	redisClient := redis.NewClient(&redis.Options{Addr: redisAddr})
+	t.Cleanup(func() { redisClient.Close() })
+
+	return setupRouter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), redisClient,
+		handler.NewCurrencyHandler(currencies, translations, cfg.Pagination),
+		handler.NewConversionHandler(nil),
+		handler.NewAdminHandler(nil),
+		handler.NewRateHandler(nil, nil, cfg.Pagination),
+		handler.NewHealthHandler(nil, ""),
+	)
+}
+
+func TestSetupRouterFollowsGinMode(t *testing.T) {
+	defer gin.SetMode(gin.Mode())
+	// Debug mode prints every registered route
+	defaultWriter := gin.DefaultWriter
+	gin.DefaultWriter = io.Discard
+	defer func() { gin.DefaultWriter = defaultWriter }()
+
+	// Debug comes after release so it can't pass by being gin's default
+	for _, mode := range []string{gin.ReleaseMode, gin.DebugMode, gin.TestMode} {
+		t.Run(mode, func(t *testing.T) {
+			t.Setenv("GIN_MODE", mode)
+			t.Setenv("DB_PASSWORD", "secret")
+
+			cfg, err := config.Load()
+			require.NoError(t, err)
+			buildRouter(t, cfg)
+
+			assert.Equal(t, mode, gin.Mode())
+		})
+	}
+}
+
+func TestSetupRouterServesHeadForCurrencyCodes(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	handlers := map[string]string{}
+	for _, route := range buildRouter(t, cfg).Routes() {
+		if route.Path == "/api/v1/currencies/:code" {
+			handlers[route.Method] = route.Handler
+		}
+	}
+
+	require.Contains(t, handlers, http.MethodHead)
+	assert.Equal(t, handlers[http.MethodGet], handlers[http.MethodHead])
+	assert.Contains(t, cfg.CORS.AllowedMethods, http.MethodHead)
+}
+
+func TestSetupRouterRateLimitsUsersBehindOneIPSeparately(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("RATE_LIMIT_RPM", "1")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	router := buildRouterWithRedis(t, cfg, miniredis.RunT(t).Addr())
+
+	get := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/currencies/schema", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	alice, bob := signedToken(t, cfg.Auth.JWTSecret), signedToken(t, cfg.Auth.JWTSecret)
+
+	assert.Equal(t, http.StatusOK, get(alice))
+	assert.Equal(t, http.StatusTooManyRequests, get(alice))
+	assert.Equal(t, http.StatusOK, get(bob))
+	assert.Equal(t, http.StatusOK, get(""))
+	assert.Equal(t, http.StatusTooManyRequests, get(""))
+}
+
+func signedToken(t *testing.T, secret string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   uuid.NewString(),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	signed, err := token.SignedString([]byte(secret))
+	require.NoError(t, err)
+	return signed
+}
+
+func TestSetupRouterRequiresAuthForCurrencyAudit(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_SECRET", "test-secret")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	router := buildRouter(t, cfg)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/currencies/USD/audit", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSetupRouterRequiresAuthForRateImport(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_SECRET", "test-secret")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	router := buildRouter(t, cfg)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/rates/import", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSetupRouterRequiresAuthForRatePinning(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_SECRET", "test-secret")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	router := buildRouter(t, cfg)
+
+	for _, method := range []string{http.MethodPost, http.MethodDelete} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, "/api/v1/admin/rates/USD/EUR/pin", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, method)
+	}
+}
+
+func TestSetupRouterRequiresAuthForCacheFlush(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_SECRET", "test-secret")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	router := buildRouter(t, cfg)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/admin/cache/flush", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSetupRouterSetsCacheControlPerEndpointGroup(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("CACHE_CONTROL_CURRENCIES_MAX_AGE", "120")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	router := buildRouter(t, cfg)
+
+	serveWithToken := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+signedToken(t, cfg.Auth.JWTSecret))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Currency reads use the configured max-age
+	w := serveWithToken(http.MethodGet, "/api/v1/currencies/schema")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=120", w.Header().Get("Cache-Control"))
+
+	// Mutating and operational endpoints are never cached
+	w = serveWithToken(http.MethodPost, "/api/v1/rates/refresh")
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	w = serveWithToken(http.MethodPost, "/api/v1/rates/import")
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+}
+
+// crudCurrencyService is a CurrencyServiceInterface holding just USD,
+// enough to exercise the currency CRUD routes
+type crudCurrencyService struct {
+	service.CurrencyServiceInterface
+}
+
+func (crudCurrencyService) GetCurrencyByCode(ctx context.Context, code string) (*model.Currency, error) {
+	if code != "USD" {
+		return nil, apperrors.ErrCurrencyNotFound
+	}
+	return &model.Currency{ID: uuid.New(), Code: "USD", Description: "US Dollar", Factor: 100, IsActive: true, Version: 1}, nil
+}
+
+func (crudCurrencyService) CreateCurrency(ctx context.Context, currency *model.Currency) error {
+	return nil
+}
+
+func (crudCurrencyService) UpdateCurrency(ctx context.Context, currency *model.Currency) error {
+	return nil
+}
+
+func (crudCurrencyService) DeleteCurrency(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+type noTranslations struct {
+	service.TranslationServiceInterface
+}
+
+func (noTranslations) Localize(ctx context.Context, currencies []*model.Currency, locales []string) (map[string]*model.CurrencyTranslation, error) {
+	return nil, nil
+}
+
+func TestSetupRouterServesCurrencyCRUD(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_SECRET", "test-secret")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	router := buildRouterWithCurrencies(t, cfg, miniredis.RunT(t).Addr(), crudCurrencyService{}, noTranslations{})
+	token := signedToken(t, cfg.Auth.JWTSecret)
+
+	create := `{"code":"EUR","description":"Euro"}`
+	update := `{"version":1,"description":"US Dollar","amount_display_format":"###,###.##","factor":100,"rounding_mode":"half_even"}`
+	for _, tc := range []struct {
+		method, path, body string
+		auth               bool
+		want               int
+	}{
+		{http.MethodGet, "/api/v1/currencies/USD", "", false, http.StatusOK},
+		{http.MethodGet, "/api/v1/currencies/XYZ", "", false, http.StatusNotFound},
+		{http.MethodPost, "/api/v1/currencies", create, true, http.StatusCreated},
+		{http.MethodPost, "/api/v1/currencies", create, false, http.StatusUnauthorized},
+		{http.MethodPut, "/api/v1/currencies/USD", update, true, http.StatusOK},
+		{http.MethodPut, "/api/v1/currencies/XYZ", update, true, http.StatusNotFound},
+		{http.MethodPut, "/api/v1/currencies/USD", update, false, http.StatusUnauthorized},
+		{http.MethodDelete, "/api/v1/currencies/USD", "", true, http.StatusOK},
+		{http.MethodDelete, "/api/v1/currencies/XYZ", "", true, http.StatusNotFound},
+		{http.MethodDelete, "/api/v1/currencies/USD", "", false, http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		if tc.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if tc.auth {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tc.want, w.Code, "%s %s auth=%v: %s", tc.method, tc.path, tc.auth, w.Body.String())
+	}
+
+	// Browsers can preflight every verb
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete} {
+		req := httptest.NewRequest(http.MethodOptions, "/api/v1/currencies/USD", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", method)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code, method)
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), method)
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestSetupRouterServesMetricsWhenEnabled(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Metrics.Enabled, "off by default")
+
+	w := httptest.NewRecorder()
+	buildRouter(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	t.Setenv("METRICS_ENABLED", "true")
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	// main enables collection along with the route
+	metrics.Enable()
+	router := buildRouter(t, cfg)
+
+	for _, path := range []string{"/api/v1/currencies/schema", "/api/v1/currencies/schema", "/no/such/route"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	for _, want := range []string{
+		`currency_api_http_requests_total{method="GET",path="/api/v1/currencies/schema",status="200"} 2`,
+		`currency_api_http_requests_total{method="GET",path="unmatched",status="404"} 1`,
+		`currency_api_http_request_duration_seconds_count{method="GET",path="/api/v1/currencies/schema"} 2`,
+	} {
+		assert.Contains(t, body, want)
+	}
+	// Unmatched URLs share one label rather than adding a series each
+	assert.NotContains(t, body, `path="/no/such/route"`)
+}
+
+func TestSetupRouterCompressesAllButProbesAndScrapes(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("METRICS_ENABLED", "true")
+	t.Setenv("GZIP_MIN_LENGTH", "1")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	metrics.Enable()
+	router := buildRouter(t, cfg)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("Origin", "https://app.example.com")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/api/v1/currencies/schema")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+
+	w = get("/health/live")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+
+	// The Prometheus handler negotiates compression itself, so the scrape
+	// is gzipped exactly once
+	w = get("/metrics")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	reader, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	scrape, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(scrape), "# HELP "), string(scrape))
+}
+
+func TestCloseAllRunsEveryCloser(t *testing.T) {
+	var output strings.Builder
+	log.SetOutput(&output)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	var closed []string
+	closing := func(name string, err error) closer {
+		return closer{name, func() error {
+			closed = append(closed, name)
+			return err
+		}}
+	}
+
+	// A failing close is logged and the rest still run
+	closeAll(
+		closing("database", errors.New("connection reset")),
+		closing("redis", nil),
+	)
+
+	assert.Equal(t, []string{"database", "redis"}, closed)
+	assert.Contains(t, output.String(), "Failed to close database: connection reset")
+	assert.NotContains(t, output.String(), "Failed to close redis")
+}
+
+func TestCloseAllClosesDatabaseAndRedis(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	redisClient := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	require.NoError(t, redisClient.Ping(context.Background()).Err())
+
+	closeAll(
+		closer{"database", func() error { return database.CloseConnection(db) }},
+		closer{"redis", redisClient.Close},
+	)
+
+	assert.ErrorContains(t, sqlDB.Ping(), "database is closed")
+	assert.ErrorIs(t, redisClient.Ping(context.Background()).Err(), redis.ErrClosed)
+}
+
+// openAPIPath turns a gin route path such as /currencies/:code into its
+// OpenAPI form, /currencies/{code}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func TestSetupRouterServesOpenAPISpec(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	router := buildRouter(t, cfg)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var spec struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.True(t, strings.HasPrefix(spec.OpenAPI, "3."), spec.OpenAPI)
+	for _, schema := range []string{"CreateCurrencyRequest", "APIResponse", "ErrorResponse", "PaginationResponse", "Currency"} {
+		assert.Contains(t, spec.Components.Schemas, schema)
+	}
+
+	// Every API and health route is documented, and every documented
+	// operation is routed
+	routed := make(map[string]bool)
+	for _, route := range router.Routes() {
+		if route.Method == http.MethodOptions || !(strings.HasPrefix(route.Path, "/api/") || strings.HasPrefix(route.Path, "/health")) {
+			continue
+		}
+		path := openAPIPath(route.Path)
+		routed[route.Method+" "+path] = true
+		assert.Contains(t, spec.Paths[path], strings.ToLower(route.Method), "%s %s is not documented", route.Method, path)
+	}
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			assert.True(t, routed[strings.ToUpper(method)+" "+path], "%s %s is documented but not routed", method, path)
+		}
+	}
+
+	// Every schema reference resolves
+	for _, ref := range regexp.MustCompile(`"\$ref":\s*"#/components/schemas/([^"]+)"`).FindAllStringSubmatch(w.Body.String(), -1) {
+		assert.Contains(t, spec.Components.Schemas, ref[1])
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "/openapi.json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/swagger/", w.Header().Get("Location"))
+}
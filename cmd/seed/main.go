@@ -0,0 +1,99 @@
+// Command seed populates the currencies table with the major ISO 4217
+// currencies. It is idempotent: codes that already exist are skipped.
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/config"
+	"github.com/Tarifsiz/go-currency-api/internal/database"
+	"github.com/Tarifsiz/go-currency-api/internal/logging"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// seedData holds the currencies to seed
+//
+//go:embed currencies.json
+var seedData []byte
+
+// systemUserID is recorded as the creator of seeded currencies. It matches
+// the user used by the SQL seed migration.
+var systemUserID = uuid.MustParse("1609b0e1-30c4-402c-a76e-8f5b4d6cfc24")
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+	slog.SetDefault(logging.New(cfg.Log.Level))
+
+	db, err := database.NewPostgresConnection(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer database.CloseConnection(db)
+
+	currencyRepo := repository.NewCurrencyRepository(db, database.RetryPolicy{
+		MaxRetries: cfg.Database.TxMaxRetries,
+		Backoff:    cfg.Database.TxRetryBackoff,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	created, skipped, err := seed(ctx, currencyRepo)
+	if err != nil {
+		log.Fatal("Failed to seed currencies:", err)
+	}
+
+	log.Printf("Seeded currencies: %d created, %d already present", created, skipped)
+}
+
+// seed inserts every seed currency whose code doesn't exist yet in a single
+// batch and returns the number of currencies created and skipped
+func seed(ctx context.Context, currencyRepo repository.CurrencyRepositoryInterface) (int, int, error) {
+	var currencies []*model.Currency
+	if err := json.Unmarshal(seedData, &currencies); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse seed data: %w", err)
+	}
+
+	codes := make([]string, len(currencies))
+	for i, currency := range currencies {
+		codes[i] = currency.Code
+	}
+
+	existing, err := currencyRepo.GetByCodes(ctx, codes)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to check existing currencies: %w", err)
+	}
+	present := make(map[string]bool, len(existing))
+	for _, currency := range existing {
+		// Codes may have been stored in another case
+		present[model.CanonicalCode(currency.Code)] = true
+	}
+
+	var missing []*model.Currency
+	for _, currency := range currencies {
+		if present[currency.Code] {
+			continue
+		}
+		currency.CreatedBy = systemUserID
+		missing = append(missing, currency)
+	}
+
+	if len(missing) > 0 {
+		if err := currencyRepo.CreateBatch(ctx, missing); err != nil {
+			return 0, 0, fmt.Errorf("failed to create currencies: %w", err)
+		}
+	}
+
+	return len(missing), len(currencies) - len(missing), nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/money"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCurrencyRepo stores currencies in memory, rejecting a code that
+// already exists in any case the way the unique index does. Methods the
+// seed doesn't use are left to the embedded nil interface.
+type fakeCurrencyRepo struct {
+	repository.CurrencyRepositoryInterface
+
+	currencies []*model.Currency
+}
+
+func (r *fakeCurrencyRepo) GetByCodes(ctx context.Context, codes []string) ([]*model.Currency, error) {
+	var found []*model.Currency
+	for _, code := range codes {
+		if currency := r.find(code); currency != nil {
+			found = append(found, currency)
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeCurrencyRepo) CreateBatch(ctx context.Context, currencies []*model.Currency) error {
+	for i, currency := range currencies {
+		if r.find(currency.Code) != nil {
+			return &repository.BatchError{Index: i, Code: currency.Code, Err: fmt.Errorf("%w: %s", apperrors.ErrDuplicateCurrency, currency.Code)}
+		}
+	}
+	r.currencies = append(r.currencies, currencies...)
+	return nil
+}
+
+func (r *fakeCurrencyRepo) find(code string) *model.Currency {
+	for _, currency := range r.currencies {
+		if strings.EqualFold(currency.Code, code) {
+			return currency
+		}
+	}
+	return nil
+}
+
+func seedCount(t *testing.T) int {
+	t.Helper()
+	var currencies []*model.Currency
+	require.NoError(t, json.Unmarshal(seedData, &currencies))
+	return len(currencies)
+}
+
+func TestSeedTwiceCreatesNoDuplicates(t *testing.T) {
+	repo := &fakeCurrencyRepo{}
+	total := seedCount(t)
+
+	created, skipped, err := seed(context.Background(), repo)
+	require.NoError(t, err)
+	assert.Equal(t, total, created)
+	assert.Zero(t, skipped)
+
+	created, skipped, err = seed(context.Background(), repo)
+	require.NoError(t, err)
+	assert.Zero(t, created)
+	assert.Equal(t, total, skipped)
+
+	seen := map[string]bool{}
+	for _, currency := range repo.currencies {
+		assert.False(t, seen[currency.Code], "%s seeded twice", currency.Code)
+		seen[currency.Code] = true
+	}
+	assert.Len(t, repo.currencies, total)
+}
+
+func TestSeedSkipsExistingCodesInAnyCase(t *testing.T) {
+	repo := &fakeCurrencyRepo{currencies: []*model.Currency{{Code: "usd", Description: "Kept"}}}
+
+	created, skipped, err := seed(context.Background(), repo)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped)
+	assert.Equal(t, seedCount(t)-1, created)
+	assert.Equal(t, "Kept", repo.find("USD").Description)
+}
+
+func TestSeedDataIsValid(t *testing.T) {
+	var currencies []*model.Currency
+	require.NoError(t, json.Unmarshal(seedData, &currencies))
+	require.NotEmpty(t, currencies)
+
+	for _, currency := range currencies {
+		code, err := model.NormalizeCode(currency.Code)
+		require.NoError(t, err)
+		assert.Equal(t, code, currency.Code)
+		assert.NotEmpty(t, currency.Description, code)
+		assert.NotEmpty(t, currency.HtmlEncodedSymbol, code)
+		places, ok := model.ISO4217MinorUnits(code)
+		require.True(t, ok, "%s isn't an ISO 4217 code", code)
+		assert.Equal(t, int32(places), money.DecimalPlaces(currency.Factor), code)
+	}
+}
@@ -0,0 +1,23 @@
+// Package apperrors defines the sentinel errors shared by the repository,
+// service and handler layers. Lower layers wrap them with context using
+// fmt.Errorf("%w ...") and handlers map them to HTTP statuses with errors.Is.
+package apperrors
+
+import "errors"
+
+var (
+	// ErrInvalidCurrencyCode is returned for currency codes that are not
+	// three letters A-Z
+	ErrInvalidCurrencyCode = errors.New("invalid currency code")
+	// ErrCurrencyNotFound is returned when a looked up currency does not exist
+	ErrCurrencyNotFound = errors.New("currency not found")
+	// ErrCurrencyInactive is returned when an operation needs an active
+	// currency but the currency has been deactivated
+	ErrCurrencyInactive = errors.New("currency is inactive")
+	// ErrDuplicateCurrency is returned when a live currency with the same code already exists
+	ErrDuplicateCurrency = errors.New("currency already exists")
+	// ErrStaleUpdate is returned when an update was based on an outdated version of a record
+	ErrStaleUpdate = errors.New("record was modified by another request")
+	// ErrExchangeRateNotFound is returned when no rate is stored for a currency pair
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+)
@@ -1,30 +1,95 @@
 package config
 
 import (
+	"compress/gzip"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Redis      RedisConfig
+	HTTPCache  HTTPCacheConfig
+	Pagination PaginationConfig
+	Currency   CurrencyConfig
+	Cache      CacheConfig
+	Log        LogConfig
+	Auth       AuthConfig
+	Metrics    MetricsConfig
+	Rates      RatesConfig
+	CORS       CORSConfig
+	Health     HealthConfig
 }
 
 type ServerConfig struct {
-	Port int
-	Host string
-	Mode string
+	Port                  int
+	Host                  string
+	Mode                  string        // Gin mode: "debug", "release" or "test"
+	MaxConcurrentRequests int           // 0 disables the limit
+	QueueTimeout          time.Duration // 0 rejects immediately when at the limit
+	RateLimitRPM          int           // Requests per minute per client; 0 disables rate limiting
+	GzipLevel             int           // 1 (fastest) to 9 (smallest), -1 for the gzip default; 0 disables compression
+	GzipMinLength         int           // Responses shorter than this many bytes are not compressed
+	IdempotencyTTL        time.Duration // How long Idempotency-Key responses are kept; 0 disables idempotency keys
+}
+
+// validate checks the listen port, gin mode, request limits and response
+// compression settings
+func (c *ServerConfig) validate() error {
+	if c.Port < 1 || c.Port > 65535 {
+		return fmt.Errorf("SERVER_PORT must be between 1 and 65535")
+	}
+	switch c.Mode {
+	case "debug", "release", "test":
+	default:
+		return fmt.Errorf("invalid GIN_MODE %q, must be one of: debug, release, test", c.Mode)
+	}
+	if c.MaxConcurrentRequests < 0 {
+		return fmt.Errorf("MAX_CONCURRENT_REQUESTS must not be negative")
+	}
+	if c.QueueTimeout < 0 {
+		return fmt.Errorf("CONCURRENCY_QUEUE_TIMEOUT_MS must not be negative")
+	}
+	if c.RateLimitRPM < 0 {
+		return fmt.Errorf("RATE_LIMIT_RPM must not be negative")
+	}
+	if c.IdempotencyTTL < 0 {
+		return fmt.Errorf("IDEMPOTENCY_TTL must not be negative")
+	}
+	if c.GzipLevel < gzip.DefaultCompression || c.GzipLevel > gzip.BestCompression {
+		return fmt.Errorf("GZIP_LEVEL must be between -1 and 9")
+	}
+	if c.GzipMinLength < 0 {
+		return fmt.Errorf("GZIP_MIN_LENGTH must not be negative")
+	}
+	return nil
 }
 
 type DatabaseConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Host           string
+	Port           int
+	User           string
+	Password       string
+	DBName         string
+	SSLMode        string
+	SSLRootCert    string
+	SSLCert        string
+	SSLKey         string
+	TxMaxRetries   int
+	TxRetryBackoff time.Duration
+	RunMigrations  bool // Apply pending schema migrations on startup
+
+	// Connection pool
+	MaxOpenConns    int           // 0 means unlimited
+	MaxIdleConns    int           // 0 keeps no idle connections
+	ConnMaxLifetime time.Duration // 0 means connections are reused forever
+	ConnMaxIdleTime time.Duration // 0 means idle connections are never closed for idleness
 }
 
 type RedisConfig struct {
@@ -33,36 +98,399 @@ type RedisConfig struct {
 	DB       int
 }
 
+// HTTPCacheConfig holds the Cache-Control max-age (in seconds) per endpoint group
+type HTTPCacheConfig struct {
+	CurrenciesMaxAge int
+}
+
+// validate checks that the max-ages are not negative
+func (c *HTTPCacheConfig) validate() error {
+	if c.CurrenciesMaxAge < 0 {
+		return fmt.Errorf("CACHE_CONTROL_CURRENCIES_MAX_AGE must not be negative")
+	}
+	return nil
+}
+
+// PaginationConfig holds the page sizes of paginated list endpoints
+type PaginationConfig struct {
+	DefaultLimit int // Used when ?limit= is omitted or invalid
+	MaxLimit     int // Larger ?limit= values are capped to this
+}
+
+// validate checks that the default page size is within the maximum
+func (c *PaginationConfig) validate() error {
+	if c.MaxLimit < 1 {
+		return fmt.Errorf("PAGINATION_MAX_LIMIT must be at least 1")
+	}
+	if c.DefaultLimit < 1 || c.DefaultLimit > c.MaxLimit {
+		return fmt.Errorf("PAGINATION_DEFAULT_LIMIT must be between 1 and PAGINATION_MAX_LIMIT (%d)", c.MaxLimit)
+	}
+	return nil
+}
+
+// CurrencyConfig holds business rules applied by the currency service.
+//
+// MaxCurrencies caps the whole deployment. There is no per-tenant override
+// because currencies aren't scoped to a tenant: every caller shares one
+// currency table, so a deployment is a tenant and this is its cap.
+type CurrencyConfig struct {
+	MaxCurrencies  int    // 0 means unlimited
+	ValidationMode string // "iso" for codes on the ISO 4217 list or "lenient" for any three letters
+	StrictDefaults bool   // Reject omitted factor/format instead of defaulting
+	StrictISO      bool   // Only allow creating codes from the ISO 4217 list
+
+	FuzzySearchThreshold float64 // Minimum trigram similarity (0-1) for fuzzy search matches
+}
+
+// validate checks that the fuzzy search threshold is a similarity
+func (c *CurrencyConfig) validate() error {
+	if c.FuzzySearchThreshold < 0 || c.FuzzySearchThreshold > 1 {
+		return fmt.Errorf("FUZZY_SEARCH_THRESHOLD must be between 0 and 1")
+	}
+	return nil
+}
+
+// AuthConfig holds JWT authentication settings
+type AuthConfig struct {
+	JWTSecret string // HMAC secret used to verify HS256 bearer tokens
+}
+
+// Validate checks that authentication can be enforced. It is only required
+// by commands that serve authenticated routes.
+func (c *AuthConfig) Validate() error {
+	if c.JWTSecret == "" {
+		return fmt.Errorf("JWT_SECRET is required")
+	}
+	return nil
+}
+
+// RatesConfig holds exchange rate provider settings
+type RatesConfig struct {
+	ProviderURL     string        // Rates API endpoint; empty disables the refresh job
+	APIKey          string        // Sent as a bearer token when set
+	BaseCurrencies  []string      // Base currencies fetched on every refresh
+	RefreshInterval time.Duration // Time between refreshes
+	MaxBackoff      time.Duration // Longest time between refreshes while the provider keeps failing
+	Timeout         time.Duration // Maximum duration of one provider request
+	PivotCurrency   string        // Conversions without a rate of their own go through this currency; empty disables cross rates
+}
+
+// validate checks the pivot currency, and the refresh job settings when a
+// provider is configured
+func (c *RatesConfig) validate() error {
+	if c.PivotCurrency != "" && len(c.PivotCurrency) != 3 {
+		return fmt.Errorf("RATE_PIVOT_CURRENCY must be a three letter currency code")
+	}
+	if c.ProviderURL == "" {
+		return nil
+	}
+	if c.RefreshInterval <= 0 {
+		return fmt.Errorf("RATE_REFRESH_INTERVAL must be positive")
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("RATE_PROVIDER_TIMEOUT_MS must be positive")
+	}
+	if c.MaxBackoff < c.RefreshInterval {
+		return fmt.Errorf("RATE_REFRESH_MAX_BACKOFF must not be shorter than RATE_REFRESH_INTERVAL")
+	}
+	return nil
+}
+
+// CORSConfig holds the cross-origin request policy
+type CORSConfig struct {
+	AllowedOrigins []string // Origins allowed to call the API; "*" allows any origin without credentials
+	AllowedMethods []string // Methods announced in preflight responses
+	AllowedHeaders []string // Request headers announced in preflight responses
+}
+
+// HealthConfig holds health endpoint settings
+type HealthConfig struct {
+	DetailsToken string // Bearer token required by /health/details; empty leaves it open
+}
+
+// MetricsConfig holds Prometheus metrics settings
+type MetricsConfig struct {
+	Enabled bool // Collect metrics and serve them on /metrics
+}
+
+// LogConfig holds structured logging settings
+type LogConfig struct {
+	Level string // "debug", "info", "warn" or "error"
+}
+
+// validate checks that the log level is known
+func (c *LogConfig) validate() error {
+	switch strings.ToLower(c.Level) {
+	case "debug", "info", "warn", "error":
+		return nil
+	}
+	return fmt.Errorf("invalid LOG_LEVEL %q, must be one of: debug, info, warn, error", c.Level)
+}
+
+// CacheConfig holds Redis caching settings
+type CacheConfig struct {
+	TTL          time.Duration // 0 disables Redis caching entirely
+	HotCodes     []string      // Currency codes warmed when the cache is rebuilt
+	MaxListPages int           // Number of leading list pages cached per query; 0 disables list caching
+}
+
+// validate checks that the cache TTL and list cache size are not negative
+func (c *CacheConfig) validate() error {
+	if c.TTL < 0 {
+		return fmt.Errorf("CACHE_TTL_SECONDS must not be negative")
+	}
+	if c.MaxListPages < 0 {
+		return fmt.Errorf("CACHE_MAX_LIST_PAGES must not be negative")
+	}
+	return nil
+}
+
+// Load reads the configuration from the environment and validates it.
+// Malformed values and invalid settings are all reported in one error.
 func Load() (*Config, error) {
+	env := &envParser{}
 	cfg := &Config{
 		Server: ServerConfig{
-			Port: getEnvAsInt("SERVER_PORT", 8080),
-			Host: getEnv("SERVER_HOST", "localhost"),
-			Mode: getEnv("GIN_MODE", "release"),
+			Port:                  env.int("SERVER_PORT", 8080),
+			Host:                  getEnv("SERVER_HOST", "localhost"),
+			Mode:                  getEnv("GIN_MODE", "release"),
+			MaxConcurrentRequests: env.int("MAX_CONCURRENT_REQUESTS", 0),
+			QueueTimeout:          time.Duration(env.int("CONCURRENCY_QUEUE_TIMEOUT_MS", 0)) * time.Millisecond,
+			RateLimitRPM:          env.int("RATE_LIMIT_RPM", 0),
+			GzipLevel:             env.int("GZIP_LEVEL", gzip.DefaultCompression),
+			GzipMinLength:         env.int("GZIP_MIN_LENGTH", 1024),
+			IdempotencyTTL:        env.duration("IDEMPOTENCY_TTL", 24*time.Hour),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvAsInt("DB_PORT", 5432),
-			User:     getEnv("DB_USER", "currency_user"),
-			Password: getEnv("DB_PASSWORD", "currency_pass"),
-			DBName:   getEnv("DB_NAME", "currency_db"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:           getEnv("DB_HOST", "localhost"),
+			Port:           env.int("DB_PORT", 5432),
+			User:           getEnv("DB_USER", "currency_user"),
+			Password:       getEnv("DB_PASSWORD", "currency_pass"),
+			DBName:         getEnv("DB_NAME", "currency_db"),
+			SSLMode:        getEnv("DB_SSLMODE", "disable"),
+			SSLRootCert:    getEnv("DB_SSL_ROOT_CERT", ""),
+			SSLCert:        getEnv("DB_SSL_CERT", ""),
+			SSLKey:         getEnv("DB_SSL_KEY", ""),
+			TxMaxRetries:   env.int("DB_TX_MAX_RETRIES", 3),
+			TxRetryBackoff: time.Duration(env.int("DB_TX_RETRY_BACKOFF_MS", 50)) * time.Millisecond,
+			RunMigrations:  env.bool("RUN_MIGRATIONS", false),
+
+			MaxOpenConns:    env.int("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    env.int("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetime: env.duration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+			ConnMaxIdleTime: env.duration("DB_CONN_MAX_IDLE_TIME", time.Minute),
 		},
 		Redis: RedisConfig{
 			Addr:     getEnv("REDIS_ADDR", "localhost:6379"),
 			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnvAsInt("REDIS_DB", 0),
+			DB:       env.int("REDIS_DB", 0),
+		},
+		HTTPCache: HTTPCacheConfig{
+			// Aligned with the 15 minute Redis cache TTL
+			CurrenciesMaxAge: env.int("CACHE_CONTROL_CURRENCIES_MAX_AGE", 900),
+		},
+		Pagination: PaginationConfig{
+			DefaultLimit: env.int("PAGINATION_DEFAULT_LIMIT", 50),
+			MaxLimit:     env.int("PAGINATION_MAX_LIMIT", 100),
+		},
+		Currency: CurrencyConfig{
+			MaxCurrencies:  env.int("MAX_CURRENCIES", 0),
+			ValidationMode: getEnv("CURRENCY_VALIDATION_MODE", "lenient"),
+			StrictDefaults: env.bool("STRICT_DEFAULTS", false),
+			StrictISO:      env.bool("STRICT_ISO_VALIDATION", false),
+
+			FuzzySearchThreshold: env.float("FUZZY_SEARCH_THRESHOLD", 0.3),
+		},
+		Cache: CacheConfig{
+			TTL:          time.Duration(env.int("CACHE_TTL_SECONDS", 900)) * time.Second,
+			HotCodes:     getEnvAsSlice("CACHE_HOT_CODES", []string{"USD", "EUR", "GBP"}),
+			MaxListPages: env.int("CACHE_MAX_LIST_PAGES", 10),
+		},
+		Log: LogConfig{
+			Level: getEnv("LOG_LEVEL", "info"),
+		},
+		Auth: AuthConfig{
+			JWTSecret: getEnv("JWT_SECRET", ""),
+		},
+		Metrics: MetricsConfig{
+			Enabled: env.bool("METRICS_ENABLED", false),
+		},
+		Health: HealthConfig{
+			DetailsToken: getEnv("HEALTH_CHECK_TOKEN", ""),
+		},
+		Rates: RatesConfig{
+			ProviderURL:     getEnv("RATE_PROVIDER_URL", ""),
+			APIKey:          getEnv("RATE_PROVIDER_API_KEY", ""),
+			BaseCurrencies:  getEnvAsSlice("RATE_BASE_CURRENCIES", []string{"USD"}),
+			RefreshInterval: env.duration("RATE_REFRESH_INTERVAL", time.Hour),
+			MaxBackoff:      env.duration("RATE_REFRESH_MAX_BACKOFF", 6*time.Hour),
+			Timeout:         time.Duration(env.int("RATE_PROVIDER_TIMEOUT_MS", 10000)) * time.Millisecond,
+			PivotCurrency:   strings.ToUpper(strings.TrimSpace(getEnv("RATE_PIVOT_CURRENCY", "USD"))),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: getEnvAsSlice("CORS_ALLOWED_ORIGINS", []string{"*"}),
+			AllowedMethods: getEnvAsSlice("CORS_ALLOWED_METHODS", []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}),
+			AllowedHeaders: getEnvAsSlice("CORS_ALLOWED_HEADERS", []string{
+				"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Origin",
+				"Cache-Control", "X-Requested-With", "X-Request-ID", "If-None-Match", "If-Match", "Idempotency-Key",
+			}),
 		},
 	}
 
+	errs := env.errs
+	// DATABASE_URL, as injected by most PaaS platforms, takes precedence
+	// over the individual DB_* settings
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL != "" {
+		if err := cfg.Database.applyURL(databaseURL); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// Production must not run with the development database password
+	if cfg.Server.Mode == "release" && databaseURL == "" && os.Getenv("DB_PASSWORD") == "" {
+		errs = append(errs, fmt.Errorf("DB_PASSWORD must be set explicitly when GIN_MODE is release"))
+	}
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	return cfg, nil
 }
 
+// Validate checks that the configuration is complete and consistent. The
+// first problem of each section is reported.
+func (c *Config) Validate() error {
+	return errors.Join(
+		c.Server.validate(),
+		c.Database.validate(),
+		c.HTTPCache.validate(),
+		c.Pagination.validate(),
+		c.Currency.validate(),
+		c.Cache.validate(),
+		c.Log.validate(),
+		c.Rates.validate(),
+	)
+}
+
 func (c *DatabaseConfig) GetDSN() string {
-	return fmt.Sprintf(
+	dsn := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode,
+		dsnValue(c.Host), c.Port, dsnValue(c.User), dsnValue(c.Password), dsnValue(c.DBName), dsnValue(c.SSLMode),
 	)
+	if c.SSLRootCert != "" {
+		dsn += " sslrootcert=" + dsnValue(c.SSLRootCert)
+	}
+	if c.SSLCert != "" {
+		dsn += " sslcert=" + dsnValue(c.SSLCert)
+	}
+	if c.SSLKey != "" {
+		dsn += " sslkey=" + dsnValue(c.SSLKey)
+	}
+	return dsn
+}
+
+// dsnValue quotes a keyword/value DSN value when it is empty or contains
+// spaces, quotes or backslashes, e.g. a password decoded from DATABASE_URL
+func dsnValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "'" + escaped + "'"
+}
+
+// applyURL overrides the connection settings with those of a postgres:// or
+// postgresql:// URL such as DATABASE_URL. The port defaults to 5432; the
+// sslmode, sslrootcert, sslcert and sslkey query parameters override their
+// fields when present.
+func (c *DatabaseConfig) applyURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		// url.Error repeats the whole URL, password included
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("invalid DATABASE_URL: %w", err)
+	}
+	if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+		return fmt.Errorf("invalid DATABASE_URL scheme %q, must be postgres or postgresql", u.Scheme)
+	}
+
+	c.Host = u.Hostname()
+	c.Port = 5432
+	if port := u.Port(); port != "" {
+		if c.Port, err = strconv.Atoi(port); err != nil {
+			return fmt.Errorf("invalid DATABASE_URL port %q", port)
+		}
+	}
+	c.User = u.User.Username()
+	c.Password, _ = u.User.Password()
+	c.DBName = strings.TrimPrefix(u.Path, "/")
+
+	query := u.Query()
+	for param, field := range map[string]*string{
+		"sslmode":     &c.SSLMode,
+		"sslrootcert": &c.SSLRootCert,
+		"sslcert":     &c.SSLCert,
+		"sslkey":      &c.SSLKey,
+	} {
+		if value := query.Get(param); value != "" {
+			*field = value
+		}
+	}
+
+	return nil
+}
+
+// validate checks that the connection settings are complete
+func (c *DatabaseConfig) validate() error {
+	switch {
+	case c.Host == "":
+		return fmt.Errorf("DB_HOST must not be empty")
+	case c.Port < 1 || c.Port > 65535:
+		return fmt.Errorf("DB_PORT must be between 1 and 65535")
+	case c.User == "":
+		return fmt.Errorf("DB_USER must not be empty")
+	case c.DBName == "":
+		return fmt.Errorf("DB_NAME must not be empty")
+	case c.TxMaxRetries < 0:
+		return fmt.Errorf("DB_TX_MAX_RETRIES must not be negative")
+	case c.MaxOpenConns < 0:
+		return fmt.Errorf("DB_MAX_OPEN_CONNS must not be negative")
+	case c.MaxIdleConns < 0:
+		return fmt.Errorf("DB_MAX_IDLE_CONNS must not be negative")
+	case c.ConnMaxLifetime < 0:
+		return fmt.Errorf("DB_CONN_MAX_LIFETIME must not be negative")
+	case c.ConnMaxIdleTime < 0:
+		return fmt.Errorf("DB_CONN_MAX_IDLE_TIME must not be negative")
+	case c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns:
+		return fmt.Errorf("DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", c.MaxIdleConns, c.MaxOpenConns)
+	}
+	return c.validateSSL()
+}
+
+// validateSSL checks that the SSL mode is known and that certificate
+// verifying modes have a root certificate to verify against
+func (c *DatabaseConfig) validateSSL() error {
+	switch c.SSLMode {
+	case "disable", "allow", "prefer", "require":
+	case "verify-ca", "verify-full":
+		if c.SSLRootCert == "" {
+			return fmt.Errorf("DB_SSL_ROOT_CERT is required when DB_SSLMODE is %s", c.SSLMode)
+		}
+	default:
+		return fmt.Errorf("invalid DB_SSLMODE %q", c.SSLMode)
+	}
+
+	if (c.SSLCert == "") != (c.SSLKey == "") {
+		return fmt.Errorf("DB_SSL_CERT and DB_SSL_KEY must be set together")
+	}
+
+	return nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -72,11 +500,82 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// envParser reads typed values from the environment. Malformed values fall
+// back to the default and are recorded, so Load can report them instead of
+// silently ignoring a typo.
+type envParser struct {
+	errs []error
+}
+
+func (p *envParser) lookup(key string) (string, bool) {
+	value := os.Getenv(key)
+	return value, value != ""
+}
+
+func (p *envParser) invalid(key, value, want string) {
+	p.errs = append(p.errs, fmt.Errorf("invalid %s %q, must be %s", key, value, want))
+}
+
+func (p *envParser) int(key string, defaultValue int) int {
+	if value, ok := p.lookup(key); ok {
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			p.invalid(key, value, "an integer")
+			return defaultValue
 		}
+		return intValue
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func (p *envParser) float(key string, defaultValue float64) float64 {
+	if value, ok := p.lookup(key); ok {
+		floatValue, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			p.invalid(key, value, "a number")
+			return defaultValue
+		}
+		return floatValue
+	}
+	return defaultValue
+}
+
+func (p *envParser) bool(key string, defaultValue bool) bool {
+	if value, ok := p.lookup(key); ok {
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			p.invalid(key, value, "true or false")
+			return defaultValue
+		}
+		return boolValue
+	}
+	return defaultValue
+}
+
+// duration parses a Go duration such as "30m" or "1h30m"
+func (p *envParser) duration(key string, defaultValue time.Duration) time.Duration {
+	if value, ok := p.lookup(key); ok {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			p.invalid(key, value, `a duration such as "30m"`)
+			return defaultValue
+		}
+		return duration
+	}
+	return defaultValue
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// undefinedFunctionCode is the Postgres SQLSTATE for undefined_function,
+// returned e.g. when an extension providing the function isn't installed
+const undefinedFunctionCode = "42883"
+
+// IsUndefinedFunction reports whether err is a Postgres undefined_function error
+func IsUndefinedFunction(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == undefinedFunctionCode
+}
+
+// uniqueViolationCode is the Postgres SQLSTATE for unique_violation
+const uniqueViolationCode = "23505"
+
+// IsUniqueViolation reports whether err is a Postgres unique_violation error
+func IsUniqueViolation(err error) bool {
+	_, ok := UniqueViolation(err)
+	return ok
+}
+
+// UniqueViolation returns the name of the violated constraint or unique index
+// when err is a Postgres unique_violation error
+func UniqueViolation(err error) (constraint string, ok bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolationCode {
+		return "", false
+	}
+	return pgErr.ConstraintName, true
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniqueViolation(t *testing.T) {
+	violation := &pgconn.PgError{Code: "23505", ConstraintName: "idx_currencies_code_active"}
+
+	for name, err := range map[string]error{
+		"unique violation": violation,
+		"wrapped":          fmt.Errorf("failed to create currency: %w", violation),
+	} {
+		constraint, ok := UniqueViolation(err)
+		assert.True(t, ok, name)
+		assert.Equal(t, "idx_currencies_code_active", constraint, name)
+		assert.True(t, IsUniqueViolation(err), name)
+	}
+
+	// The SQLSTATE decides, not the message text
+	for name, err := range map[string]error{
+		"other SQLSTATE":    &pgconn.PgError{Code: "23503", Message: "duplicate key value violates unique constraint"},
+		"message only":      errors.New(`duplicate key value violates unique constraint "idx_currencies_code_active"`),
+		"localized message": &pgconn.PgError{Code: "23505", Message: "doppelter Schlüsselwert verletzt Unique-Constraint"},
+		"nil":               nil,
+	} {
+		_, ok := UniqueViolation(err)
+		assert.Equal(t, name == "localized message", ok, name)
+	}
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratepgx "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"gorm.io/gorm"
+)
+
+// RunMigrations applies every pending versioned SQL migration in migrationsFS
+// and then auto-migrates the given models. The SQL migrations are the source
+// of truth for the schema and cover what AutoMigrate can't, such as
+// extensions and partial indexes; AutoMigrate then reconciles anything the
+// models declare on top.
+func RunMigrations(db *gorm.DB, migrationsFS fs.FS, models ...interface{}) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+
+	source, err := iofs.New(migrationsFS, ".")
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	driver, err := migratepgx.WithInstance(sqlDB, &migratepgx.Config{})
+	if err != nil {
+		return fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "pgx5", driver)
+	if err != nil {
+		return fmt.Errorf("failed to create migrator: %w", err)
+	}
+	m.Log = migrateLogger{}
+
+	from, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("schema version %d is dirty; fix the failed migration and force the version before restarting", from)
+	}
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	to, _, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	slog.Info("Database schema migrations completed", "from_version", from, "to_version", to)
+
+	return AutoMigrate(db, models...)
+}
+
+// migrateLogger routes golang-migrate's log output, including each applied
+// migration, through the default structured logger
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...interface{}) {
+	slog.Info("migrate: " + fmt.Sprintf(format, v...))
+}
+
+func (migrateLogger) Verbose() bool {
+	return true
+}
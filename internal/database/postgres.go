@@ -1,6 +1,8 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"time"
@@ -36,10 +38,10 @@ func NewPostgresConnection(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	}
 	
 	// Configure connection pool
-	sqlDB.SetMaxOpenConns(25)                 // Maximum number of open connections
-	sqlDB.SetMaxIdleConns(10)                 // Maximum number of idle connections
-	sqlDB.SetConnMaxLifetime(5 * time.Minute) // Maximum connection lifetime
-	sqlDB.SetConnMaxIdleTime(1 * time.Minute) // Maximum idle time
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)       // Maximum number of open connections
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)       // Maximum number of idle connections
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime) // Maximum connection lifetime
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime) // Maximum idle time
 	
 	// Test the connection
 	if err := sqlDB.Ping(); err != nil {
@@ -75,4 +77,27 @@ func CloseConnection(db *gorm.DB) error {
 	
 	log.Println("Database connection closed successfully")
 	return nil
+}
+
+// GetPoolStats returns the connection pool statistics of the underlying sql.DB
+func GetPoolStats(db *gorm.DB) (sql.DBStats, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return sql.DBStats{}, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+
+	return sqlDB.Stats(), nil
+}
+
+// Ping verifies the database is reachable within the deadline of ctx
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
 }
\ No newline at end of file
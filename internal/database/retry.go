@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/logging"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// serializationFailureCode is the Postgres SQLSTATE for serialization_failure
+const serializationFailureCode = "40001"
+
+// RetryPolicy controls how transactions are retried on serialization failures
+type RetryPolicy struct {
+	MaxRetries int           // Number of retries after the first attempt
+	Backoff    time.Duration // Initial delay, doubled after every retry
+}
+
+// IsSerializationFailure reports whether err is a Postgres serialization failure
+func IsSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == serializationFailureCode
+}
+
+// RunInTransaction runs fn inside a transaction, re-running the whole
+// transaction with exponential backoff when it fails with a serialization
+// failure. Any other error is returned immediately.
+func RunInTransaction(ctx context.Context, db *gorm.DB, policy RetryPolicy, fn func(tx *gorm.DB) error) error {
+	backoff := policy.Backoff
+	for attempt := 0; ; attempt++ {
+		err := db.WithContext(ctx).Transaction(fn)
+		if err == nil || !IsSerializationFailure(err) || attempt >= policy.MaxRetries {
+			return err
+		}
+
+		logging.FromContext(ctx).Warn("retrying transaction after serialization failure",
+			"attempt", attempt+1,
+			"backoff", backoff.String(),
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// txDriver is a database/sql driver whose connections only begin, commit
+// and roll back transactions, counting how many were started
+type txDriver struct {
+	begun atomic.Int32
+}
+
+func (d *txDriver) Open(name string) (driver.Conn, error) { return &txConn{driver: d}, nil }
+
+type txConn struct {
+	driver *txDriver
+}
+
+func (c *txConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("txConn doesn't run statements")
+}
+
+func (c *txConn) Close() error { return nil }
+
+func (c *txConn) Begin() (driver.Tx, error) {
+	c.driver.begun.Add(1)
+	return fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+// newTxTestDB returns a gorm DB over a txDriver
+func newTxTestDB(t *testing.T) (*gorm.DB, *txDriver) {
+	t.Helper()
+	drv := &txDriver{}
+	sqlDB := sql.OpenDB(connector{drv})
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return db, drv
+}
+
+type connector struct {
+	driver *txDriver
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open("") }
+func (c connector) Driver() driver.Driver                        { return c.driver }
+
+var errSerialization = &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+
+// failingFn returns fn failing with err the first failures times it runs
+// and succeeding afterwards, and a counter of how often it ran
+func failingFn(failures int, err error) (func(tx *gorm.DB) error, *int) {
+	calls := 0
+	return func(tx *gorm.DB) error {
+		calls++
+		if calls <= failures {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func TestRunInTransactionRetriesSerializationFailures(t *testing.T) {
+	db, drv := newTxTestDB(t)
+	fn, calls := failingFn(3, errSerialization)
+
+	err := RunInTransaction(context.Background(), db, RetryPolicy{MaxRetries: 5, Backoff: time.Millisecond}, fn)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, *calls)
+	// Every attempt runs in a transaction of its own
+	assert.Equal(t, int32(4), drv.begun.Load())
+}
+
+func TestRunInTransactionRetriesWrappedSerializationFailures(t *testing.T) {
+	db, _ := newTxTestDB(t)
+	fn, calls := failingFn(1, errors.Join(errors.New("failed to update"), errSerialization))
+
+	require.NoError(t, RunInTransaction(context.Background(), db, RetryPolicy{MaxRetries: 1, Backoff: time.Millisecond}, fn))
+	assert.Equal(t, 2, *calls)
+}
+
+func TestRunInTransactionStopsAtMaxRetries(t *testing.T) {
+	db, _ := newTxTestDB(t)
+	fn, calls := failingFn(10, errSerialization)
+
+	err := RunInTransaction(context.Background(), db, RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}, fn)
+
+	assert.ErrorIs(t, err, errSerialization)
+	assert.True(t, IsSerializationFailure(err))
+	assert.Equal(t, 3, *calls)
+}
+
+func TestRunInTransactionWithoutRetries(t *testing.T) {
+	db, _ := newTxTestDB(t)
+	fn, calls := failingFn(1, errSerialization)
+
+	assert.ErrorIs(t, RunInTransaction(context.Background(), db, RetryPolicy{}, fn), errSerialization)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestRunInTransactionReturnsOtherErrorsAtOnce(t *testing.T) {
+	for name, failure := range map[string]error{
+		"plain error":      errors.New("boom"),
+		"other SQLSTATE":   &pgconn.PgError{Code: "23505"},
+		"deadlock":         &pgconn.PgError{Code: "40P01"},
+		"record not found": gorm.ErrRecordNotFound,
+	} {
+		t.Run(name, func(t *testing.T) {
+			db, _ := newTxTestDB(t)
+			fn, calls := failingFn(1, failure)
+
+			err := RunInTransaction(context.Background(), db, RetryPolicy{MaxRetries: 5, Backoff: time.Millisecond}, fn)
+
+			assert.ErrorIs(t, err, failure)
+			assert.Equal(t, 1, *calls)
+		})
+	}
+}
+
+func TestRunInTransactionStopsWhenContextIsCancelled(t *testing.T) {
+	db, _ := newTxTestDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	fn := func(tx *gorm.DB) error {
+		calls++
+		cancel()
+		return errSerialization
+	}
+
+	start := time.Now()
+	err := RunInTransaction(ctx, db, RetryPolicy{MaxRetries: 5, Backoff: time.Hour}, fn)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+	assert.Less(t, time.Since(start), time.Minute, "waited out the backoff")
+}
+
+func TestRunInTransactionDoublesBackoff(t *testing.T) {
+	db, _ := newTxTestDB(t)
+	var starts []time.Time
+	fn := func(tx *gorm.DB) error {
+		starts = append(starts, time.Now())
+		if len(starts) < 4 {
+			return errSerialization
+		}
+		return nil
+	}
+
+	require.NoError(t, RunInTransaction(context.Background(), db, RetryPolicy{MaxRetries: 3, Backoff: 10 * time.Millisecond}, fn))
+
+	require.Len(t, starts, 4)
+	for i, want := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond} {
+		assert.GreaterOrEqual(t, starts[i+1].Sub(starts[i]), want, "retry %d", i+1)
+	}
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	assert.True(t, IsSerializationFailure(errSerialization))
+	assert.True(t, IsSerializationFailure(errors.Join(errors.New("wrapped"), errSerialization)))
+	assert.False(t, IsSerializationFailure(&pgconn.PgError{Code: "40P01"}))
+	assert.False(t, IsSerializationFailure(errors.New("40001")))
+	assert.False(t, IsSerializationFailure(nil))
+}
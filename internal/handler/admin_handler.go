@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Tarifsiz/go-currency-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler handles HTTP requests for operational endpoints
+type AdminHandler struct {
+	adminService service.AdminServiceInterface
+}
+
+// NewAdminHandler creates a new admin handler instance
+func NewAdminHandler(adminService service.AdminServiceInterface) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+// GetReport handles GET /api/v1/admin/report
+func (h *AdminHandler) GetReport(c *gin.Context) {
+	report, err := h.adminService.GetStatusReport(c.Request.Context())
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to build status report", err)
+		return
+	}
+
+	successResponse(c, report, "Status report generated successfully")
+}
+
+// RebuildCache handles POST /api/v1/admin/cache/rebuild
+func (h *AdminHandler) RebuildCache(c *gin.Context) {
+	result, err := h.adminService.RebuildCache(c.Request.Context())
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to rebuild cache", err)
+		return
+	}
+
+	successResponse(c, result, "Cache rebuilt successfully")
+}
+
+// FlushCache handles POST /api/v1/admin/cache/flush
+func (h *AdminHandler) FlushCache(c *gin.Context) {
+	result, err := h.adminService.FlushCache(c.Request.Context())
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to flush cache", err)
+		return
+	}
+
+	successResponse(c, result, "Cache flushed successfully")
+}
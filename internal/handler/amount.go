@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// maxAmountLength bounds the raw ?amount= string so parsing stays cheap
+	maxAmountLength = 64
+	// maxAmountIntegerDigits and maxAmountDecimalPlaces bound the magnitude
+	// and precision of an amount. Exponent notation such as 1e2000000 is
+	// short but would make every later multiplication and rounding huge.
+	maxAmountIntegerDigits = 18
+	maxAmountDecimalPlaces = 12
+)
+
+// errAmountOutOfRange is returned by parseAmount for well-formed amounts
+// with too many digits
+var errAmountOutOfRange = fmt.Errorf("amount must have at most %d integer digits and %d decimal places", maxAmountIntegerDigits, maxAmountDecimalPlaces)
+
+// parseAmount parses a decimal amount, rejecting amounts whose integer part
+// or decimal places exceed the limits above
+func parseAmount(raw string) (decimal.Decimal, error) {
+	if len(raw) > maxAmountLength {
+		return decimal.Decimal{}, errAmountOutOfRange
+	}
+	amount, err := decimal.NewFromString(raw)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	// Check the exponent before anything that rescales the coefficient
+	exp := amount.Exponent()
+	if exp > maxAmountIntegerDigits || exp < -maxAmountDecimalPlaces {
+		return decimal.Decimal{}, errAmountOutOfRange
+	}
+	if !amount.IsZero() && int64(amount.NumDigits())+int64(exp) > maxAmountIntegerDigits {
+		return decimal.Decimal{}, errAmountOutOfRange
+	}
+	return amount, nil
+}
+
+// amountFormat is how a locale writes numbers: the decimal separator and
+// the separators it groups thousands with
+type amountFormat struct {
+	decimal string
+	groups  []string
+}
+
+var (
+	// dotDecimal is used by locales such as en and ja: 1,234.56
+	dotDecimal = amountFormat{decimal: ".", groups: []string{","}}
+	// commaDecimal is used by most of continental Europe and Latin America:
+	// 1.234,56, or 1 234,56 with a space, no-break space or narrow
+	// no-break space
+	commaDecimal = amountFormat{decimal: ",", groups: []string{".", " ", "\u00a0", "\u202f"}}
+	// apostropheGroups is used in Switzerland and Liechtenstein: 1'234.56
+	apostropheGroups = amountFormat{decimal: ".", groups: []string{"'", "\u2019"}}
+)
+
+// amountFormatsByLanguage maps the language of a locale to its number
+// format. Locales whose language isn't listed can't be used to parse
+// amounts rather than risk reading 1.234 a thousand times off.
+var amountFormatsByLanguage = map[string]amountFormat{
+	"en": dotDecimal, "ja": dotDecimal, "zh": dotDecimal, "ko": dotDecimal,
+	"he": dotDecimal, "th": dotDecimal, "hi": dotDecimal, "ms": dotDecimal,
+	"de": commaDecimal, "fr": commaDecimal, "es": commaDecimal, "it": commaDecimal,
+	"pt": commaDecimal, "nl": commaDecimal, "ru": commaDecimal, "pl": commaDecimal,
+	"tr": commaDecimal, "sv": commaDecimal, "da": commaDecimal, "nb": commaDecimal,
+	"fi": commaDecimal, "cs": commaDecimal, "id": commaDecimal, "uk": commaDecimal,
+}
+
+// amountFormatsByRegion overrides the language's format in some regions
+var amountFormatsByRegion = map[string]amountFormat{
+	"CH": apostropheGroups, "LI": apostropheGroups,
+	"MX": dotDecimal,
+}
+
+// errUnsupportedAmountLocale is returned by parseLocalizedAmount for locales
+// whose number format isn't known
+var errUnsupportedAmountLocale = errors.New("amounts can't be parsed for this locale")
+
+// errAmountFormat is returned by parseLocalizedAmount for input that isn't a
+// number in the locale's format, including input that only another format
+// would read, such as 1234.56 for de
+var errAmountFormat = errors.New("amount doesn't match the locale's number format")
+
+// localeAmountFormat returns the number format of a locale such as de-CH
+func localeAmountFormat(locale string) (amountFormat, bool) {
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 {
+		return amountFormat{}, false
+	}
+	format, ok := amountFormatsByLanguage[strings.ToLower(parts[0])]
+	if !ok {
+		return amountFormat{}, false
+	}
+	for _, subtag := range parts[1:] {
+		if regional, ok := amountFormatsByRegion[strings.ToUpper(subtag)]; ok && len(subtag) == 2 {
+			return regional, true
+		}
+	}
+	return format, true
+}
+
+// parseLocalizedAmount parses an amount written the way locale writes
+// numbers, e.g. "1.234,56" for de or "1,234.56" for en, into a decimal.
+// Thousands groups must be three digits, so input that would mean something
+// else in another format is rejected rather than guessed at. Without a
+// locale amounts are strict dot-decimal, as parseAmount reads them.
+func parseLocalizedAmount(raw, locale string) (decimal.Decimal, error) {
+	if locale == "" {
+		return parseAmount(raw)
+	}
+	format, ok := localeAmountFormat(locale)
+	if !ok {
+		return decimal.Decimal{}, errUnsupportedAmountLocale
+	}
+	if len(raw) > maxAmountLength {
+		return decimal.Decimal{}, errAmountOutOfRange
+	}
+	canonical, err := canonicalAmount(strings.TrimSpace(raw), format)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	return parseAmount(canonical)
+}
+
+// canonicalAmount rewrites an amount in format as a plain dot-decimal number
+func canonicalAmount(raw string, format amountFormat) (string, error) {
+	sign := ""
+	if strings.HasPrefix(raw, "-") || strings.HasPrefix(raw, "+") {
+		sign, raw = raw[:1], raw[1:]
+	}
+
+	integer, fraction, hasFraction := strings.Cut(raw, format.decimal)
+	if hasFraction && (fraction == "" || !isDigits(fraction)) {
+		return "", errAmountFormat
+	}
+
+	digits, err := ungroup(integer, format.groups)
+	if err != nil {
+		return "", err
+	}
+	if hasFraction {
+		return sign + digits + "." + fraction, nil
+	}
+	return sign + digits, nil
+}
+
+// ungroup removes thousands separators from the integer part of an amount.
+// Only one of the separators may be used, the first group has one to three
+// digits and every later group exactly three.
+func ungroup(integer string, separators []string) (string, error) {
+	for _, separator := range separators {
+		if !strings.Contains(integer, separator) {
+			continue
+		}
+		groups := strings.Split(integer, separator)
+		if len(groups[0]) < 1 || len(groups[0]) > 3 {
+			return "", errAmountFormat
+		}
+		for _, group := range groups[1:] {
+			if len(group) != 3 {
+				return "", errAmountFormat
+			}
+		}
+		integer = strings.Join(groups, "")
+		break
+	}
+	if !isDigits(integer) {
+		return "", errAmountFormat
+	}
+	return integer, nil
+}
+
+// isDigits reports whether s is one or more ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ConversionHandler handles HTTP requests for currency conversion
+type ConversionHandler struct {
+	conversionService service.ConversionServiceInterface
+}
+
+// NewConversionHandler creates a new conversion handler instance
+func NewConversionHandler(conversionService service.ConversionServiceInterface) *ConversionHandler {
+	return &ConversionHandler{
+		conversionService: conversionService,
+	}
+}
+
+// Convert handles GET /api/v1/convert?from=USD&to=EUR&amount=100[&locale=de]
+func (h *ConversionHandler) Convert(c *gin.Context) {
+	from, err := model.NormalizeCode(c.Query("from"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return
+	}
+	to, err := model.NormalizeCode(c.Query("to"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return
+	}
+
+	// ?locale= lets clients pass amounts as users typed them, e.g. 1.234,56
+	locale := c.Query("locale")
+	if locale != "" && !isValidLocale(locale) {
+		errorResponse(c, http.StatusBadRequest, "Invalid locale, must be a language tag such as fr or fr-CA", nil)
+		return
+	}
+
+	amount, err := parseLocalizedAmount(c.Query("amount"), locale)
+	switch {
+	case errors.Is(err, errAmountOutOfRange):
+		errorResponse(c, http.StatusBadRequest, fmt.Sprintf("Amount must have at most %d integer digits and %d decimal places", maxAmountIntegerDigits, maxAmountDecimalPlaces), err)
+		return
+	case errors.Is(err, errUnsupportedAmountLocale):
+		errorResponse(c, http.StatusBadRequest, fmt.Sprintf("Amounts can't be parsed for locale %s", locale), err)
+		return
+	case errors.Is(err, errAmountFormat):
+		errorResponse(c, http.StatusBadRequest, fmt.Sprintf("Amount must be a number formatted for locale %s", locale), err)
+		return
+	case err != nil:
+		errorResponse(c, http.StatusBadRequest, "Amount must be a number", err)
+		return
+	}
+
+	result, err := h.conversionService.ConvertAmount(c.Request.Context(), from, to, amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAmount):
+			errorResponse(c, http.StatusBadRequest, "Amount must not be negative", err)
+		case errors.Is(err, apperrors.ErrCurrencyNotFound):
+			errorResponse(c, http.StatusNotFound, "Currency not found", err)
+		case errors.Is(err, apperrors.ErrCurrencyInactive):
+			errorResponseWithCode(c, http.StatusGone, ErrorCodeCurrencyInactive, "Currency is no longer active", err)
+		case errors.Is(err, apperrors.ErrExchangeRateNotFound):
+			errorResponse(c, http.StatusNotFound, "Exchange rate not found", err)
+		default:
+			errorResponse(c, http.StatusInternalServerError, "Failed to convert amount", err)
+		}
+		return
+	}
+
+	successResponse(c, result, "Amount converted successfully")
+}
+
+// GetRateMatrix handles GET /api/v1/rates/matrix?codes=USD,EUR,GBP. It
+// returns the rate between every two of the requested currencies, marking
+// the cells no rate could be found for as missing. At most maxCodes
+// currencies can be requested since the matrix grows with their square.
+func (h *ConversionHandler) GetRateMatrix(c *gin.Context) {
+	codes, ok := parseCodes(c)
+	if !ok {
+		return
+	}
+	if len(codes) == 0 {
+		errorResponse(c, http.StatusBadRequest, "At least one currency code must be requested in codes", nil)
+		return
+	}
+
+	matrix, err := h.conversionService.RateMatrix(c.Request.Context(), codes)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrCurrencyNotFound):
+			errorResponse(c, http.StatusNotFound, "Currency not found", err)
+		case errors.Is(err, apperrors.ErrCurrencyInactive):
+			errorResponseWithCode(c, http.StatusGone, ErrorCodeCurrencyInactive, "Currency is no longer active", err)
+		default:
+			errorResponse(c, http.StatusInternalServerError, "Failed to compute rate matrix", err)
+		}
+		return
+	}
+
+	successResponse(c, matrix, "Rate matrix computed successfully")
+}
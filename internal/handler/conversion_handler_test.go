@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConversionService converts at a fixed rate of 2 and records the
+// amounts it was asked to convert
+type fakeConversionService struct {
+	amounts []decimal.Decimal
+	codes   []string
+}
+
+func (s *fakeConversionService) ConvertAmount(ctx context.Context, from, to string, amount decimal.Decimal) (*service.ConversionResult, error) {
+	s.amounts = append(s.amounts, amount)
+	rate := decimal.NewFromInt(2)
+	return &service.ConversionResult{From: from, To: to, Amount: amount, Rate: rate, Result: amount.Mul(rate)}, nil
+}
+
+// RateMatrix returns a matrix whose only missing cells are those between
+// the first and last code
+func (s *fakeConversionService) RateMatrix(ctx context.Context, codes []string) (*service.RateMatrix, error) {
+	s.codes = codes
+	matrix := &service.RateMatrix{Codes: codes, Rows: make([][]service.RateMatrixCell, len(codes))}
+	for i, from := range codes {
+		for j, to := range codes {
+			cell := service.RateMatrixCell{From: from, To: to}
+			if (i == 0 && j == len(codes)-1) || (j == 0 && i == len(codes)-1) {
+				cell.Missing = true
+				matrix.Missing++
+			} else {
+				rate := decimal.NewFromInt(int64(i + 1)).Div(decimal.NewFromInt(int64(j + 1)))
+				cell.Rate = &rate
+			}
+			matrix.Rows[i] = append(matrix.Rows[i], cell)
+		}
+	}
+	return matrix, nil
+}
+
+func newConvertRouter(svc service.ConversionServiceInterface) http.Handler {
+	router := newTestRouter()
+	h := NewConversionHandler(svc)
+	router.GET("/api/v1/convert", h.Convert)
+	router.GET("/api/v1/rates/matrix", h.GetRateMatrix)
+	return router
+}
+
+func convertPath(amount string) string {
+	return "/api/v1/convert?from=USD&to=EUR&amount=" + url.QueryEscape(amount)
+}
+
+func TestConvertAcceptsAmountsWithinLimits(t *testing.T) {
+	for _, amount := range []string{
+		"0",
+		"100.50",
+		"999999999999999999",   // 18 integer digits
+		"0.000000000001",       // 12 decimal places
+		"123456789012345678.5", // both at once
+		"1e17",
+		"1.5e-11",
+	} {
+		svc := &fakeConversionService{}
+
+		w := serve(newConvertRouter(svc), http.MethodGet, convertPath(amount), "", nil)
+
+		require.Equal(t, http.StatusOK, w.Code, "%s: %s", amount, w.Body.String())
+		require.Len(t, svc.amounts, 1, amount)
+		assert.True(t, decimal.RequireFromString(amount).Equal(svc.amounts[0]), amount)
+	}
+}
+
+func TestConvertRejectsAmountsOutOfRange(t *testing.T) {
+	for _, amount := range []string{
+		"1e2000000",
+		"1e-2000000",
+		"1e19",
+		"0e100",
+		"1000000000000000000",  // 19 integer digits
+		"0.0000000000001",      // 13 decimal places
+		"-1000000000000000000", // negative amounts are bounded too
+		"1" + strings.Repeat("0", 100),
+	} {
+		svc := &fakeConversionService{}
+
+		w := serve(newConvertRouter(svc), http.MethodGet, convertPath(amount), "", nil)
+
+		require.Equal(t, http.StatusBadRequest, w.Code, amount)
+		var resp APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "Amount must have at most 18 integer digits and 12 decimal places", resp.Error, amount)
+		assert.Empty(t, svc.amounts, "%s reached the service", amount)
+	}
+}
+
+func TestConvertRejectsMalformedAmounts(t *testing.T) {
+	for _, amount := range []string{"", "abc", "1,5", "1..2"} {
+		svc := &fakeConversionService{}
+
+		w := serve(newConvertRouter(svc), http.MethodGet, convertPath(amount), "", nil)
+
+		require.Equal(t, http.StatusBadRequest, w.Code, amount)
+		assert.Contains(t, w.Body.String(), "Amount must be a number", amount)
+		assert.Empty(t, svc.amounts, amount)
+	}
+}
+
+func TestConvertParsesAmountsForLocale(t *testing.T) {
+	tests := []struct {
+		locale string
+		amount string
+		want   string
+	}{
+		// Dot decimal, comma groups
+		{"en", "1,234.56", "1234.56"},
+		{"en-US", "1,234,567.5", "1234567.5"},
+		{"en", "1234.56", "1234.56"},
+		{"en", "1,234", "1234"},
+		{"ja", "100", "100"},
+		{"en", "-0.5", "-0.5"},
+		// Comma decimal, dot or space groups
+		{"de", "1.234,56", "1234.56"},
+		{"de-DE", "1.234.567,5", "1234567.5"},
+		{"de", "1234,56", "1234.56"},
+		{"de", "1.234", "1234"},
+		{"fr", "1 234,56", "1234.56"},
+		{"fr-FR", "1\u00a0234,56", "1234.56"},
+		{"fr", "1\u202f234,56", "1234.56"},
+		{"pt-BR", " 0,5 ", "0.5"},
+		// Regional formats
+		{"de-CH", "1'234.56", "1234.56"},
+		{"fr-CH", "1\u2019234.56", "1234.56"},
+		{"es-MX", "1,234.56", "1234.56"},
+		{"es", "1.234,56", "1234.56"},
+	}
+
+	for _, tt := range tests {
+		svc := &fakeConversionService{}
+		path := convertPath(tt.amount) + "&locale=" + url.QueryEscape(tt.locale)
+
+		w := serve(newConvertRouter(svc), http.MethodGet, path, "", nil)
+
+		require.Equal(t, http.StatusOK, w.Code, "%s %q: %s", tt.locale, tt.amount, w.Body.String())
+		require.Len(t, svc.amounts, 1)
+		assert.Equal(t, tt.want, svc.amounts[0].String(), "%s %q", tt.locale, tt.amount)
+	}
+}
+
+func TestConvertRejectsAmountsAmbiguousForLocale(t *testing.T) {
+	tests := []struct {
+		locale string
+		amount string
+	}{
+		// Written for the other format
+		{"de", "1,234.56"},
+		{"de", "1234.56"},
+		{"en", "1.234,56"},
+		{"en", "1234,56"},
+		// Groups that aren't thousands
+		{"en", "1,23"},
+		{"en", "12,34,567"},
+		{"de", "1.2345,6"},
+		{"en", "1234,567.8"},
+		{"en", ",123"},
+		// Mixed or repeated separators
+		{"fr", "1.234 567,8"},
+		{"de", "1,2,3"},
+		{"en", "1.2.3"},
+		{"en", "1,234."},
+		// Exponents are only read without a locale
+		{"en", "1e3"},
+	}
+
+	for _, tt := range tests {
+		svc := &fakeConversionService{}
+		path := convertPath(tt.amount) + "&locale=" + url.QueryEscape(tt.locale)
+
+		w := serve(newConvertRouter(svc), http.MethodGet, path, "", nil)
+
+		require.Equal(t, http.StatusBadRequest, w.Code, "%s %q", tt.locale, tt.amount)
+		assert.Contains(t, w.Body.String(), "Amount must be a number formatted for locale "+tt.locale, "%s %q", tt.locale, tt.amount)
+		assert.Empty(t, svc.amounts, "%s %q reached the service", tt.locale, tt.amount)
+	}
+}
+
+func TestConvertWithoutLocaleIsStrictDotDecimal(t *testing.T) {
+	for _, amount := range []string{"1,234.56", "1.234,56", "1 234"} {
+		svc := &fakeConversionService{}
+
+		w := serve(newConvertRouter(svc), http.MethodGet, convertPath(amount), "", nil)
+
+		require.Equal(t, http.StatusBadRequest, w.Code, amount)
+		assert.Contains(t, w.Body.String(), "Amount must be a number", amount)
+	}
+}
+
+func TestConvertRejectsUnknownLocales(t *testing.T) {
+	for locale, message := range map[string]string{
+		"xx":     "Amounts can't be parsed for locale xx",
+		"ar-EG":  "Amounts can't be parsed for locale ar-EG",
+		"not a!": "Invalid locale",
+	} {
+		svc := &fakeConversionService{}
+
+		w := serve(newConvertRouter(svc), http.MethodGet, convertPath("1")+"&locale="+url.QueryEscape(locale), "", nil)
+
+		require.Equal(t, http.StatusBadRequest, w.Code, locale)
+		assert.Contains(t, w.Body.String(), message, locale)
+	}
+}
+
+func TestConvertBoundsLocalizedAmounts(t *testing.T) {
+	svc := &fakeConversionService{}
+
+	w := serve(newConvertRouter(svc), http.MethodGet, convertPath("1.000.000.000.000.000.000")+"&locale=de", "", nil)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Amount must have at most 18 integer digits")
+}
+
+func TestRateMatrixMarksMissingCells(t *testing.T) {
+	svc := &fakeConversionService{}
+
+	w := serve(newConvertRouter(svc), http.MethodGet, "/api/v1/rates/matrix?codes=usd,EUR,GBP", "", nil)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"USD", "EUR", "GBP"}, svc.codes)
+	var resp struct {
+		Data struct {
+			Codes   []string `json:"codes"`
+			Missing int      `json:"missing"`
+			Rows    [][]struct {
+				From    string  `json:"from"`
+				To      string  `json:"to"`
+				Rate    *string `json:"rate"`
+				Missing bool    `json:"missing"`
+			} `json:"rows"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"USD", "EUR", "GBP"}, resp.Data.Codes)
+	assert.Equal(t, 2, resp.Data.Missing)
+	require.Len(t, resp.Data.Rows, 3)
+
+	usdGBP := resp.Data.Rows[0][2]
+	assert.Equal(t, "USD", usdGBP.From)
+	assert.Equal(t, "GBP", usdGBP.To)
+	assert.True(t, usdGBP.Missing)
+	assert.Nil(t, usdGBP.Rate, "missing cells have a null rate")
+
+	eurUSD := resp.Data.Rows[1][0]
+	assert.False(t, eurUSD.Missing)
+	require.NotNil(t, eurUSD.Rate)
+	assert.Equal(t, "2", *eurUSD.Rate)
+}
+
+func TestRateMatrixRejectsBadCodeLists(t *testing.T) {
+	tooMany := make([]string, maxCodes+1)
+	for i := range tooMany {
+		tooMany[i] = string([]byte{'A', 'A' + byte(i/26), 'A' + byte(i%26)})
+	}
+
+	for query, message := range map[string]string{
+		"":                                     "At least one currency code must be requested",
+		"?codes=":                              "At least one currency code must be requested",
+		"?codes=USD,EU":                        "Invalid currency code format",
+		"?codes=" + strings.Join(tooMany, ","): fmt.Sprintf("At most %d codes can be requested at once", maxCodes),
+	} {
+		svc := &fakeConversionService{}
+
+		w := serve(newConvertRouter(svc), http.MethodGet, "/api/v1/rates/matrix"+query, "", nil)
+
+		require.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.Contains(t, w.Body.String(), message, query)
+		assert.Nil(t, svc.codes, "%s reached the service", query)
+	}
+}
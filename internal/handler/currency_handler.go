@@ -1,142 +1,701 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/auth"
+	"github.com/Tarifsiz/go-currency-api/internal/config"
 	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/money"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
 	"github.com/Tarifsiz/go-currency-api/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
+// maxImportSize caps the size of an uploaded import file
+const maxImportSize = 1 << 20
+
+// maxCodes caps the number of codes accepted in a single ?codes= request
+const maxCodes = 50
+
+// numericCodePattern is the accepted format of ISO 4217 numeric codes
+var numericCodePattern = regexp.MustCompile(`^[0-9]{3}$`)
+
 // CurrencyHandler handles HTTP requests for currency operations
 type CurrencyHandler struct {
-	currencyService service.CurrencyServiceInterface
+	currencyService    service.CurrencyServiceInterface
+	translationService service.TranslationServiceInterface
+	pagination         config.PaginationConfig
 }
 
 // NewCurrencyHandler creates a new currency handler instance
-func NewCurrencyHandler(currencyService service.CurrencyServiceInterface) *CurrencyHandler {
+func NewCurrencyHandler(currencyService service.CurrencyServiceInterface, translationService service.TranslationServiceInterface, pagination config.PaginationConfig) *CurrencyHandler {
 	return &CurrencyHandler{
-		currencyService: currencyService,
+		currencyService:    currencyService,
+		translationService: translationService,
+		pagination:         pagination,
 	}
 }
 
-// APIResponse represents the standard API response format
-type APIResponse struct {
-	Success   bool        `json:"success"`
-	Data      interface{} `json:"data,omitempty"`
-	Error     string      `json:"error,omitempty"`
-	Message   string      `json:"message,omitempty"`
-	Timestamp time.Time   `json:"timestamp"`
+// CreateCurrencyRequest represents the request body for creating a currency
+type CreateCurrencyRequest struct {
+	Code                string     `json:"code" binding:"required,len=3"`
+	NumericCode         string     `json:"numeric_code,omitempty" binding:"omitempty,len=3,numeric"`
+	Description         string     `json:"description" binding:"required,max=255"`
+	AmountDisplayFormat string     `json:"amount_display_format,omitempty"`
+	HtmlEncodedSymbol   string     `json:"html_encoded_symbol,omitempty"`
+	Factor              int        `json:"factor,omitempty"`
+	RoundingMode        string     `json:"rounding_mode,omitempty" binding:"omitempty,oneof=half_up half_even down up"`
+	ValidFrom           *time.Time `json:"valid_from,omitempty"`
+	ValidUntil          *time.Time `json:"valid_until,omitempty"`
+	VisibleAfter        *time.Time `json:"visible_after,omitempty"`
 }
 
-// PaginationResponse represents paginated API response
-type PaginationResponse struct {
-	Success    bool        `json:"success"`
-	Data       interface{} `json:"data,omitempty"`
-	Error      string      `json:"error,omitempty"`
-	Message    string      `json:"message,omitempty"`
-	Timestamp  time.Time   `json:"timestamp"`
-	Pagination struct {
-		Page    int   `json:"page"`
-		Limit   int   `json:"limit"`
-		Offset  int   `json:"offset"`
-		Total   int64 `json:"total,omitempty"`
-	} `json:"pagination,omitempty"`
+// UpdateCurrencyRequest represents the request body for replacing a
+// currency. It is the full representation: every field overwrites the
+// stored one, and omitted optional fields are cleared. Partial updates use
+// PatchCurrencyRequest. Version must be the currency's current version
+// unless an If-Match header is sent.
+type UpdateCurrencyRequest struct {
+	Version             int        `json:"version,omitempty" binding:"omitempty,min=1"`
+	NumericCode         string     `json:"numeric_code" binding:"omitempty,len=3,numeric"`
+	Description         string     `json:"description" binding:"required,max=255"`
+	AmountDisplayFormat string     `json:"amount_display_format" binding:"required"`
+	HtmlEncodedSymbol   string     `json:"html_encoded_symbol"`
+	Factor              int        `json:"factor" binding:"required,min=1"`
+	RoundingMode        string     `json:"rounding_mode" binding:"required,oneof=half_up half_even down up"`
+	ValidFrom           *time.Time `json:"valid_from"`
+	ValidUntil          *time.Time `json:"valid_until"`
+	VisibleAfter        *time.Time `json:"visible_after"`
 }
 
-// CreateCurrencyRequest represents the request body for creating a currency
-type CreateCurrencyRequest struct {
-	Code                string `json:"code" binding:"required,len=3"`
-	Description         string `json:"description" binding:"required,max=255"`
-	AmountDisplayFormat string `json:"amount_display_format,omitempty"`
-	HtmlEncodedSymbol   string `json:"html_encoded_symbol,omitempty"`
-	Factor              int    `json:"factor,omitempty"`
+// CurrencySymbolResponse is the trimmed payload needed to render a price.
+// Symbol is HtmlEncodedSymbol decoded to UTF-8 for non-HTML clients.
+type CurrencySymbolResponse struct {
+	Code                string `json:"code"`
+	HtmlEncodedSymbol   string `json:"html_encoded_symbol"`
+	Symbol              string `json:"symbol"`
+	AmountDisplayFormat string `json:"amount_display_format"`
 }
 
-// UpdateCurrencyRequest represents the request body for updating a currency
-type UpdateCurrencyRequest struct {
-	Description         string `json:"description,omitempty"`
-	AmountDisplayFormat string `json:"amount_display_format,omitempty"`
-	HtmlEncodedSymbol   string `json:"html_encoded_symbol,omitempty"`
-	Factor              int    `json:"factor,omitempty"`
+// CurrencyCountResponse is the payload of GET /api/v1/currencies/count
+type CurrencyCountResponse struct {
+	Total int64 `json:"total"`
+}
+
+// FormatAmountRequest represents the request body for formatting an amount.
+// Amount is in minor units, e.g. cents for USD.
+type FormatAmountRequest struct {
+	Amount *int64 `json:"amount" binding:"required"`
+}
+
+// FormatPreviewRequest represents the request body for previewing the
+// display format of a currency. Amounts are in minor units; a non-empty
+// AmountDisplayFormat is previewed instead of the currency's own.
+type FormatPreviewRequest struct {
+	Amounts             []int64 `json:"amounts" binding:"required,min=1,max=100"`
+	AmountDisplayFormat string  `json:"amount_display_format" binding:"max=50"`
+}
+
+// PatchCurrencyRequest represents the request body for patching a single
+// currency. Omitted fields are left unchanged; fields sent as "" are cleared.
+// Version must be the currency's current version unless an If-Match header is sent.
+type PatchCurrencyRequest struct {
+	Version             int     `json:"version,omitempty" binding:"omitempty,min=1"`
+	Description         *string `json:"description,omitempty"`
+	AmountDisplayFormat *string `json:"amount_display_format,omitempty"`
+	HtmlEncodedSymbol   *string `json:"html_encoded_symbol,omitempty"`
+	Factor              *int    `json:"factor,omitempty" binding:"omitempty,min=1"`
+	RoundingMode        *string `json:"rounding_mode,omitempty" binding:"omitempty,oneof=half_up half_even down up"`
+}
+
+// PatchCurrencyItem represents one item of a batch PATCH request. Omitted
+// fields are left unchanged; fields sent as "" are cleared. Version must be
+// the currency's current version.
+type PatchCurrencyItem struct {
+	Code                string  `json:"code" binding:"required"`
+	Version             int     `json:"version" binding:"required,min=1"`
+	Description         *string `json:"description,omitempty"`
+	AmountDisplayFormat *string `json:"amount_display_format,omitempty"`
+	HtmlEncodedSymbol   *string `json:"html_encoded_symbol,omitempty"`
+	Factor              *int    `json:"factor,omitempty" binding:"omitempty,min=1"`
+	RoundingMode        *string `json:"rounding_mode,omitempty" binding:"omitempty,oneof=half_up half_even down up"`
+}
+
+// currencyList is one page of currencies queried for GetCurrencies, before
+// it is written in a version's response envelope
+type currencyList struct {
+	currencies []*model.Currency
+	scored     []*repository.ScoredCurrency // set instead of currencies for fuzzy searches
+	fields     map[string]bool
+	paged      bool // offset pagination applies; code and factor queries return every match
+	keyset     bool // cursor pagination applies
+	page       int
+	limit      int
+	offset     int
+	total      int64
+	nextCursor string
 }
 
 // GetCurrencies handles GET /api/v1/currencies
 func (h *CurrencyHandler) GetCurrencies(c *gin.Context) {
+	list, ok := h.listCurrencies(c)
+	if !ok {
+		return
+	}
+	
+	response := PaginationResponse{
+		Success:   true,
+		Data:      list.currencies,
+		Timestamp: model.Now(),
+	}
+	if list.scored != nil {
+		// Fuzzy results carry a similarity score per currency
+		response.Data = list.scored
+	}
+	var err error
+	if response.Data, err = selectFields(response.Data, list.fields); err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currencies", err)
+		return
+	}
+	
+	response.Pagination.Page = list.page
+	response.Pagination.Limit = list.limit
+	response.Pagination.Offset = list.offset
+	response.Pagination.Total = list.total
+	response.Pagination.NextCursor = list.nextCursor
+	
+	switch {
+	case list.keyset:
+		setCursorLinks(c, list.limit, list.nextCursor)
+	case list.paged:
+		setPageLinks(c, list.page, list.limit, list.total)
+	}
+	
+	writePage(c, response)
+}
+
+// listCurrencies parses the query parameters shared by every version of
+// GetCurrencies and fetches the page they select. It writes an error
+// response and returns false if the request is invalid or the lookup fails.
+func (h *CurrencyHandler) listCurrencies(c *gin.Context) (*currencyList, bool) {
 	// Parse query parameters
-	page := h.getQueryInt(c, "page", 1)
-	limit := h.getQueryInt(c, "limit", 50)
+	page := pageNumber(c)
+	limit := pageLimit(c, h.pagination)
 	search := c.Query("search")
 	factor := h.getQueryInt(c, "factor", 0)
+	sortField, sortOrder := parseSort(c)
+	
+	searchOpts, ok := parseSearchOptions(c)
+	if !ok {
+		return nil, false
+	}
+	
+	// Fuzzy search always matches on description by trigram similarity
+	fuzzy, _ := strconv.ParseBool(c.Query("fuzzy"))
+	
+	// Inactive (obsolete) currencies are only listed on request
+	includeInactive := !searchOpts.ActiveOnly
+	
+	codes, ok := parseCodes(c)
+	if !ok {
+		return nil, false
+	}
+	
+	fields, ok := parseFields(c)
+	if !ok {
+		return nil, false
+	}
+	
+	filter, ok := parseListFilter(c)
+	if !ok {
+		return nil, false
+	}
+	
+	if !filter.IsZero() && (len(codes) > 0 || search != "" || factor > 0) {
+		errorResponse(c, http.StatusBadRequest, "Date filters can't be combined with codes, search or factor", nil)
+		return nil, false
+	}
+	filter.ActiveOnly = !includeInactive
+	filter.ShowHidden = searchOpts.ShowHidden
+	
+	locales, ok := requestLocales(c)
+	if !ok {
+		return nil, false
+	}
 	
 	// Calculate offset
 	offset := (page - 1) * limit
 	
-	// Validate pagination parameters
-	if limit > 100 {
-		limit = 100 // Max limit
-	}
-	if limit < 1 {
-		limit = 10 // Default limit
+	// ?cursor= switches to keyset pagination, which takes precedence over
+	// ?page=. An empty cursor starts from the first currency.
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		if len(codes) > 0 || search != "" || factor > 0 || !filter.IsZero() {
+			errorResponse(c, http.StatusBadRequest, "cursor can't be combined with codes, search, factor or date filters", nil)
+			return nil, false
+		}
+		if sortField, sortOrder = repository.NormalizeSort(sortField, sortOrder); sortField != "code" || sortOrder != "asc" {
+			errorResponse(c, http.StatusBadRequest, "cursor pagination is always sorted by code ascending", nil)
+			return nil, false
+		}
+		return h.listCurrenciesAfter(c, cursor, limit, filter, fields, locales)
 	}
 	
 	var currencies []*model.Currency
+	var scored []*repository.ScoredCurrency
+	var total int64
 	var err error
 	
 	// Handle different query types
-	if search != "" {
-		currencies, err = h.currencyService.SearchCurrencies(c.Request.Context(), search)
+	if len(codes) > 0 {
+		currencies, err = h.currencyService.GetCurrenciesByCodes(c.Request.Context(), codes)
+	} else if search != "" && fuzzy {
+		scored, total, err = h.currencyService.FuzzySearchCurrencies(c.Request.Context(), searchOpts, limit, offset)
+	} else if search != "" {
+		currencies, total, err = h.currencyService.SearchCurrencies(c.Request.Context(), searchOpts, limit, offset)
 	} else if factor > 0 {
 		currencies, err = h.currencyService.GetCurrenciesByFactor(c.Request.Context(), factor)
 	} else {
-		currencies, err = h.currencyService.GetAllCurrencies(c.Request.Context(), limit, offset)
+		currencies, err = h.currencyService.GetAllCurrencies(c.Request.Context(), limit, offset, sortField, sortOrder, filter)
+	}
+	
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currencies", err)
+		return nil, false
 	}
 	
+	// Code and factor queries are unpaginated, so inactive currencies can be
+	// dropped here rather than in the query
+	if (len(codes) > 0 || factor > 0) && !includeInactive {
+		currencies = activeCurrencies(currencies)
+	}
+	
+	// Fuzzy searches return only scored results, which embed their currency
+	localized := currencies
+	for _, result := range scored {
+		localized = append(localized, &result.Currency)
+	}
+	if _, ok := h.localize(c, localized, locales); !ok {
+		return nil, false
+	}
+	
+	// Get total count for pagination. Search returns its own total; code and
+	// factor queries are unpaginated, so their total is the size of the result set.
+	if len(codes) > 0 || factor > 0 {
+		total = int64(len(currencies))
+	} else if search == "" {
+		if total, err = h.currencyService.CountFilteredCurrencies(c.Request.Context(), filter); err != nil {
+			errorResponse(c, http.StatusInternalServerError, "Failed to count currencies", err)
+			return nil, false
+		}
+	}
+	
+	return &currencyList{
+		currencies: currencies,
+		scored:     scored,
+		fields:     fields,
+		paged:      len(codes) == 0 && factor <= 0,
+		page:       page,
+		limit:      limit,
+		offset:     offset,
+		total:      total,
+	}, true
+}
+
+// GetCurrenciesGrouped handles GET /api/v1/currencies/grouped. It returns
+// all currencies keyed by factor in ascending order, e.g. {"1": [...],
+// "100": [...]}, and accepts the same search, field and match parameters as
+// GetCurrencies.
+func (h *CurrencyHandler) GetCurrenciesGrouped(c *gin.Context) {
+	search, ok := parseSearchOptions(c)
+	if !ok {
+		return
+	}
+	
+	groups, err := h.currencyService.GetCurrenciesGroupedByFactor(c.Request.Context(), search)
 	if err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currencies", err)
+		errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currencies", err)
 		return
 	}
 	
-	// Get total count for pagination (only for regular list, not search results)
-	var total int64
-	if search == "" && factor == 0 {
-		total, _ = h.currencyService.GetCurrencyCount(c.Request.Context())
+	successResponse(c, numericKeyedObject(groups), "Currencies retrieved successfully")
+}
+
+// GetCurrenciesWithoutRates handles GET /api/v1/currencies/without-rates?base=USD.
+// It lists the active currencies with no stored exchange rate against base in
+// either direction.
+func (h *CurrencyHandler) GetCurrenciesWithoutRates(c *gin.Context) {
+	base, err := model.NormalizeCode(c.Query("base"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return
+	}
+	
+	currencies, err := h.currencyService.GetCurrenciesWithoutRates(c.Request.Context(), base)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrCurrencyNotFound) {
+			currencyNotFound(c, base, err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currencies", err)
+		return
+	}
+	
+	successResponse(c, currencies, "Currencies retrieved successfully")
+}
+
+// parseSearchOptions reads the search, field, match, include_inactive and
+// show_hidden query parameters; inactive currencies are left out unless
+// include_inactive is set, and soft-launched ones not yet visible unless
+// show_hidden is. It writes a 400 response and returns false if field or
+// match is not supported, and a 401 if show_hidden is sent without a token.
+func parseSearchOptions(c *gin.Context) (repository.SearchOptions, bool) {
+	includeInactive, _ := strconv.ParseBool(c.Query("include_inactive"))
+	search := repository.SearchOptions{
+		Term:       c.Query("search"),
+		Field:      c.DefaultQuery("field", repository.SearchFieldDescription),
+		Match:      c.DefaultQuery("match", repository.SearchMatchContains),
+		ActiveOnly: !includeInactive,
+	}
+	if value := c.Query("show_hidden"); value != "" {
+		showHidden, err := strconv.ParseBool(value)
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, "Invalid show_hidden parameter", err)
+			return search, false
+		}
+		if showHidden {
+			if _, ok := auth.UserIDFromContext(c.Request.Context()); !ok {
+				errorResponse(c, http.StatusUnauthorized, "Authentication required to show hidden currencies", nil)
+				return search, false
+			}
+			// Hidden currencies mustn't end up in a shared cache
+			c.Header("Cache-Control", "no-store")
+		}
+		search.ShowHidden = showHidden
+	}
+	if !repository.IsValidSearchField(search.Field) {
+		errorResponse(c, http.StatusBadRequest, "Invalid search field, must be one of: description, code", nil)
+		return search, false
+	}
+	if !repository.IsValidSearchMatch(search.Match) {
+		errorResponse(c, http.StatusBadRequest, "Invalid match mode, must be one of: contains, prefix, exact", nil)
+		return search, false
+	}
+	return search, true
+}
+
+// CountCurrencies handles GET /api/v1/currencies/count. It returns the number
+// of currencies, narrowed by the same search, field, match, factor and
+// include_inactive parameters as GetCurrencies; unlike there, search and
+// factor combine.
+func (h *CurrencyHandler) CountCurrencies(c *gin.Context) {
+	search, ok := parseSearchOptions(c)
+	if !ok {
+		return
+	}
+	factor := h.getQueryInt(c, "factor", 0)
+	
+	total, err := h.currencyService.CountCurrencies(c.Request.Context(), search, factor)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to count currencies", err)
+		return
+	}
+	
+	successResponse(c, CurrencyCountResponse{Total: total}, "Currency count retrieved successfully")
+}
+
+// GetCurrencyChanges handles GET /api/v1/currencies/changes?since=<timestamp>.
+// It lists currencies created or updated after since, oldest change first.
+func (h *CurrencyHandler) GetCurrencyChanges(c *gin.Context) {
+	raw := c.Query("since")
+	if raw == "" {
+		errorResponse(c, http.StatusBadRequest, "since is required", nil)
+		return
+	}
+	// An unescaped "+" in a UTC offset arrives decoded as a space
+	since, err := time.Parse(time.RFC3339, strings.ReplaceAll(raw, " ", "+"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid since, must be an RFC 3339 timestamp", err)
+		return
+	}
+	
+	page := pageNumber(c)
+	limit := pageLimit(c, h.pagination)
+	offset := (page - 1) * limit
+	
+	changes, total, err := h.currencyService.GetCurrencyChanges(c.Request.Context(), since, limit, offset)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currency changes", err)
+		return
 	}
 	
 	response := PaginationResponse{
 		Success:   true,
-		Data:      currencies,
-		Timestamp: time.Now().UTC(),
+		Data:      changes,
+		Timestamp: model.Now(),
+	}
+	response.Pagination.Page = page
+	response.Pagination.Limit = limit
+	response.Pagination.Offset = offset
+	response.Pagination.Total = total
+	
+	writePage(c, response)
+}
+
+// GetCurrencyAudit handles GET /api/v1/currencies/:code/audit. It returns
+// one page of the currency's audit trail, the creations, updates, deletions
+// and restorations of every currency that had the code, oldest first, each
+// with the acting user.
+func (h *CurrencyHandler) GetCurrencyAudit(c *gin.Context) {
+	code, err := model.NormalizeCode(c.Param("code"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return
+	}
+	
+	page := pageNumber(c)
+	limit := pageLimit(c, h.pagination)
+	offset := (page - 1) * limit
+	
+	changes, total, err := h.currencyService.GetCurrencyAudit(c.Request.Context(), code, limit, offset)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrCurrencyNotFound) {
+			currencyNotFound(c, code, err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currency audit trail", err)
+		return
 	}
 	
+	response := PaginationResponse{
+		Success:   true,
+		Data:      changes,
+		Timestamp: model.Now(),
+	}
 	response.Pagination.Page = page
 	response.Pagination.Limit = limit
 	response.Pagination.Offset = offset
 	response.Pagination.Total = total
 	
-	c.JSON(http.StatusOK, response)
+	writePage(c, response)
+}
+
+// listCurrenciesAfter fetches one keyset page of currencies matching filter
+// ordered by code, localized to locales
+func (h *CurrencyHandler) listCurrenciesAfter(c *gin.Context, cursor string, limit int, filter repository.ListFilter, fields map[string]bool, locales []string) (*currencyList, bool) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid cursor", err)
+		return nil, false
+	}
+	
+	currencies, hasMore, err := h.currencyService.GetCurrenciesAfter(c.Request.Context(), after, limit, filter)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currencies", err)
+		return nil, false
+	}
+	if _, ok := h.localize(c, currencies, locales); !ok {
+		return nil, false
+	}
+	total, err := h.currencyService.CountFilteredCurrencies(c.Request.Context(), filter)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to count currencies", err)
+		return nil, false
+	}
+	
+	list := &currencyList{
+		currencies: currencies,
+		fields:     fields,
+		keyset:     true,
+		limit:      limit,
+		total:      total,
+	}
+	if hasMore {
+		list.nextCursor = encodeCursor(currencies[len(currencies)-1].Code)
+	}
+	return list, true
 }
 
-// GetCurrencyByCode handles GET /api/v1/currencies/:code
+// GetCurrencyByCode handles GET /api/v1/currencies/:code. The description
+// is localized to ?locale=, or else to Accept-Language, when a translation
+// exists; Content-Language names the locale used.
 func (h *CurrencyHandler) GetCurrencyByCode(c *gin.Context) {
-	code := strings.ToUpper(c.Param("code"))
+	code, err := model.NormalizeCode(c.Param("code"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return
+	}
 	
-	// Validate currency code format
-	if len(code) != 3 {
-		h.errorResponse(c, http.StatusBadRequest, "Invalid currency code format", nil)
+	fields, ok := parseFields(c)
+	if !ok {
+		return
+	}
+	
+	locales, ok := requestLocales(c)
+	if !ok {
 		return
 	}
 	
 	currency, err := h.currencyService.GetCurrencyByCode(c.Request.Context(), code)
 	if err != nil {
-		h.errorResponse(c, http.StatusNotFound, "Currency not found", err)
+		currencyLookupFailed(c, code, err)
+		return
+	}
+	
+	translations, ok := h.localize(c, []*model.Currency{currency}, locales)
+	if !ok {
+		return
+	}
+	version := currencyVersion(currency)
+	if translation, ok := translations[currency.Code]; ok {
+		c.Header("Content-Language", translation.Locale)
+		version = localizedCurrencyVersion(currency, translation)
+	}
+	
+	// Let polling clients revalidate without downloading the record again
+	if notModified(c, representationETag(c, version, fields)) {
+		return
+	}
+	
+	data, err := selectFields(currency, fields)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currency", err)
 		return
 	}
 	
-	h.successResponse(c, currency, "Currency retrieved successfully")
+	successResponse(c, data, "Currency retrieved successfully")
+}
+
+// localize replaces the descriptions of currencies with their translations
+// into locales and returns the translations used, keyed by currency code. The
+// response varies by Accept-Language either way. It writes a 500 response and
+// returns false if the translations can't be loaded.
+func (h *CurrencyHandler) localize(c *gin.Context, currencies []*model.Currency, locales []string) (map[string]*model.CurrencyTranslation, bool) {
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	
+	translations, err := h.translationService.Localize(c.Request.Context(), currencies, locales)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currency translations", err)
+		return nil, false
+	}
+	return translations, true
+}
+
+// GetCurrencySymbol handles GET /api/v1/currencies/:code/symbol
+func (h *CurrencyHandler) GetCurrencySymbol(c *gin.Context) {
+	code, err := model.NormalizeCode(c.Param("code"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return
+	}
+	
+	// Goes through the cached lookup used by GET /currencies/:code
+	currency, err := h.currencyService.GetCurrencyByCode(c.Request.Context(), code)
+	if err != nil {
+		currencyLookupFailed(c, code, err)
+		return
+	}
+	if !currency.IsActive {
+		currencyInactive(c, code, nil)
+		return
+	}
+	
+	successResponse(c, CurrencySymbolResponse{
+		Code:                currency.Code,
+		HtmlEncodedSymbol:   currency.HtmlEncodedSymbol,
+		Symbol:              model.DecodeSymbol(currency.HtmlEncodedSymbol),
+		AmountDisplayFormat: currency.AmountDisplayFormat,
+	}, "Currency symbol retrieved successfully")
+}
+
+// FormatAmount handles POST /api/v1/currencies/:code/format
+func (h *CurrencyHandler) FormatAmount(c *gin.Context) {
+	code, err := model.NormalizeCode(c.Param("code"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return
+	}
+	
+	var req FormatAmountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingError(c, err)
+		return
+	}
+	
+	result, err := h.currencyService.FormatCurrencyAmount(c.Request.Context(), code, *req.Amount)
+	if err != nil {
+		currencyLookupFailed(c, code, err)
+		return
+	}
+	
+	successResponse(c, result, "Amount formatted successfully")
+}
+
+// PreviewFormat handles POST /api/v1/currencies/:code/format-preview. It
+// formats every amount in the body, so operators can check a display format
+// against zero, negative and large amounts before saving it.
+func (h *CurrencyHandler) PreviewFormat(c *gin.Context) {
+	code, err := model.NormalizeCode(c.Param("code"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return
+	}
+	
+	var req FormatPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingError(c, err)
+		return
+	}
+	
+	preview, err := h.currencyService.PreviewAmountFormat(c.Request.Context(), code, req.Amounts, req.AmountDisplayFormat)
+	if err != nil {
+		currencyLookupFailed(c, code, err)
+		return
+	}
+	
+	successResponse(c, preview, "Amounts formatted successfully")
+}
+
+// GetCurrencyByNumericCode handles GET /api/v1/currencies/numeric/:numericCode
+func (h *CurrencyHandler) GetCurrencyByNumericCode(c *gin.Context) {
+	numericCode := c.Param("numericCode")
+	
+	// Validate numeric code format
+	if !numericCodePattern.MatchString(numericCode) {
+		errorResponse(c, http.StatusBadRequest, "Invalid numeric currency code format", nil)
+		return
+	}
+	
+	fields, ok := parseFields(c)
+	if !ok {
+		return
+	}
+	
+	currency, err := h.currencyService.GetCurrencyByNumericCode(c.Request.Context(), numericCode)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrCurrencyNotFound) {
+			errorResponseWithCode(c, http.StatusNotFound, ErrorCodeCurrencyNotFound, fmt.Sprintf("Currency with numeric code %s not found", numericCode), err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currency", err)
+		return
+	}
+	
+	data, err := selectFields(currency, fields)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currency", err)
+		return
+	}
+	
+	successResponse(c, data, "Currency retrieved successfully")
 }
 
 // CreateCurrency handles POST /api/v1/currencies
@@ -144,106 +703,572 @@ func (h *CurrencyHandler) CreateCurrency(c *gin.Context) {
 	var req CreateCurrencyRequest
 	
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.errorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		bindingError(c, err)
 		return
 	}
 	
-	// Convert to uppercase
-	req.Code = strings.ToUpper(req.Code)
-	
 	// Create currency model
 	currency := &model.Currency{
 		Code:                req.Code,
+		NumericCode:         req.NumericCode,
 		Description:         req.Description,
 		AmountDisplayFormat: req.AmountDisplayFormat,
 		HtmlEncodedSymbol:   req.HtmlEncodedSymbol,
 		Factor:              req.Factor,
+		RoundingMode:        money.RoundingMode(req.RoundingMode),
+		ValidFrom:           req.ValidFrom,
+		ValidUntil:          req.ValidUntil,
+		VisibleAfter:        req.VisibleAfter,
 	}
 	
 	if err := h.currencyService.CreateCurrency(c.Request.Context(), currency); err != nil {
-		if strings.Contains(err.Error(), "duplicate") {
-			h.errorResponse(c, http.StatusConflict, "Currency code already exists", err)
+		if errors.Is(err, service.ErrInvalidCurrency) {
+			errorResponse(c, http.StatusUnprocessableEntity, err.Error(), err)
+			return
+		}
+		if errors.Is(err, service.ErrMissingField) {
+			errorResponse(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		if errors.Is(err, service.ErrUnauthenticated) {
+			errorResponse(c, http.StatusUnauthorized, "Authentication required", err)
+			return
+		}
+		if errors.Is(err, service.ErrCurrencyLimitReached) {
+			errorResponse(c, http.StatusForbidden, "Maximum number of currencies reached", err)
+			return
+		}
+		if errors.Is(err, apperrors.ErrDuplicateCurrency) {
+			errorResponse(c, http.StatusConflict, "Currency code already exists", err)
 			return
 		}
-		h.errorResponse(c, http.StatusInternalServerError, "Failed to create currency", err)
+		errorResponse(c, http.StatusInternalServerError, "Failed to create currency", err)
 		return
 	}
 	
-	h.successResponse(c, currency, "Currency created successfully")
+	successResponse(c, currency, "Currency created successfully")
+}
+
+// CreateCurrenciesBatch handles POST /api/v1/currencies/batch
+func (h *CurrencyHandler) CreateCurrenciesBatch(c *gin.Context) {
+	var req []CreateCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingError(c, err)
+		return
+	}
+	if len(req) == 0 {
+		errorResponse(c, http.StatusUnprocessableEntity, "Batch must contain at least one item", nil)
+		return
+	}
+
+	currencies := make([]*model.Currency, len(req))
+	for i, item := range req {
+		currencies[i] = &model.Currency{
+			Code:                item.Code,
+			NumericCode:         item.NumericCode,
+			Description:         item.Description,
+			AmountDisplayFormat: item.AmountDisplayFormat,
+			HtmlEncodedSymbol:   item.HtmlEncodedSymbol,
+			Factor:              item.Factor,
+			RoundingMode:        money.RoundingMode(item.RoundingMode),
+			ValidFrom:           item.ValidFrom,
+			ValidUntil:          item.ValidUntil,
+			VisibleAfter:        item.VisibleAfter,
+		}
+	}
+
+	results, err := h.currencyService.CreateCurrenciesBatch(c.Request.Context(), currencies)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBatchFailed):
+			writeEnvelope(c, http.StatusUnprocessableEntity, APIResponse{
+				Success:   false,
+				Data:      results,
+				Error:     "Batch create rolled back",
+				Timestamp: model.Now(),
+			})
+		case errors.Is(err, service.ErrDuplicateCodes):
+			errorResponse(c, http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, service.ErrCurrencyLimitReached):
+			errorResponse(c, http.StatusForbidden, "Maximum number of currencies reached", err)
+		default:
+			errorResponse(c, http.StatusInternalServerError, "Failed to create currencies", err)
+		}
+		return
+	}
+
+	writeEnvelope(c, http.StatusCreated, APIResponse{
+		Success:   true,
+		Data:      currencies,
+		Message:   "Currencies created successfully",
+		Timestamp: model.Now(),
+	})
 }
 
 // UpdateCurrency handles PUT /api/v1/currencies/:code
 func (h *CurrencyHandler) UpdateCurrency(c *gin.Context) {
-	code := strings.ToUpper(c.Param("code"))
-	
-	// Validate currency code format
-	if len(code) != 3 {
-		h.errorResponse(c, http.StatusBadRequest, "Invalid currency code format", nil)
+	code, err := model.NormalizeCode(c.Param("code"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
 		return
 	}
 	
 	var req UpdateCurrencyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.errorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+		bindingError(c, err)
 		return
 	}
 	
 	// Get existing currency
 	currency, err := h.currencyService.GetCurrencyByCode(c.Request.Context(), code)
 	if err != nil {
-		h.errorResponse(c, http.StatusNotFound, "Currency not found", err)
+		currencyLookupFailed(c, code, err)
 		return
 	}
 	
-	// Update fields if provided
-	if req.Description != "" {
-		currency.Description = req.Description
-	}
-	if req.AmountDisplayFormat != "" {
-		currency.AmountDisplayFormat = req.AmountDisplayFormat
-	}
-	if req.HtmlEncodedSymbol != "" {
-		currency.HtmlEncodedSymbol = req.HtmlEncodedSymbol
-	}
-	if req.Factor > 0 {
-		currency.Factor = req.Factor
+	// The update only applies if nobody changed the currency since the client read it
+	version, ok := expectedVersion(c, req.Version, currency)
+	if !ok {
+		return
 	}
+	currency.Version = version
+	
+	// Replace every editable field; omitted optional fields are cleared
+	currency.NumericCode = req.NumericCode
+	currency.Description = req.Description
+	currency.AmountDisplayFormat = req.AmountDisplayFormat
+	currency.HtmlEncodedSymbol = req.HtmlEncodedSymbol
+	currency.Factor = req.Factor
+	currency.RoundingMode = money.RoundingMode(req.RoundingMode)
+	currency.ValidFrom = req.ValidFrom
+	currency.ValidUntil = req.ValidUntil
+	currency.VisibleAfter = req.VisibleAfter
 	
 	if err := h.currencyService.UpdateCurrency(c.Request.Context(), currency); err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, "Failed to update currency", err)
+		if errors.Is(err, service.ErrInvalidCurrency) {
+			errorResponse(c, http.StatusUnprocessableEntity, err.Error(), err)
+			return
+		}
+		if errors.Is(err, service.ErrUnauthenticated) {
+			errorResponse(c, http.StatusUnauthorized, "Authentication required", err)
+			return
+		}
+		if errors.Is(err, apperrors.ErrDuplicateCurrency) {
+			errorResponse(c, http.StatusConflict, "Numeric code already used by another currency", err)
+			return
+		}
+		if errors.Is(err, apperrors.ErrStaleUpdate) {
+			staleUpdate(c, code, err)
+			return
+		}
+		if errors.Is(err, apperrors.ErrCurrencyNotFound) {
+			currencyNotFound(c, code, err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, "Failed to update currency", err)
 		return
 	}
 	
-	h.successResponse(c, currency, "Currency updated successfully")
+	successResponse(c, currency, "Currency updated successfully")
 }
 
-// DeleteCurrency handles DELETE /api/v1/currencies/:code
+// DeleteCurrency handles DELETE /api/v1/currencies/:code[?force=true]
 func (h *CurrencyHandler) DeleteCurrency(c *gin.Context) {
-	code := strings.ToUpper(c.Param("code"))
-	
-	// Validate currency code format
-	if len(code) != 3 {
-		h.errorResponse(c, http.StatusBadRequest, "Invalid currency code format", nil)
+	code, err := model.NormalizeCode(c.Param("code"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
 		return
 	}
 	
 	// Get currency to get its ID
 	currency, err := h.currencyService.GetCurrencyByCode(c.Request.Context(), code)
 	if err != nil {
-		h.errorResponse(c, http.StatusNotFound, "Currency not found", err)
+		currencyLookupFailed(c, code, err)
 		return
 	}
 	
-	if err := h.currencyService.DeleteCurrency(c.Request.Context(), currency.ID); err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, "Failed to delete currency", err)
+	// ?force=true removes the record permanently instead of soft-deleting it
+	force, _ := strconv.ParseBool(c.Query("force"))
+	deleteCurrency := h.currencyService.DeleteCurrency
+	if force {
+		deleteCurrency = h.currencyService.HardDeleteCurrency
+	}
+	
+	if err := deleteCurrency(c.Request.Context(), currency.ID); err != nil {
+		// The currency may have been deleted concurrently since the lookup
+		if errors.Is(err, apperrors.ErrCurrencyNotFound) {
+			currencyNotFound(c, code, err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, "Failed to delete currency", err)
 		return
 	}
 	
-	h.successResponse(c, nil, "Currency deleted successfully")
+	successResponse(c, nil, "Currency deleted successfully")
+}
+
+// PatchCurrency handles PATCH /api/v1/currencies/:code
+func (h *CurrencyHandler) PatchCurrency(c *gin.Context) {
+	code, err := model.NormalizeCode(c.Param("code"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return
+	}
+
+	var req PatchCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	// The current record is only needed to check an If-Match header
+	var current *model.Currency
+	if req.Version == 0 && c.GetHeader("If-Match") != "" {
+		var err error
+		current, err = h.currencyService.GetCurrencyByCode(c.Request.Context(), code)
+		if err != nil {
+			currencyLookupFailed(c, code, err)
+			return
+		}
+	}
+	version, ok := expectedVersion(c, req.Version, current)
+	if !ok {
+		return
+	}
+
+	currency, err := h.currencyService.PatchCurrency(c.Request.Context(), service.CurrencyPatch{
+		Code:                code,
+		Version:             version,
+		Description:         req.Description,
+		AmountDisplayFormat: req.AmountDisplayFormat,
+		HtmlEncodedSymbol:   req.HtmlEncodedSymbol,
+		Factor:              req.Factor,
+		RoundingMode:        (*money.RoundingMode)(req.RoundingMode),
+	})
+	if err != nil {
+		if errors.Is(err, apperrors.ErrCurrencyNotFound) {
+			currencyNotFound(c, code, err)
+			return
+		}
+		if errors.Is(err, apperrors.ErrStaleUpdate) {
+			staleUpdate(c, code, err)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCurrency) {
+			errorResponse(c, http.StatusUnprocessableEntity, err.Error(), err)
+			return
+		}
+		if errors.Is(err, service.ErrUnauthenticated) {
+			errorResponse(c, http.StatusUnauthorized, "Authentication required", err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, "Failed to update currency", err)
+		return
+	}
+
+	successResponse(c, currency, "Currency updated successfully")
+}
+
+// ImportCurrencies handles POST /api/v1/currencies/import. It accepts a CSV
+// or JSON file in the multipart "file" field; ?upsert=true updates existing
+// codes instead of skipping them.
+func (h *CurrencyHandler) ImportCurrencies(c *gin.Context) {
+	upsert := false
+	if value := c.Query("upsert"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, "Invalid upsert parameter", err)
+			return
+		}
+		upsert = parsed
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Missing import file in the \"file\" form field", err)
+		return
+	}
+	if file.Size > maxImportSize {
+		errorResponse(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Import file must not exceed %d bytes", maxImportSize), nil)
+		return
+	}
+
+	var format string
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		format = service.ImportFormatCSV
+	case ".json":
+		format = service.ImportFormatJSON
+	default:
+		errorResponse(c, http.StatusUnsupportedMediaType, "Import file must be a .csv or .json file", nil)
+		return
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to read import file", err)
+		return
+	}
+	defer f.Close()
+
+	rows, err := service.ParseCurrencyImport(format, f)
+	if err != nil {
+		if errors.Is(err, service.ErrMalformedImport) {
+			errorResponse(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, "Failed to read import file", err)
+		return
+	}
+	if len(rows) == 0 {
+		errorResponse(c, http.StatusUnprocessableEntity, "Import file contains no currencies", nil)
+		return
+	}
+
+	summary, err := h.currencyService.ImportCurrencies(c.Request.Context(), rows, upsert)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnauthenticated):
+			errorResponse(c, http.StatusUnauthorized, "Authentication required", err)
+		case errors.Is(err, service.ErrCurrencyLimitReached):
+			errorResponse(c, http.StatusForbidden, "Maximum number of currencies reached", err)
+		case errors.Is(err, apperrors.ErrDuplicateCurrency):
+			// A currency with the same code was created concurrently
+			errorResponse(c, http.StatusConflict, err.Error(), err)
+		default:
+			errorResponse(c, http.StatusInternalServerError, "Failed to import currencies", err)
+		}
+		return
+	}
+
+	successResponse(c, summary, "Currencies imported")
+}
+
+// PatchCurrencies handles PATCH /api/v1/currencies/batch
+func (h *CurrencyHandler) PatchCurrencies(c *gin.Context) {
+	var req []PatchCurrencyItem
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingError(c, err)
+		return
+	}
+	if len(req) == 0 {
+		errorResponse(c, http.StatusUnprocessableEntity, "Batch must contain at least one item", nil)
+		return
+	}
+
+	atomic := true
+	if value := c.Query("atomic"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, "Invalid atomic parameter", err)
+			return
+		}
+		atomic = parsed
+	}
+
+	patches := make([]service.CurrencyPatch, len(req))
+	for i, item := range req {
+		code, err := model.NormalizeCode(item.Code)
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, fmt.Sprintf("Invalid currency code format in item %d", i), err)
+			return
+		}
+		patches[i] = service.CurrencyPatch{
+			Code:                code,
+			Version:             item.Version,
+			Description:         item.Description,
+			AmountDisplayFormat: item.AmountDisplayFormat,
+			HtmlEncodedSymbol:   item.HtmlEncodedSymbol,
+			Factor:              item.Factor,
+			RoundingMode:        (*money.RoundingMode)(item.RoundingMode),
+		}
+	}
+
+	results, err := h.currencyService.PatchCurrencies(c.Request.Context(), patches, atomic)
+	if err != nil {
+		if errors.Is(err, service.ErrBatchFailed) {
+			writeEnvelope(c, http.StatusUnprocessableEntity, APIResponse{
+				Success:   false,
+				Data:      results,
+				Error:     "Batch update rolled back",
+				Timestamp: model.Now(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrDuplicateCodes) {
+			errorResponse(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		if errors.Is(err, service.ErrUnauthenticated) {
+			errorResponse(c, http.StatusUnauthorized, "Authentication required", err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, "Failed to update currencies", err)
+		return
+	}
+
+	successResponse(c, results, "Currencies updated successfully")
+}
+
+// RestoreCurrency handles POST /api/v1/currencies/:code/restore
+func (h *CurrencyHandler) RestoreCurrency(c *gin.Context) {
+	code, err := model.NormalizeCode(c.Param("code"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return
+	}
+
+	currency, err := h.currencyService.RestoreCurrency(c.Request.Context(), code)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrCurrencyNotFound):
+			errorResponseWithCode(c, http.StatusNotFound, ErrorCodeCurrencyNotFound, fmt.Sprintf("No deleted currency %s found", code), err)
+		case errors.Is(err, apperrors.ErrDuplicateCurrency):
+			errorResponse(c, http.StatusConflict, fmt.Sprintf("Currency %s conflicts with an active currency", code), err)
+		default:
+			errorResponse(c, http.StatusInternalServerError, "Failed to restore currency", err)
+		}
+		return
+	}
+
+	successResponse(c, currency, "Currency restored successfully")
+}
+
+// DeactivateCurrency handles POST /api/v1/currencies/:code/deactivate
+func (h *CurrencyHandler) DeactivateCurrency(c *gin.Context) {
+	h.setCurrencyActive(c, false)
+}
+
+// ActivateCurrency handles POST /api/v1/currencies/:code/activate
+func (h *CurrencyHandler) ActivateCurrency(c *gin.Context) {
+	h.setCurrencyActive(c, true)
+}
+
+// setCurrencyActive activates or deactivates the currency named in the path
+func (h *CurrencyHandler) setCurrencyActive(c *gin.Context, active bool) {
+	code, err := model.NormalizeCode(c.Param("code"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return
+	}
+
+	var currency *model.Currency
+	message := "Currency activated successfully"
+	if active {
+		currency, err = h.currencyService.ActivateCurrency(c.Request.Context(), code)
+	} else {
+		currency, err = h.currencyService.DeactivateCurrency(c.Request.Context(), code)
+		message = "Currency deactivated successfully"
+	}
+	if err != nil {
+		if errors.Is(err, apperrors.ErrCurrencyNotFound) {
+			currencyNotFound(c, code, err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, "Failed to update currency status", err)
+		return
+	}
+
+	successResponse(c, currency, message)
 }
 
 // Helper methods
 
+// parseSort reads ?sort=field (or ?sort=-field for descending) and the
+// optional ?order=asc|desc. Field validation is left to the repository
+// allow-list, which falls back to code ASC.
+func parseSort(c *gin.Context) (string, string) {
+	sortField := c.DefaultQuery("sort", "code")
+	sortOrder := strings.ToLower(c.DefaultQuery("order", "asc"))
+	if strings.HasPrefix(sortField, "-") {
+		sortField = strings.TrimPrefix(sortField, "-")
+		sortOrder = "desc"
+	}
+	return sortField, sortOrder
+}
+
+// parseListFilter parses the optional created_after, created_before,
+// updated_after and updated_before RFC 3339 timestamps, and valid_on, which
+// also accepts a plain date meaning midnight UTC. It writes a 400 response
+// and returns false if one is malformed or a range is empty.
+func parseListFilter(c *gin.Context) (repository.ListFilter, bool) {
+	var filter repository.ListFilter
+	params := []struct {
+		name  string
+		bound **time.Time
+	}{
+		{"created_after", &filter.CreatedAfter},
+		{"created_before", &filter.CreatedBefore},
+		{"updated_after", &filter.UpdatedAfter},
+		{"updated_before", &filter.UpdatedBefore},
+	}
+	for _, param := range params {
+		raw := c.Query(param.name)
+		if raw == "" {
+			continue
+		}
+		// An unescaped "+" in a UTC offset arrives decoded as a space
+		t, err := time.Parse(time.RFC3339, strings.ReplaceAll(raw, " ", "+"))
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, fmt.Sprintf("Invalid %s, must be an RFC 3339 timestamp", param.name), err)
+			return filter, false
+		}
+		*param.bound = &t
+	}
+
+	if raw := c.Query("valid_on"); raw != "" {
+		t, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			t, err = time.Parse(time.RFC3339, strings.ReplaceAll(raw, " ", "+"))
+		}
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, "Invalid valid_on, must be a date (YYYY-MM-DD) or an RFC 3339 timestamp", err)
+			return filter, false
+		}
+		filter.ValidOn = &t
+	}
+
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && !filter.CreatedAfter.Before(*filter.CreatedBefore) {
+		errorResponse(c, http.StatusBadRequest, "created_after must be before created_before", nil)
+		return filter, false
+	}
+	if filter.UpdatedAfter != nil && filter.UpdatedBefore != nil && !filter.UpdatedAfter.Before(*filter.UpdatedBefore) {
+		errorResponse(c, http.StatusBadRequest, "updated_after must be before updated_before", nil)
+		return filter, false
+	}
+
+	return filter, true
+}
+
+// parseCodes parses the comma separated ?codes= parameter into upper-cased
+// codes. It writes a 400 response and returns false if the list is invalid.
+func parseCodes(c *gin.Context) ([]string, bool) {
+	raw := c.Query("codes")
+	if raw == "" {
+		return nil, true
+	}
+
+	var codes []string
+	for _, code := range strings.Split(raw, ",") {
+		if strings.TrimSpace(code) == "" {
+			continue
+		}
+		code, err := model.NormalizeCode(code)
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+			return nil, false
+		}
+		codes = append(codes, code)
+	}
+
+	if len(codes) > maxCodes {
+		errorResponse(c, http.StatusBadRequest, fmt.Sprintf("At most %d codes can be requested at once", maxCodes), nil)
+		return nil, false
+	}
+
+	return codes, true
+}
+
 func (h *CurrencyHandler) getQueryInt(c *gin.Context, param string, defaultValue int) int {
 	valueStr := c.Query(param)
 	if valueStr == "" {
@@ -258,34 +1283,61 @@ func (h *CurrencyHandler) getQueryInt(c *gin.Context, param string, defaultValue
 	return value
 }
 
-func (h *CurrencyHandler) successResponse(c *gin.Context, data interface{}, message string) {
-	response := APIResponse{
-		Success:   true,
-		Data:      data,
-		Message:   message,
-		Timestamp: time.Now().UTC(),
+// currencyNotFound writes a 404 echoing the requested code. Callers must have
+// normalized code with model.NormalizeCode so no raw input is reflected.
+func currencyNotFound(c *gin.Context, code string, err error) {
+	errorResponseWithCode(c, http.StatusNotFound, ErrorCodeCurrencyNotFound, fmt.Sprintf("Currency %s not found", code), err)
+}
+
+// expectedVersion returns the version a PUT or PATCH expects the currency to
+// be at: the version from the request body if set, otherwise current's version
+// if the If-Match header matches its ETag. It writes a 428 when neither is
+// sent and a 412 when If-Match doesn't match, and returns false.
+func expectedVersion(c *gin.Context, bodyVersion int, current *model.Currency) (int, bool) {
+	if bodyVersion > 0 {
+		return bodyVersion, true
 	}
-	
-	statusCode := http.StatusOK
-	if message == "Currency created successfully" {
-		statusCode = http.StatusCreated
+
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		errorResponse(c, http.StatusPreconditionRequired, "A version field or If-Match header is required", nil)
+		return 0, false
 	}
-	
-	c.JSON(statusCode, response)
+	if current == nil || !ifMatchMatches(ifMatch, currencyETag(current)) {
+		errorResponse(c, http.StatusPreconditionFailed, "Currency has been modified since it was read", nil)
+		return 0, false
+	}
+
+	return current.Version, true
+}
+
+// staleUpdate writes a 409 for an update based on an outdated version
+func staleUpdate(c *gin.Context, code string, err error) {
+	errorResponse(c, http.StatusConflict, fmt.Sprintf("Currency %s was modified by another request; fetch it again and retry", code), err)
 }
 
-func (h *CurrencyHandler) errorResponse(c *gin.Context, statusCode int, message string, err error) {
-	response := APIResponse{
-		Success:   false,
-		Error:     message,
-		Timestamp: time.Now().UTC(),
+// currencyInactive writes a 410 for an operation on a deactivated currency
+func currencyInactive(c *gin.Context, code string, err error) {
+	errorResponseWithCode(c, http.StatusGone, ErrorCodeCurrencyInactive, fmt.Sprintf("Currency %s is no longer active", code), err)
+}
+
+// activeCurrencies returns the active currencies of currencies, in order
+func activeCurrencies(currencies []*model.Currency) []*model.Currency {
+	active := make([]*model.Currency, 0, len(currencies))
+	for _, currency := range currencies {
+		if currency.IsActive {
+			active = append(active, currency)
+		}
 	}
-	
-	// Log the actual error for debugging
-	if err != nil {
-		// In production, you'd want to use a proper logger
-		println("Error:", err.Error())
+	return active
+}
+
+// currencyLookupFailed responds to a failed lookup of the currency with the
+// given code: 404 when it doesn't exist, 500 for any other error
+func currencyLookupFailed(c *gin.Context, code string, err error) {
+	if errors.Is(err, apperrors.ErrCurrencyNotFound) {
+		currencyNotFound(c, code, err)
+		return
 	}
-	
-	c.JSON(statusCode, response)
+	errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currency", err)
 }
\ No newline at end of file
@@ -0,0 +1,567 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/auth"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPatchRouter(svc *fakeCurrencyService) http.Handler {
+	h := NewCurrencyHandler(svc, nil, testPagination)
+	router := newTestRouter()
+	router.PATCH("/api/v1/currencies/:code", h.PatchCurrency)
+	router.PATCH("/api/v1/currencies/batch", h.PatchCurrencies)
+	return router
+}
+
+func newPutRouter(svc *fakeCurrencyService) http.Handler {
+	h := NewCurrencyHandler(svc, nil, testPagination)
+	router := newTestRouter()
+	router.PUT("/api/v1/currencies/:code", h.UpdateCurrency)
+	return router
+}
+
+func TestPutReplacesEveryField(t *testing.T) {
+	validFrom := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc := newFakeCurrencyService(&model.Currency{
+		Code: "USD", NumericCode: "840", Description: "US Dollar", AmountDisplayFormat: "###,###.##",
+		HtmlEncodedSymbol: "&#36;", Factor: 100, RoundingMode: "half_even", ValidFrom: &validFrom, IsActive: true, Version: 1,
+	})
+
+	// Optional fields left out of the representation are cleared
+	w := serve(newPutRouter(svc), http.MethodPut, "/api/v1/currencies/USD",
+		`{"version":1,"description":"United States dollar","amount_display_format":"#,##0.00","factor":1000,"rounding_mode":"up"}`, nil)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	stored := svc.currencies["USD"]
+	assert.Equal(t, "United States dollar", stored.Description)
+	assert.Equal(t, "#,##0.00", stored.AmountDisplayFormat)
+	assert.Equal(t, 1000, stored.Factor)
+	assert.Equal(t, "up", string(stored.RoundingMode))
+	assert.Empty(t, stored.NumericCode)
+	assert.Empty(t, stored.HtmlEncodedSymbol)
+	assert.Nil(t, stored.ValidFrom)
+	assert.True(t, stored.IsActive, "activity isn't part of the representation")
+	assert.Equal(t, 2, stored.Version)
+}
+
+func TestPutRequiresFullRepresentation(t *testing.T) {
+	svc := newFakeCurrencyService(&model.Currency{Code: "USD", Description: "US Dollar", Factor: 100, Version: 1})
+
+	w := serve(newPutRouter(svc), http.MethodPut, "/api/v1/currencies/USD", `{"version":1,"description":"US Dollar"}`, nil)
+
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	var resp APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	var missing []string
+	for _, detail := range resp.Details {
+		assert.Equal(t, "required", detail.Rule, detail.Field)
+		missing = append(missing, detail.Field)
+	}
+	assert.ElementsMatch(t, []string{"amount_display_format", "factor", "rounding_mode"}, missing)
+	assert.Equal(t, 1, svc.currencies["USD"].Version)
+}
+
+func TestPatchCurrencyClearsSymbolAndKeepsDescription(t *testing.T) {
+	svc := newFakeCurrencyService(&model.Currency{Code: "USD", Description: "US Dollar", HtmlEncodedSymbol: "&#36;", Factor: 100, Version: 1})
+
+	w := serve(newPatchRouter(svc), http.MethodPatch, "/api/v1/currencies/USD", `{"version":1,"html_encoded_symbol":""}`, nil)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, svc.patches, 1)
+	patch := svc.patches[0]
+	require.NotNil(t, patch.HtmlEncodedSymbol)
+	assert.Equal(t, "", *patch.HtmlEncodedSymbol)
+	assert.Nil(t, patch.Description)
+
+	var resp struct {
+		Data model.Currency `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "", resp.Data.HtmlEncodedSymbol)
+	assert.Equal(t, "US Dollar", resp.Data.Description)
+}
+
+func TestPatchRejectsNonPositiveFactor(t *testing.T) {
+	for _, tc := range []struct{ path, body string }{
+		{"/api/v1/currencies/USD", `{"version":1,"factor":0}`},
+		{"/api/v1/currencies/USD", `{"version":1,"factor":-100}`},
+		{"/api/v1/currencies/batch", `[{"code":"USD","version":1,"factor":0}]`},
+	} {
+		svc := newFakeCurrencyService(&model.Currency{Code: "USD", Description: "US Dollar", Factor: 100, Version: 1})
+
+		w := serve(newPatchRouter(svc), http.MethodPatch, tc.path, tc.body, nil)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, tc.body)
+		assert.Contains(t, w.Body.String(), `"field":"factor"`, tc.body)
+		assert.Empty(t, svc.patches, tc.body)
+	}
+}
+
+func TestHeadCurrencyReportsExistenceWithoutBody(t *testing.T) {
+	svc := newFakeCurrencyService(&model.Currency{ID: uuid.New(), Code: "USD", Description: "US Dollar", Factor: 100, Version: 1})
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies/:code", h.GetCurrencyByCode)
+	router.HEAD("/api/v1/currencies/:code", h.GetCurrencyByCode)
+	// Only a real server drops the body of HEAD responses
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	do := func(method, code string, header map[string]string) (*http.Response, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL+"/api/v1/currencies/"+code, nil)
+		require.NoError(t, err)
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	get, getBody := do(http.MethodGet, "USD", nil)
+	require.Equal(t, http.StatusOK, get.StatusCode)
+	require.NotEmpty(t, getBody)
+
+	head, headBody := do(http.MethodHead, "USD", nil)
+	assert.Equal(t, http.StatusOK, head.StatusCode)
+	assert.Empty(t, headBody)
+	assert.Equal(t, get.Header.Get("Content-Length"), head.Header.Get("Content-Length"))
+	assert.Equal(t, get.Header.Get("ETag"), head.Header.Get("ETag"))
+
+	head, headBody = do(http.MethodHead, "USD", map[string]string{"If-None-Match": get.Header.Get("ETag")})
+	assert.Equal(t, http.StatusNotModified, head.StatusCode)
+	assert.Empty(t, headBody)
+
+	head, headBody = do(http.MethodHead, "XYZ", nil)
+	assert.Equal(t, http.StatusNotFound, head.StatusCode)
+	assert.Empty(t, headBody)
+}
+
+func newInactiveRouter(svc *fakeCurrencyService) http.Handler {
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies", h.GetCurrencies)
+	router.GET("/api/v1/currencies/count", h.CountCurrencies)
+	router.GET("/api/v1/currencies/grouped", h.GetCurrenciesGrouped)
+	return router
+}
+
+func TestEndpointsLeaveOutInactiveCurrenciesUnlessIncluded(t *testing.T) {
+	router := newInactiveRouter(newListTestService())
+
+	for _, tc := range []struct {
+		query string
+		total int
+	}{
+		{"", 8},
+		{"?include_inactive=false", 8},
+		{"?include_inactive=true", 9},
+	} {
+		list := getList(t, router, "limit=100&"+strings.TrimPrefix(tc.query, "?"))
+		assert.Len(t, list.Data, tc.total, "list%s", tc.query)
+		assert.Equal(t, int64(tc.total), list.Pagination.Total, "list%s", tc.query)
+
+		w := serve(router, http.MethodGet, "/api/v1/currencies/count"+tc.query, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var count struct {
+			Data CurrencyCountResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &count))
+		assert.Equal(t, int64(tc.total), count.Data.Total, "count%s", tc.query)
+
+		w = serve(router, http.MethodGet, "/api/v1/currencies/grouped"+tc.query, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var grouped struct {
+			Data map[string][]model.Currency `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &grouped))
+		assert.Len(t, grouped.Data["100"], tc.total, "grouped%s", tc.query)
+	}
+}
+
+func TestCountCurrenciesFilteredAndUnfiltered(t *testing.T) {
+	router := newInactiveRouter(newFakeCurrencyService(
+		&model.Currency{Code: "USD", Description: "US Dollar", Factor: 100, IsActive: true},
+		&model.Currency{Code: "CAD", Description: "Canadian Dollar", Factor: 100, IsActive: true},
+		&model.Currency{Code: "JPY", Description: "Japanese Yen", Factor: 1, IsActive: true},
+		&model.Currency{Code: "BHD", Description: "Bahraini Dinar", Factor: 1000, IsActive: true},
+		&model.Currency{Code: "ZWD", Description: "Zimbabwe Dollar", Factor: 100},
+	))
+
+	tests := map[string]int64{
+		"":                                  4,
+		"?include_inactive=true":            5,
+		"?search=dollar":                    2,
+		"?search=dollar&include_inactive=1": 3,
+		"?factor=100":                       2,
+		"?factor=1":                         1,
+		"?factor=5":                         0,
+		"?search=dinar&factor=1000":         1,
+		"?search=dinar&factor=100":          0,
+		"?search=peso":                      0,
+	}
+	for query, want := range tests {
+		w := serve(router, http.MethodGet, "/api/v1/currencies/count"+query, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, "%s: %s", query, w.Body.String())
+		var resp struct {
+			Data CurrencyCountResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, want, resp.Data.Total, query)
+	}
+
+	for _, query := range []string{"?field=symbol", "?match=fuzzy", "?show_hidden=maybe"} {
+		w := serve(router, http.MethodGet, "/api/v1/currencies/count"+query, "", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "%s: %s", query, w.Body.String())
+	}
+}
+
+func TestGroupedCurrenciesKeysAndCounts(t *testing.T) {
+	router := newInactiveRouter(newFakeCurrencyService(
+		&model.Currency{Code: "USD", Description: "US Dollar", Factor: 100, IsActive: true},
+		&model.Currency{Code: "CAD", Description: "Canadian Dollar", Factor: 100, IsActive: true},
+		&model.Currency{Code: "EUR", Description: "Euro", Factor: 100, IsActive: true},
+		&model.Currency{Code: "JPY", Description: "Japanese Yen", Factor: 1, IsActive: true},
+		&model.Currency{Code: "KWD", Description: "Kuwaiti Dinar", Factor: 1000, IsActive: true},
+		&model.Currency{Code: "BHD", Description: "Bahraini Dinar", Factor: 1000, IsActive: true},
+	))
+
+	grouped := func(query string) map[string][]string {
+		w := serve(router, http.MethodGet, "/api/v1/currencies/grouped"+query, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp struct {
+			Data map[string][]model.Currency `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		codes := make(map[string][]string, len(resp.Data))
+		for factor, currencies := range resp.Data {
+			for _, currency := range currencies {
+				codes[factor] = append(codes[factor], currency.Code)
+			}
+		}
+		return codes
+	}
+
+	assert.Equal(t, map[string][]string{
+		"1":    {"JPY"},
+		"100":  {"CAD", "EUR", "USD"},
+		"1000": {"BHD", "KWD"},
+	}, grouped(""))
+	assert.Equal(t, map[string][]string{"100": {"CAD", "USD"}}, grouped("?search=dollar"))
+	assert.Empty(t, grouped("?search=peso"))
+
+	w := serve(router, http.MethodGet, "/api/v1/currencies/grouped?search=usd&field=symbol", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
+
+func TestCurrenciesWithoutRatesListsUncoveredCurrencies(t *testing.T) {
+	svc := newListTestService()
+	// Only some currencies are quoted against USD; DEM is inactive
+	svc.rated = map[string][]string{"USD": {"EUR", "GBP", "JPY"}}
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies/without-rates", h.GetCurrenciesWithoutRates)
+
+	w := serve(router, http.MethodGet, "/api/v1/currencies/without-rates?base=usd", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data []model.Currency `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	var codes []string
+	for _, currency := range resp.Data {
+		codes = append(codes, currency.Code)
+	}
+	assert.Equal(t, []string{"AUD", "CAD", "CHF", "NZD"}, codes)
+
+	w = serve(router, http.MethodGet, "/api/v1/currencies/without-rates?base=XAU", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	for _, query := range []string{"", "?base=US"} {
+		w = serve(router, http.MethodGet, "/api/v1/currencies/without-rates"+query, "", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestCurrencyAuditListsChangesWithActingUser(t *testing.T) {
+	creator, editor := uuid.New(), uuid.New()
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	svc := newFakeCurrencyService()
+	svc.changeLog = []*model.ChangeLog{
+		{CurrencyCode: "USD", Action: model.ChangeCreated, Version: 1, ChangedBy: &creator, ChangedAt: start},
+		{CurrencyCode: "USD", Action: model.ChangeUpdated, Version: 2, ChangedBy: &editor, ChangedAt: start.Add(time.Hour)},
+		{CurrencyCode: "USD", Action: model.ChangeDeleted, Version: 2, ChangedAt: start.Add(2 * time.Hour)},
+	}
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies/:code/audit", h.GetCurrencyAudit)
+
+	// The currency is gone, its trail isn't
+	w := serve(router, http.MethodGet, "/api/v1/currencies/usd/audit", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data []struct {
+			Action    string  `json:"action"`
+			Version   int     `json:"version"`
+			ChangedBy *string `json:"changed_by"`
+			ChangedAt string  `json:"changed_at"`
+		} `json:"data"`
+		Pagination struct {
+			Total int64 `json:"total"`
+		} `json:"pagination"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 3)
+	assert.Equal(t, int64(3), resp.Pagination.Total)
+	assert.Equal(t, "created", resp.Data[0].Action)
+	assert.Equal(t, creator.String(), *resp.Data[0].ChangedBy)
+	assert.Equal(t, "2024-01-02T03:04:05.000000Z", resp.Data[0].ChangedAt)
+	assert.Equal(t, "updated", resp.Data[1].Action)
+	assert.Equal(t, 2, resp.Data[1].Version)
+	assert.Equal(t, editor.String(), *resp.Data[1].ChangedBy)
+	assert.Equal(t, "deleted", resp.Data[2].Action)
+	assert.Nil(t, resp.Data[2].ChangedBy)
+
+	w = serve(router, http.MethodGet, "/api/v1/currencies/XAU/audit", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = serve(router, http.MethodGet, "/api/v1/currencies/US/audit", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetCurrencySymbolDecodesEntity(t *testing.T) {
+	svc := newFakeCurrencyService(
+		&model.Currency{Code: "USD", HtmlEncodedSymbol: "&#36;", AmountDisplayFormat: "###,###.##", IsActive: true},
+		&model.Currency{Code: "EUR", HtmlEncodedSymbol: "&euro;", IsActive: true},
+		&model.Currency{Code: "CHF", HtmlEncodedSymbol: "CHF", IsActive: true},
+	)
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies/:code/symbol", h.GetCurrencySymbol)
+
+	for code, want := range map[string]CurrencySymbolResponse{
+		"usd": {Code: "USD", HtmlEncodedSymbol: "&#36;", Symbol: "$", AmountDisplayFormat: "###,###.##"},
+		"EUR": {Code: "EUR", HtmlEncodedSymbol: "&euro;", Symbol: "€"},
+		"CHF": {Code: "CHF", HtmlEncodedSymbol: "CHF", Symbol: "CHF"},
+	} {
+		w := serve(router, http.MethodGet, "/api/v1/currencies/"+code+"/symbol", "", nil)
+		require.Equal(t, http.StatusOK, w.Code, "%s: %s", code, w.Body.String())
+		var resp struct {
+			Data CurrencySymbolResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, want, resp.Data, code)
+	}
+}
+
+func TestFormatAmountEndpoint(t *testing.T) {
+	svc := newFakeCurrencyService(
+		&model.Currency{Code: "USD", Factor: 100, AmountDisplayFormat: "###,###.##"},
+		&model.Currency{Code: "JPY", Factor: 1, AmountDisplayFormat: "###,###"},
+	)
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.POST("/api/v1/currencies/:code/format", h.FormatAmount)
+
+	tests := []struct {
+		path, body string
+		status     int
+		formatted  string
+	}{
+		{"/api/v1/currencies/usd/format", `{"amount":123456}`, http.StatusOK, "1,234.56"},
+		{"/api/v1/currencies/USD/format", `{"amount":-5}`, http.StatusOK, "-0.05"},
+		// Zero is an amount, not a missing one
+		{"/api/v1/currencies/USD/format", `{"amount":0}`, http.StatusOK, "0.00"},
+		{"/api/v1/currencies/JPY/format", `{"amount":1234567}`, http.StatusOK, "1,234,567"},
+		{"/api/v1/currencies/JPY/format", `{"amount":0}`, http.StatusOK, "0"},
+		{"/api/v1/currencies/USD/format", `{}`, http.StatusUnprocessableEntity, ""},
+		{"/api/v1/currencies/USD/format", `{"amount":1.5}`, http.StatusBadRequest, ""},
+		{"/api/v1/currencies/XYZ/format", `{"amount":1}`, http.StatusNotFound, ""},
+		{"/api/v1/currencies/US/format", `{"amount":1}`, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		w := serve(router, http.MethodPost, tt.path, tt.body, nil)
+		require.Equal(t, tt.status, w.Code, "%s %s: %s", tt.path, tt.body, w.Body.String())
+		if tt.status != http.StatusOK {
+			continue
+		}
+		var resp struct {
+			Data service.FormattedAmount `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, tt.formatted, resp.Data.Formatted, "%s %s", tt.path, tt.body)
+	}
+}
+
+func TestFormatPreviewFormatsEveryAmount(t *testing.T) {
+	svc := newFakeCurrencyService(
+		&model.Currency{Code: "USD", Factor: 100, AmountDisplayFormat: "###,###.##"},
+		&model.Currency{Code: "JPY", Factor: 1, AmountDisplayFormat: "###,###"},
+	)
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.POST("/api/v1/currencies/:code/format-preview", h.PreviewFormat)
+
+	type preview struct {
+		Data struct {
+			AmountDisplayFormat string `json:"amount_display_format"`
+			Amounts             []struct {
+				Amount    int64  `json:"amount"`
+				Formatted string `json:"formatted"`
+			} `json:"amounts"`
+		} `json:"data"`
+	}
+	formatted := func(resp preview) []string {
+		var out []string
+		for _, amount := range resp.Data.Amounts {
+			out = append(out, amount.Formatted)
+		}
+		return out
+	}
+
+	w := serve(router, http.MethodPost, "/api/v1/currencies/usd/format-preview", `{"amounts":[0,1,99,-99,100000000]}`, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp preview
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"0.00", "0.01", "0.99", "-0.99", "1,000,000.00"}, formatted(resp))
+	assert.Equal(t, int64(-99), resp.Data.Amounts[3].Amount)
+
+	w = serve(router, http.MethodPost, "/api/v1/currencies/USD/format-preview", `{"amounts":[-123456],"amount_display_format":"###.###,##"}`, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	resp = preview{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "###.###,##", resp.Data.AmountDisplayFormat)
+	assert.Equal(t, []string{"-1.234,56"}, formatted(resp))
+
+	// Zero-decimal currencies have no fraction
+	w = serve(router, http.MethodPost, "/api/v1/currencies/JPY/format-preview", `{"amounts":[0,-1,1000000]}`, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	resp = preview{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"0", "-1", "1,000,000"}, formatted(resp))
+}
+
+func TestFormatPreviewRejectsBadRequests(t *testing.T) {
+	svc := newFakeCurrencyService(&model.Currency{Code: "USD", Factor: 100, AmountDisplayFormat: "###,###.##"})
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.POST("/api/v1/currencies/:code/format-preview", h.PreviewFormat)
+
+	tests := []struct {
+		path   string
+		body   string
+		status int
+	}{
+		{"/api/v1/currencies/USD/format-preview", `{}`, http.StatusUnprocessableEntity},
+		{"/api/v1/currencies/USD/format-preview", `{"amounts":[]}`, http.StatusUnprocessableEntity},
+		{"/api/v1/currencies/USD/format-preview", `{"amounts":[1.5]}`, http.StatusBadRequest},
+		{"/api/v1/currencies/USD/format-preview", `{"amounts":[` + strings.Repeat("1,", 100) + `1]}`, http.StatusUnprocessableEntity},
+		{"/api/v1/currencies/XYZ/format-preview", `{"amounts":[1]}`, http.StatusNotFound},
+		{"/api/v1/currencies/US/format-preview", `{"amounts":[1]}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := serve(router, http.MethodPost, tt.path, tt.body, nil)
+		assert.Equal(t, tt.status, w.Code, "%s %s: %s", tt.path, tt.body, w.Body.String())
+	}
+}
+
+func TestShowHiddenListsSoftLaunchedCurrenciesForAuthenticatedUsers(t *testing.T) {
+	svc := newListTestService()
+	launch := time.Now().Add(time.Hour)
+	svc.currencies["XTS"] = &model.Currency{Code: "XTS", Description: "Test", Factor: 100, IsActive: true, VisibleAfter: &launch}
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.Use(func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			c.Request = c.Request.WithContext(auth.WithUserID(c.Request.Context(), uuid.New()))
+		}
+	})
+	router.GET("/api/v1/currencies", h.GetCurrencies)
+	bearer := map[string]string{"Authorization": "Bearer token"}
+
+	codes := func(resp listResponse) []string {
+		var out []string
+		for _, currency := range resp.Data {
+			out = append(out, currency.Code)
+		}
+		return out
+	}
+
+	listed := getList(t, router, "limit=100")
+	assert.NotContains(t, codes(listed), "XTS")
+	assert.Equal(t, int64(8), listed.Pagination.Total)
+
+	w := serve(router, http.MethodGet, "/api/v1/currencies?show_hidden=true", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = serve(router, http.MethodGet, "/api/v1/currencies?show_hidden=maybe", "", bearer)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(router, http.MethodGet, "/api/v1/currencies?show_hidden=true&limit=100", "", bearer)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var resp listResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Contains(t, codes(resp), "XTS")
+	assert.Equal(t, int64(9), resp.Pagination.Total)
+
+	// Keyset pages hide them the same way
+	listed = getList(t, router, "limit=100&cursor=")
+	assert.NotContains(t, codes(listed), "XTS")
+}
+
+func TestNotFoundResponsesEchoTheRequestedCode(t *testing.T) {
+	h := NewCurrencyHandler(newListTestService(), fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies/:code", h.GetCurrencyByCode)
+	router.GET("/api/v1/currencies/numeric/:numericCode", h.GetCurrencyByNumericCode)
+	router.DELETE("/api/v1/currencies/:code", h.DeleteCurrency)
+
+	for _, tc := range []struct {
+		method, path, message string
+	}{
+		{http.MethodGet, "/api/v1/currencies/XYZ", "Currency XYZ not found"},
+		// The echoed code is the normalized one, not the raw input
+		{http.MethodGet, "/api/v1/currencies/x.y.z", "Currency XYZ not found"},
+		{http.MethodGet, "/api/v1/currencies/numeric/999", "Currency with numeric code 999 not found"},
+		{http.MethodDelete, "/api/v1/currencies/xyz", "Currency XYZ not found"},
+	} {
+		w := serve(router, tc.method, tc.path, "", nil)
+		require.Equal(t, http.StatusNotFound, w.Code, tc.path)
+		var resp APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), tc.path)
+		assert.Equal(t, tc.message, resp.Error, tc.path)
+		assert.Equal(t, ErrorCodeCurrencyNotFound, resp.ErrorCode, tc.path)
+
+		// Bare errors carry the same code
+		w = serve(router, tc.method, tc.path+"?envelope=false", "", nil)
+		var bare BareError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bare), tc.path)
+		assert.Equal(t, BareError{Error: tc.message, ErrorCode: ErrorCodeCurrencyNotFound}, bare, tc.path)
+	}
+
+	// Input that isn't a valid code is rejected without being reflected
+	for _, path := range []string{"/api/v1/currencies/%3Cb%3E", "/api/v1/currencies/numeric/9%3Cb%3E"} {
+		w := serve(router, http.MethodGet, path, "", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+		assert.NotContains(t, w.Body.String(), "<b>", path)
+		assert.NotContains(t, w.Body.String(), ErrorCodeCurrencyNotFound, path)
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currencyResourceType is the JSON:API type of currency resources
+const currencyResourceType = "currencies"
+
+// GetCurrenciesV2 handles GET /api/v2/currencies. It accepts the same query
+// parameters as GetCurrencies and returns the page as JSON:API resource
+// objects; fuzzy search scores are reported in each resource's meta.
+func (h *CurrencyHandler) GetCurrenciesV2(c *gin.Context) {
+	list, ok := h.listCurrencies(c)
+	if !ok {
+		return
+	}
+
+	resources := make([]V2Resource, 0, len(list.currencies)+len(list.scored))
+	for _, currency := range list.currencies {
+		resource, err := newV2Resource(currencyResourceType, currency, list.fields)
+		if err != nil {
+			errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currencies", err)
+			return
+		}
+		resources = append(resources, resource)
+	}
+	for _, result := range list.scored {
+		resource, err := newV2Resource(currencyResourceType, &result.Currency, list.fields)
+		if err != nil {
+			errorResponse(c, http.StatusInternalServerError, "Failed to retrieve currencies", err)
+			return
+		}
+		if list.fields == nil || list.fields["score"] {
+			resource.Meta = map[string]interface{}{"score": result.Score}
+		}
+		resources = append(resources, resource)
+	}
+
+	writeJSON(c, http.StatusOK, V2Document{
+		Data: resources,
+		Meta: V2PageMeta{
+			Page:       list.page,
+			Limit:      list.limit,
+			Offset:     list.offset,
+			Total:      list.total,
+			NextCursor: list.nextCursor,
+		},
+	})
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVersionedListRouter serves the currency list under both API versions,
+// the way setupRouter does
+func newVersionedListRouter(svc *fakeCurrencyService) http.Handler {
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies", h.GetCurrencies)
+	v2 := router.Group("/api/v2")
+	v2.Use(APIVersion(2))
+	v2.GET("/currencies", h.GetCurrenciesV2)
+	return router
+}
+
+type v2ListResponse struct {
+	Data []V2Resource `json:"data"`
+	Meta V2PageMeta   `json:"meta"`
+}
+
+func TestCurrencyListV1AndV2(t *testing.T) {
+	svc := newListTestService()
+	for _, currency := range svc.currencies {
+		currency.ID = uuid.New()
+	}
+	router := newVersionedListRouter(svc)
+
+	v1 := getList(t, router, "limit=3&page=2")
+	require.Len(t, v1.Data, 3)
+	assert.Equal(t, []string{"EUR", "GBP", "JPY"}, []string{v1.Data[0].Code, v1.Data[1].Code, v1.Data[2].Code})
+	assert.Equal(t, 2, v1.Pagination.Page)
+	assert.Equal(t, 3, v1.Pagination.Limit)
+	assert.Equal(t, int64(8), v1.Pagination.Total)
+
+	w := serve(router, http.MethodGet, "/api/v2/currencies?limit=3&page=2", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var v2 v2ListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &v2))
+
+	require.Len(t, v2.Data, 3)
+	for i, resource := range v2.Data {
+		assert.Equal(t, "currencies", resource.Type)
+		assert.Equal(t, v1.Data[i].ID.String(), resource.ID)
+		assert.Equal(t, v1.Data[i].Code, resource.Attributes["code"])
+		assert.NotContains(t, resource.Attributes, "id")
+	}
+	assert.Equal(t, V2PageMeta{Page: 2, Limit: 3, Offset: 3, Total: 8}, v2.Meta)
+	assert.NotContains(t, w.Body.String(), `"success"`)
+}
+
+func TestCurrencyListV2SelectsFields(t *testing.T) {
+	router := newVersionedListRouter(newListTestService())
+
+	w := serve(router, http.MethodGet, "/api/v2/currencies?limit=1&fields=code,factor", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var v2 v2ListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &v2))
+
+	require.Len(t, v2.Data, 1)
+	assert.Equal(t, map[string]interface{}{"code": "AUD", "factor": float64(100)}, v2.Data[0].Attributes)
+}
+
+func TestCurrencyListErrorsUseEachVersionsEnvelope(t *testing.T) {
+	router := newVersionedListRouter(newListTestService())
+
+	w := serve(router, http.MethodGet, "/api/v1/currencies?cursor=not-a-cursor", "", nil)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var v1 APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &v1))
+	assert.False(t, v1.Success)
+	assert.Equal(t, "Invalid cursor", v1.Error)
+
+	w = serve(router, http.MethodGet, "/api/v2/currencies?cursor=not-a-cursor", "", nil)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var v2 V2Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &v2))
+	assert.Equal(t, []V2Error{{Status: "400", Title: "Invalid cursor"}}, v2.Errors)
+	assert.Nil(t, v2.Data)
+	assert.NotContains(t, w.Body.String(), `"success"`)
+}
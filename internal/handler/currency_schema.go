@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// FieldSchema describes a single field of the Currency resource
+type FieldSchema struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Required  bool   `json:"required"`
+	MaxLength int    `json:"max_length,omitempty"`
+	Editable  bool   `json:"editable"`
+}
+
+var varcharPattern = regexp.MustCompile(`varchar\((\d+)\)`)
+
+// currencySchema is derived once from the model and request struct tags so
+// it stays in sync with the actual validation rules
+var currencySchema = buildCurrencySchema()
+
+// GetCurrencySchema handles GET /api/v1/currencies/schema
+func (h *CurrencyHandler) GetCurrencySchema(c *gin.Context) {
+	successResponse(c, currencySchema, "Currency schema retrieved successfully")
+}
+
+func buildCurrencySchema() []FieldSchema {
+	createTags := jsonFieldTags(reflect.TypeOf(CreateCurrencyRequest{}), "binding")
+	updateTags := jsonFieldTags(reflect.TypeOf(UpdateCurrencyRequest{}), "binding")
+
+	modelType := reflect.TypeOf(model.Currency{})
+	fields := make([]FieldSchema, 0, modelType.NumField())
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		name := jsonName(field)
+		if name == "" {
+			continue
+		}
+
+		schema := FieldSchema{
+			Name: name,
+			Type: schemaType(field.Type),
+		}
+		if match := varcharPattern.FindStringSubmatch(field.Tag.Get("gorm")); match != nil {
+			schema.MaxLength, _ = strconv.Atoi(match[1])
+		}
+		if binding, ok := createTags[name]; ok {
+			schema.Required = strings.Contains(binding, "required")
+		}
+		_, schema.Editable = updateTags[name]
+
+		fields = append(fields, schema)
+	}
+
+	return fields
+}
+
+// jsonFieldTags maps the JSON name of every field in t to the given struct tag
+func jsonFieldTags(t reflect.Type, tag string) map[string]string {
+	tags := make(map[string]string, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			tags[name] = t.Field(i).Tag.Get(tag)
+		}
+	}
+	return tags
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func schemaType(t reflect.Type) string {
+	// Nullable fields have the type they point to
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case reflect.TypeOf(uuid.UUID{}):
+		return "uuid"
+	case reflect.TypeOf(time.Time{}):
+		return "timestamp"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Bool:
+		return "boolean"
+	default:
+		return t.Kind().String()
+	}
+}
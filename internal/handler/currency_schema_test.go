@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCurrencySchemaDescribesFieldsAndConstraints(t *testing.T) {
+	h := NewCurrencyHandler(newFakeCurrencyService(), fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies/schema", h.GetCurrencySchema)
+
+	w := serve(router, http.MethodGet, "/api/v1/currencies/schema", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data []FieldSchema `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	fields := make(map[string]FieldSchema, len(resp.Data))
+	for _, field := range resp.Data {
+		fields[field.Name] = field
+	}
+	assert.Equal(t, "code", resp.Data[1].Name, "fields are listed in model order")
+	assert.NotContains(t, fields, "", "every field has a name")
+
+	for _, want := range []FieldSchema{
+		{Name: "id", Type: "uuid"},
+		{Name: "code", Type: "string", Required: true, MaxLength: 3},
+		{Name: "numeric_code", Type: "string", MaxLength: 3, Editable: true},
+		{Name: "description", Type: "string", Required: true, MaxLength: 255, Editable: true},
+		{Name: "html_encoded_symbol", Type: "string", MaxLength: 50, Editable: true},
+		{Name: "factor", Type: "integer", Editable: true},
+		{Name: "rounding_mode", Type: "string", MaxLength: 10, Editable: true},
+		{Name: "is_active", Type: "boolean"},
+		{Name: "valid_until", Type: "timestamp", Editable: true},
+		{Name: "created_at", Type: "timestamp"},
+		{Name: "updated_by", Type: "uuid"},
+		{Name: "version", Type: "integer", Editable: true},
+	} {
+		assert.Equal(t, want, fields[want.Name], want.Name)
+	}
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+)
+
+// errInvalidCursor is returned when a pagination cursor can't be decoded
+var errInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor returns the opaque cursor pointing after the currency with
+// the given code. Clients must treat it as opaque so the encoding can change.
+func encodeCursor(code string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(code))
+}
+
+// decodeCursor returns the currency code a cursor points after. The empty
+// cursor starts from the beginning.
+func decodeCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", errInvalidCursor
+	}
+	// Cursors are only ever minted from stored codes, so they must already
+	// be in canonical form
+	code, err := model.NormalizeCode(string(raw))
+	if err != nil || code != string(raw) {
+		return "", errInvalidCursor
+	}
+	return code, nil
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, code := range []string{"USD", "EUR", "BTC", "XAU"} {
+		decoded, err := decodeCursor(encodeCursor(code))
+		require.NoError(t, err, code)
+		assert.Equal(t, code, decoded)
+	}
+
+	decoded, err := decodeCursor("")
+	require.NoError(t, err)
+	assert.Equal(t, "", decoded)
+}
+
+func TestDecodeCursorRejectsInvalidCursors(t *testing.T) {
+	for _, cursor := range []string{
+		"not base64!",
+		encodeCursor("usd"),    // not canonical
+		encodeCursor("DOLLAR"), // too long
+		encodeCursor("U$D"),
+	} {
+		_, err := decodeCursor(cursor)
+		assert.ErrorIs(t, err, errInvalidCursor, cursor)
+	}
+}
+
+func newListTestService() *fakeCurrencyService {
+	var currencies []*model.Currency
+	for _, code := range []string{"AUD", "CAD", "CHF", "EUR", "GBP", "JPY", "NZD", "USD"} {
+		currencies = append(currencies, &model.Currency{Code: code, Description: code, Factor: 100, IsActive: true})
+	}
+	// Inactive currencies are left out of listings by default
+	currencies = append(currencies, &model.Currency{Code: "DEM", Description: "Deutsche Mark", Factor: 100})
+	return newFakeCurrencyService(currencies...)
+}
+
+func newListRouter(svc *fakeCurrencyService) http.Handler {
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies", h.GetCurrencies)
+	return router
+}
+
+type listResponse struct {
+	Data       []model.Currency `json:"data"`
+	Pagination struct {
+		Page       int    `json:"page"`
+		Limit      int    `json:"limit"`
+		Total      int64  `json:"total"`
+		NextCursor string `json:"next_cursor"`
+	} `json:"pagination"`
+}
+
+func getList(t *testing.T, router http.Handler, query string) listResponse {
+	t.Helper()
+	w := serve(router, http.MethodGet, "/api/v1/currencies?"+query, "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp listResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestCursorPaginationIteratesFullSet(t *testing.T) {
+	router := newListRouter(newListTestService())
+
+	var codes []string
+	cursor := ""
+	for pages := 1; ; pages++ {
+		require.LessOrEqual(t, pages, 3, "cursor pagination didn't terminate")
+
+		resp := getList(t, router, fmt.Sprintf("limit=3&cursor=%s", url.QueryEscape(cursor)))
+		assert.Equal(t, int64(8), resp.Pagination.Total)
+		for _, currency := range resp.Data {
+			codes = append(codes, currency.Code)
+		}
+		if resp.Pagination.NextCursor == "" {
+			break
+		}
+		cursor = resp.Pagination.NextCursor
+	}
+
+	assert.Equal(t, []string{"AUD", "CAD", "CHF", "EUR", "GBP", "JPY", "NZD", "USD"}, codes)
+}
+
+func TestCursorPaginationRejectsInvalidCursor(t *testing.T) {
+	router := newListRouter(newListTestService())
+
+	for _, cursor := range []string{"%%%", encodeCursor("usd")} {
+		w := serve(router, http.MethodGet, "/api/v1/currencies?cursor="+url.QueryEscape(cursor), "", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, cursor)
+		assert.Contains(t, w.Body.String(), "Invalid cursor")
+	}
+}
+
+func TestPageLimitFallsBackToDefaultAndCapsAtMax(t *testing.T) {
+	router := newListRouter(newListTestService())
+
+	for query, want := range map[string]int{
+		"limit=0":    testPagination.DefaultLimit,
+		"limit=-5":   testPagination.DefaultLimit,
+		"limit=abc":  testPagination.DefaultLimit,
+		"limit=3":    3,
+		"limit=100":  testPagination.MaxLimit,
+		"limit=1000": testPagination.MaxLimit,
+	} {
+		assert.Equal(t, want, getList(t, router, query).Pagination.Limit, query)
+	}
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Tarifsiz/go-currency-api/api"
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPISpec handles GET /openapi.json with the OpenAPI description of the API
+func OpenAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", api.Spec)
+}
+
+// SwaggerUI handles GET /swagger/*any with an interactive view of the spec
+// served by OpenAPISpec
+func SwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", api.SwaggerUI)
+}
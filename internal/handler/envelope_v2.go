@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiVersionKey is the gin context key holding the API version of a route
+const apiVersionKey = "api_version"
+
+// V2Document is the JSON:API style envelope of /api/v2 responses. Successful
+// responses carry Data, failed ones carry Errors.
+type V2Document struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []V2Error   `json:"errors,omitempty"`
+	Meta   interface{} `json:"meta,omitempty"`
+}
+
+// V2Resource is a JSON:API resource object. Attributes hold every field of
+// the resource except its id.
+type V2Resource struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Attributes map[string]interface{} `json:"attributes"`
+	Meta       map[string]interface{} `json:"meta,omitempty"`
+}
+
+// V2Error is a JSON:API error object. Status is the HTTP status as a string.
+type V2Error struct {
+	Status string `json:"status"`
+	Code   string `json:"code,omitempty"`
+	Title  string `json:"title"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// V2PageMeta is the meta object of a paginated /api/v2 list. Page and Offset
+// are omitted in cursor mode.
+type V2PageMeta struct {
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset,omitempty"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// APIVersion records the API version a route group serves. Shared helpers
+// such as errorResponse use it to answer in that version's envelope.
+func APIVersion(version int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionKey, version)
+		c.Next()
+	}
+}
+
+// apiVersion returns the API version of the current route, 1 unless set by APIVersion
+func apiVersion(c *gin.Context) int {
+	if version := c.GetInt(apiVersionKey); version > 0 {
+		return version
+	}
+	return 1
+}
+
+// v2ErrorDocument converts a v1 error response into a JSON:API error
+// document, with one error object per field error when there are any
+func v2ErrorDocument(statusCode int, response APIResponse) V2Document {
+	status := strconv.Itoa(statusCode)
+	if len(response.Details) == 0 {
+		return V2Document{Errors: []V2Error{{Status: status, Code: response.ErrorCode, Title: response.Error}}}
+	}
+
+	errs := make([]V2Error, len(response.Details))
+	for i, detail := range response.Details {
+		errs[i] = V2Error{Status: status, Code: response.ErrorCode, Title: response.Error, Detail: detail.Message}
+	}
+	return V2Document{Errors: errs}
+}
+
+// newV2Resource builds a resource object from a model, reduced to fields
+// when set. The model's "id" field becomes the resource id.
+func newV2Resource(resourceType string, model interface{}, fields map[string]bool) (V2Resource, error) {
+	encoded, err := json.Marshal(model)
+	if err != nil {
+		return V2Resource{}, fmt.Errorf("failed to encode resource: %w", err)
+	}
+
+	// Decode numbers as json.Number so they are written back unchanged
+	var attributes map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&attributes); err != nil {
+		return V2Resource{}, fmt.Errorf("failed to decode resource: %w", err)
+	}
+
+	id, _ := attributes["id"].(string)
+	delete(attributes, "id")
+	if fields != nil {
+		pruneFields(attributes, fields)
+	}
+	return V2Resource{Type: resourceType, ID: id, Attributes: attributes}, nil
+}
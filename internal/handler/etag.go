@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// currencyETag returns a strong ETag for the default representation of a
+// currency, the one If-Match preconditions are checked against
+func currencyETag(currency *model.Currency) string {
+	return versionETag(currencyVersion(currency))
+}
+
+// currencyVersion identifies a version of a currency record. Every write
+// bumps UpdatedAt, so the ID and UpdatedAt are enough. UpdatedAt is
+// truncated to Postgres' microsecond precision so a freshly written record
+// and the same record read back produce the same version.
+func currencyVersion(currency *model.Currency) string {
+	return fmt.Sprintf("%s:%d", currency.ID, currency.UpdatedAt.UnixMicro())
+}
+
+// localizedCurrencyVersion identifies a version of a currency served with a
+// translated description, which changes when either record is updated
+func localizedCurrencyVersion(currency *model.Currency, translation *model.CurrencyTranslation) string {
+	return fmt.Sprintf("%s:%s:%d", currencyVersion(currency), translation.ID, translation.UpdatedAt.UnixMicro())
+}
+
+// representationETag returns a strong ETag for one representation of a
+// record version. Strong tags promise byte-identical bodies, so the field
+// selection, envelope and indentation the body was rendered with are part
+// of the tag. The default representation gets the plain version's tag.
+func representationETag(c *gin.Context, version string, fields map[string]bool) string {
+	var variant []string
+	if len(fields) > 0 {
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		variant = append(variant, "fields="+strings.Join(names, ","))
+	}
+	if !enveloped(c) {
+		variant = append(variant, "envelope=false")
+	}
+	if pretty, _ := strconv.ParseBool(c.Query("pretty")); pretty {
+		variant = append(variant, "pretty=true")
+	}
+	if len(variant) > 0 {
+		version += "|" + strings.Join(variant, "&")
+	}
+	return versionETag(version)
+}
+
+// versionETag hashes a version string into a quoted ETag
+func versionETag(version string) string {
+	sum := sha256.Sum256([]byte(version))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header matches etag. Weak
+// comparison is used, as RFC 9110 requires for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchMatches reports whether an If-Match header matches etag. Strong
+// comparison is used, as RFC 9110 requires for If-Match, so weak tags never match.
+func ifMatchMatches(ifMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified sets the ETag header and, when the request's If-None-Match
+// matches it, writes 304 Not Modified and returns true
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+
+	ifNoneMatch := c.GetHeader("If-None-Match")
+	if ifNoneMatch == "" || !etagMatches(ifNoneMatch, etag) {
+		return false
+	}
+
+	c.Status(http.StatusNotModified)
+	return true
+}
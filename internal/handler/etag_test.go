@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETagComparison(t *testing.T) {
+	const etag = `"abc"`
+
+	for _, tc := range []struct {
+		header      string
+		ifNoneMatch bool
+		ifMatch     bool
+	}{
+		{`"abc"`, true, true},
+		{`W/"abc"`, true, false},
+		{`"xyz", "abc"`, true, true},
+		{`"xyz", W/"abc"`, true, false},
+		{`*`, true, true},
+		{`"xyz"`, false, false},
+		{`abc`, false, false},
+	} {
+		assert.Equal(t, tc.ifNoneMatch, etagMatches(tc.header, etag), "If-None-Match: %s", tc.header)
+		assert.Equal(t, tc.ifMatch, ifMatchMatches(tc.header, etag), "If-Match: %s", tc.header)
+	}
+}
+
+func TestCurrencyETagTracksUpdates(t *testing.T) {
+	currency := &model.Currency{ID: uuid.New(), Code: "USD", UpdatedAt: time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)}
+	etag := currencyETag(currency)
+
+	// Postgres keeps microseconds, so the nanoseconds lost on a round trip don't matter
+	readBack := *currency
+	readBack.UpdatedAt = readBack.UpdatedAt.Truncate(time.Microsecond)
+	assert.Equal(t, etag, currencyETag(&readBack))
+
+	updated := *currency
+	updated.UpdatedAt = updated.UpdatedAt.Add(time.Millisecond)
+	assert.NotEqual(t, etag, currencyETag(&updated))
+}
+
+func TestGetCurrencyConditionalRequests(t *testing.T) {
+	svc := newFakeCurrencyService(&model.Currency{
+		ID: uuid.New(), Code: "USD", Description: "US Dollar", Factor: 100, Version: 1,
+		UpdatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	})
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies/:code", h.GetCurrencyByCode)
+	router.PATCH("/api/v1/currencies/:code", h.PatchCurrency)
+
+	// A fresh request gets the record and its ETag
+	w := serve(router, http.MethodGet, "/api/v1/currencies/USD", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// Revalidating with the ETag, strong or weak, gets an empty 304
+	for _, ifNoneMatch := range []string{etag, "W/" + etag} {
+		w = serve(router, http.MethodGet, "/api/v1/currencies/USD", "", map[string]string{"If-None-Match": ifNoneMatch})
+		assert.Equal(t, http.StatusNotModified, w.Code, ifNoneMatch)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+	}
+
+	// A weak ETag can't be used as a precondition for a write
+	w = serve(router, http.MethodPatch, "/api/v1/currencies/USD", `{"description":"Dollar"}`, map[string]string{"If-Match": "W/" + etag})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = serve(router, http.MethodPatch, "/api/v1/currencies/USD", `{"description":"Dollar"}`, map[string]string{"If-Match": etag})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// After the update the old ETag no longer matches
+	w = serve(router, http.MethodGet, "/api/v1/currencies/USD", "", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"description":"Dollar"`)
+}
+
+func TestETagDiffersPerRepresentation(t *testing.T) {
+	svc := newFakeCurrencyService(&model.Currency{
+		ID: uuid.New(), Code: "USD", Description: "US Dollar", Factor: 100, Version: 1,
+		UpdatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	})
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies/:code", h.GetCurrencyByCode)
+
+	etagOf := func(query string) string {
+		w := serve(router, http.MethodGet, "/api/v1/currencies/USD"+query, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, query)
+		return w.Header().Get("ETag")
+	}
+
+	// The default representation keeps the tag If-Match is checked against
+	assert.Equal(t, currencyETag(svc.currencies["USD"]), etagOf(""))
+	assert.Equal(t, etagOf(""), etagOf("?envelope=true&pretty=false"))
+
+	seen := map[string]string{}
+	for _, query := range []string{"", "?fields=code", "?fields=code,factor", "?envelope=false", "?pretty=true", "?fields=code&envelope=false&pretty=true"} {
+		etag := etagOf(query)
+		if other, ok := seen[etag]; ok {
+			t.Errorf("%q and %q share ETag %s", query, other, etag)
+		}
+		seen[etag] = query
+	}
+
+	// The order fields are named in doesn't change the body
+	assert.Equal(t, etagOf("?fields=code,factor"), etagOf("?fields=factor,%20CODE"))
+
+	// A tag only revalidates the representation it was issued for
+	bare := etagOf("?envelope=false")
+	w := serve(router, http.MethodGet, "/api/v1/currencies/USD", "", map[string]string{"If-None-Match": bare})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serve(router, http.MethodGet, "/api/v1/currencies/USD?envelope=false", "", map[string]string{"If-None-Match": bare})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
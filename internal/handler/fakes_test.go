@@ -0,0 +1,387 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/config"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/Tarifsiz/go-currency-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// testPagination mirrors the default pagination settings
+var testPagination = config.PaginationConfig{DefaultLimit: 50, MaxLimit: 100}
+
+// fakeCurrencyService is an in-memory CurrencyServiceInterface. Methods a
+// test doesn't need are left to the embedded nil interface, so calling one
+// panics.
+type fakeCurrencyService struct {
+	service.CurrencyServiceInterface
+
+	mu         sync.Mutex
+	currencies map[string]*model.Currency
+	patches    []service.CurrencyPatch
+	createErr  error               // returned by CreateCurrency and CreateCurrenciesBatch when set
+	countErr   error               // returned by CountFilteredCurrencies when set
+	rated      map[string][]string // codes with a rate against each base, for GetCurrenciesWithoutRates
+	changeLog  []*model.ChangeLog  // audit trail of every code, for GetCurrencyAudit
+	searches   []searchCall        // every SearchCurrencies call, in order
+}
+
+// searchCall records the arguments of a SearchCurrencies call
+type searchCall struct {
+	search        repository.SearchOptions
+	limit, offset int
+}
+
+func newFakeCurrencyService(currencies ...*model.Currency) *fakeCurrencyService {
+	svc := &fakeCurrencyService{currencies: make(map[string]*model.Currency)}
+	for _, currency := range currencies {
+		svc.currencies[currency.Code] = currency
+	}
+	return svc
+}
+
+func (s *fakeCurrencyService) GetCurrencyByCode(ctx context.Context, code string) (*model.Currency, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	currency, ok := s.currencies[code]
+	if !ok {
+		return nil, apperrors.ErrCurrencyNotFound
+	}
+	copied := *currency
+	return &copied, nil
+}
+
+func (s *fakeCurrencyService) GetCurrencyByNumericCode(ctx context.Context, numericCode string) (*model.Currency, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, currency := range s.currencies {
+		if currency.NumericCode == numericCode {
+			copied := *currency
+			return &copied, nil
+		}
+	}
+	return nil, apperrors.ErrCurrencyNotFound
+}
+
+func (s *fakeCurrencyService) FormatCurrencyAmount(ctx context.Context, code string, amount int64) (*service.FormattedAmount, error) {
+	currency, err := s.GetCurrencyByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return &service.FormattedAmount{Code: currency.Code, Amount: amount, Formatted: service.FormatAmount(currency, amount)}, nil
+}
+
+func (s *fakeCurrencyService) PreviewAmountFormat(ctx context.Context, code string, amounts []int64, displayFormat string) (*service.FormatPreview, error) {
+	currency, err := s.GetCurrencyByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if displayFormat != "" {
+		currency.AmountDisplayFormat = displayFormat
+	}
+	preview := &service.FormatPreview{Code: currency.Code, AmountDisplayFormat: currency.AmountDisplayFormat, Factor: currency.Factor}
+	for _, amount := range amounts {
+		preview.Amounts = append(preview.Amounts, service.FormattedAmount{Code: currency.Code, Amount: amount, Formatted: service.FormatAmount(currency, amount)})
+	}
+	return preview, nil
+}
+
+func (s *fakeCurrencyService) CreateCurrency(ctx context.Context, currency *model.Currency) error {
+	if s.createErr != nil {
+		return s.createErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.currencies[currency.Code]; ok {
+		return apperrors.ErrDuplicateCurrency
+	}
+	copied := *currency
+	s.currencies[currency.Code] = &copied
+	return nil
+}
+
+func (s *fakeCurrencyService) CreateCurrenciesBatch(ctx context.Context, currencies []*model.Currency) ([]service.BatchItemResult, error) {
+	if s.createErr != nil {
+		return nil, s.createErr
+	}
+	results := make([]service.BatchItemResult, len(currencies))
+	for i, currency := range currencies {
+		if err := s.CreateCurrency(ctx, currency); err != nil {
+			return nil, err
+		}
+		results[i] = service.BatchItemResult{Index: i, Code: currency.Code, Success: true}
+	}
+	return results, nil
+}
+
+// sorted returns copies of the currencies matching filter's active flag,
+// created and updated bounds and validity date, ordered by code
+func (s *fakeCurrencyService) sorted(filter repository.ListFilter) []*model.Currency {
+	currencies := make([]*model.Currency, 0, len(s.currencies))
+	for _, currency := range s.currencies {
+		if filter.ActiveOnly && !currency.IsActive {
+			continue
+		}
+		if !withinBounds(currency.CreatedAt, filter.CreatedAfter, filter.CreatedBefore) ||
+			!withinBounds(currency.UpdatedAt, filter.UpdatedAfter, filter.UpdatedBefore) {
+			continue
+		}
+		if filter.ValidOn != nil && !withinBounds(*filter.ValidOn, currency.ValidFrom, currency.ValidUntil) {
+			continue
+		}
+		if !filter.ShowHidden && currency.VisibleAfter != nil && currency.VisibleAfter.After(time.Now()) {
+			continue
+		}
+		copied := *currency
+		currencies = append(currencies, &copied)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i].Code < currencies[j].Code })
+	return currencies
+}
+
+// withinBounds reports whether t lies in the range of a ListFilter bound
+// pair, where after is inclusive, before exclusive and either may be unset
+func withinBounds(t time.Time, after, before *time.Time) bool {
+	return (after == nil || !t.Before(*after)) && (before == nil || t.Before(*before))
+}
+
+func (s *fakeCurrencyService) GetAllCurrencies(ctx context.Context, limit, offset int, sortField, sortOrder string, filter repository.ListFilter) ([]*model.Currency, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	currencies := s.sorted(filter)
+	if offset >= len(currencies) {
+		return []*model.Currency{}, nil
+	}
+	currencies = currencies[offset:]
+	if limit < len(currencies) {
+		currencies = currencies[:limit]
+	}
+	return currencies, nil
+}
+
+func (s *fakeCurrencyService) GetCurrenciesAfter(ctx context.Context, cursorCode string, limit int, filter repository.ListFilter) ([]*model.Currency, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var after []*model.Currency
+	for _, currency := range s.sorted(filter) {
+		if currency.Code > cursorCode {
+			after = append(after, currency)
+		}
+	}
+	if len(after) > limit {
+		return after[:limit], true, nil
+	}
+	return after, false, nil
+}
+
+// SearchCurrencies returns a page of the currencies matching search's active
+// flag whose description contains the term, ignoring field and match
+func (s *fakeCurrencyService) SearchCurrencies(ctx context.Context, search repository.SearchOptions, limit, offset int) ([]*model.Currency, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.searches = append(s.searches, searchCall{search, limit, offset})
+
+	var found []*model.Currency
+	for _, currency := range s.sorted(repository.ListFilter{ActiveOnly: search.ActiveOnly}) {
+		if strings.Contains(strings.ToLower(currency.Description), strings.ToLower(search.Term)) {
+			found = append(found, currency)
+		}
+	}
+	total := int64(len(found))
+	found = found[min(offset, len(found)):]
+	return found[:min(limit, len(found))], total, nil
+}
+
+func (s *fakeCurrencyService) CountFilteredCurrencies(ctx context.Context, filter repository.ListFilter) (int64, error) {
+	if s.countErr != nil {
+		return 0, s.countErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(s.sorted(filter))), nil
+}
+
+// CountCurrencies counts the currencies matching search's active flag whose
+// description contains the term, ignoring field and match
+func (s *fakeCurrencyService) CountCurrencies(ctx context.Context, search repository.SearchOptions, factor int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var count int64
+	for _, currency := range s.sorted(repository.ListFilter{ActiveOnly: search.ActiveOnly}) {
+		if (factor <= 0 || currency.Factor == factor) && strings.Contains(strings.ToLower(currency.Description), strings.ToLower(search.Term)) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetCurrenciesGroupedByFactor groups the currencies matching search's
+// active flag whose description contains the term, ignoring field and match
+func (s *fakeCurrencyService) GetCurrenciesGroupedByFactor(ctx context.Context, search repository.SearchOptions) (map[int][]*model.Currency, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := make(map[int][]*model.Currency)
+	for _, currency := range s.sorted(repository.ListFilter{ActiveOnly: search.ActiveOnly}) {
+		if strings.Contains(strings.ToLower(currency.Description), strings.ToLower(search.Term)) {
+			groups[currency.Factor] = append(groups[currency.Factor], currency)
+		}
+	}
+	return groups, nil
+}
+
+// GetCurrenciesWithoutRates returns the active currencies other than base
+// that aren't listed in rated[base], ordered by code
+func (s *fakeCurrencyService) GetCurrenciesWithoutRates(ctx context.Context, base string) ([]*model.Currency, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.currencies[base]; !ok {
+		return nil, apperrors.ErrCurrencyNotFound
+	}
+	rated := map[string]bool{base: true}
+	for _, code := range s.rated[base] {
+		rated[code] = true
+	}
+	currencies := []*model.Currency{}
+	for _, currency := range s.sorted(repository.ListFilter{ActiveOnly: true}) {
+		if !rated[currency.Code] {
+			currencies = append(currencies, currency)
+		}
+	}
+	return currencies, nil
+}
+
+// GetCurrencyAudit pages the changeLog entries for code; a code without
+// entries or currency isn't found
+func (s *fakeCurrencyService) GetCurrencyAudit(ctx context.Context, code string, limit, offset int) ([]*model.ChangeLog, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changes := []*model.ChangeLog{}
+	for _, change := range s.changeLog {
+		if change.CurrencyCode == code {
+			changes = append(changes, change)
+		}
+	}
+	total := int64(len(changes))
+	if _, ok := s.currencies[code]; !ok && total == 0 {
+		return nil, 0, apperrors.ErrCurrencyNotFound
+	}
+	if offset >= len(changes) {
+		return []*model.ChangeLog{}, total, nil
+	}
+	changes = changes[offset:]
+	if limit < len(changes) {
+		changes = changes[:limit]
+	}
+	return changes, total, nil
+}
+
+// GetCurrencyChanges reports every currency updated at or after since as an
+// update, ordered by code
+func (s *fakeCurrencyService) GetCurrencyChanges(ctx context.Context, since time.Time, limit, offset int) ([]*service.CurrencyChange, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var changes []*service.CurrencyChange
+	for _, currency := range s.sorted(repository.ListFilter{}) {
+		if !currency.UpdatedAt.Before(since) {
+			changes = append(changes, &service.CurrencyChange{Currency: *currency, Type: "updated"})
+		}
+	}
+	total := int64(len(changes))
+	if offset >= len(changes) {
+		return []*service.CurrencyChange{}, total, nil
+	}
+	changes = changes[offset:]
+	if limit < len(changes) {
+		changes = changes[:limit]
+	}
+	return changes, total, nil
+}
+
+func (s *fakeCurrencyService) UpdateCurrency(ctx context.Context, currency *model.Currency) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.currencies[currency.Code]
+	if !ok {
+		return apperrors.ErrCurrencyNotFound
+	}
+	if stored.Version != currency.Version {
+		return apperrors.ErrStaleUpdate
+	}
+	currency.Version++
+	copied := *currency
+	s.currencies[currency.Code] = &copied
+	return nil
+}
+
+func (s *fakeCurrencyService) PatchCurrency(ctx context.Context, patch service.CurrencyPatch) (*model.Currency, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.patches = append(s.patches, patch)
+
+	stored, ok := s.currencies[patch.Code]
+	if !ok {
+		return nil, apperrors.ErrCurrencyNotFound
+	}
+	if stored.Version != patch.Version {
+		return nil, apperrors.ErrStaleUpdate
+	}
+
+	currency := *stored
+	if patch.Description != nil {
+		currency.Description = *patch.Description
+	}
+	if patch.AmountDisplayFormat != nil {
+		currency.AmountDisplayFormat = *patch.AmountDisplayFormat
+	}
+	if patch.HtmlEncodedSymbol != nil {
+		currency.HtmlEncodedSymbol = *patch.HtmlEncodedSymbol
+	}
+	if patch.Factor != nil {
+		currency.Factor = *patch.Factor
+	}
+	currency.Version++
+	currency.UpdatedAt = stored.UpdatedAt.Add(time.Second)
+	s.currencies[patch.Code] = &currency
+
+	copied := currency
+	return &copied, nil
+}
+
+// fakeTranslationService has no translations
+type fakeTranslationService struct {
+	service.TranslationServiceInterface
+}
+
+func (fakeTranslationService) Localize(ctx context.Context, currencies []*model.Currency, locales []string) (map[string]*model.CurrencyTranslation, error) {
+	return nil, nil
+}
+
+// newTestRouter returns a gin engine in test mode without any middleware
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return gin.New()
+}
+
+// serve sends a request with an optional JSON body through router
+func serve(router http.Handler, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
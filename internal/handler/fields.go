@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// selectableFields is the allow-list for ?fields=. It is derived from the
+// currency schema so new model fields become selectable automatically; score
+// is only present on fuzzy search results.
+var selectableFields = buildSelectableFields()
+
+func buildSelectableFields() map[string]bool {
+	fields := make(map[string]bool, len(currencySchema)+1)
+	for _, field := range currencySchema {
+		fields[field.Name] = true
+	}
+	fields["score"] = true
+	return fields
+}
+
+// parseFields parses the comma separated ?fields= parameter into the set of
+// JSON fields to return. A nil set means all fields. It writes a 400
+// response and returns false if a field is not selectable.
+func parseFields(c *gin.Context) (map[string]bool, bool) {
+	raw := c.Query("fields")
+	if raw == "" {
+		return nil, true
+	}
+
+	fields := make(map[string]bool)
+	var unknown []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !selectableFields[name] {
+			unknown = append(unknown, name)
+			continue
+		}
+		fields[name] = true
+	}
+
+	if len(unknown) > 0 {
+		errorResponse(c, http.StatusBadRequest, fmt.Sprintf("Unknown fields: %s; must be any of: %s", strings.Join(unknown, ", "), strings.Join(selectableFieldNames(), ", ")), nil)
+		return nil, false
+	}
+	if len(fields) == 0 {
+		errorResponse(c, http.StatusBadRequest, "fields must name at least one field", nil)
+		return nil, false
+	}
+	return fields, true
+}
+
+// selectFields reduces a single resource or a list of resources to the given
+// JSON fields by marshaling it to generic maps and pruning them. The selected
+// fields are written in key order, so the same selection always produces the
+// same bytes. Data is returned unchanged when fields is nil.
+func selectFields(data interface{}, fields map[string]bool) (interface{}, error) {
+	if fields == nil {
+		return data, nil
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response data: %w", err)
+	}
+
+	// Decode numbers as json.Number so they are written back unchanged
+	var decoded interface{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode response data: %w", err)
+	}
+
+	switch value := decoded.(type) {
+	case map[string]interface{}:
+		return pruneFields(value, fields), nil
+	case []interface{}:
+		for i, item := range value {
+			if object, ok := item.(map[string]interface{}); ok {
+				value[i] = pruneFields(object, fields)
+			}
+		}
+	case nil:
+		return data, nil
+	}
+	return decoded, nil
+}
+
+// pruneFields returns the given fields of object, sorted by name
+func pruneFields(object map[string]interface{}, fields map[string]bool) orderedObject {
+	for name := range object {
+		if !fields[name] {
+			delete(object, name)
+		}
+	}
+	return sortedObject(object)
+}
+
+// selectableFieldNames lists the allow-list in a stable order for error messages
+func selectableFieldNames() []string {
+	names := make([]string, 0, len(selectableFields))
+	for name := range selectableFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
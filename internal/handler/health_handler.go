@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// serviceName identifies this service in health responses
+const serviceName = "currency-api"
+
+// HealthHandler handles liveness and readiness probes
+type HealthHandler struct {
+	healthService service.HealthServiceInterface
+	detailsToken  string
+}
+
+// NewHealthHandler creates a new health handler instance. A non-empty
+// detailsToken must be sent as a bearer token to GET /health/details.
+func NewHealthHandler(healthService service.HealthServiceInterface, detailsToken string) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+		detailsToken:  detailsToken,
+	}
+}
+
+// Live handles GET /health/live. It only reports that the process is up and
+// serving requests, so a dependency outage never gets the pod restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":    service.HealthStatusUp,
+		"timestamp": model.Now(),
+		"service":   serviceName,
+	})
+}
+
+// Ready handles GET /health/ready and GET /health. It checks every dependency
+// and returns 503 when a critical one is down. Latencies are left out since
+// these endpoints are open to anyone who can reach the service.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.healthService.CheckReadiness(c.Request.Context())
+
+	dependencies := make(map[string]gin.H, len(report.Dependencies))
+	for name, dep := range report.Dependencies {
+		dependencies[name] = gin.H{"status": dep.Status, "critical": dep.Critical}
+	}
+
+	c.JSON(readinessStatus(report), gin.H{
+		"status":       report.Status,
+		"dependencies": dependencies,
+		"timestamp":    report.Timestamp,
+		"service":      serviceName,
+	})
+}
+
+// Details handles GET /health/details. It reports the same checks as Ready
+// along with each dependency's latency, and requires the configured health
+// check token when there is one.
+func (h *HealthHandler) Details(c *gin.Context) {
+	if h.detailsToken != "" {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.detailsToken)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="currency-api"`)
+			c.Header("Cache-Control", "no-store")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success":   false,
+				"error":     "Missing or invalid health check token",
+				"timestamp": model.Now(),
+			})
+			return
+		}
+	}
+
+	report := h.healthService.CheckReadiness(c.Request.Context())
+
+	c.JSON(readinessStatus(report), gin.H{
+		"status":       report.Status,
+		"dependencies": report.Dependencies,
+		"timestamp":    report.Timestamp,
+		"service":      serviceName,
+	})
+}
+
+// readinessStatus returns 503 when a critical dependency is down, 200 otherwise
+func readinessStatus(report *service.HealthReport) int {
+	if report.Status == service.HealthStatusDown {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
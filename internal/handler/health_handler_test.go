@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubHealthService struct {
+	report service.HealthReport
+}
+
+func (s *stubHealthService) CheckReadiness(ctx context.Context) *service.HealthReport {
+	report := s.report
+	return &report
+}
+
+func newHealthRouter(token string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewHealthHandler(&stubHealthService{report: service.HealthReport{
+		Status: service.HealthStatusUp,
+		Dependencies: map[string]service.DependencyHealth{
+			"database": {Status: service.HealthStatusUp, Critical: true, LatencyMS: 3},
+		},
+		Timestamp: model.Now(),
+	}}, token)
+
+	router := gin.New()
+	router.GET("/health/ready", h.Ready)
+	router.GET("/health/details", h.Details)
+	return router
+}
+
+func getHealth(t *testing.T, router *gin.Engine, path, authorization string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w, body
+}
+
+func databaseHealth(t *testing.T, body map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	dependencies, ok := body["dependencies"].(map[string]interface{})
+	require.True(t, ok, "dependencies missing from %v", body)
+	database, ok := dependencies["database"].(map[string]interface{})
+	require.True(t, ok, "database missing from %v", dependencies)
+	return database
+}
+
+func TestReadyOmitsLatency(t *testing.T) {
+	w, body := getHealth(t, newHealthRouter("secret"), "/health/ready", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	database := databaseHealth(t, body)
+	assert.Equal(t, service.HealthStatusUp, database["status"])
+	assert.NotContains(t, database, "latency_ms")
+}
+
+func TestDetailsRequiresToken(t *testing.T) {
+	router := newHealthRouter("secret")
+
+	for name, authorization := range map[string]string{
+		"missing": "",
+		"wrong":   "Bearer guess",
+		"scheme":  "secret",
+	} {
+		t.Run(name, func(t *testing.T) {
+			w, body := getHealth(t, router, "/health/details", authorization)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.NotContains(t, body, "dependencies")
+		})
+	}
+}
+
+func TestDetailsWithToken(t *testing.T) {
+	w, body := getHealth(t, newHealthRouter("secret"), "/health/details", "Bearer secret")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(3), databaseHealth(t, body)["latency_ms"])
+}
+
+func TestDetailsOpenWithoutConfiguredToken(t *testing.T) {
+	w, body := getHealth(t, newHealthRouter(""), "/health/details", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, databaseHealth(t, body), "latency_ms")
+}
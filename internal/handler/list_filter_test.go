@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+)
+
+// newDatedService returns currencies created a month apart, each updated a
+// month after it was created except EUR
+func newDatedService() *fakeCurrencyService {
+	day := func(month time.Month) time.Time {
+		return time.Date(2024, month, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return newFakeCurrencyService(
+		&model.Currency{Code: "USD", Factor: 100, IsActive: true, CreatedAt: day(1), UpdatedAt: day(3)},
+		&model.Currency{Code: "EUR", Factor: 100, IsActive: true, CreatedAt: day(2), UpdatedAt: day(2)},
+		&model.Currency{Code: "JPY", Factor: 1, IsActive: true, CreatedAt: day(3), UpdatedAt: day(4)},
+	)
+}
+
+// listedCodes returns the codes of a list response in order
+func listedCodes(resp listResponse) []string {
+	var codes []string
+	for _, currency := range resp.Data {
+		codes = append(codes, currency.Code)
+	}
+	return codes
+}
+
+func TestListFilterOpenEndedRanges(t *testing.T) {
+	router := newListRouter(newDatedService())
+
+	tests := []struct {
+		query string
+		codes []string
+		total int64
+	}{
+		// "after" bounds are inclusive and "before" bounds exclusive
+		{"created_after=2024-02-01T00:00:00Z", []string{"EUR", "JPY"}, 2},
+		{"created_before=2024-02-01T00:00:00Z", []string{"USD"}, 1},
+		{"updated_after=2024-03-01T00:00:00Z", []string{"JPY", "USD"}, 2},
+		{"updated_before=2024-03-01T00:00:00Z", []string{"EUR"}, 1},
+		{"created_after=2024-01-15T00:00:00Z&updated_before=2024-03-15T00:00:00Z", []string{"EUR"}, 1},
+		{"created_after=2024-01-01T00:00:00Z&created_before=2024-03-01T00:00:00Z", []string{"EUR", "USD"}, 2},
+		// An unescaped "+" offset arrives as a space
+		{"created_after=2024-02-01T01:00:00+01:00", []string{"EUR", "JPY"}, 2},
+		// The total counts the filtered set, not the page
+		{"created_after=2024-01-01T00:00:00Z&limit=1&page=2", []string{"JPY"}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp := getList(t, router, tt.query)
+			assert.Equal(t, tt.codes, listedCodes(resp))
+			assert.Equal(t, tt.total, resp.Pagination.Total)
+		})
+	}
+}
+
+func TestListFilterRejectsInvalidTimestamps(t *testing.T) {
+	router := newListRouter(newDatedService())
+
+	tests := map[string]string{
+		"created_after=yesterday":           "Invalid created_after, must be an RFC 3339 timestamp",
+		"created_before=2024-01-01":         "Invalid created_before, must be an RFC 3339 timestamp",
+		"updated_after=2024-01-01T00:00:00": "Invalid updated_after, must be an RFC 3339 timestamp",
+		"updated_before=1704067200":         "Invalid updated_before, must be an RFC 3339 timestamp",
+		"created_after=2024-03-01T00:00:00Z&created_before=2024-03-01T00:00:00Z": "created_after must be before created_before",
+		"updated_after=2024-04-01T00:00:00Z&updated_before=2024-03-01T00:00:00Z": "updated_after must be before updated_before",
+	}
+
+	for query, message := range tests {
+		w := serve(router, http.MethodGet, "/api/v1/currencies?"+query, "", nil)
+		require.Equal(t, http.StatusBadRequest, w.Code, "%s: %s", query, w.Body.String())
+		var resp APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, message, resp.Error, query)
+	}
+}
+
+// newValidityService returns currencies valid without bounds, from a date
+// on, until a date and between two dates
+func newValidityService() *fakeCurrencyService {
+	day := func(year int, month time.Month, d int) *time.Time {
+		t := time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	return newFakeCurrencyService(
+		&model.Currency{Code: "USD", Factor: 100, IsActive: true},
+		&model.Currency{Code: "EUR", Factor: 100, IsActive: true, ValidFrom: day(1999, 1, 1)},
+		&model.Currency{Code: "DEM", Factor: 100, IsActive: true, ValidUntil: day(2002, 1, 1)},
+		&model.Currency{Code: "XEU", Factor: 100, IsActive: true, ValidFrom: day(1979, 3, 13), ValidUntil: day(1999, 1, 1)},
+	)
+}
+
+func TestListValidOnOpenEndedAndBoundedRanges(t *testing.T) {
+	router := newListRouter(newValidityService())
+
+	tests := []struct {
+		query string
+		codes []string
+	}{
+		{"", []string{"DEM", "EUR", "USD", "XEU"}},
+		{"valid_on=1970-01-01", []string{"DEM", "USD"}},
+		{"valid_on=1990-06-15", []string{"DEM", "USD", "XEU"}},
+		// ValidFrom is inclusive and ValidUntil exclusive
+		{"valid_on=1999-01-01", []string{"DEM", "EUR", "USD"}},
+		{"valid_on=1998-12-31T23:59:59Z", []string{"DEM", "USD", "XEU"}},
+		{"valid_on=2001-12-31T23:59:59Z", []string{"DEM", "EUR", "USD"}},
+		{"valid_on=2002-01-01", []string{"EUR", "USD"}},
+		{"valid_on=2002-01-01T00:30:00+01:00", []string{"DEM", "EUR", "USD"}},
+		{"valid_on=2030-01-01", []string{"EUR", "USD"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp := getList(t, router, tt.query)
+			assert.Equal(t, tt.codes, listedCodes(resp))
+			assert.Equal(t, int64(len(tt.codes)), resp.Pagination.Total)
+		})
+	}
+
+	for _, query := range []string{"valid_on=2002", "valid_on=01/01/2002", "valid_on=2002-01-01T00:00:00"} {
+		w := serve(router, http.MethodGet, "/api/v1/currencies?"+query, "", nil)
+		require.Equal(t, http.StatusBadRequest, w.Code, "%s: %s", query, w.Body.String())
+		var resp APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "Invalid valid_on, must be a date (YYYY-MM-DD) or an RFC 3339 timestamp", resp.Error, query)
+	}
+}
+
+func TestCreateCurrencyStoresValidity(t *testing.T) {
+	svc := newFakeCurrencyService()
+	router := newCreateRouter(svc)
+
+	w := serve(router, http.MethodPost, "/api/v1/currencies",
+		`{"code":"XEU","description":"European Currency Unit","valid_from":"1979-03-13T00:00:00Z","valid_until":"1999-01-01T00:00:00Z"}`, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	stored := svc.currencies["XEU"]
+	require.NotNil(t, stored.ValidFrom)
+	require.NotNil(t, stored.ValidUntil)
+	assert.True(t, time.Date(1979, 3, 13, 0, 0, 0, 0, time.UTC).Equal(*stored.ValidFrom))
+	assert.True(t, time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC).Equal(*stored.ValidUntil))
+
+	// Either bound may be left open
+	w = serve(router, http.MethodPost, "/api/v1/currencies", `{"code":"EUR","description":"Euro","valid_from":"1999-01-01T00:00:00Z"}`, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.NotNil(t, svc.currencies["EUR"].ValidFrom)
+	assert.Nil(t, svc.currencies["EUR"].ValidUntil)
+}
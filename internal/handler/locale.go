@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// localePattern is the accepted format of a locale: a BCP 47 language tag
+// such as "fr" or "pt-BR"
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,8}(-[A-Za-z0-9]{1,8})*$`)
+
+// maxLocaleLength matches the width of currency_translations.locale
+const maxLocaleLength = 35
+
+// requestLocales returns the locales the client prefers, most preferred
+// first: the ?locale= parameter when present, otherwise the Accept-Language
+// header. It writes a 400 response and returns false if ?locale= is not a
+// valid language tag.
+func requestLocales(c *gin.Context) ([]string, bool) {
+	if locale, ok := c.GetQuery("locale"); ok {
+		if !isValidLocale(locale) {
+			errorResponse(c, http.StatusBadRequest, "Invalid locale, must be a language tag such as fr or fr-CA", nil)
+			return nil, false
+		}
+		return []string{locale}, true
+	}
+	return parseAcceptLanguage(c.GetHeader("Accept-Language")), true
+}
+
+func isValidLocale(locale string) bool {
+	return len(locale) <= maxLocaleLength && localePattern.MatchString(locale)
+}
+
+// parseAcceptLanguage returns the language tags of an Accept-Language
+// header ordered by quality, keeping the header's order among equal
+// qualities. The "*" wildcard, tags refused with q=0 and malformed entries
+// are dropped.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if !isValidLocale(tag) {
+			continue
+		}
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	locales := make([]string, len(tags))
+	for i, t := range tags {
+		locales[i] = t.tag
+	}
+	return locales
+}
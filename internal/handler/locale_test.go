@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/service"
+)
+
+// fakeTranslationRepo has French translations of USD, in general and for
+// Canada
+type fakeTranslationRepo struct{}
+
+var usdTranslations = []*model.CurrencyTranslation{
+	{ID: uuid.New(), CurrencyCode: "USD", Locale: "fr", Description: "Dollar américain"},
+	{ID: uuid.New(), CurrencyCode: "USD", Locale: "fr-ca", Description: "Dollar des États-Unis"},
+}
+
+func (fakeTranslationRepo) GetByCodes(ctx context.Context, codes, locales []string) ([]*model.CurrencyTranslation, error) {
+	wanted := make(map[string]bool, len(codes)+len(locales))
+	for _, value := range append(append([]string(nil), codes...), locales...) {
+		wanted[value] = true
+	}
+	var found []*model.CurrencyTranslation
+	for _, t := range usdTranslations {
+		if wanted[t.CurrencyCode] && wanted[t.Locale] {
+			found = append(found, t)
+		}
+	}
+	return found, nil
+}
+
+func newLocaleRouter() http.Handler {
+	svc := newFakeCurrencyService(&model.Currency{Code: "USD", Description: "US Dollar", Factor: 100, IsActive: true})
+	h := NewCurrencyHandler(svc, service.NewTranslationService(fakeTranslationRepo{}), testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies/:code", h.GetCurrencyByCode)
+	return router
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := map[string][]string{
+		"":                                 {},
+		"fr":                               {"fr"},
+		"de;q=0.5, fr-CA, fr;q=0.9":        {"fr-CA", "fr", "de"},
+		"en;q=0.8, de;q=0.8, it":           {"it", "en", "de"},
+		"*, fr;q=0, de;q=abc, <b>, es":     {"es"},
+		" pt-BR ; q=0.7 ,ja-JP-x-kana;q=1": {"ja-JP-x-kana", "pt-BR"},
+	}
+	for header, want := range tests {
+		assert.Equal(t, want, parseAcceptLanguage(header), header)
+	}
+}
+
+func TestGetCurrencyLocalizesDescription(t *testing.T) {
+	router := newLocaleRouter()
+
+	tests := []struct {
+		name            string
+		query           string
+		acceptLanguage  string
+		wantDescription string
+		wantLanguage    string
+	}{
+		{"locale hit", "?locale=fr", "", "Dollar américain", "fr"},
+		{"regional translation", "?locale=fr-CA", "", "Dollar des États-Unis", "fr-ca"},
+		{"regional fallback to language", "?locale=fr-BE", "", "Dollar américain", "fr"},
+		{"unknown locale", "?locale=sw", "", "US Dollar", ""},
+		{"header when no query", "", "de, fr-CA;q=0.8", "Dollar des États-Unis", "fr-ca"},
+		{"query wins over header", "?locale=sw", "fr", "US Dollar", ""},
+		{"neither", "", "", "US Dollar", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, "/api/v1/currencies/USD"+tt.query, "", map[string]string{"Accept-Language": tt.acceptLanguage})
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var resp struct {
+				Data model.Currency `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantDescription, resp.Data.Description)
+			assert.Equal(t, tt.wantLanguage, w.Header().Get("Content-Language"))
+			assert.Contains(t, w.Header().Values("Vary"), "Accept-Language")
+		})
+	}
+}
+
+func TestGetCurrencyLocaleVariesETag(t *testing.T) {
+	router := newLocaleRouter()
+
+	french := serve(router, http.MethodGet, "/api/v1/currencies/USD?locale=fr", "", nil)
+	canadian := serve(router, http.MethodGet, "/api/v1/currencies/USD?locale=fr-CA", "", nil)
+	fallback := serve(router, http.MethodGet, "/api/v1/currencies/USD?locale=sw", "", nil)
+	plain := serve(router, http.MethodGet, "/api/v1/currencies/USD", "", nil)
+
+	assert.NotEqual(t, french.Header().Get("ETag"), canadian.Header().Get("ETag"))
+	assert.NotEqual(t, french.Header().Get("ETag"), plain.Header().Get("ETag"))
+	assert.Equal(t, plain.Header().Get("ETag"), fallback.Header().Get("ETag"))
+}
+
+func TestGetCurrencyRejectsInvalidLocale(t *testing.T) {
+	router := newLocaleRouter()
+
+	for _, locale := range []string{"", "f", "fr_FR", "fr-", "<script>", "abcdefghijklmnopqrstuvwxyz-abcdefghij"} {
+		w := serve(router, http.MethodGet, "/api/v1/currencies/USD?locale="+locale, "", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "%q: %s", locale, w.Body.String())
+	}
+
+	// Malformed Accept-Language entries are ignored rather than rejected
+	w := serve(router, http.MethodGet, "/api/v1/currencies/USD", "", map[string]string{"Accept-Language": "fr_FR, <b>"})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
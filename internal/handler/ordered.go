@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// objectMember is one key and value of an orderedObject
+type objectMember struct {
+	Key   string
+	Value interface{}
+}
+
+// orderedObject is a JSON object written with its members in the order
+// they are listed. Responses built from maps go through it so their key
+// order is part of the response rather than a side effect of how
+// encoding/json happens to write maps.
+type orderedObject []objectMember
+
+// MarshalJSON implements json.Marshaler
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, member := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(member.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(member.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// sortedObject returns the members of object ordered by key
+func sortedObject(object map[string]interface{}) orderedObject {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ordered := make(orderedObject, len(keys))
+	for i, key := range keys {
+		ordered[i] = objectMember{Key: key, Value: object[key]}
+	}
+	return ordered
+}
+
+// numericKeyedObject returns the entries of groups as an object keyed by
+// their number in ascending numeric order, so 5 comes before 100 where
+// encoding/json would write "100" first
+func numericKeyedObject[V any](groups map[int]V) orderedObject {
+	keys := make([]int, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	ordered := make(orderedObject, len(keys))
+	for i, key := range keys {
+		ordered[i] = objectMember{Key: strconv.Itoa(key), Value: groups[key]}
+	}
+	return ordered
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+)
+
+// objectKeys returns the keys of a JSON object in the order they are written
+func objectKeys(t *testing.T, raw json.RawMessage) []string {
+	t.Helper()
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	token, err := decoder.Token()
+	require.NoError(t, err)
+	require.Equal(t, json.Delim('{'), token, string(raw))
+
+	var keys []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		require.NoError(t, err)
+		keys = append(keys, token.(string))
+		var value json.RawMessage
+		require.NoError(t, decoder.Decode(&value))
+	}
+	return keys
+}
+
+// responseData returns the data of a successful enveloped response as written
+func responseData(t *testing.T, router http.Handler, path string) json.RawMessage {
+	t.Helper()
+	w := serve(router, http.MethodGet, path, "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Data
+}
+
+func TestOrderedObjectKeepsMemberOrder(t *testing.T) {
+	encoded, err := json.Marshal(orderedObject{{"b", 1}, {"a", []string{"x"}}, {"c", nil}})
+	require.NoError(t, err)
+	assert.Equal(t, `{"b":1,"a":["x"],"c":null}`, string(encoded))
+
+	encoded, err = json.Marshal(orderedObject{})
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(encoded))
+}
+
+func TestSelectedFieldsHaveStableKeyOrder(t *testing.T) {
+	router := newEnvelopeRouter(newListTestService())
+
+	for _, path := range []string{"/api/v1/currencies?fields=is_active,code,factor,description", "/api/v1/currencies/USD?fields=is_active,code,factor,description"} {
+		data := responseData(t, router, path)
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, string(data), string(responseData(t, router, path)), path)
+		}
+
+		object := data
+		var list []json.RawMessage
+		if json.Unmarshal(data, &list) == nil {
+			require.NotEmpty(t, list)
+			object = list[0]
+		}
+		assert.Equal(t, []string{"code", "description", "factor", "is_active"}, objectKeys(t, object), path)
+	}
+}
+
+func TestGroupedCurrenciesHaveNumericKeyOrder(t *testing.T) {
+	router := newInactiveRouter(newFakeCurrencyService(
+		&model.Currency{Code: "USD", Factor: 100, IsActive: true},
+		&model.Currency{Code: "BHD", Factor: 1000, IsActive: true},
+		&model.Currency{Code: "JPY", Factor: 1, IsActive: true},
+		&model.Currency{Code: "MRU", Factor: 5, IsActive: true},
+	))
+
+	data := responseData(t, router, "/api/v1/currencies/grouped")
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, string(data), string(responseData(t, router, "/api/v1/currencies/grouped")))
+	}
+	assert.Equal(t, []string{"1", "5", "100", "1000"}, objectKeys(t, data))
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Tarifsiz/go-currency-api/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// pageLimit returns the ?limit= page size, capped at the configured maximum.
+// A missing, malformed or non-positive limit gets the configured default.
+func pageLimit(c *gin.Context, pagination config.PaginationConfig) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		return pagination.DefaultLimit
+	}
+	if limit > pagination.MaxLimit {
+		return pagination.MaxLimit
+	}
+	return limit
+}
+
+// pageNumber returns the 1-based ?page= number, defaulting to the first page
+func pageNumber(c *gin.Context) int {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		return 1
+	}
+	return page
+}
+
+// setPageLinks sets an RFC 8288 Link header for one page of an offset
+// paginated list of total items. first and last are always included, prev
+// and next only when those pages exist. The links are relative to the
+// request path and keep its other query parameters, so filters carry over.
+func setPageLinks(c *gin.Context, page, limit int, total int64) {
+	if limit < 1 {
+		return
+	}
+	last := int((total + int64(limit) - 1) / int64(limit))
+	if last < 1 {
+		last = 1
+	}
+
+	pageURL := func(n int) string {
+		return linkURL(c, map[string]string{"page": strconv.Itoa(n), "limit": strconv.Itoa(limit)})
+	}
+	links := []string{formatLink(pageURL(1), "first")}
+	if page > 1 {
+		links = append(links, formatLink(pageURL(min(page-1, last)), "prev"))
+	}
+	if page < last {
+		links = append(links, formatLink(pageURL(page+1), "next"))
+	}
+	links = append(links, formatLink(pageURL(last), "last"))
+
+	c.Header("Link", strings.Join(links, ", "))
+}
+
+// setCursorLinks sets an RFC 8288 Link header for one keyset page: first,
+// and next when more results follow. Keyset pages have no prev or last.
+func setCursorLinks(c *gin.Context, limit int, nextCursor string) {
+	links := []string{formatLink(linkURL(c, map[string]string{"cursor": "", "limit": strconv.Itoa(limit)}), "first")}
+	if nextCursor != "" {
+		links = append(links, formatLink(linkURL(c, map[string]string{"cursor": nextCursor, "limit": strconv.Itoa(limit)}), "next"))
+	}
+	c.Header("Link", strings.Join(links, ", "))
+}
+
+// linkURL returns the request path and query with the given parameters replaced
+func linkURL(c *gin.Context, params map[string]string) string {
+	query := c.Request.URL.Query()
+	for name, value := range params {
+		query.Set(name, value)
+	}
+	link := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+	return link.String()
+}
+
+func formatLink(target, rel string) string {
+	return fmt.Sprintf(`<%s>; rel="%s"`, target, rel)
+}
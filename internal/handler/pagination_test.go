@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTreatsInvalidPagesAsFirstPage(t *testing.T) {
+	router := newListRouter(newListTestService())
+	first := getList(t, router, "limit=3")
+
+	for _, page := range []string{"0", "-5", "abc", ""} {
+		resp := getList(t, router, "limit=3&page="+url.QueryEscape(page))
+		assert.Equal(t, 1, resp.Pagination.Page, page)
+		assert.Equal(t, first.Data, resp.Data, page)
+	}
+}
+
+func TestCurrencyChangesTreatInvalidPagesAsFirstPage(t *testing.T) {
+	h := NewCurrencyHandler(newListTestService(), fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies/changes", h.GetCurrencyChanges)
+
+	for _, page := range []string{"0", "-5", "1"} {
+		w := serve(router, http.MethodGet, "/api/v1/currencies/changes?since=0001-01-01T00:00:00Z&limit=3&page="+page, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp struct {
+			Data       []service.CurrencyChange `json:"data"`
+			Pagination struct {
+				Page   int `json:"page"`
+				Offset int `json:"offset"`
+			} `json:"pagination"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), page)
+		assert.Equal(t, 1, resp.Pagination.Page, page)
+		assert.Equal(t, 0, resp.Pagination.Offset, page)
+		require.Len(t, resp.Data, 3, page)
+		assert.Equal(t, "AUD", resp.Data[0].Code, page)
+	}
+}
+
+func TestListPageLinks(t *testing.T) {
+	router := newListRouter(newListTestService())
+	link := func(page int) string {
+		return `</api/v1/currencies?limit=3&page=` + strconv.Itoa(page) + `>`
+	}
+
+	// Eight active currencies make three pages of three
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"limit=3", link(1) + `; rel="first", ` + link(2) + `; rel="next", ` + link(3) + `; rel="last"`},
+		{"limit=3&page=0", link(1) + `; rel="first", ` + link(2) + `; rel="next", ` + link(3) + `; rel="last"`},
+		{"limit=3&page=2", link(1) + `; rel="first", ` + link(1) + `; rel="prev", ` + link(3) + `; rel="next", ` + link(3) + `; rel="last"`},
+		{"limit=3&page=3", link(1) + `; rel="first", ` + link(2) + `; rel="prev", ` + link(3) + `; rel="last"`},
+		{"limit=3&page=9", link(1) + `; rel="first", ` + link(3) + `; rel="prev", ` + link(3) + `; rel="last"`},
+	}
+
+	for _, tt := range tests {
+		w := serve(router, http.MethodGet, "/api/v1/currencies?"+tt.query, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, tt.query)
+		assert.Equal(t, tt.want, w.Header().Get("Link"), tt.query)
+	}
+}
+
+func TestPageLinksKeepFilters(t *testing.T) {
+	router := newListRouter(newListTestService())
+
+	w := serve(router, http.MethodGet, "/api/v1/currencies?include_inactive=true&limit=3&page=2", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Link"), `</api/v1/currencies?include_inactive=true&limit=3&page=3>; rel="next"`)
+}
+
+func TestListFailsWhenTotalCantBeCounted(t *testing.T) {
+	svc := newListTestService()
+	svc.countErr = errors.New("connection refused")
+	router := newListRouter(svc)
+
+	// Offset and keyset pages both report a total, so neither may claim 0
+	for _, query := range []string{"limit=3", "limit=3&cursor="} {
+		w := serve(router, http.MethodGet, "/api/v1/currencies?"+query, "", nil)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code, query)
+		var resp APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), query)
+		assert.Equal(t, "Failed to count currencies", resp.Error, query)
+		assert.NotContains(t, w.Body.String(), `"total"`, query)
+	}
+}
@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/config"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// RateHandler handles HTTP requests for exchange rates
+type RateHandler struct {
+	rateService   service.RateServiceInterface
+	rateRefresher service.RateRefresherInterface
+	pagination    config.PaginationConfig
+}
+
+// PinRateRequest represents the request body for pinning a manual rate.
+// Among several pinned rates for a pair the highest priority wins.
+type PinRateRequest struct {
+	Rate     *decimal.Decimal `json:"rate" binding:"required"`
+	Priority int              `json:"priority" binding:"min=0,max=1000"`
+}
+
+// NewRateHandler creates a new rate handler instance. rateRefresher is nil
+// when no rate provider is configured.
+func NewRateHandler(rateService service.RateServiceInterface, rateRefresher service.RateRefresherInterface, pagination config.PaginationConfig) *RateHandler {
+	return &RateHandler{
+		rateService:   rateService,
+		rateRefresher: rateRefresher,
+		pagination:    pagination,
+	}
+}
+
+// GetRate handles GET /api/v1/rates?from=USD&to=EUR&date=2024-01-15. It
+// returns the rate in effect on the given date, i.e. the last rate quoted on
+// or before the end of that day (UTC). date may also be an RFC 3339 timestamp
+// for an exact point in time; without it the latest rate is returned.
+func (h *RateHandler) GetRate(c *gin.Context) {
+	from, err := model.NormalizeCode(c.Query("from"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return
+	}
+	to, err := model.NormalizeCode(c.Query("to"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return
+	}
+
+	at, ok := parseRateDate(c)
+	if !ok {
+		return
+	}
+
+	rate, err := h.rateService.GetRateAt(c.Request.Context(), from, to, at)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrExchangeRateNotFound) {
+			errorResponse(c, http.StatusNotFound, "No exchange rate on or before the requested date", err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, "Failed to retrieve exchange rate", err)
+		return
+	}
+
+	successResponse(c, rate, "Exchange rate retrieved successfully")
+}
+
+// ListPairs handles GET /api/v1/rates/pairs[?base=USD]. It lists the
+// currency pairs that have a stored rate, so clients can discover which
+// conversions are possible.
+func (h *RateHandler) ListPairs(c *gin.Context) {
+	base := c.Query("base")
+	if base != "" {
+		var err error
+		if base, err = model.NormalizeCode(base); err != nil {
+			errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+			return
+		}
+	}
+
+	page := pageNumber(c)
+	limit := pageLimit(c, h.pagination)
+	offset := (page - 1) * limit
+
+	pairs, total, err := h.rateService.ListPairs(c.Request.Context(), base, limit, offset)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to retrieve exchange rate pairs", err)
+		return
+	}
+
+	response := PaginationResponse{
+		Success:   true,
+		Data:      pairs,
+		Timestamp: model.Now(),
+	}
+	response.Pagination.Page = page
+	response.Pagination.Limit = limit
+	response.Pagination.Offset = offset
+	response.Pagination.Total = total
+
+	writePage(c, response)
+}
+
+// RefreshRates handles POST /api/v1/rates/refresh. It fetches and stores
+// rates for every configured base currency immediately and reports the
+// outcome per base.
+func (h *RateHandler) RefreshRates(c *gin.Context) {
+	if h.rateRefresher == nil {
+		errorResponse(c, http.StatusServiceUnavailable, "No exchange rate provider is configured", nil)
+		return
+	}
+
+	results := h.rateRefresher.RefreshAll(c.Request.Context())
+	for _, result := range results {
+		if result.Error == "" {
+			successResponse(c, results, "Exchange rates refreshed")
+			return
+		}
+	}
+
+	writeEnvelope(c, http.StatusBadGateway, APIResponse{
+		Success:   false,
+		Data:      results,
+		Error:     "Failed to refresh exchange rates",
+		Timestamp: model.Now(),
+	})
+}
+
+// ImportRates handles POST /api/v1/rates/import. It accepts a CSV file with
+// base, quote, rate, as_of and source columns in the multipart "file" field
+// and upserts every valid row, reporting the outcome per row.
+func (h *RateHandler) ImportRates(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Missing import file in the \"file\" form field", err)
+		return
+	}
+	if file.Size > maxImportSize {
+		errorResponse(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Import file must not exceed %d bytes", maxImportSize), nil)
+		return
+	}
+	if !strings.EqualFold(filepath.Ext(file.Filename), ".csv") {
+		errorResponse(c, http.StatusUnsupportedMediaType, "Import file must be a .csv file", nil)
+		return
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to read import file", err)
+		return
+	}
+	defer f.Close()
+
+	rows, err := service.ParseRateImport(f)
+	if err != nil {
+		if errors.Is(err, service.ErrMalformedImport) {
+			errorResponse(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, "Failed to read import file", err)
+		return
+	}
+	if len(rows) == 0 {
+		errorResponse(c, http.StatusUnprocessableEntity, "Import file contains no rates", nil)
+		return
+	}
+
+	summary, err := h.rateService.ImportRates(c.Request.Context(), rows)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, "Failed to import exchange rates", err)
+		return
+	}
+
+	successResponse(c, summary, "Exchange rates imported")
+}
+
+// PinRate handles POST /api/v1/admin/rates/:from/:to/pin. It stores a
+// manual rate for the pair that overrides its provider rates until unpinned.
+func (h *RateHandler) PinRate(c *gin.Context) {
+	from, to, ok := ratePairParams(c)
+	if !ok {
+		return
+	}
+
+	var req PinRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	rate, err := h.rateService.PinRate(c.Request.Context(), from, to, *req.Rate, req.Priority)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRate):
+			errorResponse(c, http.StatusUnprocessableEntity, err.Error(), err)
+		case errors.Is(err, apperrors.ErrCurrencyNotFound):
+			errorResponse(c, http.StatusNotFound, "Currency not found", err)
+		default:
+			errorResponse(c, http.StatusInternalServerError, "Failed to pin exchange rate", err)
+		}
+		return
+	}
+
+	writeEnvelope(c, http.StatusCreated, APIResponse{
+		Success:   true,
+		Data:      rate,
+		Message:   "Exchange rate pinned",
+		Timestamp: model.Now(),
+	})
+}
+
+// UnpinRate handles DELETE /api/v1/admin/rates/:from/:to/pin. It removes
+// the pair's pinned rates, so conversions use its provider rates again.
+func (h *RateHandler) UnpinRate(c *gin.Context) {
+	from, to, ok := ratePairParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.rateService.UnpinRate(c.Request.Context(), from, to); err != nil {
+		if errors.Is(err, apperrors.ErrExchangeRateNotFound) {
+			errorResponse(c, http.StatusNotFound, "No pinned exchange rate for the pair", err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, "Failed to unpin exchange rate", err)
+		return
+	}
+
+	successResponse(c, nil, "Exchange rate unpinned")
+}
+
+// ratePairParams parses the :from and :to path parameters. It writes a 400
+// response and returns false if either isn't a currency code.
+func ratePairParams(c *gin.Context) (string, string, bool) {
+	from, err := model.NormalizeCode(c.Param("from"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return "", "", false
+	}
+	to, err := model.NormalizeCode(c.Param("to"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid currency code format", err)
+		return "", "", false
+	}
+	return from, to, true
+}
+
+// parseRateDate parses the ?date= parameter into the point in time a rate is
+// looked up at. A plain date resolves to the last instant of that day in UTC.
+// It writes a 400 response and returns false if the date is invalid.
+func parseRateDate(c *gin.Context) (time.Time, bool) {
+	raw := c.Query("date")
+	if raw == "" {
+		return time.Now().UTC(), true
+	}
+
+	if day, err := time.Parse(time.DateOnly, raw); err == nil {
+		return day.AddDate(0, 0, 1).Add(-time.Microsecond), true
+	}
+
+	// An unescaped "+" in a UTC offset arrives decoded as a space
+	t, err := time.Parse(time.RFC3339, strings.ReplaceAll(raw, " ", "+"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, "Invalid date, must be YYYY-MM-DD or an RFC 3339 timestamp", err)
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,362 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/Tarifsiz/go-currency-api/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRateService records the rows it was asked to import and reports
+// every one of them imported. It pins rates between any two currencies
+// other than XXX, and only USD/EUR has a pin to remove. Historical lookups
+// pick the latest of history quoted on or before the requested time, and
+// the pairs listed are those of history.
+type fakeRateService struct {
+	service.RateServiceInterface
+
+	imported []service.RateImportRow
+	pinned   []*model.ExchangeRate
+	unpinned []string
+	history  []*model.ExchangeRate
+}
+
+func (s *fakeRateService) GetRateAt(ctx context.Context, from, to string, at time.Time) (*model.ExchangeRate, error) {
+	var found *model.ExchangeRate
+	for _, rate := range s.history {
+		if rate.FromCode != from || rate.ToCode != to || rate.Timestamp.After(at) {
+			continue
+		}
+		if found == nil || rate.Timestamp.After(found.Timestamp) {
+			found = rate
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w for %s/%s at %s", apperrors.ErrExchangeRateNotFound, from, to, at.Format(time.RFC3339))
+	}
+	return found, nil
+}
+
+func (s *fakeRateService) ListPairs(ctx context.Context, base string, limit, offset int) ([]*repository.RatePair, int64, error) {
+	latest := make(map[[2]string]*repository.RatePair)
+	for _, rate := range s.history {
+		if base != "" && rate.FromCode != base {
+			continue
+		}
+		key := [2]string{rate.FromCode, rate.ToCode}
+		if latest[key] == nil {
+			latest[key] = &repository.RatePair{FromCode: rate.FromCode, ToCode: rate.ToCode}
+		}
+		if rate.Timestamp.After(latest[key].LatestTimestamp) {
+			latest[key].LatestTimestamp = rate.Timestamp
+		}
+	}
+
+	pairs := make([]*repository.RatePair, 0, len(latest))
+	for _, pair := range latest {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].FromCode != pairs[j].FromCode {
+			return pairs[i].FromCode < pairs[j].FromCode
+		}
+		return pairs[i].ToCode < pairs[j].ToCode
+	})
+
+	total := int64(len(pairs))
+	if offset > len(pairs) {
+		offset = len(pairs)
+	}
+	pairs = pairs[offset:]
+	if limit > 0 && limit < len(pairs) {
+		pairs = pairs[:limit]
+	}
+	return pairs, total, nil
+}
+
+func (s *fakeRateService) PinRate(ctx context.Context, from, to string, rate decimal.Decimal, priority int) (*model.ExchangeRate, error) {
+	if from == "XXX" || to == "XXX" {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrCurrencyNotFound, "XXX")
+	}
+	if !rate.IsPositive() {
+		return nil, fmt.Errorf("%w: rate must be greater than zero", service.ErrInvalidRate)
+	}
+	pinned := &model.ExchangeRate{FromCode: from, ToCode: to, Rate: rate, Source: model.RateSourceManual, Pinned: true, Priority: priority}
+	s.pinned = append(s.pinned, pinned)
+	return pinned, nil
+}
+
+func (s *fakeRateService) UnpinRate(ctx context.Context, from, to string) error {
+	if from != "USD" || to != "EUR" {
+		return fmt.Errorf("%w: no pinned rate for %s/%s", apperrors.ErrExchangeRateNotFound, from, to)
+	}
+	s.unpinned = append(s.unpinned, from+"/"+to)
+	return nil
+}
+
+func (s *fakeRateService) ImportRates(ctx context.Context, rows []service.RateImportRow) (*service.RateImportSummary, error) {
+	s.imported = append(s.imported, rows...)
+	summary := &service.RateImportSummary{Imported: len(rows)}
+	for _, row := range rows {
+		summary.Rows = append(summary.Rows, service.RateImportRowResult{
+			Line: row.Line, Base: row.Rate.FromCode, Quote: row.Rate.ToCode, Status: service.ImportStatusImported,
+		})
+	}
+	return summary, nil
+}
+
+func newRateImportRouter(svc service.RateServiceInterface) http.Handler {
+	router := newTestRouter()
+	router.POST("/api/v1/rates/import", NewRateHandler(svc, nil, testPagination).ImportRates)
+	return router
+}
+
+// serveUpload posts content as the multipart "file" field
+func serveUpload(t *testing.T, router http.Handler, path, filename, content string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	return serve(router, http.MethodPost, path, body.String(), map[string]string{"Content-Type": form.FormDataContentType()})
+}
+
+func TestImportRatesReturnsRowSummary(t *testing.T) {
+	svc := &fakeRateService{}
+
+	w := serveUpload(t, newRateImportRouter(svc), "/api/v1/rates/import", "rates.csv",
+		"base,quote,rate,as_of,source\nUSD,EUR,0.92,2024-01-15,ecb\n")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data service.RateImportSummary `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Data.Imported)
+	assert.Equal(t, []service.RateImportRowResult{{Line: 2, Base: "USD", Quote: "EUR", Status: service.ImportStatusImported}}, resp.Data.Rows)
+	require.Len(t, svc.imported, 1)
+	assert.Equal(t, "ecb", svc.imported[0].Rate.Source)
+}
+
+func TestImportRatesRejectsBadFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		status   int
+	}{
+		{"not CSV", "rates.json", "[]", http.StatusUnsupportedMediaType},
+		{"missing column", "rates.csv", "base,quote,rate\nUSD,EUR,0.92\n", http.StatusBadRequest},
+		{"no rows", "rates.csv", "base,quote,rate,as_of,source\n", http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeRateService{}
+
+			w := serveUpload(t, newRateImportRouter(svc), "/api/v1/rates/import", tt.filename, tt.content)
+
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			assert.Empty(t, svc.imported)
+		})
+	}
+}
+
+func newRatePinRouter(svc service.RateServiceInterface) http.Handler {
+	h := NewRateHandler(svc, nil, testPagination)
+	router := newTestRouter()
+	router.POST("/api/v1/admin/rates/:from/:to/pin", h.PinRate)
+	router.DELETE("/api/v1/admin/rates/:from/:to/pin", h.UnpinRate)
+	return router
+}
+
+func TestPinRateReturnsPinnedRate(t *testing.T) {
+	svc := &fakeRateService{}
+
+	w := serve(newRatePinRouter(svc), http.MethodPost, "/api/v1/admin/rates/usd/EUR/pin", `{"rate":"0.9","priority":2}`, nil)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var resp struct {
+		Data model.ExchangeRate `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Data.Pinned)
+	assert.Equal(t, 2, resp.Data.Priority)
+	assert.Equal(t, "USD", resp.Data.FromCode)
+	require.Len(t, svc.pinned, 1)
+	assert.True(t, decimal.RequireFromString("0.9").Equal(svc.pinned[0].Rate))
+}
+
+func TestPinRateRejectsBadRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"bad code", "/api/v1/admin/rates/US/EUR/pin", `{"rate":"0.9"}`, http.StatusBadRequest},
+		{"missing rate", "/api/v1/admin/rates/USD/EUR/pin", `{"priority":1}`, http.StatusUnprocessableEntity},
+		{"negative priority", "/api/v1/admin/rates/USD/EUR/pin", `{"rate":"0.9","priority":-1}`, http.StatusUnprocessableEntity},
+		{"non-positive rate", "/api/v1/admin/rates/USD/EUR/pin", `{"rate":"0"}`, http.StatusUnprocessableEntity},
+		{"unknown currency", "/api/v1/admin/rates/USD/XXX/pin", `{"rate":"0.9"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeRateService{}
+
+			w := serve(newRatePinRouter(svc), http.MethodPost, tt.path, tt.body, nil)
+
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			assert.Empty(t, svc.pinned)
+		})
+	}
+}
+
+func TestUnpinRate(t *testing.T) {
+	svc := &fakeRateService{}
+	router := newRatePinRouter(svc)
+
+	w := serve(router, http.MethodDelete, "/api/v1/admin/rates/USD/EUR/pin", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"USD/EUR"}, svc.unpinned)
+
+	w = serve(router, http.MethodDelete, "/api/v1/admin/rates/EUR/USD/pin", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+func TestGetRateChoosesNearestPriorSnapshot(t *testing.T) {
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	snapshot := func(rate string, at time.Time) *model.ExchangeRate {
+		return &model.ExchangeRate{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString(rate), Timestamp: at}
+	}
+	svc := &fakeRateService{history: []*model.ExchangeRate{
+		snapshot("0.90", day.AddDate(0, 0, -5)),
+		snapshot("0.91", day.Add(9*time.Hour)),
+		snapshot("0.92", day.Add(18*time.Hour)),
+		snapshot("0.93", day.AddDate(0, 0, 3)),
+		{FromCode: "EUR", ToCode: "USD", Rate: decimal.RequireFromString("1.5"), Timestamp: day},
+	}}
+	router := newTestRouter()
+	router.GET("/api/v1/rates", NewRateHandler(svc, nil, testPagination).GetRate)
+
+	tests := map[string]string{
+		// A date covers the whole day, so its last snapshot applies
+		"date=2024-01-15": "0.92",
+		// Days without a snapshot of their own use the one before
+		"date=2024-01-12": "0.90",
+		"date=2024-01-17": "0.92",
+		"date=2024-01-18": "0.93",
+		"date=2024-02-01": "0.93",
+		// A timestamp picks the snapshot in effect at that instant
+		"date=2024-01-15T12:00:00Z": "0.91",
+		"date=2024-01-15T09:00:00Z": "0.91",
+		"date=2024-01-15T08:59:59Z": "0.90",
+		// An unescaped "+" offset arrives as a space
+		"date=2024-01-15T19:00:00+02:00":   "0.91",
+		"date=2024-01-15T19:00:00%2B02:00": "0.91",
+		"date=2024-01-15T21:00:00%2B02:00": "0.92",
+		// A snapshot is in effect from the instant it was quoted
+		"date=2024-01-10T00:00:00Z": "0.90",
+	}
+	for query, want := range tests {
+		w := serve(router, http.MethodGet, "/api/v1/rates?from=usd&to=EUR&"+query, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, "%s: %s", query, w.Body.String())
+		var resp struct {
+			Data model.ExchangeRate `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, decimal.RequireFromString(want).Equal(resp.Data.Rate), "%s: got %s", query, resp.Data.Rate)
+	}
+
+	// Nothing was quoted on or before the date
+	for _, query := range []string{"date=2024-01-09", "date=2024-01-09T23:59:59Z"} {
+		w := serve(router, http.MethodGet, "/api/v1/rates?from=USD&to=EUR&"+query, "", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code, "%s: %s", query, w.Body.String())
+	}
+	w := serve(router, http.MethodGet, "/api/v1/rates?from=USD&to=GBP&date=2024-01-15", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	for _, query := range []string{"from=USD&to=EUR&date=15.01.2024", "from=USD&to=EUR&date=2024-01-15T12:00", "from=US&to=EUR"} {
+		w := serve(router, http.MethodGet, "/api/v1/rates?"+query, "", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "%s: %s", query, w.Body.String())
+	}
+}
+
+func TestListPairsFromSeededRates(t *testing.T) {
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	rate := func(from, to, value string, at time.Time) *model.ExchangeRate {
+		return &model.ExchangeRate{FromCode: from, ToCode: to, Rate: decimal.RequireFromString(value), Timestamp: at}
+	}
+	svc := &fakeRateService{history: []*model.ExchangeRate{
+		rate("USD", "EUR", "0.91", day),
+		rate("USD", "EUR", "0.92", day.AddDate(0, 0, 1)),
+		rate("USD", "GBP", "0.79", day),
+		rate("USD", "JPY", "148", day),
+		rate("EUR", "USD", "1.09", day),
+	}}
+	router := newTestRouter()
+	router.GET("/api/v1/rates/pairs", NewRateHandler(svc, nil, testPagination).ListPairs)
+
+	type pairsResponse struct {
+		Data       []repository.RatePair `json:"data"`
+		Pagination struct {
+			Page  int   `json:"page"`
+			Limit int   `json:"limit"`
+			Total int64 `json:"total"`
+		} `json:"pagination"`
+	}
+	list := func(query string) (pairsResponse, []string) {
+		t.Helper()
+		w := serve(router, http.MethodGet, "/api/v1/rates/pairs?"+query, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, "%s: %s", query, w.Body.String())
+		var resp pairsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		names := make([]string, len(resp.Data))
+		for i, pair := range resp.Data {
+			names[i] = pair.FromCode + "/" + pair.ToCode
+		}
+		return resp, names
+	}
+
+	// Every pair is listed once, with the time of its latest rate
+	resp, names := list("")
+	assert.Equal(t, []string{"EUR/USD", "USD/EUR", "USD/GBP", "USD/JPY"}, names)
+	assert.Equal(t, int64(4), resp.Pagination.Total)
+	assert.True(t, day.AddDate(0, 0, 1).Equal(resp.Data[1].LatestTimestamp))
+
+	resp, names = list("base=usd")
+	assert.Equal(t, []string{"USD/EUR", "USD/GBP", "USD/JPY"}, names)
+	assert.Equal(t, int64(3), resp.Pagination.Total)
+
+	resp, names = list("base=USD&page=2&limit=2")
+	assert.Equal(t, []string{"USD/JPY"}, names)
+	assert.Equal(t, 2, resp.Pagination.Page)
+	assert.Equal(t, 2, resp.Pagination.Limit)
+	assert.Equal(t, int64(3), resp.Pagination.Total)
+
+	resp, names = list("base=GBP")
+	assert.Empty(t, names)
+	assert.Zero(t, resp.Pagination.Total)
+
+	w := serve(router, http.MethodGet, "/api/v1/rates/pairs?base=US", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
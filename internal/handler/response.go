@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Tarifsiz/go-currency-api/internal/logging"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Machine readable error codes returned in APIResponse.ErrorCode
+const (
+	ErrorCodeCurrencyNotFound = "CURRENCY_NOT_FOUND"
+	ErrorCodeCurrencyInactive = "CURRENCY_INACTIVE"
+)
+
+// APIResponse represents the standard API response format
+type APIResponse struct {
+	Success   bool            `json:"success"`
+	Data      interface{}     `json:"data,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	ErrorCode string          `json:"error_code,omitempty"`
+	Details   []FieldError    `json:"details,omitempty"`
+	Message   string          `json:"message,omitempty"`
+	Timestamp model.Timestamp `json:"timestamp"`
+}
+
+// BareError is the body of an error response written with ?envelope=false
+type BareError struct {
+	Error     string       `json:"error"`
+	ErrorCode string       `json:"error_code,omitempty"`
+	Details   []FieldError `json:"details,omitempty"`
+}
+
+// PaginationResponse represents paginated API response
+type PaginationResponse struct {
+	Success    bool            `json:"success"`
+	Data       interface{}     `json:"data,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Message    string          `json:"message,omitempty"`
+	Timestamp  model.Timestamp `json:"timestamp"`
+	Pagination struct {
+		Page    int   `json:"page"`
+		Limit   int   `json:"limit"`
+		Offset  int   `json:"offset"`
+		Total   int64 `json:"total,omitempty"`
+		// Set in cursor mode when more results follow the current page
+		NextCursor string `json:"next_cursor,omitempty"`
+	} `json:"pagination,omitempty"`
+}
+
+// Helper functions shared by all handlers
+
+func successResponse(c *gin.Context, data interface{}, message string) {
+	response := APIResponse{
+		Success:   true,
+		Data:      data,
+		Message:   message,
+		Timestamp: model.Now(),
+	}
+	
+	statusCode := http.StatusOK
+	if message == "Currency created successfully" {
+		statusCode = http.StatusCreated
+	}
+	
+	writeEnvelope(c, statusCode, response)
+}
+
+func errorResponse(c *gin.Context, statusCode int, message string, err error) {
+	errorResponseWithCode(c, statusCode, "", message, err)
+}
+
+func errorResponseWithCode(c *gin.Context, statusCode int, errorCode, message string, err error) {
+	writeError(c, statusCode, APIResponse{ErrorCode: errorCode}, message, err)
+}
+
+func errorResponseWithDetails(c *gin.Context, statusCode int, message string, details []FieldError, err error) {
+	writeError(c, statusCode, APIResponse{Details: details}, message, err)
+}
+
+// writeError completes and sends an error response, logging err
+func writeError(c *gin.Context, statusCode int, response APIResponse, message string, err error) {
+	response.Success = false
+	response.Error = message
+	response.Timestamp = model.Now()
+	
+	// Log the underlying error; client errors are expected and logged at a lower level
+	if err != nil {
+		level := slog.LevelWarn
+		if statusCode >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logging.FromContext(c.Request.Context()).Log(c.Request.Context(), level, message,
+			"status", statusCode,
+			"error_code", response.ErrorCode,
+			"error", err.Error(),
+		)
+	}
+	
+	// Never let intermediaries cache error responses
+	c.Header("Cache-Control", "no-store")
+	
+	if apiVersion(c) >= 2 {
+		writeJSON(c, statusCode, v2ErrorDocument(statusCode, response))
+		return
+	}
+	writeEnvelope(c, statusCode, response)
+}
+
+// enveloped reports whether the response is wrapped in the APIResponse
+// envelope. Clients opt out with ?envelope=false.
+func enveloped(c *gin.Context) bool {
+	wrap, err := strconv.ParseBool(c.DefaultQuery("envelope", "true"))
+	return err != nil || wrap
+}
+
+// writeEnvelope writes an APIResponse, or with ?envelope=false only its
+// payload: Data when set, else the error as a BareError, else no body with
+// 204 No Content
+func writeEnvelope(c *gin.Context, statusCode int, response APIResponse) {
+	switch {
+	case enveloped(c):
+		writeJSON(c, statusCode, response)
+	case response.Data != nil:
+		writeJSON(c, statusCode, response.Data)
+	case !response.Success:
+		writeJSON(c, statusCode, BareError{Error: response.Error, ErrorCode: response.ErrorCode, Details: response.Details})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// writePage writes a PaginationResponse, or with ?envelope=false only its
+// Data, moving the total and next cursor to the X-Total-Count and
+// X-Next-Cursor headers
+func writePage(c *gin.Context, response PaginationResponse) {
+	if enveloped(c) {
+		writeJSON(c, http.StatusOK, response)
+		return
+	}
+	
+	c.Header("X-Total-Count", strconv.FormatInt(response.Pagination.Total, 10))
+	if response.Pagination.NextCursor != "" {
+		c.Header("X-Next-Cursor", response.Pagination.NextCursor)
+	}
+	writeJSON(c, http.StatusOK, response.Data)
+}
+
+// bindingErrorStatus maps a request binding error to an HTTP status. Bodies
+// that cannot be parsed are 400, well-formed bodies failing validation are 422.
+func bindingErrorStatus(err error) int {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return http.StatusUnprocessableEntity
+	}
+	var sliceErrs binding.SliceValidationError
+	if errors.As(err, &sliceErrs) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
+
+// writeJSON renders obj as JSON, indented when the client asks for ?pretty=true
+func writeJSON(c *gin.Context, statusCode int, obj interface{}) {
+	if pretty, _ := strconv.ParseBool(c.Query("pretty")); pretty {
+		c.IndentedJSON(statusCode, obj)
+		return
+	}
+	c.JSON(statusCode, obj)
+}
\ No newline at end of file
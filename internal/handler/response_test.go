@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEnvelopeRouter(svc *fakeCurrencyService) http.Handler {
+	h := NewCurrencyHandler(svc, fakeTranslationService{}, testPagination)
+	router := newTestRouter()
+	router.GET("/api/v1/currencies", h.GetCurrencies)
+	router.GET("/api/v1/currencies/:code", h.GetCurrencyByCode)
+	router.POST("/api/v1/currencies", h.CreateCurrency)
+	router.DELETE("/api/v1/nothing", func(c *gin.Context) {
+		successResponse(c, nil, "Deleted")
+	})
+	return router
+}
+
+func TestSingleCurrencyEnvelopedAndBare(t *testing.T) {
+	router := newEnvelopeRouter(newListTestService())
+
+	for _, query := range []string{"", "?envelope=true", "?envelope=nonsense"} {
+		w := serve(router, http.MethodGet, "/api/v1/currencies/USD"+query, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, query)
+		var resp struct {
+			Success   bool           `json:"success"`
+			Data      model.Currency `json:"data"`
+			Timestamp string         `json:"timestamp"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), query)
+		assert.True(t, resp.Success, query)
+		assert.Equal(t, "USD", resp.Data.Code, query)
+		assert.NotEmpty(t, resp.Timestamp, query)
+	}
+
+	w := serve(router, http.MethodGet, "/api/v1/currencies/USD?envelope=false", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var bare map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bare))
+	assert.Equal(t, "USD", bare["code"])
+	assert.NotContains(t, bare, "success")
+	assert.NotContains(t, bare, "data")
+}
+
+func TestListEnvelopedAndBare(t *testing.T) {
+	router := newEnvelopeRouter(newListTestService())
+
+	enveloped := getList(t, router, "limit=3")
+	assert.Len(t, enveloped.Data, 3)
+	assert.Equal(t, int64(8), enveloped.Pagination.Total)
+
+	w := serve(router, http.MethodGet, "/api/v1/currencies?limit=3&envelope=false", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var bare []model.Currency
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bare))
+	assert.Equal(t, enveloped.Data, bare)
+	assert.Equal(t, "8", w.Header().Get("X-Total-Count"))
+	assert.Empty(t, w.Header().Get("X-Next-Cursor"))
+
+	// Keyset pages also report the next cursor in a header
+	w = serve(router, http.MethodGet, "/api/v1/currencies?limit=3&cursor=&envelope=false", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bare))
+	assert.Len(t, bare, 3)
+	assert.Equal(t, encodeCursor("CHF"), w.Header().Get("X-Next-Cursor"))
+}
+
+func TestErrorsEnvelopedAndBare(t *testing.T) {
+	router := newEnvelopeRouter(newListTestService())
+
+	w := serve(router, http.MethodGet, "/api/v1/currencies/XYZ", "", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	var enveloped APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &enveloped))
+	assert.False(t, enveloped.Success)
+	assert.NotEmpty(t, enveloped.Error)
+
+	w = serve(router, http.MethodGet, "/api/v1/currencies/XYZ?envelope=false", "", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	var bare map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bare))
+	assert.Equal(t, enveloped.Error, bare["error"])
+	assert.NotContains(t, bare, "success")
+	assert.NotContains(t, bare, "timestamp")
+
+	// Field errors keep their details
+	w = serve(router, http.MethodPost, "/api/v1/currencies?envelope=false", `{"code":"US","description":"x"}`, nil)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var bareErr BareError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bareErr))
+	assert.Equal(t, "Invalid request body", bareErr.Error)
+	assert.Equal(t, []FieldError{{Field: "code", Rule: "len", Message: "code must be exactly 3 characters"}}, bareErr.Details)
+}
+
+func TestBareResponseWithoutDataHasNoContent(t *testing.T) {
+	router := newEnvelopeRouter(newListTestService())
+
+	w := serve(router, http.MethodDelete, "/api/v1/nothing", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"message":"Deleted"`)
+
+	w = serve(router, http.MethodDelete, "/api/v1/nothing?envelope=false", "", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestResponseTimestampFormat(t *testing.T) {
+	router := newEnvelopeRouter(newListTestService())
+
+	for _, path := range []string{"/api/v1/currencies/USD", "/api/v1/currencies", "/api/v1/currencies/XYZ"} {
+		w := serve(router, http.MethodGet, path, "", nil)
+		var resp struct {
+			Timestamp string `json:"timestamp"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), path)
+		assert.Regexp(t, `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}Z$`, resp.Timestamp, path)
+	}
+}
+
+func TestPrettyResponsesAreIndented(t *testing.T) {
+	router := newEnvelopeRouter(newListTestService())
+
+	for _, path := range []string{"/api/v1/currencies/USD", "/api/v1/currencies/USD?envelope=false", "/api/v1/currencies", "/api/v1/currencies/XYZ"} {
+		compact := serve(router, http.MethodGet, path, "", nil)
+		assert.NotContains(t, compact.Body.String(), "\n", path)
+
+		sep := "?"
+		if strings.Contains(path, "?") {
+			sep = "&"
+		}
+		for _, value := range []string{"true", "1"} {
+			pretty := serve(router, http.MethodGet, path+sep+"pretty="+value, "", nil)
+			assert.Equal(t, compact.Code, pretty.Code, path)
+			assert.Contains(t, pretty.Body.String(), "{\n    \"", path)
+
+			// Indentation is the only difference, apart from the timestamp
+			var indented bytes.Buffer
+			require.NoError(t, json.Indent(&indented, compact.Body.Bytes(), "", "    "), path)
+			timestamp := regexp.MustCompile(`"timestamp": "[^"]*"`)
+			assert.Equal(t, timestamp.ReplaceAllString(indented.String(), ""), timestamp.ReplaceAllString(pretty.Body.String(), ""), path)
+		}
+
+		// Anything that isn't true stays compact
+		for _, value := range []string{"false", "nonsense", ""} {
+			w := serve(router, http.MethodGet, path+sep+"pretty="+value, "", nil)
+			assert.NotContains(t, w.Body.String(), "\n", "%s pretty=%s", path, value)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSearchTestService returns a service with more dollar currencies than
+// the maximum page size, and a euro
+func newSearchTestService() *fakeCurrencyService {
+	var currencies []*model.Currency
+	for i := 0; i < testPagination.MaxLimit+20; i++ {
+		code := fmt.Sprintf("%c%c%c", 'A'+i/26/26%26, 'A'+i/26%26, 'A'+i%26)
+		currencies = append(currencies, &model.Currency{Code: code, Description: code + " Dollar", Factor: 100, IsActive: true})
+	}
+	currencies = append(currencies, &model.Currency{Code: "EUR", Description: "Euro", Factor: 100, IsActive: true})
+	return newFakeCurrencyService(currencies...)
+}
+
+func TestSearchIsPaginated(t *testing.T) {
+	svc := newSearchTestService()
+	router := newListRouter(svc)
+	matches := int64(testPagination.MaxLimit + 20)
+
+	resp := getList(t, router, "search=dollar&limit=5&page=3")
+	assert.Len(t, resp.Data, 5)
+	assert.Equal(t, "AAK", resp.Data[0].Code)
+	assert.Equal(t, matches, resp.Pagination.Total)
+	require.Len(t, svc.searches, 1)
+	assert.Equal(t, 5, svc.searches[0].limit)
+	assert.Equal(t, 10, svc.searches[0].offset)
+
+	// The same default and maximum page size as listings
+	for query, limit := range map[string]int{
+		"search=dollar":            testPagination.DefaultLimit,
+		"search=dollar&limit=0":    testPagination.DefaultLimit,
+		"search=dollar&limit=1000": testPagination.MaxLimit,
+	} {
+		resp := getList(t, router, query)
+		assert.Len(t, resp.Data, limit, query)
+		assert.Equal(t, limit, resp.Pagination.Limit, query)
+		assert.Equal(t, matches, resp.Pagination.Total, query)
+	}
+
+	// Pages past the last match are empty
+	resp = getList(t, router, "search=dollar&limit=100&page=3")
+	assert.Empty(t, resp.Data)
+	assert.Equal(t, matches, resp.Pagination.Total)
+}
+
+func TestSearchPassesFieldAndMatchThrough(t *testing.T) {
+	svc := newSearchTestService()
+	router := newListRouter(svc)
+
+	tests := []struct {
+		query        string
+		field, match string
+	}{
+		{"search=dollar", repository.SearchFieldDescription, repository.SearchMatchContains},
+		{"search=dollar&match=prefix", repository.SearchFieldDescription, repository.SearchMatchPrefix},
+		{"search=euro&match=exact", repository.SearchFieldDescription, repository.SearchMatchExact},
+		{"search=us&field=code", repository.SearchFieldCode, repository.SearchMatchContains},
+		{"search=usd&field=code&match=exact", repository.SearchFieldCode, repository.SearchMatchExact},
+	}
+
+	for i, tt := range tests {
+		getList(t, router, tt.query)
+		require.Len(t, svc.searches, i+1, tt.query)
+		search := svc.searches[i].search
+		assert.Equal(t, tt.field, search.Field, tt.query)
+		assert.Equal(t, tt.match, search.Match, tt.query)
+	}
+}
+
+func TestSearchRejectsUnknownFieldAndMatch(t *testing.T) {
+	svc := newSearchTestService()
+	router := newListRouter(svc)
+
+	for query, message := range map[string]string{
+		"search=dollar&match=suffix":    "Invalid match mode, must be one of: contains, prefix, exact",
+		"search=dollar&match=EXACT":     "Invalid match mode, must be one of: contains, prefix, exact",
+		"search=dollar&field=symbol":    "Invalid search field, must be one of: description, code",
+		"search=dollar&field=code%20--": "Invalid search field, must be one of: description, code",
+	} {
+		w := serve(router, http.MethodGet, "/api/v1/currencies?"+query, "", nil)
+		require.Equal(t, http.StatusBadRequest, w.Code, query)
+		var resp APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, message, resp.Error, query)
+	}
+	assert.Empty(t, svc.searches)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes why one field of a request body was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func init() {
+	// Report fields by their JSON name rather than the Go struct field name.
+	// This has to happen before the first request is validated, because
+	// the validator caches struct metadata on first use.
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+// jsonFieldName returns the name a struct field has in JSON
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// bindingError responds to a request body that failed to bind. Validation
+// failures are reported field by field in the details of the response.
+func bindingError(c *gin.Context, err error) {
+	errorResponseWithDetails(c, bindingErrorStatus(err), "Invalid request body", fieldErrors(err), err)
+}
+
+// fieldErrors extracts field level errors from a binding error. It returns
+// nil for errors that aren't about a particular field, such as malformed
+// JSON.
+func fieldErrors(err error) []FieldError {
+	var sliceErrs binding.SliceValidationError
+	if errors.As(err, &sliceErrs) {
+		// Gin doesn't record which items failed, so the fields of all failed
+		// items are reported together
+		var details []FieldError
+		for _, itemErr := range sliceErrs {
+			details = append(details, fieldErrors(itemErr)...)
+		}
+		return details
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		details := make([]FieldError, len(validationErrs))
+		for i, fieldErr := range validationErrs {
+			details[i] = FieldError{
+				Field:   fieldErr.Field(),
+				Rule:    fieldErr.Tag(),
+				Message: validationMessage(fieldErr),
+			}
+		}
+		return details
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("%s must be a JSON %s", typeErr.Field, jsonTypeName(typeErr.Type)),
+		}}
+	}
+	return nil
+}
+
+// validationMessage renders a failed validation rule as a sentence
+func validationMessage(fieldErr validator.FieldError) string {
+	field, param := fieldErr.Field(), fieldErr.Param()
+	unit := "characters"
+	if fieldErr.Kind() != reflect.String {
+		unit = "items"
+	}
+	numeric := isNumberKind(fieldErr.Kind())
+
+	switch fieldErr.Tag() {
+	case "required":
+		return field + " is required"
+	case "len":
+		return fmt.Sprintf("%s must be exactly %s %s", field, param, unit)
+	case "min":
+		if numeric {
+			return fmt.Sprintf("%s must be at least %s", field, param)
+		}
+		return fmt.Sprintf("%s must be at least %s %s", field, param, unit)
+	case "max":
+		if numeric {
+			return fmt.Sprintf("%s must be at most %s", field, param)
+		}
+		return fmt.Sprintf("%s must be at most %s %s", field, param, unit)
+	case "numeric":
+		return field + " must contain only digits"
+	}
+	return fmt.Sprintf("%s failed the %s rule", field, fieldErr.Tag())
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// jsonTypeName names the JSON type that decodes into t
+func jsonTypeName(t reflect.Type) string {
+	switch {
+	case isNumberKind(t.Kind()):
+		return "number"
+	case t.Kind() == reflect.String:
+		return "string"
+	case t.Kind() == reflect.Bool:
+		return "boolean"
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return "array"
+	}
+	return "object"
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCreateRouter(svc *fakeCurrencyService) http.Handler {
+	h := NewCurrencyHandler(svc, nil, testPagination)
+	router := newTestRouter()
+	router.POST("/api/v1/currencies", h.CreateCurrency)
+	router.POST("/api/v1/currencies/batch", h.CreateCurrenciesBatch)
+	return router
+}
+
+func bindingErrorDetails(t *testing.T, w *httptest.ResponseRecorder) []FieldError {
+	t.Helper()
+	var resp APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	assert.False(t, resp.Success)
+	assert.Equal(t, "Invalid request body", resp.Error)
+	return resp.Details
+}
+
+func TestBindingErrorDetails(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		status  int
+		details []FieldError
+	}{
+		{
+			name:   "missing required field",
+			path:   "/api/v1/currencies",
+			body:   `{"code":"USD"}`,
+			status: http.StatusUnprocessableEntity,
+			details: []FieldError{
+				{Field: "description", Rule: "required", Message: "description is required"},
+			},
+		},
+		{
+			name:   "code too short",
+			path:   "/api/v1/currencies",
+			body:   `{"code":"US","description":"US Dollar"}`,
+			status: http.StatusUnprocessableEntity,
+			details: []FieldError{
+				{Field: "code", Rule: "len", Message: "code must be exactly 3 characters"},
+			},
+		},
+		{
+			name:   "code too long",
+			path:   "/api/v1/currencies",
+			body:   `{"code":"USDX","description":"US Dollar"}`,
+			status: http.StatusUnprocessableEntity,
+			details: []FieldError{
+				{Field: "code", Rule: "len", Message: "code must be exactly 3 characters"},
+			},
+		},
+		{
+			name:   "several fields",
+			path:   "/api/v1/currencies",
+			body:   `{"code":"US"}`,
+			status: http.StatusUnprocessableEntity,
+			details: []FieldError{
+				{Field: "code", Rule: "len", Message: "code must be exactly 3 characters"},
+				{Field: "description", Rule: "required", Message: "description is required"},
+			},
+		},
+		{
+			name:   "wrong type",
+			path:   "/api/v1/currencies",
+			body:   `{"code":"USD","description":"US Dollar","factor":"100"}`,
+			status: http.StatusBadRequest,
+			details: []FieldError{
+				{Field: "factor", Rule: "type", Message: "factor must be a JSON number"},
+			},
+		},
+		{
+			name:   "batch item",
+			path:   "/api/v1/currencies/batch",
+			body:   `[{"code":"USD","description":"US Dollar"},{"code":"EU","description":"Euro"}]`,
+			status: http.StatusUnprocessableEntity,
+			details: []FieldError{
+				{Field: "code", Rule: "len", Message: "code must be exactly 3 characters"},
+			},
+		},
+		{
+			name:   "malformed JSON",
+			path:   "/api/v1/currencies",
+			body:   `{"code":`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newFakeCurrencyService()
+
+			w := serve(newCreateRouter(svc), http.MethodPost, tt.path, tt.body, nil)
+
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			assert.Equal(t, tt.details, bindingErrorDetails(t, w))
+		})
+	}
+}
+
+func TestCreateCurrencyLimitReached(t *testing.T) {
+	svc := newFakeCurrencyService()
+	svc.createErr = fmt.Errorf("%w: at most 3 currencies are allowed", service.ErrCurrencyLimitReached)
+	router := newCreateRouter(svc)
+
+	for path, body := range map[string]string{
+		"/api/v1/currencies":       `{"code":"USD","description":"US Dollar"}`,
+		"/api/v1/currencies/batch": `[{"code":"USD","description":"US Dollar"},{"code":"JPY","description":"Yen"}]`,
+	} {
+		w := serve(router, http.MethodPost, path, body, nil)
+
+		require.Equal(t, http.StatusForbidden, w.Code, path)
+		var resp APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), path)
+		assert.Equal(t, "Maximum number of currencies reached", resp.Error, path)
+	}
+}
+
+func TestCreateCurrencyTwiceIsConflict(t *testing.T) {
+	router := newCreateRouter(newFakeCurrencyService())
+	body := `{"code":"USD","description":"US Dollar"}`
+
+	w := serve(router, http.MethodPost, "/api/v1/currencies", body, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = serve(router, http.MethodPost, "/api/v1/currencies", body, nil)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	var resp APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.Success)
+	assert.Equal(t, "Currency code already exists", resp.Error)
+}
+
+func TestCreateCurrencyAcceptsRoundingMode(t *testing.T) {
+	svc := newFakeCurrencyService()
+	router := newCreateRouter(svc)
+
+	for code, mode := range map[string]string{"HUX": "half_up", "HEX": "half_even", "DNX": "down", "UPX": "up"} {
+		body := fmt.Sprintf(`{"code":%q,"description":"Test","factor":100,"rounding_mode":%q}`, code, mode)
+		w := serve(router, http.MethodPost, "/api/v1/currencies", body, nil)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Equal(t, mode, string(svc.currencies[code].RoundingMode))
+	}
+
+	w := serve(router, http.MethodPost, "/api/v1/currencies", `{"code":"CXG","description":"Test","rounding_mode":"ceiling"}`, nil)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	assert.Equal(t, "rounding_mode", bindingErrorDetails(t, w)[0].Field)
+}
+
+func TestCreateCurrencySeparatesMalformedFromInvalidRequests(t *testing.T) {
+	// The service rejects the factor with the error its validator returns
+	svc := newFakeCurrencyService()
+	svc.createErr = service.RequiredFieldsValidator{}.Validate(&model.Currency{Code: "USD", Description: "US Dollar", Factor: 7})
+	require.ErrorIs(t, svc.createErr, service.ErrInvalidCurrency)
+	router := newCreateRouter(svc)
+
+	for _, body := range []string{`{"code":"USD",`, `not json`, `["USD"]`} {
+		w := serve(router, http.MethodPost, "/api/v1/currencies", body, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "%s: %s", body, w.Body.String())
+	}
+
+	w := serve(router, http.MethodPost, "/api/v1/currencies", `{"code":"USD","description":"US Dollar","factor":7}`, nil)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	var resp APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Contains(t, resp.Error, "factor must be a positive power of ten")
+}
+
+func TestCreateCurrencyMissingDefaultableFieldIs400(t *testing.T) {
+	// In strict defaults mode the service rejects an omitted factor
+	svc := newFakeCurrencyService()
+	svc.createErr = fmt.Errorf("%w: factor", service.ErrMissingField)
+	router := newCreateRouter(svc)
+
+	w := serve(router, http.MethodPost, "/api/v1/currencies", `{"code":"USD","description":"US Dollar"}`, nil)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	var resp APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "missing required field: factor", resp.Error)
+}
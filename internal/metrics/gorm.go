@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// queryStartKey is the statement instance key holding the query start time
+const queryStartKey = "metrics:query_start"
+
+// InstrumentGORM registers GORM callbacks that record the duration of every
+// create, query, update, delete, row and raw operation
+func InstrumentGORM(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		operation string
+		before    error
+		after     error
+	}{
+		{"create",
+			cb.Create().Before("gorm:create").Register("metrics:before_create", startQueryTimer),
+			cb.Create().After("gorm:create").Register("metrics:after_create", observeQuery("create"))},
+		{"query",
+			cb.Query().Before("gorm:query").Register("metrics:before_query", startQueryTimer),
+			cb.Query().After("gorm:query").Register("metrics:after_query", observeQuery("query"))},
+		{"update",
+			cb.Update().Before("gorm:update").Register("metrics:before_update", startQueryTimer),
+			cb.Update().After("gorm:update").Register("metrics:after_update", observeQuery("update"))},
+		{"delete",
+			cb.Delete().Before("gorm:delete").Register("metrics:before_delete", startQueryTimer),
+			cb.Delete().After("gorm:delete").Register("metrics:after_delete", observeQuery("delete"))},
+		{"row",
+			cb.Row().Before("gorm:row").Register("metrics:before_row", startQueryTimer),
+			cb.Row().After("gorm:row").Register("metrics:after_row", observeQuery("row"))},
+		{"raw",
+			cb.Raw().Before("gorm:raw").Register("metrics:before_raw", startQueryTimer),
+			cb.Raw().After("gorm:raw").Register("metrics:after_raw", observeQuery("raw"))},
+	}
+
+	for _, hook := range hooks {
+		if hook.before != nil {
+			return fmt.Errorf("failed to register %s metrics callback: %w", hook.operation, hook.before)
+		}
+		if hook.after != nil {
+			return fmt.Errorf("failed to register %s metrics callback: %w", hook.operation, hook.after)
+		}
+	}
+
+	return nil
+}
+
+func startQueryTimer(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func observeQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		start, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		if started, ok := start.(time.Time); ok {
+			ObserveDBQuery(operation, time.Since(started))
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Package metrics exposes Prometheus metrics for the API. Collection is off
+// until Enable is called, so instrumented code can call the Observe helpers
+// unconditionally.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	enabled  atomic.Bool
+	registry = prometheus.NewRegistry()
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "currency_api_http_requests_total",
+		Help: "HTTP requests by method, route and status code.",
+	}, []string{"method", "path", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "currency_api_http_request_duration_seconds",
+		Help:    "HTTP request latency by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path"})
+
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "currency_api_cache_lookups_total",
+		Help: "Redis cache lookups by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "currency_api_db_query_duration_seconds",
+		Help:    "Database query duration by operation.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+)
+
+// Enable registers the collectors and turns metrics collection on
+func Enable() {
+	if enabled.Swap(true) {
+		return
+	}
+
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		cacheLookups,
+		dbQueryDuration,
+	)
+}
+
+// Enabled reports whether metrics collection is on
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a completed HTTP request. path should be the route
+// pattern, not the raw URL, to keep label cardinality bounded.
+func ObserveRequest(method, path string, status int, duration time.Duration) {
+	if !Enabled() {
+		return
+	}
+	httpRequests.WithLabelValues(method, path, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, path).Observe(duration.Seconds())
+}
+
+// ObserveCacheLookup records a cache hit or miss for the named cache
+func ObserveCacheLookup(cache string, hit bool) {
+	if !Enabled() {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+// ObserveDBQuery records the duration of a database operation
+func ObserveDBQuery(operation string, duration time.Duration) {
+	if !Enabled() {
+		return
+	}
+	dbQueryDuration.WithLabelValues(operation).Observe(duration.Seconds())
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// scrape returns the body of a GET of the metrics handler
+func scrape(t *testing.T) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+// Runs before Enable is called by the tests below
+func TestNothingIsCollectedUntilEnabled(t *testing.T) {
+	require.False(t, Enabled())
+
+	ObserveRequest(http.MethodGet, "/api/v1/currencies", http.StatusOK, time.Millisecond)
+	ObserveCacheLookup("currency", true)
+	ObserveDBQuery("query", time.Millisecond)
+
+	assert.NotContains(t, scrape(t), "currency_api_")
+}
+
+func TestScrapeExposesRecordedMetrics(t *testing.T) {
+	Enable()
+	Enable() // Registering the collectors twice would panic
+	require.True(t, Enabled())
+
+	ObserveRequest(http.MethodGet, "/api/v1/currencies/:code", http.StatusOK, 3*time.Millisecond)
+	ObserveRequest(http.MethodGet, "/api/v1/currencies/:code", http.StatusNotFound, time.Millisecond)
+	ObserveCacheLookup("currency", true)
+	ObserveCacheLookup("currency", false)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	require.NoError(t, InstrumentGORM(db))
+	var rows []struct{ Code string }
+	require.NoError(t, db.Table("currencies").Find(&rows).Error)
+
+	body := scrape(t)
+	for _, want := range []string{
+		`currency_api_http_requests_total{method="GET",path="/api/v1/currencies/:code",status="200"} 1`,
+		`currency_api_http_requests_total{method="GET",path="/api/v1/currencies/:code",status="404"} 1`,
+		`currency_api_http_request_duration_seconds_count{method="GET",path="/api/v1/currencies/:code"} 2`,
+		`currency_api_cache_lookups_total{cache="currency",result="hit"} 1`,
+		`currency_api_cache_lookups_total{cache="currency",result="miss"} 1`,
+		`currency_api_db_query_duration_seconds_count{operation="query"} 1`,
+		"go_goroutines",
+		"process_",
+	} {
+		assert.Contains(t, body, want)
+	}
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Tarifsiz/go-currency-api/internal/auth"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// UserIDKey is the gin context key holding the authenticated user's UUID
+const UserIDKey = "user_id"
+
+// JWTAuth requires a valid HS256-signed Bearer token whose "sub" claim is the
+// user's UUID. The user ID is stored in the gin context under UserIDKey and in
+// the request context for the service layer. Missing or invalid tokens get a 401.
+func JWTAuth(secret string) gin.HandlerFunc {
+	parse := tokenParser(secret)
+
+	return func(c *gin.Context) {
+		tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || tokenString == "" {
+			unauthorized(c, "Missing bearer token")
+			return
+		}
+
+		userID, message := parse(tokenString)
+		if message != "" {
+			unauthorized(c, message)
+			return
+		}
+
+		setUser(c, userID)
+		c.Next()
+	}
+}
+
+// OptionalJWTAuth identifies the caller like JWTAuth when the request has a
+// valid Bearer token, but lets every request through. It runs ahead of
+// middleware that needs to know the caller, such as RateLimit, before the
+// per-route JWTAuth decides whether a token is required.
+func OptionalJWTAuth(secret string) gin.HandlerFunc {
+	parse := tokenParser(secret)
+
+	return func(c *gin.Context) {
+		if tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && tokenString != "" {
+			if userID, message := parse(tokenString); message == "" {
+				setUser(c, userID)
+			}
+		}
+		c.Next()
+	}
+}
+
+// tokenParser returns a function validating a token signed with secret. It
+// returns the user ID from the "sub" claim, or a message saying why the
+// token was rejected.
+func tokenParser(secret string) func(tokenString string) (uuid.UUID, string) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	keyFunc := func(*jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}
+
+	return func(tokenString string) (uuid.UUID, string) {
+		token, err := parser.Parse(tokenString, keyFunc)
+		if err != nil || !token.Valid {
+			return uuid.Nil, "Invalid or expired token"
+		}
+
+		subject, err := token.Claims.GetSubject()
+		if err != nil {
+			return uuid.Nil, "Invalid token subject"
+		}
+		userID, err := uuid.Parse(subject)
+		if err != nil || userID == uuid.Nil {
+			return uuid.Nil, "Invalid token subject"
+		}
+		return userID, ""
+	}
+}
+
+// setUser records the authenticated user in the gin and request contexts
+func setUser(c *gin.Context, userID uuid.UUID) {
+	c.Set(UserIDKey, userID)
+	c.Request = c.Request.WithContext(auth.WithUserID(c.Request.Context(), userID))
+}
+
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="currency-api"`)
+	c.Header("Cache-Control", "no-store")
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"success":   false,
+		"error":     message,
+		"timestamp": model.Now(),
+	})
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheControl sets the Cache-Control header for a route group. Safe
+// (GET/HEAD) requests are marked publicly cacheable for maxAge seconds,
+// everything else is marked no-store. A maxAge of 0 disables caching.
+func CacheControl(maxAge int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if maxAge > 0 && (method == http.MethodGet || method == http.MethodHead) {
+			c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+		} else {
+			c.Header("Cache-Control", "no-store")
+		}
+
+		c.Next()
+	}
+}
+
+// NoStore marks every response of a route group as non-cacheable
+func NoStore() gin.HandlerFunc {
+	return CacheControl(0)
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCacheControlRouter(handler gin.HandlerFunc) *gin.Engine {
+	router := newTestRouter()
+	router.Use(handler)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		router.Handle(method, "/", ok)
+	}
+	return router
+}
+
+func TestCacheControlMarksReadsCacheable(t *testing.T) {
+	router := newCacheControlRouter(CacheControl(300))
+
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		w := serve(router, method, "/", "", nil)
+		assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"), method)
+	}
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		w := serve(router, method, "/", "", nil)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"), method)
+	}
+}
+
+func TestCacheControlWithoutMaxAgeIsNoStore(t *testing.T) {
+	for name, handler := range map[string]gin.HandlerFunc{"max-age 0": CacheControl(0), "NoStore": NoStore()} {
+		router := newCacheControlRouter(handler)
+
+		for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodPost} {
+			w := serve(router, method, "/", "", nil)
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"), "%s %s", name, method)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// ConcurrencyLimit bounds the number of requests handled at once. When the
+// limit is reached a request waits up to queueTimeout for a free slot; with a
+// zero queueTimeout it is rejected immediately. Rejected requests get a 503
+// with a Retry-After header. A max of 0 or less disables the limit.
+func ConcurrencyLimit(max int, queueTimeout time.Duration) gin.HandlerFunc {
+	if max <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	slots := make(chan struct{}, max)
+
+	return func(c *gin.Context) {
+		if !acquire(c, slots, queueTimeout) {
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"success":   false,
+				"error":     "Server is busy, please retry later",
+				"timestamp": model.Now(),
+			})
+			return
+		}
+		defer func() { <-slots }()
+
+		c.Next()
+	}
+}
+
+func acquire(c *gin.Context, slots chan struct{}, queueTimeout time.Duration) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if queueTimeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-c.Request.Context().Done():
+		return false
+	}
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingRouter serves GET / through handler, holding every request until
+// release is closed. Each request signals entered once it is being handled.
+func blockingRouter(handler gin.HandlerFunc, entered chan<- struct{}, release <-chan struct{}) *gin.Engine {
+	router := newTestRouter()
+	router.Use(handler)
+	router.GET("/", func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+// serveAsync serves a GET / in the background and delivers its response
+func serveAsync(router http.Handler) <-chan *httptest.ResponseRecorder {
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() { done <- serve(router, http.MethodGet, "/", "", nil) }()
+	return done
+}
+
+func TestConcurrencyLimitRejectsBeyondLimit(t *testing.T) {
+	entered, release := make(chan struct{}, 3), make(chan struct{})
+	router := blockingRouter(ConcurrencyLimit(2, 0), entered, release)
+
+	first, second := serveAsync(router), serveAsync(router)
+	<-entered
+	<-entered
+
+	w := serve(router, http.MethodGet, "/", "", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "Server is busy")
+
+	close(release)
+	assert.Equal(t, http.StatusOK, (<-first).Code)
+	assert.Equal(t, http.StatusOK, (<-second).Code)
+
+	// Slots are given back once requests finish
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/", "", nil).Code)
+}
+
+func TestConcurrencyLimitQueuesUntilSlotFrees(t *testing.T) {
+	entered, release := make(chan struct{}, 2), make(chan struct{})
+	router := blockingRouter(ConcurrencyLimit(1, time.Minute), entered, release)
+
+	first := serveAsync(router)
+	<-entered
+	queued := serveAsync(router)
+
+	select {
+	case <-entered:
+		t.Fatal("queued request ran while the limit was reached")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	assert.Equal(t, http.StatusOK, (<-first).Code)
+	assert.Equal(t, http.StatusOK, (<-queued).Code)
+}
+
+func TestConcurrencyLimitQueueTimesOut(t *testing.T) {
+	entered, release := make(chan struct{}, 1), make(chan struct{})
+	defer close(release)
+	router := blockingRouter(ConcurrencyLimit(1, 20*time.Millisecond), entered, release)
+
+	serveAsync(router)
+	<-entered
+
+	start := time.Now()
+	w := serve(router, http.MethodGet, "/", "", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestConcurrencyLimitBoundsRequestsInFlight(t *testing.T) {
+	const limit, requests = 3, 30
+	var inFlight, peak atomic.Int32
+
+	router := newTestRouter()
+	router.Use(ConcurrencyLimit(limit, time.Minute))
+	router.GET("/", func(c *gin.Context) {
+		n := inFlight.Add(1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		inFlight.Add(-1)
+		c.Status(http.StatusOK)
+	})
+
+	var wg sync.WaitGroup
+	codes := make([]int, requests)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = serve(router, http.MethodGet, "/", "", nil).Code
+		}()
+	}
+	wg.Wait()
+
+	for i, code := range codes {
+		require.Equal(t, http.StatusOK, code, "request %d", i)
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(limit))
+	assert.Positive(t, peak.Load())
+}
+
+func TestConcurrencyLimitDisabled(t *testing.T) {
+	entered, release := make(chan struct{}, 5), make(chan struct{})
+	router := blockingRouter(ConcurrencyLimit(0, 0), entered, release)
+
+	var responses []<-chan *httptest.ResponseRecorder
+	for i := 0; i < 5; i++ {
+		responses = append(responses, serveAsync(router))
+	}
+	for i := 0; i < 5; i++ {
+		<-entered
+	}
+	close(release)
+	for _, w := range responses {
+		assert.Equal(t, http.StatusOK, (<-w).Code)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireJSON rejects POST, PUT and PATCH requests with a body whose
+// Content-Type is not application/json (or a +json suffix type) with
+// 415 Unsupported Media Type
+func RequireJSON() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength == 0 {
+			c.Next()
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		if err != nil || !isJSONMediaType(mediaType) {
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{
+				"success":   false,
+				"error":     "Content-Type must be application/json",
+				"timestamp": model.Now(),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" ||
+		(strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequireJSONRouter() *gin.Engine {
+	router := newTestRouter()
+	router.Use(RequireJSON())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		router.Handle(method, "/", ok)
+	}
+	return router
+}
+
+func TestRequireJSONAcceptsJSONContentTypes(t *testing.T) {
+	router := newRequireJSONRouter()
+
+	for _, contentType := range []string{"application/json", "application/json; charset=utf-8", "Application/JSON", "application/merge-patch+json", "application/vnd.api+json"} {
+		for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch} {
+			w := serve(router, method, "/", `{"code":"USD"}`, map[string]string{"Content-Type": contentType})
+			assert.Equal(t, http.StatusOK, w.Code, "%s %s", method, contentType)
+		}
+	}
+}
+
+func TestRequireJSONRejectsMissingAndWrongContentTypes(t *testing.T) {
+	router := newRequireJSONRouter()
+
+	for name, headers := range map[string]map[string]string{
+		"missing":   nil,
+		"form":      {"Content-Type": "application/x-www-form-urlencoded"},
+		"text":      {"Content-Type": "text/plain"},
+		"text json": {"Content-Type": "text/json"},
+		"multipart": {"Content-Type": "multipart/form-data; boundary=x"},
+		"malformed": {"Content-Type": "application/json; charset"},
+	} {
+		for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch} {
+			w := serve(router, method, "/", `{"code":"USD"}`, headers)
+			require.Equal(t, http.StatusUnsupportedMediaType, w.Code, "%s %s", method, name)
+
+			var resp map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, false, resp["success"])
+			assert.Equal(t, "Content-Type must be application/json", resp["error"])
+		}
+	}
+}
+
+func TestRequireJSONIgnoresRequestsWithoutBody(t *testing.T) {
+	router := newRequireJSONRouter()
+
+	// Reads and deletes aren't checked, nor are writes without a body
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		w := serve(router, method, "/", "text", map[string]string{"Content-Type": "text/plain"})
+		assert.Equal(t, http.StatusOK, w.Code, method)
+	}
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch} {
+		w := serve(router, method, "/", "", nil)
+		assert.Equal(t, http.StatusOK, w.Code, method)
+	}
+}
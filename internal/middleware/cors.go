@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// corsExposedHeaders are the response headers browsers may read cross-origin
+const corsExposedHeaders = "X-Request-ID, X-RateLimit-Limit, X-RateLimit-Remaining, Retry-After, ETag, Idempotent-Replayed, X-Total-Count, X-Next-Cursor, Link"
+
+// CORS answers cross-origin requests from the allowed origins. A "*" entry
+// allows any origin without credentials; otherwise a matching Origin is
+// echoed back and credentials are allowed. Requests from other origins get
+// no CORS headers, and their preflights are rejected with a 403. Preflight
+// requests are answered with the allowed methods and headers without
+// reaching the route.
+func CORS(allowedOrigins, allowedMethods, allowedHeaders []string) gin.HandlerFunc {
+	wildcard := false
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			wildcard = true
+			continue
+		}
+		origins[normalizeOrigin(origin)] = true
+	}
+	methods := strings.Join(allowedMethods, ", ")
+	headers := strings.Join(allowedHeaders, ", ")
+
+	return func(c *gin.Context) {
+		preflight := c.Request.Method == http.MethodOptions
+		origin := c.Request.Header.Get("Origin")
+
+		if !wildcard {
+			// The response depends on the Origin whenever it is echoed back
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+
+		allowed := false
+		switch {
+		case origin == "":
+			// Not a cross-origin request
+		case wildcard:
+			allowed = true
+			c.Header("Access-Control-Allow-Origin", "*")
+		case origins[normalizeOrigin(origin)]:
+			allowed = true
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if allowed {
+			c.Header("Access-Control-Expose-Headers", corsExposedHeaders)
+		}
+
+		if preflight {
+			if origin != "" && !allowed {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// normalizeOrigin lowercases an origin and drops a trailing slash so
+// configured origins match the browser's Origin header
+func normalizeOrigin(origin string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	corsMethods = []string{"GET", "POST", "DELETE"}
+	corsHeaders = []string{"Content-Type", "Authorization"}
+)
+
+// newCORSRouter serves an empty 200 on /currencies behind CORS with origins
+func newCORSRouter(origins ...string) *gin.Engine {
+	router := newTestRouter()
+	router.Use(CORS(origins, corsMethods, corsHeaders))
+	router.GET("/currencies", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func TestCORSEchoesAllowedOrigin(t *testing.T) {
+	router := newCORSRouter("https://app.example.com", "https://admin.example.com/")
+
+	// Origins are matched ignoring case and a configured trailing slash
+	for _, origin := range []string{"https://app.example.com", "https://ADMIN.example.com"} {
+		w := serve(router, http.MethodGet, "/currencies", "", map[string]string{"Origin": origin})
+
+		require.Equal(t, http.StatusOK, w.Code, origin)
+		assert.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"), origin)
+		assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "X-Request-ID", origin)
+		assert.Contains(t, w.Header().Values("Vary"), "Origin", origin)
+	}
+}
+
+func TestCORSIgnoresDisallowedOrigin(t *testing.T) {
+	router := newCORSRouter("https://app.example.com")
+
+	for _, origin := range []string{"https://evil.example.com", "https://app.example.com.evil.com", "http://app.example.com", ""} {
+		w := serve(router, http.MethodGet, "/currencies", "", map[string]string{"Origin": origin})
+
+		// The request is still served; the browser withholds the response
+		require.Equal(t, http.StatusOK, w.Code, origin)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"), origin)
+		assert.Empty(t, w.Header().Get("Access-Control-Expose-Headers"), origin)
+		assert.Contains(t, w.Header().Values("Vary"), "Origin", origin)
+	}
+}
+
+func TestCORSWildcardOmitsCredentials(t *testing.T) {
+	w := serve(newCORSRouter("*"), http.MethodGet, "/currencies", "", map[string]string{"Origin": "https://anywhere.example.com"})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.NotContains(t, w.Header().Values("Vary"), "Origin")
+}
+
+func TestCORSPreflight(t *testing.T) {
+	router := newCORSRouter("https://app.example.com")
+	preflight := func(origin string) map[string]string {
+		return map[string]string{"Origin": origin, "Access-Control-Request-Method": "DELETE"}
+	}
+
+	w := serve(router, http.MethodOptions, "/currencies", "", preflight("https://app.example.com"))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "GET, POST, DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Empty(t, w.Body.String())
+
+	// Preflights from other origins are refused outright
+	w = serve(router, http.MethodOptions, "/currencies", "", preflight("https://evil.example.com"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+
+	// A preflight never reaches the route, which has no OPTIONS handler
+	w = serve(newCORSRouter("*"), http.MethodOptions, "/currencies", "", preflight("https://anywhere.example.com"))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
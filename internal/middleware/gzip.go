@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gzip compresses responses for clients that accept gzip. Responses shorter
+// than minLength bytes, responses that already carry a Content-Encoding and
+// requests whose path starts with one of excludedPaths are sent as is. A
+// level of 0 disables compression.
+func Gzip(level, minLength int, excludedPaths ...string) gin.HandlerFunc {
+	if level == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	pool := &sync.Pool{
+		New: func() interface{} {
+			// The level is validated by the config, so this can't fail
+			gz, _ := gzip.NewWriterLevel(nil, level)
+			return gz
+		},
+	}
+
+	return func(c *gin.Context) {
+		for _, prefix := range excludedPaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		// The representation depends on Accept-Encoding whether or not this
+		// particular response ends up compressed
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		if c.Request.Method == http.MethodHead || !acceptsGzip(c.Request.Header.Get("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		writer := &gzipWriter{ResponseWriter: c.Writer, pool: pool, minLength: minLength}
+		c.Writer = writer
+		// Restore the original writer so a panic recovered further out can
+		// still write its own response
+		defer func() { c.Writer = writer.ResponseWriter }()
+
+		c.Next()
+
+		writer.close()
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		// "gzip;q=0" explicitly refuses gzip
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipWriter buffers the body until it reaches minLength, then decides
+// whether to compress it. Shorter bodies are written uncompressed on close.
+type gzipWriter struct {
+	gin.ResponseWriter
+	pool      *sync.Pool
+	minLength int
+
+	buf         []byte
+	gz          *gzip.Writer
+	passthrough bool
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	switch {
+	case w.passthrough:
+		return w.ResponseWriter.Write(data)
+	case w.gz != nil:
+		return w.gz.Write(data)
+	}
+
+	w.buf = append(w.buf, data...)
+	if len(w.buf) < w.minLength {
+		return len(data), nil
+	}
+	if err := w.start(); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written reports true once a body has been buffered, so handlers don't
+// write a second response on top of it
+func (w *gzipWriter) Written() bool {
+	return len(w.buf) > 0 || w.gz != nil || w.ResponseWriter.Written()
+}
+
+// start flushes the buffered body, compressed unless the response can't or
+// shouldn't be
+func (w *gzipWriter) start() error {
+	buf := w.buf
+	w.buf = nil
+
+	if !w.compressible() {
+		w.passthrough = true
+		_, err := w.ResponseWriter.Write(buf)
+		return err
+	}
+
+	header := w.Header()
+	header.Set("Content-Encoding", "gzip")
+	header.Del("Content-Length")
+
+	w.gz = w.pool.Get().(*gzip.Writer)
+	w.gz.Reset(w.ResponseWriter)
+	_, err := w.gz.Write(buf)
+	return err
+}
+
+// compressible reports whether the response may be gzipped
+func (w *gzipWriter) compressible() bool {
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	if w.Header().Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := w.Header().Get("Content-Type")
+	for _, prefix := range []string{"image/", "video/", "audio/", "application/gzip", "application/zip"} {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// close finishes the response: it ends the gzip stream, or writes a body
+// that stayed below minLength uncompressed
+func (w *gzipWriter) close() {
+	if w.gz != nil {
+		w.gz.Close()
+		w.gz.Reset(nil)
+		w.pool.Put(w.gz)
+		w.gz = nil
+		return
+	}
+	if len(w.buf) > 0 {
+		w.ResponseWriter.Write(w.buf)
+		w.buf = nil
+	}
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// largeBody is well over the minimum length the gzip tests use
+var largeBody = `{"data":"` + strings.Repeat("currency ", 500) + `"}`
+
+// newGzipRouter serves largeBody as JSON on /list and /health and a short
+// body on /small, compressing with Gzip(level, 1024, "/health")
+func newGzipRouter(level int) *gin.Engine {
+	router := newTestRouter()
+	router.Use(CORS([]string{"*"}, []string{"GET"}, []string{"Content-Type"}))
+	router.Use(Gzip(level, 1024, "/health"))
+	list := func(c *gin.Context) { c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(largeBody)) }
+	router.GET("/list", list)
+	router.HEAD("/list", list)
+	router.GET("/health", list)
+	router.GET("/small", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) })
+	router.GET("/encoded", func(c *gin.Context) {
+		c.Header("Content-Encoding", "br")
+		c.Data(http.StatusOK, "application/json", []byte(largeBody))
+	})
+	router.GET("/image", func(c *gin.Context) { c.Data(http.StatusOK, "image/png", []byte(largeBody)) })
+	router.GET("/empty", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return router
+}
+
+// gunzip decompresses a response body
+func gunzip(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	reader, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	return string(body)
+}
+
+func TestGzipCompressesLargeResponses(t *testing.T) {
+	router := newGzipRouter(gzip.DefaultCompression)
+	plain := serve(router, http.MethodGet, "/list", "", nil)
+	require.Equal(t, http.StatusOK, plain.Code)
+	assert.Empty(t, plain.Header().Get("Content-Encoding"))
+
+	for _, accept := range []string{"gzip", "deflate, gzip;q=0.5", "br, GZIP", "*"} {
+		w := serve(router, http.MethodGet, "/list", "", map[string]string{"Accept-Encoding": accept, "Origin": "https://app.example.com"})
+
+		require.Equal(t, http.StatusOK, w.Code, accept)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"), accept)
+		assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding", accept)
+		assert.Empty(t, w.Header().Get("Content-Length"), accept)
+		assert.Less(t, w.Body.Len(), len(largeBody), accept)
+		assert.Equal(t, plain.Body.String(), gunzip(t, w), accept)
+		// CORS headers are set before the body and survive compression
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"), accept)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"), accept)
+	}
+}
+
+func TestGzipEveryLevelRoundTrips(t *testing.T) {
+	for level := gzip.BestSpeed; level <= gzip.BestCompression; level++ {
+		w := serve(newGzipRouter(level), http.MethodGet, "/list", "", map[string]string{"Accept-Encoding": "gzip"})
+		require.Equal(t, "gzip", w.Header().Get("Content-Encoding"), level)
+		assert.Equal(t, largeBody, gunzip(t, w), level)
+	}
+}
+
+func TestGzipSkipsResponses(t *testing.T) {
+	router := newGzipRouter(gzip.DefaultCompression)
+	gzipped := map[string]string{"Accept-Encoding": "gzip"}
+
+	tests := []struct {
+		name, method, path string
+		headers            map[string]string
+	}{
+		{"gzip not accepted", http.MethodGet, "/list", map[string]string{"Accept-Encoding": "deflate, br"}},
+		{"gzip refused", http.MethodGet, "/list", map[string]string{"Accept-Encoding": "gzip;q=0"}},
+		{"no Accept-Encoding", http.MethodGet, "/list", nil},
+		{"excluded path", http.MethodGet, "/health", gzipped},
+		{"below minimum length", http.MethodGet, "/small", gzipped},
+		{"already encoded", http.MethodGet, "/encoded", gzipped},
+		{"already compressed type", http.MethodGet, "/image", gzipped},
+		{"no content", http.MethodGet, "/empty", gzipped},
+		{"HEAD", http.MethodHead, "/list", gzipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, tt.method, tt.path, "", tt.headers)
+
+			assert.NotEqual(t, "gzip", w.Header().Get("Content-Encoding"))
+			if tt.method == http.MethodGet && w.Code == http.StatusOK {
+				assert.NotEmpty(t, w.Body.String())
+				assert.NotContains(t, w.Body.String(), "\x1f\x8b")
+			}
+		})
+	}
+
+	// Short bodies are still written, just uncompressed
+	w := serve(router, http.MethodGet, "/small", "", gzipped)
+	assert.JSONEq(t, `{"ok":true}`, w.Body.String())
+	w = serve(router, http.MethodGet, "/encoded", "", gzipped)
+	assert.Equal(t, "br", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, largeBody, w.Body.String())
+}
+
+func TestGzipLevelZeroDisablesCompression(t *testing.T) {
+	w := serve(newGzipRouter(0), http.MethodGet, "/list", "", map[string]string{"Accept-Encoding": "gzip"})
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.NotContains(t, w.Header().Values("Vary"), "Accept-Encoding")
+	assert.Equal(t, largeBody, w.Body.String())
+}
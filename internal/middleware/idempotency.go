@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/logging"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// IdempotencyKeyHeader is the request header clients use to make retries safe
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyLockTTL bounds how long a key stays claimed by a request that
+// never finishes, e.g. because the instance crashed
+const idempotencyLockTTL = time.Minute
+
+// maxIdempotencyKeyLength caps the size of client supplied keys
+const maxIdempotencyKeyLength = 255
+
+// idempotentResponse is what is stored in Redis per key. Status is 0 while
+// the first request is still being handled.
+type idempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Idempotency makes requests carrying an Idempotency-Key header safe to
+// retry. The first request with a key is handled normally and its response
+// stored in Redis for ttl; repeats get the stored response back with an
+// Idempotent-Replayed header instead of being executed again. Keys are scoped
+// per client, and reusing a key for a different request body gets a 422.
+// A repeat arriving while the first request is still running gets a 409.
+// Server errors are not stored, so those requests can be retried for real.
+// A ttl of 0 or less disables it.
+//
+// If Redis is unavailable requests are handled without idempotency rather
+// than failing the API.
+func Idempotency(client *redis.Client, ttl time.Duration) gin.HandlerFunc {
+	if ttl <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"success":   false,
+				"error":     "Idempotency-Key must be at most 255 characters",
+				"timestamp": model.Now(),
+			})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"success":   false,
+				"error":     "Failed to read request body",
+				"timestamp": model.Now(),
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		logger := logging.FromContext(ctx)
+		redisKey := "idempotency:" + rateLimitClient(c) + ":" + key
+		fingerprint := requestFingerprint(c.Request, body)
+
+		claim, _ := json.Marshal(idempotentResponse{Fingerprint: fingerprint})
+		claimed, err := client.SetNX(ctx, redisKey, claim, idempotencyLockTTL).Result()
+		if err != nil {
+			logger.Warn("idempotency store unavailable, handling request without it", "error", err)
+			c.Next()
+			return
+		}
+		if !claimed {
+			replayIdempotent(c, client, redisKey, fingerprint)
+			return
+		}
+
+		writer := &captureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		// Store the outcome even if the client has gone away meanwhile, so
+		// its retry gets the response it missed
+		storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+		defer cancel()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			err = client.Del(storeCtx, redisKey).Err()
+		} else {
+			stored, _ := json.Marshal(idempotentResponse{
+				Fingerprint: fingerprint,
+				Status:      status,
+				ContentType: writer.Header().Get("Content-Type"),
+				Body:        writer.body.Bytes(),
+			})
+			err = client.Set(storeCtx, redisKey, stored, ttl).Err()
+		}
+		if err != nil {
+			logger.Warn("failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// replayIdempotent answers a repeated key with the stored response
+func replayIdempotent(c *gin.Context, client *redis.Client, redisKey, fingerprint string) {
+	raw, err := client.Get(c.Request.Context(), redisKey).Bytes()
+	var stored idempotentResponse
+	if err == nil {
+		err = json.Unmarshal(raw, &stored)
+	}
+	if err != nil {
+		// The key expired or was released between SETNX and GET
+		logging.FromContext(c.Request.Context()).Warn("failed to load idempotent response", "error", err)
+		c.Header("Retry-After", "1")
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"success":   false,
+			"error":     "Request with this Idempotency-Key could not be replayed, please retry",
+			"timestamp": model.Now(),
+		})
+		return
+	}
+
+	switch {
+	case stored.Fingerprint != fingerprint:
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"success":   false,
+			"error":     "Idempotency-Key was already used for a different request",
+			"timestamp": model.Now(),
+		})
+	case stored.Status == 0:
+		c.Header("Retry-After", "1")
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"success":   false,
+			"error":     "A request with this Idempotency-Key is still in progress",
+			"timestamp": model.Now(),
+		})
+	default:
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(stored.Status, stored.ContentType, stored.Body)
+		c.Abort()
+	}
+}
+
+// requestFingerprint identifies a request by method, path and body so a key
+// can't be reused for a different request
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// captureWriter keeps a copy of the response body as it is written
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// idempotencyTestTTL is how long responses are kept in these tests
+const idempotencyTestTTL = time.Hour
+
+// newIdempotencyRouter serves POST / with a handler that counts its calls
+// and answers 201 with the request count, or with status when it's set
+func newIdempotencyRouter(t *testing.T, status int) (*gin.Engine, *miniredis.Miniredis, *atomic.Int32) {
+	t.Helper()
+	client, server := newTestRedis(t)
+	calls := &atomic.Int32{}
+	router := newTestRouter()
+	router.Use(OptionalJWTAuth(testSecret), Idempotency(client, idempotencyTestTTL))
+	router.POST("/", func(c *gin.Context) {
+		n := calls.Add(1)
+		if status != 0 {
+			c.JSON(status, gin.H{"call": n})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"call": n})
+	})
+	return router, server, calls
+}
+
+func withKey(key string) map[string]string {
+	return map[string]string{IdempotencyKeyHeader: key, "Content-Type": "application/json"}
+}
+
+func TestIdempotencyHandlesFirstRequest(t *testing.T) {
+	router, server, calls := newIdempotencyRouter(t, 0)
+
+	w := serve(router, http.MethodPost, "/", `{"code":"USD"}`, withKey("k1"))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"call":1}`, w.Body.String())
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(1), calls.Load())
+	require.Len(t, server.Keys(), 1)
+	assert.Equal(t, idempotencyTestTTL, server.TTL(server.Keys()[0]))
+}
+
+func TestIdempotencyReplaysDuplicateWithinTTL(t *testing.T) {
+	router, server, calls := newIdempotencyRouter(t, 0)
+	first := serve(router, http.MethodPost, "/", `{"code":"USD"}`, withKey("k1"))
+
+	server.FastForward(idempotencyTestTTL - time.Second)
+	w := serve(router, http.MethodPost, "/", `{"code":"USD"}`, withKey("k1"))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, first.Body.String(), w.Body.String())
+	assert.Equal(t, first.Header().Get("Content-Type"), w.Header().Get("Content-Type"))
+	assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Once the TTL has passed the key is forgotten
+	server.FastForward(time.Second)
+	w = serve(router, http.MethodPost, "/", `{"code":"USD"}`, withKey("k1"))
+	assert.JSONEq(t, `{"call":2}`, w.Body.String())
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotencyHandlesDifferentKeysSeparately(t *testing.T) {
+	router, _, calls := newIdempotencyRouter(t, 0)
+
+	serve(router, http.MethodPost, "/", `{"code":"USD"}`, withKey("k1"))
+	w := serve(router, http.MethodPost, "/", `{"code":"USD"}`, withKey("k2"))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"call":2}`, w.Body.String())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotencyScopesKeysPerClient(t *testing.T) {
+	router, _, calls := newIdempotencyRouter(t, 0)
+	alice, bob := bearer(t), bearer(t)
+	alice[IdempotencyKeyHeader], bob[IdempotencyKeyHeader] = "k1", "k1"
+
+	serve(router, http.MethodPost, "/", `{}`, alice)
+	w := serve(router, http.MethodPost, "/", `{}`, bob)
+
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotencyRejectsKeyReusedForDifferentBody(t *testing.T) {
+	router, _, calls := newIdempotencyRouter(t, 0)
+	serve(router, http.MethodPost, "/", `{"code":"USD"}`, withKey("k1"))
+
+	w := serve(router, http.MethodPost, "/", `{"code":"EUR"}`, withKey("k1"))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "Idempotency-Key was already used for a different request")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIdempotencyRejectsDuplicateWhileFirstInFlight(t *testing.T) {
+	client, _ := newTestRedis(t)
+	entered, release := make(chan struct{}), make(chan struct{})
+	router := newTestRouter()
+	router.Use(Idempotency(client, idempotencyTestTTL))
+	router.POST("/", func(c *gin.Context) {
+		close(entered)
+		<-release
+		c.JSON(http.StatusCreated, gin.H{"done": true})
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		serve(router, http.MethodPost, "/", `{}`, withKey("k1"))
+	}()
+	<-entered
+
+	w := serve(router, http.MethodPost, "/", `{}`, withKey("k1"))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "still in progress")
+
+	close(release)
+	wg.Wait()
+	w = serve(router, http.MethodPost, "/", `{}`, withKey("k1"))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	router, server, calls := newIdempotencyRouter(t, http.StatusServiceUnavailable)
+
+	serve(router, http.MethodPost, "/", `{}`, withKey("k1"))
+	assert.Empty(t, server.Keys())
+
+	w := serve(router, http.MethodPost, "/", `{}`, withKey("k1"))
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotencyWithoutKeyOrRedis(t *testing.T) {
+	router, server, calls := newIdempotencyRouter(t, 0)
+
+	serve(router, http.MethodPost, "/", `{}`, nil)
+	serve(router, http.MethodPost, "/", `{}`, nil)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Empty(t, server.Keys())
+
+	server.Close()
+	w := serve(router, http.MethodPost, "/", `{}`, withKey("k1"))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestIdempotencyRejectsLongKeys(t *testing.T) {
+	router, _, calls := newIdempotencyRouter(t, 0)
+
+	w := serve(router, http.MethodPost, "/", `{}`, withKey(strings.Repeat("k", maxIdempotencyKeyLength+1)))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Zero(t, calls.Load())
+}
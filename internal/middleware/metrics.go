@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that didn't match any route, so scanners
+// probing random URLs can't blow up metric cardinality
+const unmatchedRoute = "unmatched"
+
+// Metrics records request count and latency per route pattern and status
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		path := c.FullPath()
+		if path == "" {
+			path = unmatchedRoute
+		}
+		metrics.ObserveRequest(c.Request.Method, path, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/logging"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// rateLimitWindow is the fixed window the per-minute limit applies to
+const rateLimitWindow = time.Minute
+
+// RateLimit allows each client at most rpm requests per minute, counted in
+// Redis with a fixed window so the limit holds across instances. Clients are
+// identified by their user ID when an earlier OptionalJWTAuth or JWTAuth has
+// authenticated them, otherwise by IP.
+// Every response carries X-RateLimit-Limit and X-RateLimit-Remaining; requests
+// over the limit get a 429 with Retry-After. name scopes the counters so
+// route groups can have independent limits. An rpm of 0 or less disables it.
+//
+// If Redis is unavailable requests are let through rather than failing the API.
+func RateLimit(client *redis.Client, name string, rpm int) gin.HandlerFunc {
+	if rpm <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		window := now.Truncate(rateLimitWindow)
+		key := fmt.Sprintf("ratelimit:%s:%s:%d", name, rateLimitClient(c), window.Unix())
+
+		ctx := c.Request.Context()
+		pipe := client.TxPipeline()
+		incr := pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, rateLimitWindow)
+		if _, err := pipe.Exec(ctx); err != nil {
+			logging.FromContext(ctx).Warn("rate limiter unavailable, allowing request", "error", err)
+			c.Next()
+			return
+		}
+
+		count := int(incr.Val())
+		remaining := rpm - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Header("X-RateLimit-Limit", strconv.Itoa(rpm))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+		if count > rpm {
+			retryAfter := int(window.Add(rateLimitWindow).Sub(now).Seconds()) + 1
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"success":   false,
+				"error":     "Rate limit exceeded, please retry later",
+				"timestamp": model.Now(),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitClient identifies the caller for rate limiting
+func rateLimitClient(c *gin.Context) string {
+	if userID, ok := c.Get(UserIDKey); ok {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRateLimitRouter(t *testing.T, rpm int) *gin.Engine {
+	t.Helper()
+	client, _ := newTestRedis(t)
+	router := newTestRouter()
+	router.Use(OptionalJWTAuth(testSecret), RateLimit(client, "test", rpm))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func TestRateLimitGivesUsersBehindOneIPSeparateBuckets(t *testing.T) {
+	router := newRateLimitRouter(t, 2)
+	alice, bob := bearer(t), bearer(t)
+
+	// httptest requests all come from the same address
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/", "", alice).Code)
+	}
+	w := serve(router, http.MethodGet, "/", "", alice)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	for i := 0; i < 2; i++ {
+		w = serve(router, http.MethodGet, "/", "", bob)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	// Anonymous callers from the IP have a bucket of their own too
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/", "", nil).Code)
+}
+
+func TestRateLimitKeysInvalidTokensByIP(t *testing.T) {
+	router := newRateLimitRouter(t, 1)
+	invalid := map[string]string{"Authorization": "Bearer not-a-token"}
+
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/", "", invalid).Code)
+	// The anonymous request shares the bucket the invalid token fell back to
+	assert.Equal(t, http.StatusTooManyRequests, serve(router, http.MethodGet, "/", "", nil).Code)
+	// A valid token isn't held back by its IP's bucket
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/", "", bearer(t)).Code)
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	router := newRateLimitRouter(t, 3)
+
+	w := serve(router, http.MethodGet, "/", "", nil)
+	assert.Equal(t, "3", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Remaining"))
+}
+
+func TestRateLimitLetsRequestsThroughWithoutRedis(t *testing.T) {
+	client, server := newTestRedis(t)
+	server.Close()
+	router := newTestRouter()
+	router.Use(RateLimit(client, "test", 1))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/", "", nil).Code)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	router := newRateLimitRouter(t, 0)
+
+	for i := 0; i < 5; i++ {
+		w := serve(router, http.MethodGet, "/", "", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger attaches a logger tagged with the request ID to the request
+// context and writes one structured line per request once it completes. It
+// must run after RequestID.
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx := c.Request.Context()
+
+		reqLogger := logger.With("request_id", logging.RequestIDFromContext(ctx))
+		c.Request = c.Request.WithContext(logging.WithLogger(ctx, reqLogger))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.String())
+		}
+
+		reqLogger.Log(ctx, level, "request completed", attrs...)
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+)
+
+// CanonicalCode returns code with surrounding whitespace removed and letters
+// upper-cased, without validating it
+func CanonicalCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// NormalizeCode returns the canonical form of a currency code from user
+// input: upper-cased with everything but letters stripped, so " usd" and
+// "U.S.D" are both USD. The result must be exactly three letters A-Z. This
+// holds in every validation mode, so a code that couldn't be normalized
+// can't be created either. Invalid codes return an error wrapping
+// apperrors.ErrInvalidCurrencyCode.
+func NormalizeCode(code string) (string, error) {
+	normalized := strings.Map(func(r rune) rune {
+		if !unicode.IsLetter(r) {
+			return -1
+		}
+		return r
+	}, CanonicalCode(code))
+
+	if !isCodeLetters(normalized) {
+		return "", fmt.Errorf("%w: %q must be three letters A-Z", apperrors.ErrInvalidCurrencyCode, code)
+	}
+	return normalized, nil
+}
+
+// isCodeLetters reports whether code is exactly three letters A-Z
+func isCodeLetters(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < 'A' || code[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
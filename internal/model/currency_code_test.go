@@ -0,0 +1,45 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCode(t *testing.T) {
+	for input, want := range map[string]string{
+		// Lowercase and mixed case
+		"USD": "USD",
+		"usd": "USD",
+		"uSd": "USD",
+		// Padding
+		"  eur ":  "EUR",
+		"\tgbp\n": "GBP",
+		// Characters other than letters are stripped
+		"U.S.D":   "USD",
+		"us-d":    "USD",
+		"u s d":   "USD",
+		"'JPY'":   "JPY",
+		"CHF1":    "CHF",
+		"1-2-AUD": "AUD",
+	} {
+		code, err := NormalizeCode(input)
+		require.NoError(t, err, "%q", input)
+		assert.Equal(t, want, code, "%q", input)
+	}
+}
+
+func TestNormalizeCodeRejectsInvalidCodes(t *testing.T) {
+	for _, input := range []string{
+		"", "   ", "US", "USDT",
+		// Too few letters once other characters are stripped
+		"123", "US1", "U$D", "US-", "€UR", "12 3",
+		// Letters outside A-Z
+		"ÉUR", "ДОЛ",
+	} {
+		_, err := NormalizeCode(input)
+		assert.ErrorIs(t, err, apperrors.ErrInvalidCurrencyCode, "%q", input)
+	}
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsISO4217(t *testing.T) {
+	for _, code := range []string{"USD", "EUR", "JPY", "KWD", "CHF", "XAU", "XXX"} {
+		assert.True(t, IsISO4217(code), code)
+	}
+	// Unassigned, crypto, withdrawn and malformed codes, and codes that
+	// haven't been normalized
+	for _, code := range []string{"ZZZ", "BTC", "DEM", "123", "US", "USDT", "", "usd", " USD"} {
+		assert.False(t, IsISO4217(code), "%q", code)
+	}
+}
+
+func TestISO4217MinorUnits(t *testing.T) {
+	for code, want := range map[string]int{"JPY": 0, "KRW": 0, "USD": 2, "EUR": 2, "KWD": 3, "BHD": 3, "CLF": 4} {
+		places, ok := ISO4217MinorUnits(code)
+		assert.True(t, ok, code)
+		assert.Equal(t, want, places, code)
+	}
+
+	_, ok := ISO4217MinorUnits("BTC")
+	assert.False(t, ok)
+}
@@ -1,22 +1,37 @@
 package model
 
 import (
-	"time"
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Tarifsiz/go-currency-api/internal/money"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"time"
 )
 
 // Currency represents a currency with its properties
 type Currency struct {
-	ID                  uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Code                string    `json:"code" gorm:"type:varchar(3);unique;not null;index"`
-	Description         string    `json:"description" gorm:"type:varchar(255);not null"`
-	AmountDisplayFormat string    `json:"amount_display_format" gorm:"type:varchar(50);default:'###,###.##'"`
-	HtmlEncodedSymbol   string    `json:"html_encoded_symbol" gorm:"type:varchar(50)"`
-	Factor              int       `json:"factor" gorm:"default:100"` // For decimal precision (100 = 2 decimal places)
-	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt           time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-	CreatedBy           uuid.UUID `json:"created_by" gorm:"type:uuid"`
+	ID                  uuid.UUID          `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Code                string             `json:"code" gorm:"type:varchar(3);not null;index;uniqueIndex:idx_currencies_code_active,expression:UPPER(code),where:deleted_at IS NULL"`
+	NumericCode         string             `json:"numeric_code" gorm:"type:varchar(3);not null;default:''"` // ISO 4217 numeric code, empty if unknown
+	Description         string             `json:"description" gorm:"type:varchar(255);not null"`
+	AmountDisplayFormat string             `json:"amount_display_format" gorm:"type:varchar(50);default:'###,###.##'"`
+	HtmlEncodedSymbol   string             `json:"html_encoded_symbol" gorm:"type:varchar(50)"`
+	Factor              int                `json:"factor" gorm:"type:integer;default:100"`                             // For decimal precision (100 = 2 decimal places)
+	RoundingMode        money.RoundingMode `json:"rounding_mode" gorm:"type:varchar(10);not null;default:'half_even'"` // How conversions round to the minor unit
+	IsActive            bool               `json:"is_active" gorm:"not null;default:true"`                             // false for obsolete currencies, e.g. pre-euro national currencies
+	ValidFrom           *time.Time         `json:"valid_from" gorm:"type:timestamptz"`                                 // Introduction date; nil for no lower bound
+	ValidUntil          *time.Time         `json:"valid_until" gorm:"type:timestamptz"`                                // Demonetization date, exclusive; nil for no upper bound
+	VisibleAfter        *time.Time         `json:"visible_after" gorm:"type:timestamptz"`                              // Soft launch: left out of listings until then; nil to list right away
+	CreatedAt           time.Time          `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time          `json:"updated_at" gorm:"autoUpdateTime;index"`
+	CreatedBy           uuid.UUID          `json:"created_by" gorm:"type:uuid;not null"`
+	UpdatedBy           *uuid.UUID         `json:"updated_by" gorm:"type:uuid"`       // nil until the currency is first updated
+	Version             int                `json:"version" gorm:"not null;default:1"` // Incremented on every update for optimistic locking
+	DeletedAt           gorm.DeletedAt     `json:"deleted_at,omitempty" gorm:"index"`
 }
 
 // BeforeCreate hook for Currency
@@ -30,4 +45,174 @@ func (c *Currency) BeforeCreate(tx *gorm.DB) error {
 // TableName method for explicit table naming
 func (Currency) TableName() string {
 	return "currencies"
-}
\ No newline at end of file
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps as Timestamp.
+// valid_from and valid_until are null when unbounded, visible_after when the
+// currency isn't soft-launched, and deleted_at unless it is soft-deleted.
+func (c Currency) MarshalJSON() ([]byte, error) {
+	type currency Currency
+	var deletedAt *Timestamp
+	if c.DeletedAt.Valid {
+		deletedAt = &Timestamp{Time: c.DeletedAt.Time}
+	}
+	return json.Marshal(struct {
+		currency
+		ValidFrom    *Timestamp `json:"valid_from"`
+		ValidUntil   *Timestamp `json:"valid_until"`
+		VisibleAfter *Timestamp `json:"visible_after"`
+		CreatedAt    Timestamp  `json:"created_at"`
+		UpdatedAt    Timestamp  `json:"updated_at"`
+		DeletedAt    *Timestamp `json:"deleted_at"`
+	}{currency(c), NewNullableTimestamp(c.ValidFrom), NewNullableTimestamp(c.ValidUntil), NewNullableTimestamp(c.VisibleAfter), NewTimestamp(c.CreatedAt), NewTimestamp(c.UpdatedAt), deletedAt})
+}
+
+// MarshalJSONWith encodes the currency followed by one extra field. Types
+// embedding a Currency use it, since the promoted MarshalJSON would drop
+// their own fields.
+func (c Currency) MarshalJSONWith(name string, value interface{}) ([]byte, error) {
+	object, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return appendJSONField(object, name, value)
+}
+
+// appendJSONField adds a field to the end of an encoded JSON object
+func appendJSONField(object []byte, name string, value interface{}) ([]byte, error) {
+	object = bytes.TrimSpace(object)
+	if len(object) < 2 || object[len(object)-1] != '}' {
+		return nil, fmt.Errorf("cannot add field %q to non-object JSON", name)
+	}
+
+	key, err := json.Marshal(name)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	out := append([]byte{}, object[:len(object)-1]...)
+	if len(object) > 2 {
+		out = append(out, ',')
+	}
+	out = append(out, key...)
+	out = append(out, ':')
+	out = append(out, encoded...)
+	return append(out, '}'), nil
+}
+
+// ExchangeRate represents the rate for converting one unit of FromCode into ToCode
+type ExchangeRate struct {
+	ID        uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	FromCode  string          `json:"from_code" gorm:"type:varchar(3);not null;index:idx_exchange_rates_pair"`
+	ToCode    string          `json:"to_code" gorm:"type:varchar(3);not null;index:idx_exchange_rates_pair"`
+	Rate      decimal.Decimal `json:"rate" gorm:"type:numeric(24,12);not null"`
+	Timestamp time.Time       `json:"timestamp" gorm:"not null;index"`
+	Source    string          `json:"source" gorm:"type:varchar(50);not null;default:'provider'"` // RateSourceProvider, or the source named by an import
+	Pinned    bool            `json:"pinned" gorm:"not null;default:false"`                       // Manual rate that overrides the pair's other rates
+	Priority  int             `json:"priority" gorm:"not null;default:0"`                         // Rank among the pair's pinned rates; the highest wins
+	CreatedAt time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
+
+const (
+	// RateSourceProvider is the source of rates fetched by the rate refresher
+	RateSourceProvider = "provider"
+	// RateSourceManual is the source of rates pinned by an operator
+	RateSourceManual = "manual"
+)
+
+// BeforeCreate hook for ExchangeRate
+func (r *ExchangeRate) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName method for explicit table naming
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps as Timestamp
+func (r ExchangeRate) MarshalJSON() ([]byte, error) {
+	type exchangeRate ExchangeRate
+	return json.Marshal(struct {
+		exchangeRate
+		Timestamp Timestamp `json:"timestamp"`
+		CreatedAt Timestamp `json:"created_at"`
+	}{exchangeRate(r), NewTimestamp(r.Timestamp), NewTimestamp(r.CreatedAt)})
+}
+
+// CurrencyTranslation is the description of a currency in one locale. Locale
+// is a lower-case BCP 47 language tag, e.g. "fr" or "fr-ca".
+type CurrencyTranslation struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	CurrencyCode string    `json:"currency_code" gorm:"type:varchar(3);not null;uniqueIndex:idx_currency_translations_code_locale"`
+	Locale       string    `json:"locale" gorm:"type:varchar(35);not null;uniqueIndex:idx_currency_translations_code_locale"`
+	Description  string    `json:"description" gorm:"type:varchar(255);not null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate hook for CurrencyTranslation
+func (t *CurrencyTranslation) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName method for explicit table naming
+func (CurrencyTranslation) TableName() string {
+	return "currency_translations"
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps as Timestamp
+func (t CurrencyTranslation) MarshalJSON() ([]byte, error) {
+	type currencyTranslation CurrencyTranslation
+	return json.Marshal(struct {
+		currencyTranslation
+		CreatedAt Timestamp `json:"created_at"`
+		UpdatedAt Timestamp `json:"updated_at"`
+	}{currencyTranslation(t), NewTimestamp(t.CreatedAt), NewTimestamp(t.UpdatedAt)})
+}
+
+// Change log actions
+const (
+	ChangeCreated  = "created"
+	ChangeUpdated  = "updated"
+	ChangeDeleted  = "deleted"  // Soft-deleted
+	ChangeRestored = "restored" // Soft deletion undone
+	ChangePurged   = "purged"   // Hard-deleted
+)
+
+// ChangeLog is one entry in the audit trail of a currency. Version is the
+// currency's version after the change; ChangedBy is nil when the acting user
+// isn't known.
+type ChangeLog struct {
+	ID           int64      `json:"-" gorm:"primaryKey;autoIncrement"`
+	CurrencyID   uuid.UUID  `json:"currency_id" gorm:"type:uuid;not null"`
+	CurrencyCode string     `json:"currency_code" gorm:"type:varchar(3);not null"`
+	Action       string     `json:"action" gorm:"type:varchar(10);not null"`
+	Version      int        `json:"version" gorm:"not null"`
+	ChangedBy    *uuid.UUID `json:"changed_by" gorm:"type:uuid"`
+	ChangedAt    time.Time  `json:"changed_at" gorm:"not null;default:now()"`
+}
+
+// TableName method for explicit table naming
+func (ChangeLog) TableName() string {
+	return "currency_change_log"
+}
+
+// MarshalJSON implements json.Marshaler, writing ChangedAt as a Timestamp
+func (l ChangeLog) MarshalJSON() ([]byte, error) {
+	type changeLog ChangeLog
+	return json.Marshal(struct {
+		changeLog
+		ChangedAt Timestamp `json:"changed_at"`
+	}{changeLog(l), NewTimestamp(l.ChangedAt)})
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxSymbolLength is the size of the html_encoded_symbol column
+const maxSymbolLength = 50
+
+// maxSymbolRunes caps the decoded symbol, which is rendered next to amounts
+const maxSymbolRunes = 8
+
+// entityPattern matches a single named, decimal or hexadecimal character
+// reference such as &euro;, &#36; or &#x20AC;. Browsers accept numeric
+// references without the trailing semicolon, and some seeded symbols rely
+// on that.
+var entityPattern = regexp.MustCompile(`&(?:#[0-9]{1,7};?|#[xX][0-9a-fA-F]{1,6};?|[A-Za-z][A-Za-z0-9]{1,31};)`)
+
+// ValidateSymbol checks that an HTML encoded symbol is made of character
+// references and short plain text only, so it is safe to insert into HTML
+// as is and decodes to a printable symbol. The empty symbol is valid.
+func ValidateSymbol(symbol string) error {
+	if symbol == "" {
+		return nil
+	}
+	if len(symbol) > maxSymbolLength {
+		return fmt.Errorf("symbol must be at most %d characters", maxSymbolLength)
+	}
+
+	for _, entity := range entityPattern.FindAllString(symbol, -1) {
+		if html.UnescapeString(entity) == entity {
+			return fmt.Errorf("symbol contains unknown entity %s", entity)
+		}
+	}
+	// Outside of entities, markup characters and bare ampersands are unsafe
+	if strings.ContainsAny(entityPattern.ReplaceAllString(symbol, ""), `&<>"'`) {
+		return errors.New("symbol must be HTML entities or plain text without markup")
+	}
+
+	decoded := DecodeSymbol(symbol)
+	for _, r := range decoded {
+		// Entities such as &lt; would reintroduce markup once decoded
+		if unicode.IsControl(r) || r == '<' || r == '>' {
+			return errors.New("symbol must decode to printable characters without markup")
+		}
+	}
+	if utf8.RuneCountInString(decoded) > maxSymbolRunes {
+		return fmt.Errorf("symbol must decode to at most %d characters", maxSymbolRunes)
+	}
+
+	return nil
+}
+
+// DecodeSymbol returns the characters an HTML encoded symbol represents,
+// e.g. "€" for "&euro;", for clients that don't render HTML
+func DecodeSymbol(symbol string) string {
+	return html.UnescapeString(symbol)
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSymbolAcceptsEntitiesAndPlainSymbols(t *testing.T) {
+	for _, symbol := range []string{
+		"",
+		// Decimal, hexadecimal and named references
+		"&#36;", "&#8364;", "&#x20AC;", "&#X20ac;", "&euro;", "&pound;", "&yen;",
+		// Numeric references without the semicolon, as some seeds have
+		"&#36", "&#8364",
+		// Several references and mixed text
+		"&#67;&#72;&#70;", "US&#36;", "R&#36;",
+		// Short plain symbols
+		"$", "€", "CHF", "kr", "₹", "د.إ",
+	} {
+		assert.NoError(t, ValidateSymbol(symbol), "%q", symbol)
+	}
+}
+
+func TestValidateSymbolRejectsMarkup(t *testing.T) {
+	for symbol, want := range map[string]string{
+		"<script>alert(1)</script>": "without markup",
+		"<script>":                  "without markup",
+		"<b>$</b>":                  "without markup",
+		`$" onmouseover="alert(1)`:  "without markup",
+		"'$'":                       "without markup",
+		"&#36;<img src=x>":          "without markup",
+		// Entities that decode to markup or control characters
+		"&lt;script&gt;":   "printable characters without markup",
+		"&#60;script&#62;": "printable characters without markup",
+		"&#x3C;":           "printable characters without markup",
+		"&#10;":            "printable characters",
+		// Broken references
+		"&zzz;": "unknown entity &zzz;",
+		"& $":   "without markup",
+		"&euro": "without markup",
+		// Too long, encoded or decoded
+		strings.Repeat("&#36;", 11): "at most 50 characters",
+		"DOLLARSIGN":                "at most 8 characters",
+	} {
+		err := ValidateSymbol(symbol)
+		if assert.Error(t, err, "%q", symbol) {
+			assert.Contains(t, err.Error(), want, "%q", symbol)
+		}
+	}
+}
+
+func TestDecodeSymbol(t *testing.T) {
+	for symbol, want := range map[string]string{
+		"&#36;":           "$",
+		"&#36":            "$",
+		"&euro;":          "€",
+		"&#x20AC;":        "€",
+		"&#8364;":         "€",
+		"&#67;&#72;&#70;": "CHF",
+		"US&#36;":         "US$",
+		"kr":              "kr",
+		"":                "",
+	} {
+		assert.Equal(t, want, DecodeSymbol(symbol), "%q", symbol)
+	}
+}
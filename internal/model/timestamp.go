@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+)
+
+// TimestampFormat is the layout of every timestamp the API returns: RFC 3339
+// in UTC with a literal Z and microseconds, the precision Postgres stores
+const TimestampFormat = "2006-01-02T15:04:05.000000Z"
+
+// Timestamp is a time.Time that is always serialized in UTC using
+// TimestampFormat, whatever location it was created in. Any RFC 3339 time
+// is accepted when decoding.
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp wraps t for serialization
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t}
+}
+
+// NewNullableTimestamp wraps t for serialization. A nil t stays nil, so it
+// is encoded as null.
+func NewNullableTimestamp(t *time.Time) *Timestamp {
+	if t == nil {
+		return nil
+	}
+	return &Timestamp{Time: *t}
+}
+
+// MarshalJSON implements json.Marshaler
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + t.UTC().Format(TimestampFormat) + `"`), nil
+}
+
+// Now returns the current time as a Timestamp
+func Now() Timestamp {
+	return Timestamp{Time: time.Now()}
+}
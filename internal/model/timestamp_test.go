@@ -0,0 +1,121 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// newYork is a zone with an offset, so conversion to UTC is visible
+var newYork = time.FixedZone("EST", -5*60*60)
+
+func TestTimestampFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		time time.Time
+		want string
+	}{
+		{"UTC", time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC), `"2024-01-02T03:04:05.123456Z"`},
+		{"other zone", time.Date(2024, 1, 1, 22, 4, 5, 123456000, newYork), `"2024-01-02T03:04:05.123456Z"`},
+		{"whole seconds", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), `"2024-01-02T03:04:05.000000Z"`},
+		{"nanoseconds truncated", time.Date(2024, 1, 2, 3, 4, 5, 999999999, time.UTC), `"2024-01-02T03:04:05.999999Z"`},
+		{"zero", time.Time{}, `"0001-01-01T00:00:00.000000Z"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := json.Marshal(NewTimestamp(tt.time))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(encoded))
+		})
+	}
+}
+
+func TestTimestampDecodesAnyRFC3339Time(t *testing.T) {
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, encoded := range []string{
+		`"2024-01-02T03:04:05Z"`,
+		`"2024-01-02T03:04:05.000000Z"`,
+		`"2024-01-01T22:04:05-05:00"`,
+	} {
+		var ts Timestamp
+		require.NoError(t, json.Unmarshal([]byte(encoded), &ts), encoded)
+		assert.True(t, want.Equal(ts.Time), encoded)
+	}
+}
+
+func TestNullableTimestamp(t *testing.T) {
+	assert.Nil(t, NewNullableTimestamp(nil))
+
+	encoded, err := json.Marshal(struct {
+		At *Timestamp `json:"at"`
+	}{NewNullableTimestamp(nil)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"at":null}`, string(encoded))
+}
+
+func TestModelTimestampsUseTimestampFormat(t *testing.T) {
+	at := time.Date(2024, 1, 1, 22, 4, 5, 123456789, newYork)
+	const want = "2024-01-02T03:04:05.123456Z"
+
+	currency := Currency{Code: "USD", ValidFrom: &at, CreatedAt: at, UpdatedAt: at, DeletedAt: gorm.DeletedAt{Time: at, Valid: true}}
+	fields := encodeFields(t, currency)
+	for _, name := range []string{"valid_from", "created_at", "updated_at", "deleted_at"} {
+		assert.Equal(t, want, fields[name], name)
+	}
+	assert.Nil(t, fields["valid_until"])
+	assert.Equal(t, "USD", fields["code"])
+
+	live := encodeFields(t, Currency{Code: "USD", CreatedAt: at, UpdatedAt: at})
+	assert.Contains(t, live, "deleted_at")
+	assert.Nil(t, live["deleted_at"])
+
+	rate := encodeFields(t, ExchangeRate{FromCode: "USD", ToCode: "EUR", Timestamp: at, CreatedAt: at})
+	assert.Equal(t, want, rate["timestamp"])
+	assert.Equal(t, want, rate["created_at"])
+
+	translation := encodeFields(t, CurrencyTranslation{CurrencyCode: "USD", Locale: "fr", CreatedAt: at, UpdatedAt: at})
+	assert.Equal(t, want, translation["created_at"])
+	assert.Equal(t, want, translation["updated_at"])
+}
+
+func TestMarshalJSONWithKeepsCurrencyAndExtraField(t *testing.T) {
+	at := time.Date(2024, 1, 1, 22, 4, 5, 0, newYork)
+	encoded, err := Currency{Code: "USD", UpdatedAt: at}.MarshalJSONWith("score", 0.5)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(encoded, &fields))
+	assert.Equal(t, "USD", fields["code"])
+	assert.Equal(t, "2024-01-02T03:04:05.000000Z", fields["updated_at"])
+	assert.Equal(t, 0.5, fields["score"])
+}
+
+func TestEncodedCurrencyDecodesBack(t *testing.T) {
+	// Cached currencies are stored in the encoded form
+	at := time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	currency := Currency{ID: uuid.New(), Code: "USD", Factor: 100, CreatedAt: at, UpdatedAt: at}
+	encoded, err := json.Marshal(currency)
+	require.NoError(t, err)
+
+	var decoded Currency
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, currency.ID, decoded.ID)
+	assert.True(t, at.Equal(decoded.CreatedAt))
+	assert.True(t, at.Equal(decoded.UpdatedAt))
+	assert.False(t, decoded.DeletedAt.Valid)
+}
+
+func encodeFields(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	encoded, err := json.Marshal(v)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(encoded, &fields))
+	return fields
+}
@@ -0,0 +1,100 @@
+// Package money converts between integer minor-unit amounts and exact
+// decimal amounts using a currency's Factor. Amounts are never held in
+// floating point.
+package money
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrPrecisionLoss is returned when an amount has more decimal places
+	// than the currency's minor unit can represent
+	ErrPrecisionLoss = errors.New("amount has more decimal places than the currency allows")
+	// ErrOutOfRange is returned when an amount doesn't fit in int64 minor units
+	ErrOutOfRange = errors.New("amount out of range")
+)
+
+// DecimalPlaces returns the number of decimal places represented by a
+// currency factor (1 -> 0, 100 -> 2, 1000 -> 3). Factors below 10 have none.
+func DecimalPlaces(factor int) int32 {
+	var places int32
+	for factor >= 10 {
+		factor /= 10
+		places++
+	}
+	return places
+}
+
+// MinorUnitsToDecimal converts an amount in minor units into major units,
+// e.g. 12345 with factor 100 is 123.45. The conversion is exact.
+func MinorUnitsToDecimal(amount int64, factor int) decimal.Decimal {
+	return decimal.New(amount, -DecimalPlaces(factor))
+}
+
+// DecimalToMinorUnits converts an amount in major units into minor units,
+// e.g. 123.45 with factor 100 is 12345. Amounts with more decimal places
+// than the factor allows return ErrPrecisionLoss rather than being rounded
+// silently; round with Round first when rounding is intended.
+func DecimalToMinorUnits(amount decimal.Decimal, factor int) (int64, error) {
+	shifted := amount.Shift(DecimalPlaces(factor))
+	if !shifted.IsInteger() {
+		return 0, fmt.Errorf("%w: %s with factor %d", ErrPrecisionLoss, amount, factor)
+	}
+
+	minor := shifted.BigInt()
+	if !minor.IsInt64() {
+		return 0, fmt.Errorf("%w: %s", ErrOutOfRange, amount)
+	}
+	return minor.Int64(), nil
+}
+
+// RoundingMode is how an amount is rounded to a currency's minor unit
+type RoundingMode string
+
+// Rounding modes accepted by Round
+const (
+	// RoundHalfUp rounds halves away from zero: 1.005 -> 1.01, -1.005 -> -1.01
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundHalfEven rounds halves to the nearest even digit (banker's
+	// rounding): 1.005 -> 1.00, 1.015 -> 1.02
+	RoundHalfEven RoundingMode = "half_even"
+	// RoundDown truncates toward zero: 1.009 -> 1.00, -1.009 -> -1.00
+	RoundDown RoundingMode = "down"
+	// RoundUp rounds away from zero: 1.001 -> 1.01, -1.001 -> -1.01
+	RoundUp RoundingMode = "up"
+)
+
+// DefaultRoundingMode is used for currencies without a rounding mode
+const DefaultRoundingMode = RoundHalfEven
+
+// RoundingModes lists every valid RoundingMode
+var RoundingModes = []RoundingMode{RoundHalfUp, RoundHalfEven, RoundDown, RoundUp}
+
+// IsValid reports whether m is one of the defined rounding modes
+func (m RoundingMode) IsValid() bool {
+	switch m {
+	case RoundHalfUp, RoundHalfEven, RoundDown, RoundUp:
+		return true
+	}
+	return false
+}
+
+// Round rounds amount to the decimal places the currency's factor allows
+// using mode. An empty mode means DefaultRoundingMode.
+func Round(amount decimal.Decimal, factor int, mode RoundingMode) decimal.Decimal {
+	places := DecimalPlaces(factor)
+	switch mode {
+	case RoundHalfUp:
+		return amount.Round(places)
+	case RoundDown:
+		return amount.RoundDown(places)
+	case RoundUp:
+		return amount.RoundUp(places)
+	default:
+		return amount.RoundBank(places)
+	}
+}
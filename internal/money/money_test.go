@@ -0,0 +1,148 @@
+package money
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecimalPlaces(t *testing.T) {
+	for factor, want := range map[int]int32{0: 0, 1: 0, 5: 0, 10: 1, 100: 2, 1000: 3, 10000: 4} {
+		assert.Equal(t, want, DecimalPlaces(factor), "factor %d", factor)
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		amount string
+		factor int
+		mode   RoundingMode
+		want   string
+	}{
+		// Half up rounds ties away from zero
+		{"1.005", 100, RoundHalfUp, "1.01"},
+		{"1.015", 100, RoundHalfUp, "1.02"},
+		{"1.0049", 100, RoundHalfUp, "1"},
+		{"-1.005", 100, RoundHalfUp, "-1.01"},
+		{"2.5", 1, RoundHalfUp, "3"},
+		{"-2.5", 1, RoundHalfUp, "-3"},
+		{"1.0005", 1000, RoundHalfUp, "1.001"},
+
+		// Half even rounds ties to the even digit
+		{"1.005", 100, RoundHalfEven, "1"},
+		{"1.015", 100, RoundHalfEven, "1.02"},
+		{"1.025", 100, RoundHalfEven, "1.02"},
+		{"1.0051", 100, RoundHalfEven, "1.01"},
+		{"-1.005", 100, RoundHalfEven, "-1"},
+		{"-1.015", 100, RoundHalfEven, "-1.02"},
+		{"2.5", 1, RoundHalfEven, "2"},
+		{"3.5", 1, RoundHalfEven, "4"},
+		{"-2.5", 1, RoundHalfEven, "-2"},
+		{"1.0005", 1000, RoundHalfEven, "1"},
+		{"1.0015", 1000, RoundHalfEven, "1.002"},
+
+		// Down truncates toward zero
+		{"1.009", 100, RoundDown, "1"},
+		{"-1.009", 100, RoundDown, "-1"},
+		{"2.9", 1, RoundDown, "2"},
+		{"-2.9", 1, RoundDown, "-2"},
+		{"1.0009", 1000, RoundDown, "1"},
+
+		// Up rounds away from zero
+		{"1.001", 100, RoundUp, "1.01"},
+		{"-1.001", 100, RoundUp, "-1.01"},
+		{"2.1", 1, RoundUp, "3"},
+		{"-2.1", 1, RoundUp, "-3"},
+		{"1.0001", 1000, RoundUp, "1.001"},
+
+		// Amounts that already fit are unchanged by every mode
+		{"1.25", 100, RoundUp, "1.25"},
+		{"-1.25", 100, RoundDown, "-1.25"},
+		{"0", 100, RoundUp, "0"},
+
+		// An empty mode means half even
+		{"1.005", 100, "", "1"},
+		{"1.015", 100, "", "1.02"},
+	}
+
+	for _, tt := range tests {
+		got := Round(decimal.RequireFromString(tt.amount), tt.factor, tt.mode)
+		assert.True(t, decimal.RequireFromString(tt.want).Equal(got), "Round(%s, %d, %q) = %s, want %s", tt.amount, tt.factor, tt.mode, got, tt.want)
+	}
+}
+
+func TestRoundingModesAreValid(t *testing.T) {
+	for _, mode := range RoundingModes {
+		assert.True(t, mode.IsValid(), mode)
+	}
+	assert.Contains(t, RoundingModes, DefaultRoundingMode)
+	assert.False(t, RoundingMode("").IsValid())
+	assert.False(t, RoundingMode("HALF_UP").IsValid())
+}
+
+func TestMinorUnitsToDecimal(t *testing.T) {
+	tests := []struct {
+		amount int64
+		factor int
+		want   string
+	}{
+		{12345, 100, "123.45"},
+		{-12345, 100, "-123.45"},
+		{12345, 1, "12345"},
+		{12345, 1000, "12.345"},
+		{0, 100, "0"},
+		{math.MaxInt64, 100, "92233720368547758.07"},
+	}
+
+	for _, tt := range tests {
+		got := MinorUnitsToDecimal(tt.amount, tt.factor)
+		assert.Equal(t, tt.want, got.String(), "%d with factor %d", tt.amount, tt.factor)
+	}
+}
+
+func TestDecimalToMinorUnits(t *testing.T) {
+	tests := []struct {
+		amount string
+		factor int
+		want   int64
+	}{
+		{"123.45", 100, 12345},
+		{"-123.45", 100, -12345},
+		{"123.4", 100, 12340},
+		{"123", 1, 123},
+		{"12.345", 1000, 12345},
+		{"0", 100, 0},
+		{"92233720368547758.07", 100, math.MaxInt64},
+	}
+
+	for _, tt := range tests {
+		got, err := DecimalToMinorUnits(decimal.RequireFromString(tt.amount), tt.factor)
+		require.NoError(t, err, "%s with factor %d", tt.amount, tt.factor)
+		assert.Equal(t, tt.want, got, "%s with factor %d", tt.amount, tt.factor)
+
+		// The conversion round trips
+		assert.True(t, decimal.RequireFromString(tt.amount).Equal(MinorUnitsToDecimal(got, tt.factor)), tt.amount)
+	}
+}
+
+func TestDecimalToMinorUnitsErrors(t *testing.T) {
+	tests := []struct {
+		amount string
+		factor int
+		want   error
+	}{
+		{"123.456", 100, ErrPrecisionLoss},
+		{"-0.001", 100, ErrPrecisionLoss},
+		{"1.5", 1, ErrPrecisionLoss},
+		{"92233720368547758.08", 100, ErrOutOfRange},
+		{"-92233720368547758.09", 100, ErrOutOfRange},
+	}
+
+	for _, tt := range tests {
+		_, err := DecimalToMinorUnits(decimal.RequireFromString(tt.amount), tt.factor)
+		assert.ErrorIs(t, err, tt.want, "%s with factor %d", tt.amount, tt.factor)
+	}
+}
@@ -0,0 +1,97 @@
+// Package rates fetches exchange rates from external providers
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrProviderUnavailable is returned when a provider can't be reached or
+// answers with an error status
+var ErrProviderUnavailable = errors.New("rate provider unavailable")
+
+// RateProvider fetches the latest exchange rates for a base currency. The
+// returned map holds, per quote currency code, the units of that currency
+// received for one unit of base.
+type RateProvider interface {
+	FetchRates(ctx context.Context, base string) (map[string]decimal.Decimal, error)
+}
+
+// maxResponseSize caps how much of a provider response is read
+const maxResponseSize = 1 << 20
+
+// HTTPProvider fetches rates from a JSON HTTP API answering
+// GET <url>?base=USD with {"base": "USD", "rates": {"EUR": 0.92, ...}}
+type HTTPProvider struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPProvider creates a provider for the API at rawURL. apiKey, if set,
+// is sent as a bearer token. Every request is bounded by timeout.
+func NewHTTPProvider(rawURL, apiKey string, timeout time.Duration) (RateProvider, error) {
+	if _, err := url.ParseRequestURI(rawURL); err != nil {
+		return nil, fmt.Errorf("invalid rate provider URL: %w", err)
+	}
+
+	return &HTTPProvider{
+		url:    rawURL,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// ratesResponse is the JSON body returned by the provider
+type ratesResponse struct {
+	Base  string                     `json:"base"`
+	Rates map[string]decimal.Decimal `json:"rates"`
+}
+
+// FetchRates implements RateProvider
+func (p *HTTPProvider) FetchRates(ctx context.Context, base string) (map[string]decimal.Decimal, error) {
+	endpoint, err := url.Parse(p.url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate provider URL: %w", err)
+	}
+	query := endpoint.Query()
+	query.Set("base", base)
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build rate request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrProviderUnavailable, resp.StatusCode)
+	}
+
+	var body ratesResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode rate response: %w", err)
+	}
+	if body.Base != "" && body.Base != base {
+		return nil, fmt.Errorf("rate provider returned base %s, requested %s", body.Base, base)
+	}
+
+	return body.Rates, nil
+}
@@ -2,8 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/auth"
+	"github.com/Tarifsiz/go-currency-api/internal/database"
 	"github.com/Tarifsiz/go-currency-api/internal/model"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -15,33 +21,258 @@ type CurrencyRepositoryInterface interface {
 	Create(ctx context.Context, currency *model.Currency) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.Currency, error)
 	GetByCode(ctx context.Context, code string) (*model.Currency, error)
-	GetAll(ctx context.Context, limit, offset int) ([]*model.Currency, error)
+	GetByNumericCode(ctx context.Context, numericCode string) (*model.Currency, error)
+	GetAll(ctx context.Context, limit, offset int, sortField, sortOrder string, filter ListFilter) ([]*model.Currency, error)
+	CountFiltered(ctx context.Context, filter ListFilter) (int64, error)
+	CountMatching(ctx context.Context, search SearchOptions, factor int) (int64, error)
+	GetAllAfter(ctx context.Context, cursorCode string, limit int, filter ListFilter) ([]*model.Currency, error)
+	GetChangedSince(ctx context.Context, since time.Time, limit, offset int) ([]*model.Currency, error)
+	CountChangedSince(ctx context.Context, since time.Time) (int64, error)
 	Update(ctx context.Context, currency *model.Currency) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	HardDelete(ctx context.Context, id uuid.UUID) error
+	ListDeleted(ctx context.Context) ([]*model.Currency, error)
+	GetDeletedByCode(ctx context.Context, code string) (*model.Currency, error)
+	Restore(ctx context.Context, id uuid.UUID) error
+	GetChangeLog(ctx context.Context, code string, limit, offset int) ([]*model.ChangeLog, error)
+	CountChangeLog(ctx context.Context, code string) (int64, error)
 	
 	// Business logic operations
 	GetCurrenciesByFactor(ctx context.Context, factor int) ([]*model.Currency, error)
-	SearchByName(ctx context.Context, name string) ([]*model.Currency, error)
+	SearchByName(ctx context.Context, search SearchOptions, limit, offset int) ([]*model.Currency, error)
+	CountByName(ctx context.Context, search SearchOptions) (int64, error)
+	FuzzySearchByName(ctx context.Context, search SearchOptions, threshold float64, limit, offset int) ([]*ScoredCurrency, error)
+	CountFuzzyByName(ctx context.Context, search SearchOptions, threshold float64) (int64, error)
 	GetByCodes(ctx context.Context, codes []string) ([]*model.Currency, error)
+	GetWithoutRates(ctx context.Context, base string) ([]*model.Currency, error)
 	CreateBatch(ctx context.Context, currencies []*model.Currency) error
 	GetCount(ctx context.Context) (int64, error)
+	GetCountsByFactor(ctx context.Context) (map[int]int64, error)
+	UpdateFields(ctx context.Context, update CurrencyFieldUpdate) error
+	UpdateFieldsBatch(ctx context.Context, updates []CurrencyFieldUpdate) error
+}
+
+// CurrencyFieldUpdate is a partial update of the currency identified by Code.
+// Only the columns present in Fields are written, including zero values.
+// When Version is set the update only applies if the currency is still at
+// that version; 0 overwrites unconditionally.
+type CurrencyFieldUpdate struct {
+	Code    string
+	Version int
+	Fields  map[string]interface{}
+}
+
+// BatchError identifies the item that made a batch operation fail
+type BatchError struct {
+	Index int
+	Code  string
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("item %d (%s): %v", e.Index, e.Code, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// sortableFields is the allow-list of columns GetAll may order by. Sort input
+// is never interpolated into SQL unless it appears here.
+var sortableFields = map[string]bool{
+	"code":        true,
+	"description": true,
+	"factor":      true,
+	"created_at":  true,
+	"updated_at":  true,
+}
+
+// SortRecent is a sort shorthand for the most recently modified currencies
+// first, i.e. updated_at DESC
+const SortRecent = "recent"
+
+// NormalizeSort validates a sort field and order, falling back to code ASC
+// for unknown fields. The returned order is either "asc" or "desc".
+func NormalizeSort(sortField, sortOrder string) (string, string) {
+	if sortField == SortRecent {
+		return "updated_at", "desc"
+	}
+	if !sortableFields[sortField] {
+		return "code", "asc"
+	}
+	if strings.EqualFold(sortOrder, "desc") {
+		return sortField, "desc"
+	}
+	return sortField, "asc"
+}
+
+// ListFilter restricts GetAll to currencies created or updated within a time
+// range, valid at ValidOn, and optionally to active currencies. Each bound is
+// optional; "after" bounds are inclusive and "before" bounds exclusive, so
+// adjacent ranges don't overlap.
+type ListFilter struct {
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	ValidOn       *time.Time
+	ActiveOnly    bool
+	// ShowHidden lists soft-launched currencies before their visible_after.
+	// The service resolves it into VisibleAt, which leaves out currencies
+	// whose visible_after is later; a nil VisibleAt leaves none out.
+	ShowHidden bool
+	VisibleAt  *time.Time
+}
+
+// IsZero reports whether the filter has no time bounds set. VisibleAt isn't
+// a bound the caller asked for, so it doesn't count.
+func (f ListFilter) IsZero() bool {
+	return f.CreatedAfter == nil && f.CreatedBefore == nil && f.UpdatedAfter == nil && f.UpdatedBefore == nil && f.ValidOn == nil
+}
+
+// apply adds a WHERE clause for every bound set on the filter
+func (f ListFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *f.CreatedAfter)
+	}
+	if f.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *f.CreatedBefore)
+	}
+	if f.UpdatedAfter != nil {
+		query = query.Where("updated_at >= ?", *f.UpdatedAfter)
+	}
+	if f.UpdatedBefore != nil {
+		query = query.Where("updated_at < ?", *f.UpdatedBefore)
+	}
+	if f.ValidOn != nil {
+		// A NULL bound leaves that side of the validity range open
+		query = query.
+			Where("valid_from IS NULL OR valid_from <= ?", *f.ValidOn).
+			Where("valid_until IS NULL OR valid_until > ?", *f.ValidOn)
+	}
+	return visibleAt(activeOnly(query, f.ActiveOnly), f.VisibleAt)
+}
+
+// activeOnly restricts query to active currencies when only is set. The
+// condition is written as a literal rather than a bind parameter so that
+// Postgres can match it against the idx_currencies_active_code partial index
+// even in a generic plan of a prepared statement.
+func activeOnly(query *gorm.DB, only bool) *gorm.DB {
+	if only {
+		return query.Where("is_active = true")
+	}
+	return query
+}
+
+// visibleAt restricts query to currencies listed at the given time, leaving
+// out those soft-launched with a later visible_after. A nil time doesn't filter.
+func visibleAt(query *gorm.DB, at *time.Time) *gorm.DB {
+	if at != nil {
+		return query.Where("visible_after IS NULL OR visible_after <= ?", *at)
+	}
+	return query
+}
+
+// ErrFuzzySearchUnavailable is returned by the fuzzy search methods when the
+// pg_trgm extension isn't installed
+var ErrFuzzySearchUnavailable = errors.New("fuzzy search unavailable: pg_trgm extension not installed")
+
+// ScoredCurrency is a fuzzy search result with its trigram similarity score
+// between 0 and 1
+type ScoredCurrency struct {
+	model.Currency
+	Score float64 `json:"score"`
+}
+
+// MarshalJSON implements json.Marshaler, keeping the score next to the currency fields
+func (s ScoredCurrency) MarshalJSON() ([]byte, error) {
+	return s.Currency.MarshalJSONWith("score", s.Score)
+}
+
+// Search fields and match modes accepted by SearchOptions
+const (
+	SearchFieldDescription = "description"
+	SearchFieldCode        = "code"
+
+	SearchMatchContains = "contains"
+	SearchMatchPrefix   = "prefix"
+	SearchMatchExact    = "exact"
+)
+
+// SearchOptions describes a case-insensitive currency search. Empty Field and
+// Match default to a substring search on the description.
+type SearchOptions struct {
+	Term       string
+	Field      string
+	Match      string
+	ActiveOnly bool // leave out inactive currencies
+	// ShowHidden and VisibleAt leave out soft-launched currencies as in ListFilter
+	ShowHidden bool
+	VisibleAt  *time.Time
+}
+
+// searchableFields maps SearchOptions.Field to its column; user input is
+// never interpolated into SQL unless it appears here
+var searchableFields = map[string]string{
+	SearchFieldDescription: "description",
+	SearchFieldCode:        "code",
+}
+
+// IsValidSearchField reports whether field is a supported search field
+func IsValidSearchField(field string) bool {
+	_, ok := searchableFields[field]
+	return ok
+}
+
+// IsValidSearchMatch reports whether match is a supported match mode
+func IsValidSearchMatch(match string) bool {
+	switch match {
+	case SearchMatchContains, SearchMatchPrefix, SearchMatchExact:
+		return true
+	}
+	return false
+}
+
+// likePattern escapes LIKE wildcards in term and wraps it for the match mode
+func likePattern(term, match string) string {
+	term = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term)
+	switch match {
+	case SearchMatchExact:
+		return term
+	case SearchMatchPrefix:
+		return term + "%"
+	default:
+		return "%" + term + "%"
+	}
 }
 
 // CurrencyRepository implements the CurrencyRepositoryInterface
 type CurrencyRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	retry database.RetryPolicy
 }
 
 // NewCurrencyRepository creates a new currency repository instance
-func NewCurrencyRepository(db *gorm.DB) CurrencyRepositoryInterface {
+func NewCurrencyRepository(db *gorm.DB, retry database.RetryPolicy) CurrencyRepositoryInterface {
 	return &CurrencyRepository{
-		db: db,
+		db:    db,
+		retry: retry,
 	}
 }
 
 // Create creates a new currency record
 func (r *CurrencyRepository) Create(ctx context.Context, currency *model.Currency) error {
-	if err := r.db.WithContext(ctx).Create(currency).Error; err != nil {
+	err := database.RunInTransaction(ctx, r.db, r.retry, func(tx *gorm.DB) error {
+		if err := tx.Create(currency).Error; err != nil {
+			return err
+		}
+		return logChanges(tx, model.ChangeCreated, gorm.Expr("created_by"), "id = ?", currency.ID)
+	})
+	
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return duplicateCurrencyError(err, currency.Code)
+		}
 		return fmt.Errorf("failed to create currency: %w", err)
 	}
 	return nil
@@ -54,7 +285,7 @@ func (r *CurrencyRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.
 	
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("currency not found with id %s", id.String())
+			return nil, fmt.Errorf("%w with id %s", apperrors.ErrCurrencyNotFound, id.String())
 		}
 		return nil, fmt.Errorf("failed to get currency by id: %w", err)
 	}
@@ -65,11 +296,12 @@ func (r *CurrencyRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.
 // GetByCode retrieves a currency by its code (e.g., "USD", "EUR")
 func (r *CurrencyRepository) GetByCode(ctx context.Context, code string) (*model.Currency, error) {
 	var currency model.Currency
-	err := r.db.WithContext(ctx).First(&currency, "code = ?", code).Error
+	// Matches the case-insensitive unique index on UPPER(code)
+	err := r.db.WithContext(ctx).First(&currency, "UPPER(code) = ?", strings.ToUpper(code)).Error
 	
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("currency not found with code %s", code)
+			return nil, fmt.Errorf("%w with code %s", apperrors.ErrCurrencyNotFound, code)
 		}
 		return nil, fmt.Errorf("failed to get currency by code: %w", err)
 	}
@@ -77,11 +309,34 @@ func (r *CurrencyRepository) GetByCode(ctx context.Context, code string) (*model
 	return &currency, nil
 }
 
-// GetAll retrieves all currencies with pagination
-func (r *CurrencyRepository) GetAll(ctx context.Context, limit, offset int) ([]*model.Currency, error) {
+// GetByNumericCode retrieves a currency by its ISO 4217 numeric code (e.g., "840")
+func (r *CurrencyRepository) GetByNumericCode(ctx context.Context, numericCode string) (*model.Currency, error) {
+	var currency model.Currency
+	err := r.db.WithContext(ctx).First(&currency, "numeric_code = ?", numericCode).Error
+	
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("%w with numeric code %s", apperrors.ErrCurrencyNotFound, numericCode)
+		}
+		return nil, fmt.Errorf("failed to get currency by numeric code: %w", err)
+	}
+	
+	return &currency, nil
+}
+
+// GetAll retrieves all currencies matching filter with pagination, ordered by
+// sortField ("asc" or "desc") and then by code. Unknown sort fields fall back
+// to code ASC; SortRecent orders by updated_at DESC.
+func (r *CurrencyRepository) GetAll(ctx context.Context, limit, offset int, sortField, sortOrder string, filter ListFilter) ([]*model.Currency, error) {
 	var currencies []*model.Currency
 	
-	query := r.db.WithContext(ctx).Order("code ASC")
+	sortField, sortOrder = NormalizeSort(sortField, sortOrder)
+	query := filter.apply(r.db.WithContext(ctx)).Order(sortField + " " + strings.ToUpper(sortOrder))
+	if sortField != "code" {
+		// Live codes are unique, so ties on the sort field always break the
+		// same way and rows don't move between pages
+		query = query.Order("code ASC")
+	}
 	
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -98,35 +353,285 @@ func (r *CurrencyRepository) GetAll(ctx context.Context, limit, offset int) ([]*
 	return currencies, nil
 }
 
-// Update updates an existing currency record
+// GetAllAfter retrieves up to limit currencies matching filter whose code
+// sorts after cursorCode, ordered by code. An empty cursorCode starts from
+// the first currency. Unlike offset pagination this stays fast on deep pages and
+// doesn't skip or repeat rows when currencies are added or removed.
+func (r *CurrencyRepository) GetAllAfter(ctx context.Context, cursorCode string, limit int, filter ListFilter) ([]*model.Currency, error) {
+	var currencies []*model.Currency
+	err := filter.apply(r.db.WithContext(ctx)).
+		Where("code > ?", cursorCode).
+		Order("code ASC").
+		Limit(limit).
+		Find(&currencies).Error
+	
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currencies after cursor: %w", err)
+	}
+	
+	return currencies, nil
+}
+
+// GetChangedSince retrieves currencies created or updated after since,
+// oldest change first
+func (r *CurrencyRepository) GetChangedSince(ctx context.Context, since time.Time, limit, offset int) ([]*model.Currency, error) {
+	var currencies []*model.Currency
+	
+	query := r.changedSince(ctx, since).Order("updated_at ASC, code ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	
+	if err := query.Find(&currencies).Error; err != nil {
+		return nil, fmt.Errorf("failed to get changed currencies: %w", err)
+	}
+	
+	return currencies, nil
+}
+
+// CountChangedSince returns the total number of currencies matching GetChangedSince
+func (r *CurrencyRepository) CountChangedSince(ctx context.Context, since time.Time) (int64, error) {
+	var count int64
+	if err := r.changedSince(ctx, since).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count changed currencies: %w", err)
+	}
+	
+	return count, nil
+}
+
+func (r *CurrencyRepository) changedSince(ctx context.Context, since time.Time) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&model.Currency{}).
+		Where("updated_at > ?", since)
+}
+
+// replacedColumns are the columns Update writes. Empty values are written
+// too, so a full update can clear optional fields. The code, activity and
+// creation columns have their own operations.
+var replacedColumns = []string{
+	"numeric_code", "description", "amount_display_format", "html_encoded_symbol",
+	"factor", "rounding_mode", "valid_from", "valid_until", "visible_after", "updated_by", "version",
+}
+
+// Update replaces the editable fields of an existing currency record if it
+// is still at currency.Version, and increments the version. A stale version
+// returns ErrStaleUpdate and leaves the record unchanged.
 func (r *CurrencyRepository) Update(ctx context.Context, currency *model.Currency) error {
-	err := r.db.WithContext(ctx).
-		Model(currency).
-		Where("id = ?", currency.ID).
-		Updates(currency).Error
+	expected := currency.Version
+	currency.Version = expected + 1
+	
+	err := database.RunInTransaction(ctx, r.db, r.retry, func(tx *gorm.DB) error {
+		result := tx.
+			Model(currency).
+			Where("id = ? AND version = ?", currency.ID, expected).
+			Select(replacedColumns).
+			Updates(currency)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNoRowsUpdated
+		}
+		return logChanges(tx, model.ChangeUpdated, gorm.Expr("updated_by"), "id = ?", currency.ID)
+	})
 	
 	if err != nil {
+		currency.Version = expected
+		if errors.Is(err, errNoRowsUpdated) {
+			return noRowsUpdatedError(r.db.WithContext(ctx).Where("id = ?", currency.ID), currency.Code, expected)
+		}
+		if database.IsUniqueViolation(err) {
+			return duplicateCurrencyError(err, currency.Code)
+		}
 		return fmt.Errorf("failed to update currency: %w", err)
 	}
 	
 	return nil
 }
 
-// Delete deletes a currency record
+// Delete soft-deletes a currency record by setting its DeletedAt
 func (r *CurrencyRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	result := r.db.WithContext(ctx).Delete(&model.Currency{}, "id = ?", id)
+	err := database.RunInTransaction(ctx, r.db, r.retry, func(tx *gorm.DB) error {
+		result := tx.Delete(&model.Currency{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNoRowsUpdated
+		}
+		return logChanges(tx, model.ChangeDeleted, actingUser(ctx), "id = ?", id)
+	})
 	
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete currency: %w", result.Error)
+	if errors.Is(err, errNoRowsUpdated) {
+		return fmt.Errorf("%w with id %s", apperrors.ErrCurrencyNotFound, id.String())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete currency: %w", err)
 	}
 	
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("currency not found with id %s", id.String())
+	return nil
+}
+
+// HardDelete permanently removes a currency record, including soft-deleted
+// ones. Its change log is kept.
+func (r *CurrencyRepository) HardDelete(ctx context.Context, id uuid.UUID) error {
+	err := database.RunInTransaction(ctx, r.db, r.retry, func(tx *gorm.DB) error {
+		// Logged first, while the currency is still there to log
+		if err := logChanges(tx, model.ChangePurged, actingUser(ctx), "id = ?", id); err != nil {
+			return err
+		}
+		result := tx.Unscoped().Delete(&model.Currency{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNoRowsUpdated
+		}
+		return nil
+	})
+	
+	if errors.Is(err, errNoRowsUpdated) {
+		return fmt.Errorf("%w with id %s", apperrors.ErrCurrencyNotFound, id.String())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to hard delete currency: %w", err)
+	}
+	
+	return nil
+}
+
+// ListDeleted retrieves all soft-deleted currencies, most recently deleted first
+func (r *CurrencyRepository) ListDeleted(ctx context.Context) ([]*model.Currency, error) {
+	var currencies []*model.Currency
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&currencies).Error
+	
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted currencies: %w", err)
+	}
+	
+	return currencies, nil
+}
+
+// GetDeletedByCode retrieves the most recently soft-deleted currency with the given code
+func (r *CurrencyRepository) GetDeletedByCode(ctx context.Context, code string) (*model.Currency, error) {
+	var currency model.Currency
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Where("UPPER(code) = ? AND deleted_at IS NOT NULL", strings.ToUpper(code)).
+		Order("deleted_at DESC").
+		First(&currency).Error
+	
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("%w: no deleted currency with code %s", apperrors.ErrCurrencyNotFound, code)
+		}
+		return nil, fmt.Errorf("failed to get deleted currency by code: %w", err)
+	}
+	
+	return &currency, nil
+}
+
+// Restore clears the DeletedAt of a soft-deleted currency
+func (r *CurrencyRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	err := database.RunInTransaction(ctx, r.db, r.retry, func(tx *gorm.DB) error {
+		result := tx.
+			Unscoped().
+			Model(&model.Currency{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNoRowsUpdated
+		}
+		return logChanges(tx, model.ChangeRestored, actingUser(ctx), "id = ?", id)
+	})
+	
+	if errors.Is(err, errNoRowsUpdated) {
+		return fmt.Errorf("%w: no deleted currency with id %s", apperrors.ErrCurrencyNotFound, id.String())
+	}
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return duplicateCurrencyError(err, id.String())
+		}
+		return fmt.Errorf("failed to restore currency: %w", err)
 	}
 	
 	return nil
 }
 
+// GetChangeLog retrieves one page of the change log of every currency that
+// had the given code, in the order the changes were made
+func (r *CurrencyRepository) GetChangeLog(ctx context.Context, code string, limit, offset int) ([]*model.ChangeLog, error) {
+	var changes []*model.ChangeLog
+	
+	query := r.changeLog(ctx, code).Order("id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	
+	if err := query.Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get currency change log: %w", err)
+	}
+	
+	return changes, nil
+}
+
+// CountChangeLog returns the number of change log entries for the given code
+func (r *CurrencyRepository) CountChangeLog(ctx context.Context, code string) (int64, error) {
+	var count int64
+	if err := r.changeLog(ctx, code).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count currency change log: %w", err)
+	}
+	return count, nil
+}
+
+func (r *CurrencyRepository) changeLog(ctx context.Context, code string) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&model.ChangeLog{}).
+		Where("UPPER(currency_code) = ?", strings.ToUpper(code))
+}
+
+// errNoRowsUpdated rolls back a transaction whose write matched no rows, so
+// the caller can explain why outside of it
+var errNoRowsUpdated = errors.New("no rows updated")
+
+// logChanges adds an entry to the change log for every currency matching
+// where, attributed to actor: the acting user's ID, nil if unknown, or an
+// expression naming the column that holds it
+func logChanges(tx *gorm.DB, action string, actor interface{}, where string, args ...interface{}) error {
+	changed := tx.
+		Unscoped().
+		Model(&model.Currency{}).
+		Select("id, code, ?, version, CAST(? AS uuid), NOW()", action, actor).
+		Where(where, args...)
+	
+	err := tx.Exec("INSERT INTO currency_change_log (currency_id, currency_code, action, version, changed_by, changed_at) ?", changed).Error
+	if err != nil {
+		return fmt.Errorf("failed to log currency change: %w", err)
+	}
+	return nil
+}
+
+// actingUser returns the ID of the authenticated user in ctx, or nil
+func actingUser(ctx context.Context) *uuid.UUID {
+	if userID, ok := auth.UserIDFromContext(ctx); ok {
+		return &userID
+	}
+	return nil
+}
+
 // GetCurrenciesByFactor retrieves currencies with a specific decimal factor
 func (r *CurrencyRepository) GetCurrenciesByFactor(ctx context.Context, factor int) ([]*model.Currency, error) {
 	var currencies []*model.Currency
@@ -142,30 +647,109 @@ func (r *CurrencyRepository) GetCurrenciesByFactor(ctx context.Context, factor i
 	return currencies, nil
 }
 
-// SearchByName searches currencies by description/name
-func (r *CurrencyRepository) SearchByName(ctx context.Context, name string) ([]*model.Currency, error) {
+// SearchByName searches currencies by description or code with pagination
+func (r *CurrencyRepository) SearchByName(ctx context.Context, search SearchOptions, limit, offset int) ([]*model.Currency, error) {
 	var currencies []*model.Currency
-	err := r.db.WithContext(ctx).
-		Where("description ILIKE ?", "%"+name+"%").
-		Order("code ASC").
-		Find(&currencies).Error
 	
-	if err != nil {
+	query := r.searchByName(ctx, search).Order("code ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	
+	if err := query.Find(&currencies).Error; err != nil {
 		return nil, fmt.Errorf("failed to search currencies by name: %w", err)
 	}
 	
 	return currencies, nil
 }
 
-// GetByCodes retrieves multiple currencies by their codes
+// CountByName returns the total number of currencies matching SearchByName
+func (r *CurrencyRepository) CountByName(ctx context.Context, search SearchOptions) (int64, error) {
+	var count int64
+	if err := r.searchByName(ctx, search).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count currencies by name: %w", err)
+	}
+	
+	return count, nil
+}
+
+func (r *CurrencyRepository) searchByName(ctx context.Context, search SearchOptions) *gorm.DB {
+	column, ok := searchableFields[search.Field]
+	if !ok {
+		column = searchableFields[SearchFieldDescription]
+	}
+	
+	query := r.db.WithContext(ctx).
+		Model(&model.Currency{}).
+		Where(column+" ILIKE ?", likePattern(search.Term, search.Match))
+	return visibleAt(activeOnly(query, search.ActiveOnly), search.VisibleAt)
+}
+
+// FuzzySearchByName finds currencies whose description has a trigram
+// similarity of at least threshold to search.Term, best matches first.
+// search.Field and search.Match don't apply; with search.ActiveOnly set
+// inactive currencies are left out.
+func (r *CurrencyRepository) FuzzySearchByName(ctx context.Context, search SearchOptions, threshold float64, limit, offset int) ([]*ScoredCurrency, error) {
+	var results []*ScoredCurrency
+	
+	query := r.fuzzySearchByName(ctx, search, threshold).
+		Select("currencies.*, similarity(description, ?) AS score", search.Term).
+		Order("score DESC, code ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	
+	if err := query.Find(&results).Error; err != nil {
+		if database.IsUndefinedFunction(err) {
+			return nil, ErrFuzzySearchUnavailable
+		}
+		return nil, fmt.Errorf("failed to fuzzy search currencies: %w", err)
+	}
+	
+	return results, nil
+}
+
+// CountFuzzyByName returns the total number of currencies matching FuzzySearchByName
+func (r *CurrencyRepository) CountFuzzyByName(ctx context.Context, search SearchOptions, threshold float64) (int64, error) {
+	var count int64
+	if err := r.fuzzySearchByName(ctx, search, threshold).Count(&count).Error; err != nil {
+		if database.IsUndefinedFunction(err) {
+			return 0, ErrFuzzySearchUnavailable
+		}
+		return 0, fmt.Errorf("failed to count fuzzy currency matches: %w", err)
+	}
+	
+	return count, nil
+}
+
+func (r *CurrencyRepository) fuzzySearchByName(ctx context.Context, search SearchOptions, threshold float64) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Model(&model.Currency{}).
+		Where("similarity(description, ?) >= ?", search.Term, threshold)
+	return visibleAt(activeOnly(query, search.ActiveOnly), search.VisibleAt)
+}
+
+// GetByCodes retrieves multiple currencies by their codes, matched
+// case-insensitively like GetByCode
 func (r *CurrencyRepository) GetByCodes(ctx context.Context, codes []string) ([]*model.Currency, error) {
 	if len(codes) == 0 {
 		return []*model.Currency{}, nil
 	}
 	
+	upper := make([]string, len(codes))
+	for i, code := range codes {
+		upper[i] = strings.ToUpper(code)
+	}
+	
 	var currencies []*model.Currency
 	err := r.db.WithContext(ctx).
-		Where("code IN ?", codes).
+		Where("UPPER(code) IN ?", upper).
 		Order("code ASC").
 		Find(&currencies).Error
 	
@@ -176,19 +760,46 @@ func (r *CurrencyRepository) GetByCodes(ctx context.Context, codes []string) ([]
 	return currencies, nil
 }
 
+// GetWithoutRates retrieves the active currencies, other than base itself,
+// with no stored exchange rate against base in either direction, ordered by
+// code. A rate quoted the other way round counts, since conversions invert
+// it. base must be upper case, as rate codes are stored.
+func (r *CurrencyRepository) GetWithoutRates(ctx context.Context, base string) ([]*model.Currency, error) {
+	var currencies []*model.Currency
+	err := activeOnly(r.db.WithContext(ctx), true).
+		Where("UPPER(code) <> ?", base).
+		Where("NOT EXISTS (?)", r.db.Table("exchange_rates").Select("1").
+			Where("exchange_rates.from_code = ? AND exchange_rates.to_code = UPPER(currencies.code)", base)).
+		Where("NOT EXISTS (?)", r.db.Table("exchange_rates").Select("1").
+			Where("exchange_rates.from_code = UPPER(currencies.code) AND exchange_rates.to_code = ?", base)).
+		Order("code ASC").
+		Find(&currencies).Error
+	
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currencies without rates: %w", err)
+	}
+	
+	return currencies, nil
+}
+
 // CreateBatch creates multiple currency records in a single transaction
 func (r *CurrencyRepository) CreateBatch(ctx context.Context, currencies []*model.Currency) error {
 	if len(currencies) == 0 {
 		return nil
 	}
 	
-	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		for _, currency := range currencies {
+	err := database.RunInTransaction(ctx, r.db, r.retry, func(tx *gorm.DB) error {
+		ids := make([]uuid.UUID, len(currencies))
+		for i, currency := range currencies {
 			if err := tx.Create(currency).Error; err != nil {
-				return fmt.Errorf("failed to create currency %s: %w", currency.Code, err)
+				if database.IsUniqueViolation(err) {
+					err = duplicateCurrencyError(err, currency.Code)
+				}
+				return &BatchError{Index: i, Code: currency.Code, Err: err}
 			}
+			ids[i] = currency.ID
 		}
-		return nil
+		return logChanges(tx, model.ChangeCreated, gorm.Expr("created_by"), "id IN ?", ids)
 	})
 	
 	if err != nil {
@@ -206,4 +817,138 @@ func (r *CurrencyRepository) GetCount(ctx context.Context) (int64, error) {
 		return 0, fmt.Errorf("failed to get currency count: %w", err)
 	}
 	return count, nil
-}
\ No newline at end of file
+}
+
+// CountFiltered returns the number of currencies matching filter
+func (r *CurrencyRepository) CountFiltered(ctx context.Context, filter ListFilter) (int64, error) {
+	var count int64
+	err := filter.apply(r.db.WithContext(ctx).Model(&model.Currency{})).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count filtered currencies: %w", err)
+	}
+	return count, nil
+}
+
+// CountMatching returns the number of currencies matching search and having
+// the given factor. An empty search term or a zero factor doesn't filter.
+func (r *CurrencyRepository) CountMatching(ctx context.Context, search SearchOptions, factor int) (int64, error) {
+	query := visibleAt(activeOnly(r.db.WithContext(ctx).Model(&model.Currency{}), search.ActiveOnly), search.VisibleAt)
+	if search.Term != "" {
+		query = r.searchByName(ctx, search)
+	}
+	if factor > 0 {
+		query = query.Where("factor = ?", factor)
+	}
+	
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count matching currencies: %w", err)
+	}
+	return count, nil
+}
+
+// GetCountsByFactor returns the number of currencies for each decimal factor
+func (r *CurrencyRepository) GetCountsByFactor(ctx context.Context) (map[int]int64, error) {
+	var rows []struct {
+		Factor int
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&model.Currency{}).
+		Select("factor, COUNT(*) AS count").
+		Group("factor").
+		Scan(&rows).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currency counts by factor: %w", err)
+	}
+
+	counts := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Factor] = row.Count
+	}
+
+	return counts, nil
+}
+
+// UpdateFields applies a partial update to a single currency
+func (r *CurrencyRepository) UpdateFields(ctx context.Context, update CurrencyFieldUpdate) error {
+	return database.RunInTransaction(ctx, r.db, r.retry, func(tx *gorm.DB) error {
+		return updateFields(tx, update)
+	})
+}
+
+// UpdateFieldsBatch applies several partial updates in a single transaction.
+// When one fails, the returned error wraps a BatchError identifying it.
+func (r *CurrencyRepository) UpdateFieldsBatch(ctx context.Context, updates []CurrencyFieldUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	err := database.RunInTransaction(ctx, r.db, r.retry, func(tx *gorm.DB) error {
+		for i, update := range updates {
+			if err := updateFields(tx, update); err != nil {
+				return &BatchError{Index: i, Code: update.Code, Err: err}
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to update currencies in batch: %w", err)
+	}
+
+	return nil
+}
+
+func updateFields(db *gorm.DB, update CurrencyFieldUpdate) error {
+	fields := make(map[string]interface{}, len(update.Fields)+1)
+	for column, value := range update.Fields {
+		fields[column] = value
+	}
+	fields["version"] = gorm.Expr("version + 1")
+
+	query := db.Model(&model.Currency{}).Where("UPPER(code) = ?", strings.ToUpper(update.Code))
+	if update.Version > 0 {
+		query = query.Where("version = ?", update.Version)
+	}
+	result := query.Updates(fields)
+
+	if result.Error != nil {
+		if database.IsUniqueViolation(result.Error) {
+			return duplicateCurrencyError(result.Error, update.Code)
+		}
+		return fmt.Errorf("failed to update currency %s: %w", update.Code, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return noRowsUpdatedError(db.Where("UPPER(code) = ?", strings.ToUpper(update.Code)), update.Code, update.Version)
+	}
+
+	return logChanges(db, model.ChangeUpdated, gorm.Expr("updated_by"), "UPPER(code) = ? AND deleted_at IS NULL", strings.ToUpper(update.Code))
+}
+
+// noRowsUpdatedError explains why a conditional update matched no rows:
+// ErrCurrencyNotFound if scope matches no live currency, ErrStaleUpdate if
+// it does but the currency has moved past the expected version
+func noRowsUpdatedError(scope *gorm.DB, code string, expected int) error {
+	var count int64
+	if err := scope.Model(&model.Currency{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check currency %s after update: %w", code, err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%w with code %s", apperrors.ErrCurrencyNotFound, code)
+	}
+	return fmt.Errorf("%w: currency %s is no longer at version %d", apperrors.ErrStaleUpdate, code, expected)
+}
+
+// duplicateCurrencyError wraps a unique violation raised while writing the
+// currency identified by ref as ErrDuplicateCurrency, naming the conflicting
+// field. Only live currencies take part in the unique indexes.
+func duplicateCurrencyError(err error, ref string) error {
+	constraint, _ := database.UniqueViolation(err)
+	if strings.Contains(constraint, "numeric_code") {
+		return fmt.Errorf("%w: numeric code of %s is already used by another currency", apperrors.ErrDuplicateCurrency, ref)
+	}
+	return fmt.Errorf("%w: %s", apperrors.ErrDuplicateCurrency, ref)
+}
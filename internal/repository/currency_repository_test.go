@@ -0,0 +1,769 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/auth"
+	"github.com/Tarifsiz/go-currency-api/internal/database"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/migrations"
+)
+
+// dryRunConnector opens connections that can only begin and end
+// transactions, which is all a dry-run database sends them
+type dryRunConnector struct{}
+
+func (dryRunConnector) Connect(context.Context) (driver.Conn, error) { return dryRunConn{}, nil }
+func (dryRunConnector) Driver() driver.Driver                        { return nil }
+
+type dryRunConn struct{}
+
+func (dryRunConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("dry run") }
+func (dryRunConn) Close() error                        { return nil }
+func (dryRunConn) Begin() (driver.Tx, error)           { return dryRunConn{}, nil }
+func (dryRunConn) Commit() error                       { return nil }
+func (dryRunConn) Rollback() error                     { return nil }
+
+// newDryRunRepository returns a repository on a database that only builds
+// SQL, along with the statements it has built so far
+func newDryRunRepository(t *testing.T) (CurrencyRepositoryInterface, *[]string) {
+	t.Helper()
+	db, statements := newDryRunDB(t)
+	return NewCurrencyRepository(db, database.RetryPolicy{}), statements
+}
+
+// newDryRunDB opens a dry-run database that records the SQL of every
+// statement instead of running it
+func newDryRunDB(t *testing.T) (*gorm.DB, *[]string) {
+	t.Helper()
+	conn := sql.OpenDB(dryRunConnector{})
+	t.Cleanup(func() { conn.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+
+	var statements []string
+	capture := func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	}
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture_query", capture))
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:capture_create", capture))
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:capture_update", capture))
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:capture_delete", capture))
+	require.NoError(t, db.Callback().Raw().After("gorm:raw").Register("test:capture_raw", capture))
+	require.NoError(t, db.Callback().Row().After("gorm:row").Register("test:capture_row", capture))
+
+	return db, &statements
+}
+
+func TestCodeLookupsIgnoreCase(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		run  func(repo CurrencyRepositoryInterface)
+	}{
+		{"GetByCode", func(repo CurrencyRepositoryInterface) { _, _ = repo.GetByCode(ctx, "usd") }},
+		{"GetByCodes", func(repo CurrencyRepositoryInterface) { _, _ = repo.GetByCodes(ctx, []string{"usd", "Eur"}) }},
+		{"GetDeletedByCode", func(repo CurrencyRepositoryInterface) { _, _ = repo.GetDeletedByCode(ctx, "usd") }},
+		{"UpdateFields", func(repo CurrencyRepositoryInterface) {
+			_ = repo.UpdateFields(ctx, CurrencyFieldUpdate{Code: "usd", Fields: map[string]interface{}{"description": "x"}})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, statements := newDryRunRepository(t)
+			tt.run(repo)
+
+			require.NotEmpty(t, *statements)
+			for _, sql := range *statements {
+				assert.Contains(t, sql, "UPPER(code)")
+				assert.NotContains(t, sql, "WHERE code")
+			}
+		})
+	}
+}
+
+func TestUpdateWritesEmptyFields(t *testing.T) {
+	repo, statements := newDryRunRepository(t)
+
+	_ = repo.Update(context.Background(), &model.Currency{ID: uuid.New(), Code: "USD", Description: "US Dollar", Factor: 100, Version: 1})
+
+	require.NotEmpty(t, *statements)
+	sql := (*statements)[0]
+	require.True(t, strings.HasPrefix(sql, "UPDATE"), sql)
+	// Cleared optional fields are written rather than skipped as zero values
+	for _, column := range []string{"numeric_code", "html_encoded_symbol", "valid_from", "valid_until", "updated_at"} {
+		assert.Contains(t, sql, `"`+column+`"=`, column)
+	}
+	for _, column := range []string{"code", "is_active", "created_at", "created_by"} {
+		assert.NotContains(t, sql, `"`+column+`"=`, column)
+	}
+}
+
+func TestUpdatePersistsUpdatedBy(t *testing.T) {
+	repo, statements := newDryRunRepository(t)
+
+	editor := uuid.New()
+	_ = repo.Update(context.Background(), &model.Currency{ID: uuid.New(), Code: "USD", Description: "US Dollar", Factor: 100, CreatedBy: uuid.New(), UpdatedBy: &editor, Version: 1})
+
+	require.NotEmpty(t, *statements)
+	sql := (*statements)[0]
+	assert.Contains(t, sql, `"updated_by"=`)
+	assert.NotContains(t, sql, `"created_by"=`)
+}
+
+// openTestDatabase connects to the database in TEST_DATABASE_URL and applies
+// the migrations, skipping the test when it isn't set. Every test runs in a
+// transaction that is rolled back afterwards.
+func openTestDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, database.RunMigrations(db, migrations.FS, &model.Currency{}))
+
+	tx := db.Begin()
+	require.NoError(t, tx.Error)
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+func TestCodesAreCaseInsensitive(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCurrencyRepository(openTestDatabase(t), database.RetryPolicy{})
+
+	require.NoError(t, repo.Create(ctx, &model.Currency{Code: "usd", Description: "US Dollar", Factor: 100, CreatedBy: uuid.New()}))
+
+	found, err := repo.GetByCode(ctx, "USD")
+	require.NoError(t, err)
+	assert.Equal(t, "usd", found.Code)
+
+	currencies, err := repo.GetByCodes(ctx, []string{"USD"})
+	require.NoError(t, err)
+	require.Len(t, currencies, 1)
+
+	require.NoError(t, repo.UpdateFields(ctx, CurrencyFieldUpdate{
+		Code:    "USD",
+		Version: found.Version,
+		Fields:  map[string]interface{}{"description": "United States Dollar"},
+	}))
+
+	// Last, since the failed insert aborts the transaction
+	err = repo.Create(ctx, &model.Currency{Code: "USD", Description: "US Dollar", Factor: 100, CreatedBy: uuid.New()})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, apperrors.ErrDuplicateCurrency), "got %v", err)
+	assert.True(t, strings.Contains(err.Error(), "USD"))
+}
+
+// failCreatesWith makes every insert into db after the first of each code
+// fail with the error violation returns for it, as a unique index would
+func failCreatesWith(t *testing.T, db *gorm.DB, violation func(code string) error) {
+	t.Helper()
+	seen := map[string]bool{}
+	require.NoError(t, db.Callback().Create().Before("gorm:create").Register("test:unique", func(tx *gorm.DB) {
+		currency, ok := tx.Statement.Dest.(*model.Currency)
+		if !ok {
+			return
+		}
+		code := strings.ToUpper(currency.Code)
+		if seen[code] {
+			tx.AddError(violation(code))
+		}
+		seen[code] = true
+	}))
+}
+
+func TestCreateReportsUniqueViolationAsDuplicate(t *testing.T) {
+	ctx := context.Background()
+	db, _ := newDryRunDB(t)
+	failCreatesWith(t, db, func(string) error {
+		return &pgconn.PgError{Code: "23505", ConstraintName: "idx_currencies_code_active"}
+	})
+	repo := NewCurrencyRepository(db, database.RetryPolicy{})
+
+	require.NoError(t, repo.Create(ctx, &model.Currency{Code: "USD", Description: "US Dollar", Factor: 100}))
+	err := repo.Create(ctx, &model.Currency{Code: "usd", Description: "US Dollar", Factor: 100})
+	require.ErrorIs(t, err, apperrors.ErrDuplicateCurrency)
+	assert.Equal(t, "currency already exists: usd", err.Error())
+}
+
+func TestCreateNamesTheConflictingNumericCode(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	failCreatesWith(t, db, func(string) error {
+		return &pgconn.PgError{Code: "23505", ConstraintName: "idx_currencies_numeric_code"}
+	})
+	repo := NewCurrencyRepository(db, database.RetryPolicy{})
+
+	require.NoError(t, repo.Create(context.Background(), &model.Currency{Code: "USD", NumericCode: "840"}))
+	err := repo.Create(context.Background(), &model.Currency{Code: "USD", NumericCode: "840"})
+	require.ErrorIs(t, err, apperrors.ErrDuplicateCurrency)
+	assert.Contains(t, err.Error(), "numeric code of USD is already used")
+}
+
+func TestCreateOnlyTreatsUniqueViolationsAsDuplicates(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	// Text that merely mentions duplicates doesn't make a conflict
+	failCreatesWith(t, db, func(code string) error {
+		return &pgconn.PgError{Code: "23514", Message: "duplicate " + code + " violates check constraint"}
+	})
+	repo := NewCurrencyRepository(db, database.RetryPolicy{})
+
+	require.NoError(t, repo.Create(context.Background(), &model.Currency{Code: "USD"}))
+	err := repo.Create(context.Background(), &model.Currency{Code: "USD"})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, apperrors.ErrDuplicateCurrency)
+	assert.True(t, strings.HasPrefix(err.Error(), "failed to create currency: "), err.Error())
+}
+
+func TestGetWithoutRatesExcludesEitherDirection(t *testing.T) {
+	repo, statements := newDryRunRepository(t)
+
+	_, _ = repo.GetWithoutRates(context.Background(), "USD")
+
+	// Subqueries are built, and captured, before the query using them
+	require.NotEmpty(t, *statements)
+	sql := (*statements)[len(*statements)-1]
+	require.True(t, strings.HasPrefix(sql, `SELECT * FROM "currencies"`), sql)
+	assert.Equal(t, 2, strings.Count(sql, "NOT EXISTS"), sql)
+	assert.Contains(t, sql, "exchange_rates.from_code = $")
+	assert.Contains(t, sql, "exchange_rates.from_code = UPPER(currencies.code)")
+	assert.Contains(t, sql, "is_active")
+	assert.Contains(t, sql, `"currencies"."deleted_at" IS NULL`)
+}
+
+func TestGetWithoutRatesWithPartialCoverage(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDatabase(t)
+	repo := NewCurrencyRepository(db, database.RetryPolicy{})
+
+	for _, code := range []string{"AAA", "BBB", "CCC", "DDD", "EEE"} {
+		require.NoError(t, repo.Create(ctx, &model.Currency{Code: code, Description: code, Factor: 100, IsActive: true, CreatedBy: uuid.New()}))
+	}
+	require.NoError(t, repo.Create(ctx, &model.Currency{Code: "FFF", Description: "Inactive", Factor: 100, CreatedBy: uuid.New()}))
+	require.NoError(t, db.Model(&model.Currency{}).Where("code = ?", "FFF").Update("is_active", false).Error)
+
+	rates := NewExchangeRateRepository(db)
+	now := time.Now().UTC()
+	require.NoError(t, rates.CreateBatch(ctx, []*model.ExchangeRate{
+		// BBB is quoted against AAA, CCC the other way round
+		{FromCode: "AAA", ToCode: "BBB", Rate: decimal.NewFromInt(2), Timestamp: now},
+		{FromCode: "CCC", ToCode: "AAA", Rate: decimal.NewFromInt(3), Timestamp: now},
+		// Rates between other currencies don't count
+		{FromCode: "DDD", ToCode: "EEE", Rate: decimal.NewFromInt(4), Timestamp: now},
+	}))
+
+	currencies, err := repo.GetWithoutRates(ctx, "AAA")
+	require.NoError(t, err)
+	var codes []string
+	for _, currency := range currencies {
+		codes = append(codes, currency.Code)
+	}
+	// Leaving out the base itself and the inactive FFF, along with the
+	// seeded currencies
+	assert.Contains(t, codes, "DDD")
+	assert.Contains(t, codes, "EEE")
+	for _, code := range []string{"AAA", "BBB", "CCC", "FFF"} {
+		assert.NotContains(t, codes, code)
+	}
+}
+
+func TestWritesAreLoggedWithTheActingUser(t *testing.T) {
+	ctx := auth.WithUserID(context.Background(), uuid.New())
+
+	repo, statements := newDryRunRepository(t)
+	require.NoError(t, repo.Create(ctx, &model.Currency{Code: "USD", Description: "US Dollar", CreatedBy: uuid.New()}))
+	// Creations are attributed to their creator
+	assert.Contains(t, strings.Join(*statements, "\n"), "INSERT INTO currency_change_log (currency_id, currency_code, action, version, changed_by, changed_at) SELECT id, code, $1, version, CAST(created_by AS uuid), NOW()")
+
+	repo, statements = newDryRunRepository(t)
+	_ = repo.HardDelete(ctx, uuid.New())
+	// Deletions to the authenticated user, logged while the currency exists
+	require.NotEmpty(t, *statements)
+	last := (*statements)[len(*statements)-1]
+	assert.True(t, strings.HasPrefix(last, `DELETE FROM "currencies"`), last)
+	assert.Contains(t, strings.Join(*statements, "\n"), "INSERT INTO currency_change_log (currency_id, currency_code, action, version, changed_by, changed_at) SELECT id, code, $1, version, CAST($2 AS uuid), NOW()")
+}
+
+func TestChangeLogInChronologicalOrder(t *testing.T) {
+	db := openTestDatabase(t)
+	repo := NewCurrencyRepository(db, database.RetryPolicy{})
+	creator, editor, deleter := uuid.New(), uuid.New(), uuid.New()
+
+	currency := &model.Currency{Code: "QQQ", Description: "Test currency", Factor: 100, CreatedBy: creator}
+	require.NoError(t, repo.Create(context.Background(), currency))
+	require.NoError(t, repo.UpdateFields(auth.WithUserID(context.Background(), editor), CurrencyFieldUpdate{
+		Code:   "qqq",
+		Fields: map[string]interface{}{"description": "Renamed", "updated_by": editor},
+	}))
+	deleted := auth.WithUserID(context.Background(), deleter)
+	require.NoError(t, repo.Delete(deleted, currency.ID))
+	require.NoError(t, repo.Restore(deleted, currency.ID))
+	require.NoError(t, repo.HardDelete(deleted, currency.ID))
+
+	changes, err := repo.GetChangeLog(context.Background(), "qqq", 0, 0)
+	require.NoError(t, err)
+	type entry struct {
+		action  string
+		version int
+		by      uuid.UUID
+	}
+	var got []entry
+	for i, change := range changes {
+		require.NotNil(t, change.ChangedBy, change.Action)
+		got = append(got, entry{change.Action, change.Version, *change.ChangedBy})
+		assert.Equal(t, currency.ID, change.CurrencyID)
+		if i > 0 {
+			assert.False(t, change.ChangedAt.Before(changes[i-1].ChangedAt))
+		}
+	}
+	assert.Equal(t, []entry{
+		{model.ChangeCreated, 1, creator},
+		{model.ChangeUpdated, 2, editor},
+		{model.ChangeDeleted, 2, deleter},
+		{model.ChangeRestored, 2, deleter},
+		{model.ChangePurged, 2, deleter},
+	}, got)
+
+	// The trail outlives the currency
+	count, err := repo.CountChangeLog(context.Background(), "QQQ")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+}
+
+func TestListingsLeaveOutCurrenciesNotVisibleYet(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		run  func(repo CurrencyRepositoryInterface, at *time.Time)
+	}{
+		{"GetAll", func(repo CurrencyRepositoryInterface, at *time.Time) {
+			_, _ = repo.GetAll(ctx, 10, 0, "code", "asc", ListFilter{VisibleAt: at})
+		}},
+		{"CountFiltered", func(repo CurrencyRepositoryInterface, at *time.Time) {
+			_, _ = repo.CountFiltered(ctx, ListFilter{VisibleAt: at})
+		}},
+		{"SearchByName", func(repo CurrencyRepositoryInterface, at *time.Time) {
+			_, _ = repo.SearchByName(ctx, SearchOptions{Term: "dollar", VisibleAt: at}, 10, 0)
+		}},
+		{"CountMatching", func(repo CurrencyRepositoryInterface, at *time.Time) {
+			_, _ = repo.CountMatching(ctx, SearchOptions{VisibleAt: at}, 0)
+		}},
+		{"FuzzySearchByName", func(repo CurrencyRepositoryInterface, at *time.Time) {
+			_, _ = repo.FuzzySearchByName(ctx, SearchOptions{Term: "dollar", VisibleAt: at}, 0.3, 10, 0)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, statements := newDryRunRepository(t)
+			tt.run(repo, &at)
+			require.Len(t, *statements, 1)
+			assert.Contains(t, (*statements)[0], "visible_after IS NULL OR visible_after <= $")
+
+			repo, statements = newDryRunRepository(t)
+			tt.run(repo, nil)
+			require.Len(t, *statements, 1)
+			assert.NotContains(t, (*statements)[0], "visible_after")
+		})
+	}
+}
+
+func TestActiveListingsMatchThePartialIndex(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		run  func(repo CurrencyRepositoryInterface)
+	}{
+		{"GetAll", func(repo CurrencyRepositoryInterface) {
+			_, _ = repo.GetAll(ctx, 10, 0, "code", "asc", ListFilter{ActiveOnly: true})
+		}},
+		{"GetAllAfter", func(repo CurrencyRepositoryInterface) {
+			_, _ = repo.GetAllAfter(ctx, "EUR", 10, ListFilter{ActiveOnly: true})
+		}},
+		{"CountFiltered", func(repo CurrencyRepositoryInterface) {
+			_, _ = repo.CountFiltered(ctx, ListFilter{ActiveOnly: true})
+		}},
+		{"SearchByName", func(repo CurrencyRepositoryInterface) {
+			_, _ = repo.SearchByName(ctx, SearchOptions{Term: "dollar", ActiveOnly: true}, 10, 0)
+		}},
+		{"CountMatching", func(repo CurrencyRepositoryInterface) {
+			_, _ = repo.CountMatching(ctx, SearchOptions{ActiveOnly: true}, 0)
+		}},
+		{"FuzzySearchByName", func(repo CurrencyRepositoryInterface) {
+			_, _ = repo.FuzzySearchByName(ctx, SearchOptions{Term: "dollar", ActiveOnly: true}, 0.3, 10, 0)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, statements := newDryRunRepository(t)
+			tt.run(repo)
+			require.Len(t, *statements, 1)
+			// A bind parameter would keep generic plans off the index
+			assert.Contains(t, (*statements)[0], "is_active = true")
+			assert.Contains(t, (*statements)[0], `"currencies"."deleted_at" IS NULL`)
+		})
+	}
+}
+
+func TestCountMatchingCombinesSearchAndFactor(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		search SearchOptions
+		factor int
+		want   string
+	}{
+		{"unfiltered", SearchOptions{}, 0, `SELECT count(*) FROM "currencies" WHERE "currencies"."deleted_at" IS NULL`},
+		{"factor", SearchOptions{}, 100, `SELECT count(*) FROM "currencies" WHERE factor = $1 AND "currencies"."deleted_at" IS NULL`},
+		{"search and factor", SearchOptions{Term: "dollar", Field: SearchFieldDescription, Match: SearchMatchContains}, 100,
+			`SELECT count(*) FROM "currencies" WHERE description ILIKE $1 AND factor = $2 AND "currencies"."deleted_at" IS NULL`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, statements := newDryRunRepository(t)
+			_, err := repo.CountMatching(ctx, tt.search, tt.factor)
+			require.NoError(t, err)
+			require.Len(t, *statements, 1)
+			assert.Equal(t, tt.want, (*statements)[0])
+		})
+	}
+}
+
+func TestDefaultListingUsesActiveIndex(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDatabase(t)
+
+	// Enough obsolete currencies that scanning the whole table costs more
+	// than the partial index
+	require.NoError(t, db.Exec(`
+		INSERT INTO currencies (code, description, factor, is_active, created_by)
+		SELECT chr(65 + i / 676) || chr(65 + i / 26 % 26) || chr(65 + i % 26), 'Obsolete', 100, false, gen_random_uuid()
+		FROM generate_series(0, 17575) AS i
+		ON CONFLICT DO NOTHING`).Error)
+	require.NoError(t, db.Exec("ANALYZE currencies").Error)
+	// Plan as the cached statement of a long-lived connection would
+	require.NoError(t, db.Exec("SET LOCAL plan_cache_mode = force_generic_plan").Error)
+
+	var query string
+	var vars []interface{}
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture_vars", func(tx *gorm.DB) {
+		query, vars = tx.Statement.SQL.String(), tx.Statement.Vars
+	}))
+	repo := NewCurrencyRepository(db.Session(&gorm.Session{DryRun: true}), database.RetryPolicy{})
+	_, err := repo.GetAll(ctx, 20, 0, "code", "asc", ListFilter{ActiveOnly: true})
+	require.NoError(t, err)
+	require.NotEmpty(t, query)
+
+	rows, err := db.Statement.ConnPool.QueryContext(ctx, "EXPLAIN "+query, vars...)
+	require.NoError(t, err)
+	defer rows.Close()
+	var plan []string
+	for rows.Next() {
+		var line string
+		require.NoError(t, rows.Scan(&line))
+		plan = append(plan, line)
+	}
+	require.NoError(t, rows.Err())
+
+	assert.Contains(t, strings.Join(plan, "\n"), "idx_currencies_active_code", strings.Join(plan, "\n"))
+}
+
+func TestNormalizeSort(t *testing.T) {
+	tests := []struct {
+		field, order         string
+		wantField, wantOrder string
+	}{
+		{"factor", "asc", "factor", "asc"},
+		{"factor", "DESC", "factor", "desc"},
+		{"description", "", "description", "asc"},
+		{"updated_at", "sideways", "updated_at", "asc"},
+		// recent is last-modified-first whatever the order says
+		{SortRecent, "", "updated_at", "desc"},
+		{SortRecent, "asc", "updated_at", "desc"},
+		// Anything off the allow-list falls back to code
+		{"is_active", "desc", "code", "asc"},
+		{"code; DROP TABLE currencies", "desc", "code", "asc"},
+		{"", "", "code", "asc"},
+	}
+
+	for _, tt := range tests {
+		field, order := NormalizeSort(tt.field, tt.order)
+		assert.Equal(t, tt.wantField, field, "%q %q", tt.field, tt.order)
+		assert.Equal(t, tt.wantOrder, order, "%q %q", tt.field, tt.order)
+	}
+}
+
+func TestGetAllBreaksTiesOnCode(t *testing.T) {
+	ctx := context.Background()
+
+	for field, want := range map[string]string{
+		"factor":      "ORDER BY factor DESC,code ASC",
+		"description": "ORDER BY description DESC,code ASC",
+		SortRecent:    "ORDER BY updated_at DESC,code ASC",
+		"code":        "ORDER BY code DESC LIMIT",
+	} {
+		repo, statements := newDryRunRepository(t)
+
+		_, _ = repo.GetAll(ctx, 2, 4, field, "desc", ListFilter{})
+
+		require.Len(t, *statements, 1, field)
+		assert.Contains(t, (*statements)[0], want, field)
+	}
+}
+
+func TestGetAllPagesThroughTiedRows(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCurrencyRepository(openTestDatabase(t), database.RetryPolicy{})
+
+	// Every row ties on factor, description and update time
+	at := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	codes := []string{"AUD", "CAD", "CHF", "EUR", "GBP", "JPY", "NZD", "USD"}
+	for _, code := range codes {
+		require.NoError(t, repo.Create(ctx, &model.Currency{
+			Code: code, Description: "Tied", Factor: 100, IsActive: true, CreatedBy: uuid.New(), CreatedAt: at, UpdatedAt: at,
+		}))
+	}
+
+	for _, sortField := range []string{"factor", "description", SortRecent} {
+		for _, order := range []string{"asc", "desc"} {
+			var paged []string
+			for offset := 0; ; offset += 3 {
+				page, err := repo.GetAll(ctx, 3, offset, sortField, order, ListFilter{})
+				require.NoError(t, err)
+				if len(page) == 0 {
+					break
+				}
+				for _, currency := range page {
+					paged = append(paged, currency.Code)
+				}
+			}
+			// Each row shows up exactly once, in code order within the tie
+			assert.Equal(t, codes, paged, "%s %s", sortField, order)
+		}
+	}
+}
+
+func TestListFilterOpenEndedRanges(t *testing.T) {
+	ctx := context.Background()
+	after := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	before := after.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name    string
+		filter  ListFilter
+		present []string
+		absent  []string
+	}{
+		{"no bounds", ListFilter{}, nil, []string{"created_at", "updated_at"}},
+		{"created after", ListFilter{CreatedAfter: &after}, []string{"created_at >= $1"}, []string{"created_at <", "updated_at"}},
+		{"created before", ListFilter{CreatedBefore: &before}, []string{"created_at < $1"}, []string{"created_at >=", "updated_at"}},
+		{"updated after", ListFilter{UpdatedAfter: &after}, []string{"updated_at >= $1"}, []string{"updated_at <", "created_at"}},
+		{"updated before", ListFilter{UpdatedBefore: &before}, []string{"updated_at < $1"}, []string{"updated_at >=", "created_at"}},
+		{"created range", ListFilter{CreatedAfter: &after, CreatedBefore: &before}, []string{"created_at >= $1", "created_at < $2"}, []string{"updated_at"}},
+		// NULL validity bounds leave that side of the range open
+		{"valid on", ListFilter{ValidOn: &after}, []string{"(valid_from IS NULL OR valid_from <= $1) AND (valid_until IS NULL OR valid_until > $2)"}, []string{"created_at", "updated_at"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, statements := newDryRunDB(t)
+			repo := NewCurrencyRepository(db, database.RetryPolicy{})
+
+			_, _ = repo.GetAll(ctx, 10, 20, "description", "desc", tt.filter)
+
+			require.Len(t, *statements, 1)
+			sql := (*statements)[0]
+			for _, want := range tt.present {
+				assert.Contains(t, sql, want)
+			}
+			for _, unwanted := range tt.absent {
+				assert.NotContains(t, sql, unwanted)
+			}
+			// Bounds combine with sorting and pagination
+			assert.Contains(t, sql, "ORDER BY description DESC,code ASC LIMIT")
+			assert.Contains(t, sql, "OFFSET")
+		})
+	}
+}
+
+func TestSearchByNameIsPaginated(t *testing.T) {
+	ctx := context.Background()
+	db, statements := newDryRunDB(t)
+	var vars [][]interface{}
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture_vars", func(tx *gorm.DB) {
+		vars = append(vars, tx.Statement.Vars)
+	}))
+	repo := NewCurrencyRepository(db, database.RetryPolicy{})
+
+	_, _ = repo.SearchByName(ctx, SearchOptions{Term: "dollar"}, 2, 4)
+	_, _ = repo.CountByName(ctx, SearchOptions{Term: "dollar"})
+
+	require.Len(t, *statements, 2)
+	assert.Contains(t, (*statements)[0], "ORDER BY code ASC LIMIT $2 OFFSET $3")
+	assert.Equal(t, []interface{}{"%dollar%", 2, 4}, vars[0])
+	// The total counts every match
+	assert.NotContains(t, (*statements)[1], "LIMIT")
+	assert.NotContains(t, (*statements)[1], "OFFSET")
+}
+
+func TestSearchByNameTruncatesToLimit(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCurrencyRepository(openTestDatabase(t), database.RetryPolicy{})
+
+	for _, code := range []string{"AUD", "CAD", "HKD", "NZD", "USD"} {
+		require.NoError(t, repo.Create(ctx, &model.Currency{Code: code, Description: code + " Dollar", Factor: 100, CreatedBy: uuid.New()}))
+	}
+	require.NoError(t, repo.Create(ctx, &model.Currency{Code: "EUR", Description: "Euro", Factor: 100, CreatedBy: uuid.New()}))
+	search := SearchOptions{Term: "dollar"}
+
+	var codes []string
+	for offset := 0; offset < 6; offset += 2 {
+		page, err := repo.SearchByName(ctx, search, 2, offset)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(page), 2)
+		for _, currency := range page {
+			codes = append(codes, currency.Code)
+		}
+	}
+	assert.Equal(t, []string{"AUD", "CAD", "HKD", "NZD", "USD"}, codes)
+
+	total, err := repo.CountByName(ctx, search)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+}
+
+func TestLikePatternForEachMatchMode(t *testing.T) {
+	tests := []struct {
+		term, match, want string
+	}{
+		{"dollar", SearchMatchContains, "%dollar%"},
+		{"dollar", "", "%dollar%"},
+		{"us", SearchMatchPrefix, "us%"},
+		{"euro", SearchMatchExact, "euro"},
+		// Wildcards in the term match literally
+		{"100%", SearchMatchContains, `%100\%%`},
+		{"a_b", SearchMatchPrefix, `a\_b%`},
+		{`c:\`, SearchMatchExact, `c:\\`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, likePattern(tt.term, tt.match), "%q %s", tt.term, tt.match)
+	}
+}
+
+func TestSearchByNameSearchesTheRequestedField(t *testing.T) {
+	ctx := context.Background()
+
+	for field, column := range map[string]string{"": "description", SearchFieldDescription: "description", SearchFieldCode: "code", "code; DROP TABLE currencies": "description"} {
+		repo, statements := newDryRunRepository(t)
+		_, _ = repo.SearchByName(ctx, SearchOptions{Term: "us", Field: field}, 0, 0)
+
+		require.NotEmpty(t, *statements, field)
+		assert.Contains(t, (*statements)[0], "WHERE "+column+" ILIKE $1", field)
+		assert.NotContains(t, (*statements)[0], "DROP", field)
+	}
+}
+
+func TestSearchByNameMatchModes(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCurrencyRepository(openTestDatabase(t), database.RetryPolicy{})
+
+	for code, description := range map[string]string{"USD": "US Dollar", "AUD": "Australian Dollar", "EUR": "Euro", "BUS": "Dollar Bus"} {
+		require.NoError(t, repo.Create(ctx, &model.Currency{Code: code, Description: description, Factor: 100, CreatedBy: uuid.New()}))
+	}
+
+	tests := []struct {
+		search SearchOptions
+		want   []string
+	}{
+		{SearchOptions{Term: "dollar"}, []string{"AUD", "BUS", "USD"}},
+		{SearchOptions{Term: "DOLLAR", Match: SearchMatchPrefix}, []string{"BUS"}},
+		{SearchOptions{Term: "us dollar", Match: SearchMatchExact}, []string{"USD"}},
+		{SearchOptions{Term: "dollar", Match: SearchMatchExact}, nil},
+		{SearchOptions{Term: "us", Field: SearchFieldCode}, []string{"BUS", "USD"}},
+		{SearchOptions{Term: "us", Field: SearchFieldCode, Match: SearchMatchPrefix}, []string{"USD"}},
+		{SearchOptions{Term: "eur", Field: SearchFieldCode, Match: SearchMatchExact}, []string{"EUR"}},
+		{SearchOptions{Term: "%", Field: SearchFieldCode}, nil},
+	}
+
+	for _, tt := range tests {
+		currencies, err := repo.SearchByName(ctx, tt.search, 0, 0)
+		require.NoError(t, err)
+		var codes []string
+		for _, currency := range currencies {
+			codes = append(codes, currency.Code)
+		}
+		assert.Equal(t, tt.want, codes, "%+v", tt.search)
+
+		total, err := repo.CountByName(ctx, tt.search)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(tt.want)), total, "%+v", tt.search)
+	}
+}
+
+func TestGetAllValidOnAgainstDatabase(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCurrencyRepository(openTestDatabase(t), database.RetryPolicy{})
+
+	day := func(year int, month time.Month, d int) *time.Time {
+		t := time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	for _, currency := range []*model.Currency{
+		{Code: "QVA", Description: "Always valid", Factor: 100, CreatedBy: uuid.New()},
+		{Code: "QVF", Description: "Valid from", Factor: 100, ValidFrom: day(1999, 1, 1), CreatedBy: uuid.New()},
+		{Code: "QVU", Description: "Valid until", Factor: 100, ValidUntil: day(2002, 1, 1), CreatedBy: uuid.New()},
+		{Code: "QVB", Description: "Valid between", Factor: 100, ValidFrom: day(1979, 3, 13), ValidUntil: day(1999, 1, 1), CreatedBy: uuid.New()},
+	} {
+		require.NoError(t, repo.Create(ctx, currency))
+	}
+
+	validOn := func(on *time.Time) []string {
+		t.Helper()
+		currencies, err := repo.GetAll(ctx, 1000, 0, "code", "asc", ListFilter{ValidOn: on})
+		require.NoError(t, err)
+		var codes []string
+		for _, currency := range currencies {
+			if strings.HasPrefix(currency.Code, "QV") {
+				codes = append(codes, currency.Code)
+			}
+		}
+		return codes
+	}
+
+	assert.Equal(t, []string{"QVA", "QVU"}, validOn(day(1970, 1, 1)))
+	assert.Equal(t, []string{"QVA", "QVB", "QVU"}, validOn(day(1990, 6, 15)))
+	assert.Equal(t, []string{"QVA", "QVF", "QVU"}, validOn(day(1999, 1, 1)))
+	assert.Equal(t, []string{"QVA", "QVF"}, validOn(day(2002, 1, 1)))
+	assert.Equal(t, []string{"QVA", "QVB", "QVF", "QVU"}, validOn(nil))
+}
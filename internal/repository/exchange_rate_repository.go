@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExchangeRateRepositoryInterface defines the contract for exchange rate data operations
+type ExchangeRateRepositoryInterface interface {
+	Create(ctx context.Context, rate *model.ExchangeRate) error
+	CreateBatch(ctx context.Context, rates []*model.ExchangeRate) error
+	UpsertBatch(ctx context.Context, rates []*model.ExchangeRate) error
+	GetLatest(ctx context.Context, fromCode, toCode string) (*model.ExchangeRate, error)
+	GetLatestAmong(ctx context.Context, codes []string) ([]*model.ExchangeRate, error)
+	Unpin(ctx context.Context, fromCode, toCode string) (int64, error)
+	GetRateAt(ctx context.Context, fromCode, toCode string, t time.Time) (*model.ExchangeRate, error)
+	GetPairs(ctx context.Context, fromCode string, limit, offset int) ([]*RatePair, error)
+	CountPairs(ctx context.Context, fromCode string) (int64, error)
+	GetStalestTimestamp(ctx context.Context) (*time.Time, error)
+}
+
+// RatePair is a currency pair with at least one stored rate, along with
+// when its latest rate was quoted
+type RatePair struct {
+	FromCode        string    `json:"from_code"`
+	ToCode          string    `json:"to_code"`
+	LatestTimestamp time.Time `json:"latest_timestamp"`
+}
+
+// MarshalJSON implements json.Marshaler, writing LatestTimestamp as a model.Timestamp
+func (p RatePair) MarshalJSON() ([]byte, error) {
+	type ratePair RatePair
+	return json.Marshal(struct {
+		ratePair
+		LatestTimestamp model.Timestamp `json:"latest_timestamp"`
+	}{ratePair(p), model.NewTimestamp(p.LatestTimestamp)})
+}
+
+// ExchangeRateRepository implements the ExchangeRateRepositoryInterface
+type ExchangeRateRepository struct {
+	db *gorm.DB
+}
+
+// NewExchangeRateRepository creates a new exchange rate repository instance
+func NewExchangeRateRepository(db *gorm.DB) ExchangeRateRepositoryInterface {
+	return &ExchangeRateRepository{
+		db: db,
+	}
+}
+
+// Create stores a new exchange rate snapshot
+func (r *ExchangeRateRepository) Create(ctx context.Context, rate *model.ExchangeRate) error {
+	if err := r.db.WithContext(ctx).Create(rate).Error; err != nil {
+		return fmt.Errorf("failed to create exchange rate: %w", err)
+	}
+	return nil
+}
+
+// CreateBatch stores several exchange rate snapshots in a single insert
+func (r *ExchangeRateRepository) CreateBatch(ctx context.Context, rates []*model.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Create(&rates).Error; err != nil {
+		return fmt.Errorf("failed to create exchange rates: %w", err)
+	}
+	return nil
+}
+
+// UpsertBatch stores several exchange rate snapshots in a single statement,
+// replacing the rate and source of any snapshot already stored for the same
+// pair and quote time. A pair and time may appear only once in rates.
+func (r *ExchangeRateRepository) UpsertBatch(ctx context.Context, rates []*model.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "from_code"}, {Name: "to_code"}, {Name: "timestamp"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "source"}),
+		}).
+		Create(&rates).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert exchange rates: %w", err)
+	}
+	return nil
+}
+
+// preferredRateOrder ranks the rates of a pair: pinned rates before all
+// others however old they are, the highest priority first among pinned
+// rates, and then the most recent
+const preferredRateOrder = "pinned DESC, priority DESC, timestamp DESC"
+
+// GetLatest retrieves the rate for converting fromCode into toCode: the pinned
+// rate with the highest priority if there is one, else the most recent
+func (r *ExchangeRateRepository) GetLatest(ctx context.Context, fromCode, toCode string) (*model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+	err := r.db.WithContext(ctx).
+		Where("from_code = ? AND to_code = ?", fromCode, toCode).
+		Order(preferredRateOrder).
+		First(&rate).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w for %s/%s", apperrors.ErrExchangeRateNotFound, fromCode, toCode)
+		}
+		return nil, fmt.Errorf("failed to get latest exchange rate: %w", err)
+	}
+
+	return &rate, nil
+}
+
+// GetLatestAmong retrieves the rate GetLatest would return for every pair
+// converting between two of codes, in either direction, in a single query
+func (r *ExchangeRateRepository) GetLatestAmong(ctx context.Context, codes []string) ([]*model.ExchangeRate, error) {
+	var rates []*model.ExchangeRate
+	if len(codes) == 0 {
+		return rates, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Select("DISTINCT ON (from_code, to_code) *").
+		Where("from_code IN ? AND to_code IN ?", codes, codes).
+		Order("from_code, to_code, " + preferredRateOrder).
+		Find(&rates).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest exchange rates: %w", err)
+	}
+
+	return rates, nil
+}
+
+// Unpin deletes the pinned rates for converting fromCode into toCode, so the
+// pair falls back to its provider rates, and returns how many were deleted
+func (r *ExchangeRateRepository) Unpin(ctx context.Context, fromCode, toCode string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("from_code = ? AND to_code = ? AND pinned", fromCode, toCode).
+		Delete(&model.ExchangeRate{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to unpin exchange rates: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetRateAt retrieves the rate GetLatest would have returned at t, among the
+// rates for converting fromCode into toCode quoted on or before t. A rate
+// pinned at some point thus applies from then on.
+func (r *ExchangeRateRepository) GetRateAt(ctx context.Context, fromCode, toCode string, t time.Time) (*model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+	err := r.db.WithContext(ctx).
+		Where("from_code = ? AND to_code = ? AND timestamp <= ?", fromCode, toCode, t).
+		Order(preferredRateOrder).
+		First(&rate).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w for %s/%s at %s", apperrors.ErrExchangeRateNotFound, fromCode, toCode, t.Format(time.RFC3339))
+		}
+		return nil, fmt.Errorf("failed to get exchange rate at %s: %w", t.Format(time.RFC3339), err)
+	}
+
+	return &rate, nil
+}
+
+// GetPairs retrieves the distinct currency pairs that have a stored rate,
+// ordered by from and to code. A non-empty fromCode restricts them to pairs
+// converting from that currency.
+func (r *ExchangeRateRepository) GetPairs(ctx context.Context, fromCode string, limit, offset int) ([]*RatePair, error) {
+	var pairs []*RatePair
+
+	query := r.pairs(ctx, fromCode).Order("from_code ASC, to_code ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	if err := query.Scan(&pairs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate pairs: %w", err)
+	}
+
+	return pairs, nil
+}
+
+// CountPairs returns the total number of pairs matching GetPairs
+func (r *ExchangeRateRepository) CountPairs(ctx context.Context, fromCode string) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Table("(?) AS pairs", r.pairs(ctx, fromCode)).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count exchange rate pairs: %w", err)
+	}
+	return count, nil
+}
+
+// GetStalestTimestamp returns when the latest rate of the least recently
+// refreshed pair was quoted, or nil when no rates are stored
+func (r *ExchangeRateRepository) GetStalestTimestamp(ctx context.Context) (*time.Time, error) {
+	var stalest sql.NullTime
+	err := r.db.WithContext(ctx).
+		Table("(?) AS pairs", r.pairs(ctx, "")).
+		Select("MIN(latest_timestamp)").
+		Scan(&stalest).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stalest exchange rate timestamp: %w", err)
+	}
+	if !stalest.Valid {
+		return nil, nil
+	}
+	return &stalest.Time, nil
+}
+
+// pairs builds the query grouping stored rates by currency pair
+func (r *ExchangeRateRepository) pairs(ctx context.Context, fromCode string) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Model(&model.ExchangeRate{}).
+		Select("from_code, to_code, MAX(timestamp) AS latest_timestamp").
+		Group("from_code, to_code")
+	if fromCode != "" {
+		query = query.Where("from_code = ?", fromCode)
+	}
+	return query
+}
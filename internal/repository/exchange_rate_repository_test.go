@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+)
+
+func TestUpsertBatchReplacesRateAtTheSameTime(t *testing.T) {
+	db, statements := newDryRunDB(t)
+	repo := NewExchangeRateRepository(db)
+
+	now := time.Now().UTC()
+	require.NoError(t, repo.UpsertBatch(context.Background(), []*model.ExchangeRate{
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.NewFromInt(1), Timestamp: now, Source: "ecb"},
+	}))
+
+	require.Len(t, *statements, 1)
+	sql := (*statements)[0]
+	assert.Contains(t, sql, `INSERT INTO "exchange_rates"`)
+	assert.Contains(t, sql, `ON CONFLICT ("from_code","to_code","timestamp") DO UPDATE SET "rate"="excluded"."rate","source"="excluded"."source"`)
+}
+
+func TestUpsertBatchAgainstDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDatabase(t)
+	repo := NewExchangeRateRepository(db)
+
+	at := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.UpsertBatch(ctx, []*model.ExchangeRate{
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.92"), Timestamp: at, Source: "ecb"},
+	}))
+	require.NoError(t, repo.UpsertBatch(ctx, []*model.ExchangeRate{
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.93"), Timestamp: at, Source: "manual"},
+	}))
+
+	rate, err := repo.GetRateAt(ctx, "USD", "EUR", at)
+	require.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("0.93").Equal(rate.Rate), rate.Rate.String())
+	assert.Equal(t, "manual", rate.Source)
+
+	var count int64
+	require.NoError(t, db.Model(&model.ExchangeRate{}).Where("from_code = ? AND to_code = ? AND timestamp = ?", "USD", "EUR", at).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestGetLatestAmongFetchesEveryPairInOneQuery(t *testing.T) {
+	db, statements := newDryRunDB(t)
+	repo := NewExchangeRateRepository(db)
+
+	_, err := repo.GetLatestAmong(context.Background(), []string{"USD", "EUR", "GBP"})
+	require.NoError(t, err)
+
+	require.Len(t, *statements, 1)
+	sql := (*statements)[0]
+	assert.Contains(t, sql, "SELECT DISTINCT ON (from_code, to_code) *")
+	assert.Contains(t, sql, "from_code IN ($1,$2,$3) AND to_code IN ($4,$5,$6)")
+	assert.Contains(t, sql, "ORDER BY from_code, to_code, pinned DESC, priority DESC, timestamp DESC")
+}
+
+func TestRateLookupsPreferPinnedRates(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	for name, lookup := range map[string]func(repo ExchangeRateRepositoryInterface){
+		"GetLatest": func(repo ExchangeRateRepositoryInterface) { _, _ = repo.GetLatest(ctx, "USD", "EUR") },
+		"GetRateAt": func(repo ExchangeRateRepositoryInterface) { _, _ = repo.GetRateAt(ctx, "USD", "EUR", at) },
+	} {
+		db, statements := newDryRunDB(t)
+		lookup(NewExchangeRateRepository(db))
+
+		require.Len(t, *statements, 1, name)
+		assert.Contains(t, (*statements)[0], "ORDER BY pinned DESC, priority DESC, timestamp DESC", name)
+	}
+}
+
+func TestGetRateAtLooksUpTheLatestSnapshotOnOrBefore(t *testing.T) {
+	db, statements := newDryRunDB(t)
+
+	_, _ = NewExchangeRateRepository(db).GetRateAt(context.Background(), "USD", "EUR", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+
+	require.Len(t, *statements, 1)
+	sql := (*statements)[0]
+	assert.Contains(t, sql, "from_code = $1 AND to_code = $2 AND timestamp <= $3")
+	assert.Contains(t, sql, "ORDER BY pinned DESC, priority DESC, timestamp DESC")
+	assert.Contains(t, sql, "LIMIT $4")
+}
+
+func TestGetRateAtChoosesHistoricalSnapshot(t *testing.T) {
+	ctx := context.Background()
+	repo := NewExchangeRateRepository(openTestDatabase(t))
+
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.CreateBatch(ctx, []*model.ExchangeRate{
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.90"), Timestamp: day.AddDate(0, 0, -5), Source: model.RateSourceProvider},
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.91"), Timestamp: day, Source: model.RateSourceProvider},
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.92"), Timestamp: day.AddDate(0, 0, 3), Source: model.RateSourceProvider},
+		{FromCode: "EUR", ToCode: "USD", Rate: decimal.RequireFromString("1.5"), Timestamp: day.AddDate(0, 0, 1), Source: model.RateSourceProvider},
+	}))
+
+	for at, want := range map[time.Time]string{
+		day.AddDate(0, 0, -5):      "0.90",
+		day.Add(-time.Microsecond): "0.90",
+		day:                        "0.91",
+		day.AddDate(0, 0, 2):       "0.91",
+		day.AddDate(0, 0, 3):       "0.92",
+		day.AddDate(1, 0, 0):       "0.92",
+	} {
+		rate, err := repo.GetRateAt(ctx, "USD", "EUR", at)
+		require.NoError(t, err, at)
+		assert.True(t, decimal.RequireFromString(want).Equal(rate.Rate), "%s: got %s", at, rate.Rate)
+	}
+
+	_, err := repo.GetRateAt(ctx, "USD", "EUR", day.AddDate(0, 0, -6))
+	assert.ErrorIs(t, err, apperrors.ErrExchangeRateNotFound)
+	_, err = repo.GetRateAt(ctx, "EUR", "USD", day)
+	assert.ErrorIs(t, err, apperrors.ErrExchangeRateNotFound)
+}
+
+func TestUnpinDeletesOnlyPinnedRates(t *testing.T) {
+	db, statements := newDryRunDB(t)
+
+	_, err := NewExchangeRateRepository(db).Unpin(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+
+	require.Len(t, *statements, 1)
+	assert.Contains(t, (*statements)[0], `DELETE FROM "exchange_rates" WHERE from_code = $1 AND to_code = $2 AND pinned`)
+}
+
+func TestPinnedRateWinsOverNewerProviderRate(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDatabase(t)
+	repo := NewExchangeRateRepository(db)
+
+	pinnedAt := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.CreateBatch(ctx, []*model.ExchangeRate{
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.90"), Timestamp: pinnedAt, Source: model.RateSourceManual, Pinned: true, Priority: 1},
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.95"), Timestamp: pinnedAt.Add(time.Hour), Source: model.RateSourceManual, Pinned: true},
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.92"), Timestamp: pinnedAt.Add(24 * time.Hour), Source: model.RateSourceProvider},
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.91"), Timestamp: pinnedAt.Add(-24 * time.Hour), Source: model.RateSourceProvider},
+	}))
+
+	// The higher priority pin beats the newer pin and the newer provider rate
+	rate, err := repo.GetLatest(ctx, "USD", "EUR")
+	require.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("0.90").Equal(rate.Rate), rate.Rate.String())
+
+	rates, err := repo.GetLatestAmong(ctx, []string{"USD", "EUR"})
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	assert.True(t, decimal.RequireFromString("0.90").Equal(rates[0].Rate), rates[0].Rate.String())
+
+	// Before the pins were quoted only the provider rate applies
+	rate, err = repo.GetRateAt(ctx, "USD", "EUR", pinnedAt.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("0.91").Equal(rate.Rate), rate.Rate.String())
+
+	removed, err := repo.Unpin(ctx, "USD", "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+
+	rate, err = repo.GetLatest(ctx, "USD", "EUR")
+	require.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("0.92").Equal(rate.Rate), rate.Rate.String())
+}
+
+func TestGetPairsGroupsRatesByPair(t *testing.T) {
+	db, statements := newDryRunDB(t)
+	repo := NewExchangeRateRepository(db)
+
+	_, _ = repo.GetPairs(context.Background(), "USD", 2, 4)
+	_, err := repo.CountPairs(context.Background(), "USD")
+	require.NoError(t, err)
+
+	pairs := `SELECT from_code, to_code, MAX(timestamp) AS latest_timestamp FROM "exchange_rates" WHERE from_code = $1 GROUP BY from_code, to_code`
+	require.Len(t, *statements, 3)
+	assert.Equal(t, pairs+" ORDER BY from_code ASC, to_code ASC LIMIT $2 OFFSET $3", (*statements)[0])
+	assert.Equal(t, "SELECT count(*) FROM ("+pairs+") AS pairs", (*statements)[2])
+}
+
+func TestGetPairsListsSeededPairsOnce(t *testing.T) {
+	ctx := context.Background()
+	repo := NewExchangeRateRepository(openTestDatabase(t))
+
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.CreateBatch(ctx, []*model.ExchangeRate{
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.91"), Timestamp: day, Source: model.RateSourceProvider},
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.92"), Timestamp: day.AddDate(0, 0, 1), Source: model.RateSourceProvider},
+		{FromCode: "USD", ToCode: "GBP", Rate: decimal.RequireFromString("0.79"), Timestamp: day, Source: model.RateSourceProvider},
+		{FromCode: "USD", ToCode: "JPY", Rate: decimal.RequireFromString("148"), Timestamp: day, Source: model.RateSourceProvider},
+		{FromCode: "EUR", ToCode: "USD", Rate: decimal.RequireFromString("1.09"), Timestamp: day, Source: model.RateSourceProvider},
+	}))
+
+	pairNames := func(pairs []*RatePair) []string {
+		names := make([]string, len(pairs))
+		for i, pair := range pairs {
+			names[i] = pair.FromCode + "/" + pair.ToCode
+		}
+		return names
+	}
+
+	pairs, err := repo.GetPairs(ctx, "", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EUR/USD", "USD/EUR", "USD/GBP", "USD/JPY"}, pairNames(pairs))
+	assert.True(t, day.AddDate(0, 0, 1).Equal(pairs[1].LatestTimestamp), "a pair carries its latest timestamp")
+
+	// Filtered by base and paged
+	pairs, err = repo.GetPairs(ctx, "USD", 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"USD/GBP", "USD/JPY"}, pairNames(pairs))
+
+	total, err := repo.CountPairs(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	total, err = repo.CountPairs(ctx, "USD")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	total, err = repo.CountPairs(ctx, "GBP")
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"gorm.io/gorm"
+)
+
+// TranslationRepositoryInterface defines the contract for currency translation data operations
+type TranslationRepositoryInterface interface {
+	GetByCodes(ctx context.Context, codes, locales []string) ([]*model.CurrencyTranslation, error)
+}
+
+// TranslationRepository implements the TranslationRepositoryInterface
+type TranslationRepository struct {
+	db *gorm.DB
+}
+
+// NewTranslationRepository creates a new translation repository instance
+func NewTranslationRepository(db *gorm.DB) TranslationRepositoryInterface {
+	return &TranslationRepository{
+		db: db,
+	}
+}
+
+// GetByCodes returns the translations of the given currencies into any of
+// the given locales. Locales must be lower case, as they are stored.
+func (r *TranslationRepository) GetByCodes(ctx context.Context, codes, locales []string) ([]*model.CurrencyTranslation, error) {
+	var translations []*model.CurrencyTranslation
+	if len(codes) == 0 || len(locales) == 0 {
+		return translations, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Where("currency_code IN ? AND locale IN ?", codes, locales).
+		Find(&translations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currency translations: %w", err)
+	}
+	return translations, nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/database"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"gorm.io/gorm"
+)
+
+// AdminServiceInterface defines operational and diagnostic operations
+type AdminServiceInterface interface {
+	GetStatusReport(ctx context.Context) (*StatusReport, error)
+	RebuildCache(ctx context.Context) (*CacheRebuildResult, error)
+	FlushCache(ctx context.Context) (*CacheFlushResult, error)
+}
+
+// StatusReport is a single diagnostic snapshot of the service
+type StatusReport struct {
+	TotalCurrencies    int64             `json:"total_currencies"`
+	ActiveCurrencies   int64             `json:"active_currencies"`
+	InactiveCurrencies int64             `json:"inactive_currencies"`
+	CurrenciesByFactor map[int]int64     `json:"currencies_by_factor"`
+	Rates              RateStats         `json:"rates"`
+	DatabasePool       DatabasePoolStats `json:"database_pool"`
+	ListCache          ListCacheStats    `json:"list_cache"`
+	GeneratedAt        model.Timestamp   `json:"generated_at"`
+}
+
+// RateStats summarizes the stored exchange rates. The stalest rate is the
+// latest rate of the least recently refreshed pair; both of its fields are
+// null when no rates are stored. Provider is null when no rate provider is
+// configured.
+type RateStats struct {
+	Pairs             int64               `json:"pairs"`
+	StalestRateAt     *model.Timestamp    `json:"stalest_rate_at"`
+	StalestRateAgeSec *int64              `json:"stalest_rate_age_seconds"`
+	Provider          *RateProviderStatus `json:"provider"`
+}
+
+// DatabasePoolStats represents the state of the database connection pool
+type DatabasePoolStats struct {
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDuration       string `json:"wait_duration"`
+}
+
+// CacheFlushResult reports what FlushCache did
+type CacheFlushResult struct {
+	KeysCleared int64 `json:"keys_cleared"`
+}
+
+// AdminService implements the AdminServiceInterface
+type AdminService struct {
+	currencyRepo    repository.CurrencyRepositoryInterface
+	rateRepo        repository.ExchangeRateRepositoryInterface
+	currencyService CurrencyServiceInterface
+	rateRefresher   RateRefresherInterface
+	db              *gorm.DB
+	hotCodes        []string
+}
+
+// NewAdminService creates a new admin service instance. rateRefresher is nil
+// when no rate provider is configured.
+func NewAdminService(currencyRepo repository.CurrencyRepositoryInterface, rateRepo repository.ExchangeRateRepositoryInterface, currencyService CurrencyServiceInterface, rateRefresher RateRefresherInterface, db *gorm.DB, hotCodes []string) AdminServiceInterface {
+	return &AdminService{
+		currencyRepo:    currencyRepo,
+		rateRepo:        rateRepo,
+		currencyService: currencyService,
+		rateRefresher:   rateRefresher,
+		db:              db,
+		hotCodes:        hotCodes,
+	}
+}
+
+// GetStatusReport aggregates repository counts and pool statistics
+func (s *AdminService) GetStatusReport(ctx context.Context) (*StatusReport, error) {
+	total, err := s.currencyRepo.GetCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build status report: %w", err)
+	}
+
+	active, err := s.currencyRepo.CountFiltered(ctx, repository.ListFilter{ActiveOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build status report: %w", err)
+	}
+
+	byFactor, err := s.currencyRepo.GetCountsByFactor(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build status report: %w", err)
+	}
+
+	rates, err := s.rateStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build status report: %w", err)
+	}
+
+	stats, err := database.GetPoolStats(s.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build status report: %w", err)
+	}
+
+	return &StatusReport{
+		TotalCurrencies:    total,
+		ActiveCurrencies:   active,
+		InactiveCurrencies: total - active,
+		CurrenciesByFactor: byFactor,
+		Rates:              *rates,
+		DatabasePool: DatabasePoolStats{
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDuration:       stats.WaitDuration.String(),
+		},
+		ListCache:   s.currencyService.ListCacheStats(),
+		GeneratedAt: model.Now(),
+	}, nil
+}
+
+// rateStats counts the stored rate pairs, finds the stalest one and adds the
+// provider's status
+func (s *AdminService) rateStats(ctx context.Context) (*RateStats, error) {
+	pairs, err := s.rateRepo.CountPairs(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	stalest, err := s.rateRepo.GetStalestTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &RateStats{Pairs: pairs}
+	if stalest != nil {
+		at := model.NewTimestamp(*stalest)
+		age := int64(time.Since(*stalest).Seconds())
+		stats.StalestRateAt = &at
+		stats.StalestRateAgeSec = &age
+	}
+	if s.rateRefresher != nil {
+		status := s.rateRefresher.ProviderStatus()
+		stats.Provider = &status
+	}
+	return stats, nil
+}
+
+// RebuildCache clears the currency cache and re-warms it with the configured hot codes
+func (s *AdminService) RebuildCache(ctx context.Context) (*CacheRebuildResult, error) {
+	return s.currencyService.RebuildCache(ctx, s.hotCodes)
+}
+
+// FlushCache clears the currency cache without re-warming it, e.g. after
+// currencies were edited directly in the database
+func (s *AdminService) FlushCache(ctx context.Context) (*CacheFlushResult, error) {
+	cleared, err := s.currencyService.FlushCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &CacheFlushResult{KeysCleared: cleared}, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type stubListCacheService struct {
+	CurrencyServiceInterface
+	stats ListCacheStats
+}
+
+func (s *stubListCacheService) ListCacheStats() ListCacheStats {
+	return s.stats
+}
+
+// newUnconnectedDB returns a gorm DB whose pool never connects, which is
+// enough for reading pool statistics
+func newUnconnectedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return db
+}
+
+func TestGetStatusReport(t *testing.T) {
+	currencyRepo := newFakeCurrencyRepo(
+		&model.Currency{Code: "USD", Factor: 100, IsActive: true},
+		&model.Currency{Code: "JPY", Factor: 1, IsActive: true},
+		&model.Currency{Code: "DEM", Factor: 100, IsActive: false},
+	)
+	stalest := time.Now().Add(-2 * time.Hour)
+	rateRepo := &fakeRateRepo{pairs: 7, stalest: &stalest}
+	currencyService := &stubListCacheService{stats: ListCacheStats{Hits: 3, Misses: 1, HitRate: 0.75}}
+
+	svc := NewAdminService(currencyRepo, rateRepo, currencyService, nil, newUnconnectedDB(t), nil)
+	report, err := svc.GetStatusReport(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), report.TotalCurrencies)
+	assert.Equal(t, int64(2), report.ActiveCurrencies)
+	assert.Equal(t, int64(1), report.InactiveCurrencies)
+	assert.Equal(t, map[int]int64{100: 2, 1: 1}, report.CurrenciesByFactor)
+	assert.Equal(t, int64(7), report.Rates.Pairs)
+	require.NotNil(t, report.Rates.StalestRateAt)
+	assert.True(t, report.Rates.StalestRateAt.Equal(stalest))
+	require.NotNil(t, report.Rates.StalestRateAgeSec)
+	assert.InDelta(t, 2*time.Hour.Seconds(), float64(*report.Rates.StalestRateAgeSec), 5)
+	assert.Equal(t, 0.75, report.ListCache.HitRate)
+	assert.False(t, report.GeneratedAt.IsZero())
+}
+
+func TestGetStatusReportWithoutRates(t *testing.T) {
+	svc := NewAdminService(newFakeCurrencyRepo(), &fakeRateRepo{}, &stubListCacheService{}, nil, newUnconnectedDB(t), nil)
+	report, err := svc.GetStatusReport(context.Background())
+	require.NoError(t, err)
+
+	assert.Zero(t, report.Rates.Pairs)
+	assert.Nil(t, report.Rates.StalestRateAt)
+	assert.Nil(t, report.Rates.StalestRateAgeSec)
+	assert.Nil(t, report.Rates.Provider)
+}
+
+func TestGetStatusReportIncludesProviderStatus(t *testing.T) {
+	provider := &fakeRateProvider{err: errors.New("provider down")}
+	refresher := newTestRefresher(provider, &fakeRateRepo{}, time.Minute, "USD")
+	refresher.RefreshAll(context.Background())
+	refresher.RefreshAll(context.Background())
+
+	svc := NewAdminService(newFakeCurrencyRepo(), &fakeRateRepo{}, &stubListCacheService{}, refresher, newUnconnectedDB(t), nil)
+	report, err := svc.GetStatusReport(context.Background())
+	require.NoError(t, err)
+
+	require.NotNil(t, report.Rates.Provider)
+	assert.Nil(t, report.Rates.Provider.LastSuccessAt)
+	assert.Equal(t, 2, report.Rates.Provider.ConsecutiveFailures)
+	assert.Equal(t, int64(4*60), report.Rates.Provider.RetryIntervalSec)
+}
+
+func TestFlushCacheMakesReadsHitTheRepository(t *testing.T) {
+	repo := newListTestRepo()
+	currencyService, server := newCachedTestCurrencyService(t, repo)
+	svc := NewAdminService(repo, &fakeRateRepo{}, currencyService, nil, newUnconnectedDB(t), nil)
+	ctx := context.Background()
+
+	read := func() (*model.Currency, []*model.Currency) {
+		currency, err := currencyService.GetCurrencyByCode(ctx, "USD")
+		require.NoError(t, err)
+		list, err := currencyService.GetAllCurrencies(ctx, 10, 0, "code", "asc", repository.ListFilter{})
+		require.NoError(t, err)
+		return currency, list
+	}
+
+	// Populate the cache, after which reads no longer reach the repository
+	read()
+	read()
+	assert.Equal(t, 1, repo.GetByCodeCalls())
+	assert.Equal(t, 1, repo.GetAllCalls())
+	require.NoError(t, server.Set("unrelated", "1"))
+
+	// An edit made directly in the database isn't seen until the flush
+	repo.mu.Lock()
+	repo.currencies["USD"].Description = "United States Dollar"
+	repo.mu.Unlock()
+	currency, _ := read()
+	assert.Equal(t, "US Dollar", currency.Description)
+
+	result, err := svc.FlushCache(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.KeysCleared)
+	assert.Equal(t, []string{"unrelated"}, server.Keys())
+
+	currency, list := read()
+	assert.Equal(t, "United States Dollar", currency.Description)
+	assert.Equal(t, []string{"EUR", "GBP", "USD"}, codesOf(list))
+	assert.Equal(t, 2, repo.GetByCodeCalls())
+	assert.Equal(t, 2, repo.GetAllCalls())
+}
+
+func TestFlushCacheWithCachingDisabled(t *testing.T) {
+	repo := newListTestRepo()
+	currencyService, server := newCachedTestCurrencyService(t, repo)
+	currencyService.cacheTimeout = 0
+	require.NoError(t, server.Set("currency:code:USD", "{}"))
+	svc := NewAdminService(repo, &fakeRateRepo{}, currencyService, nil, newUnconnectedDB(t), nil)
+
+	result, err := svc.FlushCache(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, result.KeysCleared)
+	assert.True(t, server.Exists("currency:code:USD"))
+}
@@ -0,0 +1,17 @@
+package service
+
+import "time"
+
+// Clock tells the current time. Services read it instead of calling
+// time.Now so tests can move time across a boundary.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the Clock services use outside tests
+type systemClock struct{}
+
+// Now returns the current time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/money"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrInvalidAmount is returned for amounts that cannot be converted
+	ErrInvalidAmount = errors.New("invalid amount")
+)
+
+// ConversionServiceInterface defines currency conversion operations
+type ConversionServiceInterface interface {
+	ConvertAmount(ctx context.Context, from, to string, amount decimal.Decimal) (*ConversionResult, error)
+	RateMatrix(ctx context.Context, codes []string) (*RateMatrix, error)
+}
+
+// ConversionResult is the outcome of converting an amount between two currencies
+type ConversionResult struct {
+	From          string          `json:"from"`
+	To            string          `json:"to"`
+	Amount        decimal.Decimal `json:"amount"`
+	Rate          decimal.Decimal `json:"rate"`
+	Result        decimal.Decimal `json:"result"`
+	RateTimestamp model.Timestamp `json:"rate_timestamp"`
+	Via           string          `json:"via,omitempty"` // Pivot currency of a cross rate; empty for a direct rate
+}
+
+// ConversionService implements the ConversionServiceInterface
+type ConversionService struct {
+	currencyRepo repository.CurrencyRepositoryInterface
+	rateRepo     repository.ExchangeRateRepositoryInterface
+	pivot        string
+}
+
+// NewConversionService creates a new conversion service instance. Pairs
+// without a rate of their own are converted through the pivot currency; an
+// empty pivot disables cross rates.
+func NewConversionService(currencyRepo repository.CurrencyRepositoryInterface, rateRepo repository.ExchangeRateRepositoryInterface, pivot string) ConversionServiceInterface {
+	return &ConversionService{
+		currencyRepo: currencyRepo,
+		rateRepo:     rateRepo,
+		pivot:        model.CanonicalCode(pivot),
+	}
+}
+
+// ConvertAmount converts amount from one currency into another using the
+// latest stored rate, or a cross rate through the pivot currency when the
+// pair has none (see findRate). Inactive currencies can't be converted.
+//
+// Only the final result is rounded, to the decimal places implied by the
+// target currency's Factor and using its RoundingMode. Rates, including
+// cross rates, are used at full precision; an inverted rate carries
+// decimal.DivisionPrecision places.
+func (s *ConversionService) ConvertAmount(ctx context.Context, from, to string, amount decimal.Decimal) (*ConversionResult, error) {
+	if amount.IsNegative() {
+		return nil, fmt.Errorf("%w: amount must not be negative", ErrInvalidAmount)
+	}
+
+	from, to = model.CanonicalCode(from), model.CanonicalCode(to)
+	currencies, err := s.currencyRepo.GetByCodes(ctx, []string{from, to})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load currencies: %w", err)
+	}
+	// Codes are matched case-insensitively, so the stored ones may differ in case
+	byCode := make(map[string]*model.Currency, len(currencies))
+	for _, currency := range currencies {
+		byCode[model.CanonicalCode(currency.Code)] = currency
+	}
+	for _, code := range []string{from, to} {
+		if byCode[code] == nil {
+			return nil, fmt.Errorf("%w: %s", apperrors.ErrCurrencyNotFound, code)
+		}
+		if !byCode[code].IsActive {
+			return nil, fmt.Errorf("%w: %s", apperrors.ErrCurrencyInactive, code)
+		}
+	}
+
+	result := &ConversionResult{
+		From:   from,
+		To:     to,
+		Amount: amount,
+	}
+
+	if from == to {
+		result.Rate = decimal.NewFromInt(1)
+		result.RateTimestamp = model.Now()
+	} else {
+		rate, err := s.findRate(ctx, from, to)
+		if err != nil {
+			return nil, err
+		}
+		result.Rate = rate.rate
+		result.RateTimestamp = model.NewTimestamp(rate.timestamp)
+		result.Via = rate.via
+	}
+
+	target := byCode[to]
+	result.Result = money.Round(amount.Mul(result.Rate), target.Factor, target.RoundingMode)
+
+	return result, nil
+}
+
+// quotedRate is a rate for converting between two currencies and when it
+// was quoted
+type quotedRate struct {
+	rate      decimal.Decimal
+	timestamp time.Time
+	via       string
+}
+
+// findRate returns the rate for converting from into to. The pair's own rate
+// is used when there is one; otherwise it is computed through the pivot
+// currency as from→pivot × pivot→to. A cross rate is only as recent as its
+// older leg.
+func (s *ConversionService) findRate(ctx context.Context, from, to string) (*quotedRate, error) {
+	direct, err := s.pairRate(ctx, from, to)
+	if err == nil || !errors.Is(err, apperrors.ErrExchangeRateNotFound) {
+		return direct, err
+	}
+	if s.pivot == "" || from == s.pivot || to == s.pivot {
+		return nil, err
+	}
+
+	toPivot, err := s.pairRate(ctx, from, s.pivot)
+	if err != nil {
+		return nil, s.crossRateError(from, to, err)
+	}
+	fromPivot, err := s.pairRate(ctx, s.pivot, to)
+	if err != nil {
+		return nil, s.crossRateError(from, to, err)
+	}
+
+	cross := &quotedRate{
+		rate:      toPivot.rate.Mul(fromPivot.rate),
+		timestamp: toPivot.timestamp,
+		via:       s.pivot,
+	}
+	if fromPivot.timestamp.Before(cross.timestamp) {
+		cross.timestamp = fromPivot.timestamp
+	}
+	return cross, nil
+}
+
+// pairRate returns the latest rate stored for from→to, or the inverse of the
+// latest to→from rate when only the reverse direction is stored, as is the
+// case for providers quoting every rate against one base currency
+func (s *ConversionService) pairRate(ctx context.Context, from, to string) (*quotedRate, error) {
+	rate, err := s.rateRepo.GetLatest(ctx, from, to)
+	if err == nil {
+		return &quotedRate{rate: rate.Rate, timestamp: rate.Timestamp}, nil
+	}
+	if !errors.Is(err, apperrors.ErrExchangeRateNotFound) {
+		return nil, err
+	}
+
+	reverse, reverseErr := s.rateRepo.GetLatest(ctx, to, from)
+	if reverseErr != nil {
+		if errors.Is(reverseErr, apperrors.ErrExchangeRateNotFound) {
+			return nil, err
+		}
+		return nil, reverseErr
+	}
+	if reverse.Rate.IsZero() {
+		return nil, err
+	}
+	return &quotedRate{rate: decimal.NewFromInt(1).Div(reverse.Rate), timestamp: reverse.Timestamp}, nil
+}
+
+// crossRateError reports that neither a direct nor a cross rate exists,
+// passing through errors other than a missing rate
+func (s *ConversionService) crossRateError(from, to string, err error) error {
+	if !errors.Is(err, apperrors.ErrExchangeRateNotFound) {
+		return err
+	}
+	return fmt.Errorf("%w for %s/%s, directly or via %s", apperrors.ErrExchangeRateNotFound, from, to, s.pivot)
+}
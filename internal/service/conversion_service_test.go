@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/money"
+)
+
+func TestConvertAmountFindsLowercaseStoredCode(t *testing.T) {
+	repo := newFakeCurrencyRepo(&model.Currency{Code: "usd", Factor: 100, IsActive: true})
+	svc := NewConversionService(repo, &fakeRateRepo{}, "")
+
+	result, err := svc.ConvertAmount(context.Background(), "USD", "usd", decimal.RequireFromString("12.345"))
+	require.NoError(t, err)
+	assert.Equal(t, "USD", result.From)
+	assert.Equal(t, "USD", result.To)
+	assert.True(t, result.Result.Equal(decimal.RequireFromString("12.34")), "got %s", result.Result)
+}
+
+// usdQuotedRates returns rates the way a provider quoting everything against
+// USD stores them
+func usdQuotedRates(at time.Time, quotes map[string]string) *fakeRateRepo {
+	rates := &fakeRateRepo{}
+	for code, rate := range quotes {
+		rates.stored = append(rates.stored, &model.ExchangeRate{FromCode: "USD", ToCode: code, Rate: decimal.RequireFromString(rate), Timestamp: at})
+	}
+	return rates
+}
+
+func TestConvertAmountPrefersDirectRate(t *testing.T) {
+	at := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	rates := usdQuotedRates(at, map[string]string{"EUR": "0.8", "GBP": "0.5"})
+	rates.stored = append(rates.stored, &model.ExchangeRate{FromCode: "EUR", ToCode: "GBP", Rate: decimal.RequireFromString("0.7"), Timestamp: at.Add(-time.Hour)})
+	svc := NewConversionService(newMatrixCurrencyRepo(), rates, "USD")
+
+	result, err := svc.ConvertAmount(context.Background(), "EUR", "GBP", decimal.NewFromInt(10))
+	require.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("7").Equal(result.Result), "got %s", result.Result)
+	assert.Empty(t, result.Via)
+	assert.True(t, at.Add(-time.Hour).Equal(result.RateTimestamp.Time))
+
+	// Only the reverse direction is stored, so its inverse is used
+	result, err = svc.ConvertAmount(context.Background(), "EUR", "USD", decimal.NewFromInt(10))
+	require.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("12.5").Equal(result.Result), "got %s", result.Result)
+	assert.Empty(t, result.Via)
+}
+
+func TestConvertAmountComputesCrossRateThroughPivot(t *testing.T) {
+	at := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	rates := usdQuotedRates(at, map[string]string{"EUR": "0.8"})
+	rates.stored = append(rates.stored, &model.ExchangeRate{FromCode: "USD", ToCode: "GBP", Rate: decimal.RequireFromString("0.5"), Timestamp: at.Add(-time.Hour)})
+	svc := NewConversionService(newMatrixCurrencyRepo(), rates, "USD")
+
+	// EUR→USD is the inverse of USD→EUR, 1.25, times USD→GBP
+	result, err := svc.ConvertAmount(context.Background(), "eur", "GBP", decimal.NewFromInt(10))
+	require.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("0.625").Equal(result.Rate), "got %s", result.Rate)
+	assert.True(t, decimal.RequireFromString("6.25").Equal(result.Result), "got %s", result.Result)
+	assert.Equal(t, "USD", result.Via)
+	assert.True(t, at.Add(-time.Hour).Equal(result.RateTimestamp.Time), "a cross rate is as old as its older leg")
+}
+
+func TestConvertAmountRoundsCrossRatesOnlyOnce(t *testing.T) {
+	rates := usdQuotedRates(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), map[string]string{"EUR": "0.3", "GBP": "7"})
+	svc := NewConversionService(newMatrixCurrencyRepo(), rates, "USD")
+
+	// Rounding the USD leg to 3.33 first would give 23.31
+	result, err := svc.ConvertAmount(context.Background(), "EUR", "GBP", decimal.NewFromInt(1))
+	require.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("23.33").Equal(result.Result), "got %s", result.Result)
+}
+
+func TestConvertAmountWithoutAnyRate(t *testing.T) {
+	rates := usdQuotedRates(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), map[string]string{"EUR": "0.8"})
+	ctx := context.Background()
+
+	// A missing leg leaves no path through the pivot
+	_, err := NewConversionService(newMatrixCurrencyRepo(), rates, "USD").ConvertAmount(ctx, "EUR", "GBP", decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, apperrors.ErrExchangeRateNotFound)
+	assert.ErrorContains(t, err, "EUR/GBP, directly or via USD")
+
+	// Nor is there one when the pivot is an end of the pair
+	_, err = NewConversionService(newMatrixCurrencyRepo(), rates, "USD").ConvertAmount(ctx, "USD", "GBP", decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, apperrors.ErrExchangeRateNotFound)
+
+	// Without a pivot only direct rates are used
+	rates = usdQuotedRates(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), map[string]string{"EUR": "0.8", "GBP": "0.5"})
+	_, err = NewConversionService(newMatrixCurrencyRepo(), rates, "").ConvertAmount(ctx, "EUR", "GBP", decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, apperrors.ErrExchangeRateNotFound)
+
+	// Failures other than a missing rate aren't reported as one
+	failure := errors.New("connection refused")
+	_, err = NewConversionService(newMatrixCurrencyRepo(), &fakeRateRepo{latestErr: failure}, "USD").ConvertAmount(ctx, "EUR", "GBP", decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, failure)
+	assert.NotErrorIs(t, err, apperrors.ErrExchangeRateNotFound)
+}
+
+func TestConvertAmountRoundsWithTargetRoundingMode(t *testing.T) {
+	// Converting at a rate of 1 leaves the raw amount to be rounded
+	rates := usdQuotedRates(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), map[string]string{"EUR": "1"})
+
+	amounts := []string{"1.005", "1.015", "1.001", "1.009"}
+	tests := map[money.RoundingMode][]string{
+		money.RoundHalfUp:   {"1.01", "1.02", "1.00", "1.01"},
+		money.RoundHalfEven: {"1.00", "1.02", "1.00", "1.01"},
+		money.RoundDown:     {"1.00", "1.01", "1.00", "1.00"},
+		money.RoundUp:       {"1.01", "1.02", "1.01", "1.01"},
+		// Currencies without a mode use half even
+		"": {"1.00", "1.02", "1.00", "1.01"},
+	}
+	for mode, want := range tests {
+		currencies := newFakeCurrencyRepo(
+			&model.Currency{Code: "USD", Factor: 100, IsActive: true},
+			&model.Currency{Code: "EUR", Factor: 100, RoundingMode: mode, IsActive: true},
+		)
+		svc := NewConversionService(currencies, rates, "")
+
+		for i, amount := range amounts {
+			result, err := svc.ConvertAmount(context.Background(), "USD", "EUR", decimal.RequireFromString(amount))
+			require.NoError(t, err)
+			assert.True(t, decimal.RequireFromString(want[i]).Equal(result.Result), "%q: %s -> %s", mode, amount, result.Result)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+)
+
+// GetCurrencyAudit returns one page of the audit trail of the currency with
+// the given code, oldest change first, along with the total number of
+// changes. The trail covers every currency that had the code, including
+// deleted ones; apperrors.ErrCurrencyNotFound is returned only when there is
+// no trail and no such currency.
+func (s *CurrencyService) GetCurrencyAudit(ctx context.Context, code string, limit, offset int) ([]*model.ChangeLog, int64, error) {
+	total, err := s.currencyRepo.CountChangeLog(ctx, code)
+	if err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		// Currencies created before the change log have no entries
+		if _, err := s.currencyRepo.GetByCode(ctx, code); err != nil {
+			return nil, 0, err
+		}
+		return []*model.ChangeLog{}, 0, nil
+	}
+
+	changes, err := s.currencyRepo.GetChangeLog(ctx, code, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return changes, total, nil
+}
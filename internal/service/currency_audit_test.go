@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCurrencyAuditPagesTheTrail(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := newFakeCurrencyRepo()
+	for i, action := range []string{model.ChangeCreated, model.ChangeUpdated, model.ChangeDeleted} {
+		user := uuid.New()
+		repo.changeLog = append(repo.changeLog, &model.ChangeLog{
+			CurrencyCode: "USD", Action: action, Version: 1, ChangedBy: &user, ChangedAt: start.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	repo.changeLog = append(repo.changeLog, &model.ChangeLog{CurrencyCode: "EUR", Action: model.ChangeCreated})
+	svc := newTestCurrencyService(repo)
+
+	changes, total, err := svc.GetCurrencyAudit(context.Background(), "USD", 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, changes, 2)
+	assert.Equal(t, model.ChangeUpdated, changes[0].Action)
+	assert.Equal(t, model.ChangeDeleted, changes[1].Action)
+}
+
+func TestGetCurrencyAuditWithoutTrail(t *testing.T) {
+	// Currencies created before the change log existed have no entries
+	svc := newTestCurrencyService(newFakeCurrencyRepo(&model.Currency{Code: "USD"}))
+
+	changes, total, err := svc.GetCurrencyAudit(context.Background(), "USD", 10, 0)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, changes)
+
+	_, _, err = svc.GetCurrencyAudit(context.Background(), "XAU", 10, 0)
+	assert.ErrorIs(t, err, apperrors.ErrCurrencyNotFound)
+}
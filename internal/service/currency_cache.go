@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/logging"
+	"github.com/Tarifsiz/go-currency-api/internal/metrics"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+)
+
+// currencyCachePatterns match every currency-related cache key
+var currencyCachePatterns = []string{"currency:code:*", listCachePrefix + "*", countCacheKey}
+
+// listCachePrefix prefixes every currency list cache key
+const listCachePrefix = "currencies:all:"
+
+// countCacheKey holds the total number of currencies
+const countCacheKey = "currencies:count"
+
+// listQuery holds every parameter that affects the result of a currency list
+// query. Any new filter or ordering option must be added here so it becomes
+// part of the cache key.
+type listQuery struct {
+	Limit         int    `json:"limit"`
+	Offset        int    `json:"offset"`
+	Sort          string `json:"sort"`
+	Order         string `json:"order"`
+	CreatedAfter  string `json:"created_after,omitempty"`
+	CreatedBefore string `json:"created_before,omitempty"`
+	UpdatedAfter  string `json:"updated_after,omitempty"`
+	UpdatedBefore string `json:"updated_before,omitempty"`
+	ValidOn       string `json:"valid_on,omitempty"`
+	ActiveOnly    bool   `json:"active_only,omitempty"`
+	VisibleAt     string `json:"visible_at,omitempty"`
+}
+
+// newListQuery builds the cache key parameters of a list query. Time bounds
+// are normalized to UTC so the same instant in different zones shares a key.
+func newListQuery(limit, offset int, sortField, sortOrder string, filter repository.ListFilter) listQuery {
+	bound := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return listQuery{
+		Limit:         limit,
+		Offset:        offset,
+		Sort:          sortField,
+		Order:         sortOrder,
+		CreatedAfter:  bound(filter.CreatedAfter),
+		CreatedBefore: bound(filter.CreatedBefore),
+		UpdatedAfter:  bound(filter.UpdatedAfter),
+		UpdatedBefore: bound(filter.UpdatedBefore),
+		ValidOn:       bound(filter.ValidOn),
+		ActiveOnly:    filter.ActiveOnly,
+		VisibleAt:     bound(filter.VisibleAt),
+	}
+}
+
+// listCacheKey returns a stable cache key for q. The parameters are hashed so
+// the key length stays bounded as filters are added, while keeping the
+// "currencies:all:" prefix that invalidation matches on.
+func listCacheKey(q listQuery) string {
+	// Marshalling a struct is deterministic: fields are emitted in declaration order
+	payload, _ := json.Marshal(q)
+	sum := sha256.Sum256(payload)
+	return listCachePrefix + hex.EncodeToString(sum[:16])
+}
+
+// listCacheLogInterval controls how often (in list lookups) the running list
+// cache hit/miss counters are logged
+const listCacheLogInterval = 1000
+
+// ListCacheStats reports list cache hits and misses since startup
+type ListCacheStats struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// isListPageCacheable reports whether a list page falls within the cached
+// range. Only the first MaxListPages pages of each query are cached so that
+// deep pagination can't grow Redis without bound, and only page sizes the
+// handlers can ask for.
+func (s *CurrencyService) isListPageCacheable(limit, offset int) bool {
+	if !s.cacheEnabled() || limit < 1 || limit > s.pagination.MaxLimit || s.cacheCfg.MaxListPages <= 0 {
+		return false
+	}
+	return offset/limit < s.cacheCfg.MaxListPages
+}
+
+func (s *CurrencyService) recordListCacheHit(ctx context.Context) {
+	s.listCacheHits.Add(1)
+	metrics.ObserveCacheLookup("list", true)
+	logging.FromContext(ctx).Debug("currency list cache hit")
+	s.logListCacheStats(ctx)
+}
+
+func (s *CurrencyService) recordListCacheMiss(ctx context.Context) {
+	s.listCacheMisses.Add(1)
+	metrics.ObserveCacheLookup("list", false)
+	logging.FromContext(ctx).Debug("currency list cache miss")
+	s.logListCacheStats(ctx)
+}
+
+// logListCacheStats periodically logs the hit/miss counters to help tune the cache TTL
+func (s *CurrencyService) logListCacheStats(ctx context.Context) {
+	stats := s.ListCacheStats()
+	if (stats.Hits+stats.Misses)%listCacheLogInterval == 0 {
+		logging.FromContext(ctx).Info("currency list cache stats",
+			"hits", stats.Hits,
+			"misses", stats.Misses,
+			"hit_rate", stats.HitRate,
+		)
+	}
+}
+
+// ListCacheStats returns the list cache hit/miss counters
+func (s *CurrencyService) ListCacheStats() ListCacheStats {
+	stats := ListCacheStats{
+		Hits:   s.listCacheHits.Load(),
+		Misses: s.listCacheMisses.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// CacheRebuildResult reports what RebuildCache did
+type CacheRebuildResult struct {
+	KeysCleared int64  `json:"keys_cleared"`
+	KeysWarmed  int    `json:"keys_warmed"`
+	Duration    string `json:"duration"`
+}
+
+// RebuildCache clears all currency cache keys and re-warms the first list
+// page and the given hot currency codes from the database. It is a no-op when
+// caching is disabled.
+func (s *CurrencyService) RebuildCache(ctx context.Context, hotCodes []string) (*CacheRebuildResult, error) {
+	start := time.Now()
+	result := &CacheRebuildResult{}
+	if !s.cacheEnabled() {
+		result.Duration = time.Since(start).String()
+		return result, nil
+	}
+
+	cleared, err := s.FlushCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.KeysCleared = cleared
+
+	// The default listing leaves out inactive currencies. Warming the
+	// default page size caches the most common list request.
+	if _, err := s.GetAllCurrencies(ctx, s.pagination.DefaultLimit, 0, "code", "asc", repository.ListFilter{ActiveOnly: true}); err != nil {
+		return nil, fmt.Errorf("failed to warm currency list cache: %w", err)
+	}
+	result.KeysWarmed++
+
+	// A configured hot code that is invalid or doesn't exist shouldn't fail
+	// the rebuild, but is logged so the configuration can be fixed
+	for _, raw := range hotCodes {
+		code, err := model.NormalizeCode(raw)
+		if err != nil {
+			logging.FromContext(ctx).Warn("skipping invalid hot currency code", "code", raw, "error", err)
+			continue
+		}
+		if _, err := s.GetCurrencyByCode(ctx, code); err != nil {
+			logging.FromContext(ctx).Warn("failed to warm hot currency", "code", code, "error", err)
+			continue
+		}
+		result.KeysWarmed++
+	}
+
+	result.Duration = time.Since(start).String()
+	return result, nil
+}
+
+// FlushCache deletes every currency cache key, so reads go to the database
+// until the cache is repopulated, and returns the number of keys deleted. It
+// is a no-op when caching is disabled.
+func (s *CurrencyService) FlushCache(ctx context.Context) (int64, error) {
+	if !s.cacheEnabled() {
+		return 0, nil
+	}
+
+	var cleared int64
+	for _, pattern := range currencyCachePatterns {
+		deleted, err := s.deleteKeysByPattern(ctx, pattern)
+		cleared += deleted
+		if err != nil {
+			return cleared, fmt.Errorf("failed to clear cache: %w", err)
+		}
+	}
+	return cleared, nil
+}
+
+// loadOnce runs load for key unless a load of the same key is already in
+// flight, in which case it waits for and shares that result. The load is
+// detached from the caller's cancellation so one request going away doesn't
+// fail every request waiting on it; a cancelled caller just stops waiting.
+func (s *CurrencyService) loadOnce(ctx context.Context, key string, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	results := s.loads.DoChan(key, func() (interface{}, error) {
+		return load(context.WithoutCancel(ctx))
+	})
+
+	select {
+	case result := <-results:
+		return result.Val, result.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// deleteKeysByPattern deletes all keys matching pattern using SCAN so Redis
+// is never blocked, and returns the number of keys deleted
+func (s *CurrencyService) deleteKeysByPattern(ctx context.Context, pattern string) (int64, error) {
+	const batchSize = 100
+
+	var deleted int64
+	batch := make([]string, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := s.redisClient.Del(ctx, batch...).Result()
+		deleted += n
+		batch = batch[:0]
+		return err
+	}
+
+	iter := s.redisClient.Scan(ctx, 0, pattern, batchSize).Iterator()
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, err
+	}
+
+	return deleted, flush()
+}
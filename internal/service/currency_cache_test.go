@@ -0,0 +1,342 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/config"
+	"github.com/Tarifsiz/go-currency-api/internal/logging"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newListTestRepo() *fakeCurrencyRepo {
+	return newFakeCurrencyRepo(
+		&model.Currency{Code: "EUR", Description: "Euro", IsActive: true},
+		&model.Currency{Code: "GBP", Description: "Pound Sterling", IsActive: true},
+		&model.Currency{Code: "USD", Description: "US Dollar", IsActive: true},
+	)
+}
+
+func codesOf(currencies []*model.Currency) []string {
+	codes := make([]string, len(currencies))
+	for i, currency := range currencies {
+		codes[i] = currency.Code
+	}
+	return codes
+}
+
+func TestListCacheKeyDependsOnEveryParameter(t *testing.T) {
+	day := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	nextDay := day.AddDate(0, 0, 1)
+	base := newListQuery(50, 0, "code", "asc", repository.ListFilter{})
+	variants := []listQuery{
+		newListQuery(20, 0, "code", "asc", repository.ListFilter{}),
+		newListQuery(50, 50, "code", "asc", repository.ListFilter{}),
+		newListQuery(50, 0, "description", "asc", repository.ListFilter{}),
+		newListQuery(50, 0, "code", "desc", repository.ListFilter{}),
+		newListQuery(50, 0, "code", "asc", repository.ListFilter{ActiveOnly: true}),
+		newListQuery(50, 0, "code", "asc", repository.ListFilter{CreatedAfter: &day}),
+		newListQuery(50, 0, "code", "asc", repository.ListFilter{CreatedBefore: &day}),
+		newListQuery(50, 0, "code", "asc", repository.ListFilter{UpdatedAfter: &day}),
+		newListQuery(50, 0, "code", "asc", repository.ListFilter{UpdatedBefore: &day}),
+		newListQuery(50, 0, "code", "asc", repository.ListFilter{CreatedAfter: &nextDay}),
+		newListQuery(50, 0, "code", "asc", repository.ListFilter{ValidOn: &day}),
+		newListQuery(50, 0, "code", "asc", repository.ListFilter{ValidOn: &nextDay}),
+	}
+
+	seen := map[string]bool{listCacheKey(base): true}
+	for _, q := range variants {
+		key := listCacheKey(q)
+		assert.False(t, seen[key], "%+v shares a cache key", q)
+		seen[key] = true
+	}
+	assert.Equal(t, listCacheKey(base), listCacheKey(newListQuery(50, 0, "code", "asc", repository.ListFilter{})))
+}
+
+func TestListCacheKeyNormalizesBoundsToUTC(t *testing.T) {
+	utc := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	local := utc.In(time.FixedZone("UTC+1", 60*60))
+
+	assert.Equal(t,
+		listCacheKey(newListQuery(50, 0, "code", "asc", repository.ListFilter{UpdatedAfter: &utc})),
+		listCacheKey(newListQuery(50, 0, "code", "asc", repository.ListFilter{UpdatedAfter: &local})))
+}
+
+func TestGetAllCurrenciesCachesEachSortSeparately(t *testing.T) {
+	repo := newListTestRepo()
+	svc, server := newCachedTestCurrencyService(t, repo)
+	ctx := context.Background()
+
+	ascending, err := svc.GetAllCurrencies(ctx, 10, 0, "code", "asc", repository.ListFilter{})
+	require.NoError(t, err)
+	descending, err := svc.GetAllCurrencies(ctx, 10, 0, "code", "desc", repository.ListFilter{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"EUR", "GBP", "USD"}, codesOf(ascending))
+	assert.Equal(t, []string{"USD", "GBP", "EUR"}, codesOf(descending))
+	assert.Equal(t, 2, repo.GetAllCalls())
+	assert.Len(t, server.Keys(), 2)
+
+	// Both orderings are now served from their own cache entry
+	cached, err := svc.GetAllCurrencies(ctx, 10, 0, "code", "desc", repository.ListFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"USD", "GBP", "EUR"}, codesOf(cached))
+	assert.Equal(t, 2, repo.GetAllCalls())
+}
+
+func TestRecentSortSharesCacheWithUpdatedAtDescending(t *testing.T) {
+	repo := newListTestRepo()
+	svc, server := newCachedTestCurrencyService(t, repo)
+	ctx := context.Background()
+
+	_, err := svc.GetAllCurrencies(ctx, 10, 0, repository.SortRecent, "", repository.ListFilter{})
+	require.NoError(t, err)
+	_, err = svc.GetAllCurrencies(ctx, 10, 0, "updated_at", "desc", repository.ListFilter{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, repo.GetAllCalls())
+	assert.Len(t, server.Keys(), 1)
+}
+
+func TestZeroTTLDisablesCaching(t *testing.T) {
+	repo := newListTestRepo()
+	svc, server := newCachedTestCurrencyService(t, repo)
+	svc.cacheCfg.TTL = 0
+	svc.cacheTimeout = 0
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		currency, err := svc.GetCurrencyByCode(ctx, "USD")
+		require.NoError(t, err)
+		assert.Equal(t, "USD", currency.Code)
+
+		_, err = svc.GetAllCurrencies(ctx, 10, 0, "code", "asc", repository.ListFilter{})
+		require.NoError(t, err)
+	}
+
+	// Every read went to the repository and nothing was written to Redis
+	assert.Equal(t, 2, repo.GetByCodeCalls())
+	assert.Equal(t, 2, repo.GetAllCalls())
+	assert.Empty(t, server.Keys())
+	assert.Equal(t, ListCacheStats{}, svc.ListCacheStats())
+}
+
+func TestListPagesOutsidePageSizeLimitsAreNotCached(t *testing.T) {
+	for _, limit := range []int{0, -5, testPagination.MaxLimit + 1} {
+		repo := newListTestRepo()
+		svc, server := newCachedTestCurrencyService(t, repo)
+		ctx := context.Background()
+
+		for i := 0; i < 2; i++ {
+			_, err := svc.GetAllCurrencies(ctx, limit, 0, "code", "asc", repository.ListFilter{})
+			require.NoError(t, err, "limit %d", limit)
+		}
+
+		assert.Equal(t, 2, repo.GetAllCalls(), "limit %d", limit)
+		assert.Empty(t, server.Keys(), "limit %d", limit)
+	}
+}
+
+func TestListCacheFollowsConfiguredMaxLimit(t *testing.T) {
+	repo := newListTestRepo()
+	svc, server := newCachedTestCurrencyService(t, repo)
+	svc.pagination = config.PaginationConfig{DefaultLimit: 100, MaxLimit: 250}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.GetAllCurrencies(ctx, 250, 0, "code", "asc", repository.ListFilter{})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, repo.GetAllCalls())
+	assert.Len(t, server.Keys(), 1)
+}
+
+func TestRebuildCacheWarmsDefaultPageSize(t *testing.T) {
+	repo := newListTestRepo()
+	svc, _ := newCachedTestCurrencyService(t, repo)
+	svc.pagination = config.PaginationConfig{DefaultLimit: 20, MaxLimit: 100}
+	ctx := context.Background()
+
+	_, err := svc.RebuildCache(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, repo.GetAllCalls())
+
+	currencies, err := svc.GetAllCurrencies(ctx, 20, 0, "code", "asc", repository.ListFilter{ActiveOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EUR", "GBP", "USD"}, codesOf(currencies))
+	assert.Equal(t, 1, repo.GetAllCalls())
+}
+
+func TestRebuildCacheWarmsNormalizedHotCodes(t *testing.T) {
+	repo := newListTestRepo()
+	svc, server := newCachedTestCurrencyService(t, repo)
+	var logs bytes.Buffer
+	ctx := logging.WithLogger(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)))
+
+	result, err := svc.RebuildCache(ctx, []string{" usd", "e.u.r", "US1", "1234", "JPY"})
+	require.NoError(t, err)
+
+	// The list page, USD and EUR
+	assert.Equal(t, 3, result.KeysWarmed)
+	assert.True(t, server.Exists("currency:code:USD"))
+	assert.True(t, server.Exists("currency:code:EUR"))
+	assert.Contains(t, logs.String(), `msg="skipping invalid hot currency code" code=US1`)
+	assert.Contains(t, logs.String(), `msg="skipping invalid hot currency code" code=1234`)
+	assert.Contains(t, logs.String(), `msg="failed to warm hot currency" code=JPY`)
+
+	// Warmed codes are served from the cache
+	calls := repo.GetByCodeCalls()
+	_, err = svc.GetCurrencyByCode(ctx, "USD")
+	require.NoError(t, err)
+	assert.Equal(t, calls, repo.GetByCodeCalls())
+}
+
+func TestInvalidateCacheDeletesEveryListPage(t *testing.T) {
+	svc, server := newCachedTestCurrencyService(t, newListTestRepo())
+	ctx := context.Background()
+
+	// More keys than one delete batch, and not a multiple of it
+	const pages = 250
+	for i := 0; i < pages; i++ {
+		require.NoError(t, server.Set(listCacheKey(newListQuery(10, i*10, "code", "asc", repository.ListFilter{})), "[]"))
+	}
+	require.NoError(t, server.Set("currency:code:USD", "{}"))
+	require.NoError(t, server.Set("currency:code:EUR", "{}"))
+	require.NoError(t, server.Set(countCacheKey, "3"))
+	require.NoError(t, server.Set("unrelated", "1"))
+
+	svc.invalidateCache(ctx, "USD")
+
+	assert.ElementsMatch(t, []string{"currency:code:EUR", "unrelated"}, server.Keys())
+}
+
+func TestDeleteKeysByPatternCountsDeletedKeys(t *testing.T) {
+	svc, server := newCachedTestCurrencyService(t, newListTestRepo())
+
+	for i := 0; i < 321; i++ {
+		require.NoError(t, server.Set(fmt.Sprintf("%spage:%d", listCachePrefix, i), "[]"))
+	}
+	require.NoError(t, server.Set("unrelated", "1"))
+
+	deleted, err := svc.deleteKeysByPattern(context.Background(), listCachePrefix+"*")
+	require.NoError(t, err)
+	assert.Equal(t, int64(321), deleted)
+	assert.Equal(t, []string{"unrelated"}, server.Keys())
+}
+
+// blockingCurrencyRepo holds every GetByCode and GetAll call until release
+// is closed, so concurrent cache misses pile up behind the first load
+type blockingCurrencyRepo struct {
+	*fakeCurrencyRepo
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newBlockingCurrencyRepo(repo *fakeCurrencyRepo) *blockingCurrencyRepo {
+	return &blockingCurrencyRepo{fakeCurrencyRepo: repo, entered: make(chan struct{}, 1), release: make(chan struct{})}
+}
+
+func (r *blockingCurrencyRepo) wait() {
+	select {
+	case r.entered <- struct{}{}:
+	default:
+	}
+	<-r.release
+}
+
+func (r *blockingCurrencyRepo) GetByCode(ctx context.Context, code string) (*model.Currency, error) {
+	r.wait()
+	return r.fakeCurrencyRepo.GetByCode(ctx, code)
+}
+
+func (r *blockingCurrencyRepo) GetAll(ctx context.Context, limit, offset int, sortField, sortOrder string, filter repository.ListFilter) ([]*model.Currency, error) {
+	r.wait()
+	return r.fakeCurrencyRepo.GetAll(ctx, limit, offset, sortField, sortOrder, filter)
+}
+
+// runConcurrently calls fn from n goroutines at once, releasing repo once
+// they have all started and the first has reached it
+func runConcurrently(repo *blockingCurrencyRepo, n int, fn func(i int)) {
+	var started, done sync.WaitGroup
+	started.Add(n)
+	done.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			fn(i)
+		}(i)
+	}
+	started.Wait()
+	<-repo.entered
+	// Give the rest time to join the load in flight
+	time.Sleep(50 * time.Millisecond)
+	close(repo.release)
+	done.Wait()
+}
+
+func TestConcurrentCacheMissesLoadCurrencyOnce(t *testing.T) {
+	const n = 20
+	repo := newBlockingCurrencyRepo(newListTestRepo())
+	svc, _ := newCachedTestCurrencyService(t, repo)
+
+	results := make([]*model.Currency, n)
+	errs := make([]error, n)
+	runConcurrently(repo, n, func(i int) {
+		results[i], errs[i] = svc.GetCurrencyByCode(context.Background(), "USD")
+	})
+
+	assert.Equal(t, 1, repo.GetByCodeCalls())
+	for i := 0; i < n; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, "USD", results[i].Code)
+		for j := 0; j < i; j++ {
+			assert.NotSame(t, results[j], results[i], "callers share a currency")
+		}
+	}
+}
+
+func TestConcurrentCacheMissesOfUnknownCodeLoadOnce(t *testing.T) {
+	const n = 20
+	repo := newBlockingCurrencyRepo(newListTestRepo())
+	svc, server := newCachedTestCurrencyService(t, repo)
+
+	errs := make([]error, n)
+	runConcurrently(repo, n, func(i int) {
+		_, errs[i] = svc.GetCurrencyByCode(context.Background(), "XYZ")
+	})
+
+	assert.Equal(t, 1, repo.GetByCodeCalls())
+	for _, err := range errs {
+		assert.ErrorIs(t, err, apperrors.ErrCurrencyNotFound)
+	}
+	assert.Empty(t, server.Keys())
+}
+
+func TestConcurrentListCacheMissesLoadOnce(t *testing.T) {
+	const n = 20
+	repo := newBlockingCurrencyRepo(newListTestRepo())
+	svc, _ := newCachedTestCurrencyService(t, repo)
+
+	results := make([][]*model.Currency, n)
+	errs := make([]error, n)
+	runConcurrently(repo, n, func(i int) {
+		results[i], errs[i] = svc.GetAllCurrencies(context.Background(), 10, 0, "code", "asc", repository.ListFilter{})
+	})
+
+	assert.Equal(t, 1, repo.GetAllCalls())
+	for i := 0; i < n; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, []string{"EUR", "GBP", "USD"}, codesOf(results[i]))
+	}
+}
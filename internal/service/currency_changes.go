@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+)
+
+// Change types reported by GetCurrencyChanges
+const (
+	ChangeTypeCreated = "created"
+	ChangeTypeUpdated = "updated"
+)
+
+// CurrencyChange is a currency that changed after a point in time, tagged
+// with whether it was created or only updated since then
+type CurrencyChange struct {
+	model.Currency
+	Type string `json:"type"`
+}
+
+// MarshalJSON implements json.Marshaler, keeping the type next to the currency fields
+func (c CurrencyChange) MarshalJSON() ([]byte, error) {
+	return c.Currency.MarshalJSONWith("type", c.Type)
+}
+
+// GetCurrencyChanges returns one page of currencies created or updated after
+// since, oldest change first, along with the total number of changes.
+// Soft-deleted currencies are not included.
+func (s *CurrencyService) GetCurrencyChanges(ctx context.Context, since time.Time, limit, offset int) ([]*CurrencyChange, int64, error) {
+	currencies, err := s.currencyRepo.GetChangedSince(ctx, since, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.currencyRepo.CountChangedSince(ctx, since)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	changes := make([]*CurrencyChange, len(currencies))
+	for i, currency := range currencies {
+		changes[i] = &CurrencyChange{Currency: *currency, Type: ChangeTypeUpdated}
+		if currency.CreatedAt.After(since) {
+			changes[i].Type = ChangeTypeCreated
+		}
+	}
+
+	return changes, total, nil
+}
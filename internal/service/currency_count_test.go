@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRepoWithInactive() *fakeCurrencyRepo {
+	return newFakeCurrencyRepo(
+		&model.Currency{Code: "EUR", Description: "Euro", Factor: 100, IsActive: true},
+		&model.Currency{Code: "JPY", Description: "Yen", Factor: 1, IsActive: true},
+		&model.Currency{Code: "USD", Description: "US Dollar", Factor: 100, IsActive: true},
+		&model.Currency{Code: "DEM", Description: "Deutsche Mark", Factor: 100},
+	)
+}
+
+func TestCountCurrenciesHonoursActiveOnly(t *testing.T) {
+	tests := []struct {
+		name   string
+		search repository.SearchOptions
+		factor int
+		want   int64
+	}{
+		{"active", repository.SearchOptions{ActiveOnly: true}, 0, 3},
+		{"all", repository.SearchOptions{}, 0, 4},
+		{"active by factor", repository.SearchOptions{ActiveOnly: true}, 100, 2},
+		{"all by factor", repository.SearchOptions{}, 100, 3},
+		{"active by term", repository.SearchOptions{Term: "e", ActiveOnly: true}, 0, 2},
+		{"all by term", repository.SearchOptions{Term: "e"}, 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestCurrencyService(newRepoWithInactive())
+
+			count, err := svc.CountCurrencies(context.Background(), tt.search, tt.factor)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, count)
+		})
+	}
+}
+
+func TestCachedCountIncludesInactiveCurrencies(t *testing.T) {
+	svc, server := newCachedTestCurrencyService(t, newRepoWithInactive())
+	ctx := context.Background()
+
+	active, err := svc.CountCurrencies(ctx, repository.SearchOptions{ActiveOnly: true}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), active)
+	assert.False(t, server.Exists(countCacheKey), "active count was cached as the total")
+
+	// Only the count of every currency, hidden ones too, is the cached total
+	all, err := svc.CountCurrencies(ctx, repository.SearchOptions{ShowHidden: true}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), all)
+	cached, err := server.Get(countCacheKey)
+	require.NoError(t, err)
+	assert.Equal(t, "4", cached)
+
+	// The cached total doesn't leak into the active count
+	active, err = svc.CountCurrencies(ctx, repository.SearchOptions{ActiveOnly: true}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), active)
+}
+
+func TestCachedCountInvalidatedOnCreateAndDelete(t *testing.T) {
+	repo := newRepoWithInactive()
+	svc, server := newCachedTestCurrencyService(t, repo)
+	ctx := userContext()
+
+	count := func() int64 {
+		t.Helper()
+		total, err := svc.CountCurrencies(ctx, repository.SearchOptions{ShowHidden: true}, 0)
+		require.NoError(t, err)
+		return total
+	}
+
+	assert.Equal(t, int64(4), count())
+	require.True(t, server.Exists(countCacheKey))
+
+	chf := &model.Currency{ID: uuid.New(), Code: "CHF", Description: "Swiss Franc", Factor: 100}
+	require.NoError(t, svc.CreateCurrency(ctx, chf))
+	assert.False(t, server.Exists(countCacheKey), "creating a currency leaves a stale count")
+	assert.Equal(t, int64(5), count())
+
+	require.NoError(t, svc.DeleteCurrency(ctx, chf.ID))
+	_, err := repo.GetByCode(ctx, "CHF")
+	require.ErrorIs(t, err, apperrors.ErrCurrencyNotFound)
+	assert.False(t, server.Exists(countCacheKey), "deleting a currency leaves a stale count")
+	assert.Equal(t, int64(4), count())
+
+	cached, err := server.Get(countCacheKey)
+	require.NoError(t, err)
+	assert.Equal(t, "4", cached)
+}
+
+func TestGroupedCurrenciesHonourActiveOnly(t *testing.T) {
+	svc := newTestCurrencyService(newRepoWithInactive())
+	ctx := context.Background()
+
+	active, err := svc.GetCurrenciesGroupedByFactor(ctx, repository.SearchOptions{ActiveOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EUR", "USD"}, codesOf(active[100]))
+	assert.Equal(t, []string{"JPY"}, codesOf(active[1]))
+
+	all, err := svc.GetCurrenciesGroupedByFactor(ctx, repository.SearchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DEM", "EUR", "USD"}, codesOf(all[100]))
+	assert.Equal(t, []string{"JPY"}, codesOf(all[1]))
+}
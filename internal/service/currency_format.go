@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/money"
+)
+
+// Separators used when AmountDisplayFormat doesn't specify them
+const (
+	defaultGroupSeparator   = ","
+	defaultDecimalSeparator = "."
+)
+
+// FormattedAmount is the result of formatting a minor-unit amount
+type FormattedAmount struct {
+	Code      string `json:"code"`
+	Amount    int64  `json:"amount"`
+	Formatted string `json:"formatted"`
+}
+
+// FormatCurrencyAmount formats amount (in minor units) for the currency with
+// the given code, using the cached currency lookup
+func (s *CurrencyService) FormatCurrencyAmount(ctx context.Context, code string, amount int64) (*FormattedAmount, error) {
+	currency, err := s.GetCurrencyByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FormattedAmount{
+		Code:      currency.Code,
+		Amount:    amount,
+		Formatted: FormatAmount(currency, amount),
+	}, nil
+}
+
+// FormatPreview is the result of formatting several minor-unit amounts with
+// a currency's display format
+type FormatPreview struct {
+	Code                string            `json:"code"`
+	AmountDisplayFormat string            `json:"amount_display_format"`
+	Factor              int               `json:"factor"`
+	Amounts             []FormattedAmount `json:"amounts"`
+}
+
+// PreviewAmountFormat formats each of amounts (in minor units) for the
+// currency with the given code. A non-empty displayFormat is used in place
+// of the currency's AmountDisplayFormat, so a new format can be checked
+// before it is saved.
+func (s *CurrencyService) PreviewAmountFormat(ctx context.Context, code string, amounts []int64, displayFormat string) (*FormatPreview, error) {
+	currency, err := s.GetCurrencyByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if displayFormat != "" {
+		currency.AmountDisplayFormat = displayFormat
+	}
+
+	preview := &FormatPreview{
+		Code:                currency.Code,
+		AmountDisplayFormat: currency.AmountDisplayFormat,
+		Factor:              currency.Factor,
+		Amounts:             make([]FormattedAmount, len(amounts)),
+	}
+	for i, amount := range amounts {
+		preview.Amounts[i] = FormattedAmount{
+			Code:      currency.Code,
+			Amount:    amount,
+			Formatted: FormatAmount(currency, amount),
+		}
+	}
+	return preview, nil
+}
+
+// FormatAmount renders an amount in minor units using the currency's Factor
+// for the number of decimal places and its AmountDisplayFormat for the
+// separators, e.g. 123456 USD ("###,###.##", factor 100) is "1,234.56" and
+// 1234 JPY (factor 1) is "1,234".
+func FormatAmount(currency *model.Currency, amount int64) string {
+	groupSep, decimalSep := displaySeparators(currency.AmountDisplayFormat)
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+	}
+
+	places := money.DecimalPlaces(currency.Factor)
+	whole, fraction, _ := strings.Cut(money.MinorUnitsToDecimal(amount, currency.Factor).Abs().StringFixed(places), ".")
+
+	whole = groupDigits(whole, groupSep)
+	if places == 0 {
+		return sign + whole
+	}
+	return sign + whole + decimalSep + fraction
+}
+
+// displaySeparators extracts the grouping and decimal separators from a
+// display pattern such as "###,###.##" or "###.###,##". With a single
+// separator, it is the grouping separator when followed by exactly three
+// digit placeholders and the decimal separator otherwise.
+func displaySeparators(pattern string) (group, decimal string) {
+	runes := []rune(pattern)
+	var seps []int
+	for i, r := range runes {
+		if r != '#' && r != '0' {
+			seps = append(seps, i)
+		}
+	}
+	if len(seps) == 0 {
+		return "", defaultDecimalSeparator
+	}
+
+	first, last := runes[seps[0]], runes[seps[len(seps)-1]]
+	switch {
+	case first != last:
+		return string(first), string(last)
+	case len(seps) == 1 && len(runes)-seps[0]-1 != 3:
+		return "", string(first)
+	case string(first) == defaultDecimalSeparator:
+		return string(first), defaultGroupSeparator
+	default:
+		return string(first), defaultDecimalSeparator
+	}
+}
+
+// groupDigits inserts sep between every group of three digits
+func groupDigits(digits, sep string) string {
+	if sep == "" || len(digits) <= 3 {
+		return digits
+	}
+
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
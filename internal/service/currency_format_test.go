@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAmountEdgeAmounts(t *testing.T) {
+	usd := &model.Currency{Code: "USD", Factor: 100, AmountDisplayFormat: "###,###.##"}
+	jpy := &model.Currency{Code: "JPY", Factor: 1, AmountDisplayFormat: "###,###"}
+
+	tests := []struct {
+		currency *model.Currency
+		amount   int64
+		want     string
+	}{
+		{usd, 0, "0.00"},
+		{usd, 1, "0.01"},
+		{usd, 99, "0.99"},
+		{usd, 100, "1.00"},
+		{usd, 100000000, "1,000,000.00"},
+		{usd, -1, "-0.01"},
+		{usd, -123456, "-1,234.56"},
+		{usd, math.MaxInt64, "92,233,720,368,547,758.07"},
+		{usd, math.MinInt64, "-92,233,720,368,547,758.08"},
+		// Zero-decimal currencies have no decimal separator
+		{jpy, 0, "0"},
+		{jpy, 99, "99"},
+		{jpy, 1000000, "1,000,000"},
+		{jpy, -1234, "-1,234"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, FormatAmount(tt.currency, tt.amount), "%s %d", tt.currency.Code, tt.amount)
+	}
+}
+
+func TestFormatAmountDisplayPatterns(t *testing.T) {
+	tests := []struct {
+		format string
+		factor int
+		amount int64
+		want   string
+	}{
+		{"###.###,##", 100, 123456789, "1.234.567,89"},
+		{"### ###,##", 100, -123456789, "-1 234 567,89"},
+		{"###'###.##", 100, 123456789, "1'234'567.89"},
+		{"###,###.###", 1000, 1234567, "1,234.567"},
+		{"###,###.###", 1000, -5, "-0.005"},
+		// A single separator followed by three placeholders groups digits
+		{"###,###", 1, 1234567, "1,234,567"},
+		{"###.###", 1, 1234567, "1.234.567"},
+		// Otherwise it is the decimal separator
+		{"#,##", 100, 123456, "1234,56"},
+		{"", 100, 123456, "1234.56"},
+	}
+
+	for _, tt := range tests {
+		currency := &model.Currency{Code: "TST", Factor: tt.factor, AmountDisplayFormat: tt.format}
+		assert.Equal(t, tt.want, FormatAmount(currency, tt.amount), "%q %d", tt.format, tt.amount)
+	}
+}
+
+func TestFormatCurrencyAmount(t *testing.T) {
+	svc := newTestCurrencyService(newFakeCurrencyRepo(
+		&model.Currency{Code: "JPY", Factor: 1, AmountDisplayFormat: "###,###"},
+		&model.Currency{Code: "KWD", Factor: 1000, AmountDisplayFormat: "###,###.###"},
+	))
+	ctx := context.Background()
+
+	formatted, err := svc.FormatCurrencyAmount(ctx, "JPY", 1234)
+	require.NoError(t, err)
+	assert.Equal(t, &FormattedAmount{Code: "JPY", Amount: 1234, Formatted: "1,234"}, formatted)
+
+	formatted, err = svc.FormatCurrencyAmount(ctx, "KWD", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "0.000", formatted.Formatted)
+
+	_, err = svc.FormatCurrencyAmount(ctx, "XYZ", 1)
+	assert.ErrorIs(t, err, apperrors.ErrCurrencyNotFound)
+}
+
+func TestPreviewAmountFormat(t *testing.T) {
+	svc := newTestCurrencyService(newFakeCurrencyRepo(
+		&model.Currency{Code: "EUR", Factor: 100, AmountDisplayFormat: "###,###.##"},
+		&model.Currency{Code: "JPY", Factor: 1, AmountDisplayFormat: "###,###"},
+	))
+	ctx := context.Background()
+
+	preview, err := svc.PreviewAmountFormat(ctx, "EUR", []int64{0, 1, 99, -150, 100000000}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "###,###.##", preview.AmountDisplayFormat)
+	assert.Equal(t, 100, preview.Factor)
+	assert.Equal(t, []string{"0.00", "0.01", "0.99", "-1.50", "1,000,000.00"}, formattedAmounts(preview))
+
+	// A new format is previewed without being saved
+	preview, err = svc.PreviewAmountFormat(ctx, "EUR", []int64{0, -150, 100000000}, "###.###,##")
+	require.NoError(t, err)
+	assert.Equal(t, "###.###,##", preview.AmountDisplayFormat)
+	assert.Equal(t, []string{"0,00", "-1,50", "1.000.000,00"}, formattedAmounts(preview))
+	currency, err := svc.GetCurrencyByCode(ctx, "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, "###,###.##", currency.AmountDisplayFormat)
+
+	preview, err = svc.PreviewAmountFormat(ctx, "JPY", []int64{0, -5, 1000000}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0", "-5", "1,000,000"}, formattedAmounts(preview))
+
+	_, err = svc.PreviewAmountFormat(ctx, "XYZ", []int64{0}, "")
+	assert.ErrorIs(t, err, apperrors.ErrCurrencyNotFound)
+}
+
+func formattedAmounts(preview *FormatPreview) []string {
+	formatted := make([]string, len(preview.Amounts))
+	for i, amount := range preview.Amounts {
+		formatted[i] = amount.Formatted
+	}
+	return formatted
+}
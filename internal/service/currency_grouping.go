@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+)
+
+// GetCurrenciesGroupedByFactor returns all currencies grouped by factor, each
+// group ordered by code. A non-empty search term restricts the grouping to
+// matching currencies, and with search.ActiveOnly set inactive currencies are
+// left out. Factors without currencies are not present.
+func (s *CurrencyService) GetCurrenciesGroupedByFactor(ctx context.Context, search repository.SearchOptions) (map[int][]*model.Currency, error) {
+	var currencies []*model.Currency
+	var err error
+	search = s.visibleSearch(search)
+	if search.Term != "" {
+		currencies, err = s.currencyRepo.SearchByName(ctx, search, 0, 0)
+	} else {
+		currencies, err = s.currencyRepo.GetAll(ctx, 0, 0, "code", "asc", repository.ListFilter{ActiveOnly: search.ActiveOnly, VisibleAt: search.VisibleAt})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[int][]*model.Currency)
+	for _, currency := range currencies {
+		groups[currency.Factor] = append(groups[currency.Factor], currency)
+	}
+	return groups, nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+)
+
+func newGroupingTestRepo() *fakeCurrencyRepo {
+	return newFakeCurrencyRepo(
+		&model.Currency{Code: "USD", Description: "US Dollar", Factor: 100, IsActive: true},
+		&model.Currency{Code: "EUR", Description: "Euro", Factor: 100, IsActive: true},
+		&model.Currency{Code: "CAD", Description: "Canadian Dollar", Factor: 100, IsActive: true},
+		&model.Currency{Code: "JPY", Description: "Japanese Yen", Factor: 1, IsActive: true},
+		&model.Currency{Code: "KRW", Description: "South Korean Won", Factor: 1, IsActive: true},
+		&model.Currency{Code: "KWD", Description: "Kuwaiti Dinar", Factor: 1000, IsActive: true},
+		&model.Currency{Code: "BHD", Description: "Bahraini Dinar", Factor: 1000, IsActive: true},
+		&model.Currency{Code: "DEM", Description: "Deutsche Mark", Factor: 100},
+	)
+}
+
+// groupCodes returns the codes of every group in order
+func groupCodes(groups map[int][]*model.Currency) map[int][]string {
+	codes := make(map[int][]string, len(groups))
+	for factor, currencies := range groups {
+		codes[factor] = codesOf(currencies)
+	}
+	return codes
+}
+
+func TestGetCurrenciesGroupedByFactor(t *testing.T) {
+	svc := newTestCurrencyService(newGroupingTestRepo())
+	ctx := context.Background()
+
+	groups, err := svc.GetCurrenciesGroupedByFactor(ctx, repository.SearchOptions{ActiveOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]string{
+		1:    {"JPY", "KRW"},
+		100:  {"CAD", "EUR", "USD"},
+		1000: {"BHD", "KWD"},
+	}, groupCodes(groups))
+
+	// Inactive currencies are grouped on request
+	groups, err = svc.GetCurrenciesGroupedByFactor(ctx, repository.SearchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CAD", "DEM", "EUR", "USD"}, codesOf(groups[100]))
+}
+
+func TestGetCurrenciesGroupedByFactorAppliesSearch(t *testing.T) {
+	svc := newTestCurrencyService(newGroupingTestRepo())
+	ctx := context.Background()
+
+	// Factors without a match are left out rather than empty
+	groups, err := svc.GetCurrenciesGroupedByFactor(ctx, repository.SearchOptions{Term: "dinar", ActiveOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]string{1000: {"BHD", "KWD"}}, groupCodes(groups))
+
+	groups, err = svc.GetCurrenciesGroupedByFactor(ctx, repository.SearchOptions{
+		Term: "k", Field: repository.SearchFieldCode, Match: repository.SearchMatchPrefix, ActiveOnly: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]string{1: {"KRW"}, 1000: {"KWD"}}, groupCodes(groups))
+
+	groups, err = svc.GetCurrenciesGroupedByFactor(ctx, repository.SearchOptions{Term: "peso", ActiveOnly: true})
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+}
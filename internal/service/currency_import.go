@@ -0,0 +1,354 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/auth"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/money"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Import file formats accepted by ParseCurrencyImport
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatJSON = "json"
+)
+
+// Row statuses reported in an ImportSummary
+const (
+	ImportStatusCreated = "created"
+	ImportStatusUpdated = "updated"
+	ImportStatusSkipped = "skipped"
+	ImportStatusError   = "error"
+)
+
+// ErrMalformedImport is returned when an import file can't be parsed at all.
+// The whole file is rejected and nothing is written.
+var ErrMalformedImport = errors.New("malformed import file")
+
+// csvImportColumns maps the accepted CSV header names to whether they are required
+var csvImportColumns = map[string]bool{
+	"code":                  true,
+	"description":           true,
+	"factor":                false,
+	"numeric_code":          false,
+	"amount_display_format": false,
+	"html_encoded_symbol":   false,
+	"rounding_mode":         false,
+}
+
+// ImportRow is one parsed row of an import file. Err is set when the row
+// itself is unusable, e.g. a non-numeric factor, without the file being malformed.
+type ImportRow struct {
+	Line     int
+	Currency *model.Currency
+	Err      error
+}
+
+// ImportRowResult reports what happened to one row of an import
+type ImportRowResult struct {
+	Line   int    `json:"line"`
+	Code   string `json:"code"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportSummary reports the outcome of an import
+type ImportSummary struct {
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Skipped int               `json:"skipped"`
+	Errored int               `json:"errored"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// importCurrency is the JSON shape of one imported currency
+type importCurrency struct {
+	Code                string `json:"code"`
+	NumericCode         string `json:"numeric_code"`
+	Description         string `json:"description"`
+	AmountDisplayFormat string `json:"amount_display_format"`
+	HtmlEncodedSymbol   string `json:"html_encoded_symbol"`
+	Factor              int    `json:"factor"`
+	RoundingMode        string `json:"rounding_mode"`
+}
+
+// ParseCurrencyImport parses a CSV (with a header row) or JSON (array of
+// objects) import file into rows annotated with their line numbers
+func ParseCurrencyImport(format string, r io.Reader) ([]ImportRow, error) {
+	switch format {
+	case ImportFormatCSV:
+		return parseCSVImport(r)
+	case ImportFormatJSON:
+		return parseJSONImport(r)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrMalformedImport, format)
+	}
+}
+
+func parseCSVImport(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read CSV header: %v", ErrMalformedImport, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := csvImportColumns[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown CSV column %q", ErrMalformedImport, name)
+		}
+		columns[name] = i
+	}
+	for name, required := range csvImportColumns {
+		if _, ok := columns[name]; required && !ok {
+			return nil, fmt.Errorf("%w: missing required CSV column %q", ErrMalformedImport, name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Covers wrong field counts and broken quoting
+			return nil, fmt.Errorf("%w: %v", ErrMalformedImport, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := ImportRow{
+			Line: line,
+			Currency: &model.Currency{
+				Code:                field(record, "code"),
+				NumericCode:         field(record, "numeric_code"),
+				Description:         field(record, "description"),
+				AmountDisplayFormat: field(record, "amount_display_format"),
+				HtmlEncodedSymbol:   field(record, "html_encoded_symbol"),
+				RoundingMode:        money.RoundingMode(field(record, "rounding_mode")),
+			},
+		}
+		if factor := field(record, "factor"); factor != "" {
+			row.Currency.Factor, err = strconv.Atoi(factor)
+			if err != nil {
+				row.Err = fmt.Errorf("%w: factor %q is not a number", ErrInvalidCurrency, factor)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseJSONImport(r io.Reader) ([]ImportRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import file: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, fmt.Errorf("%w: JSON import must be an array of currencies", ErrMalformedImport)
+	}
+
+	var rows []ImportRow
+	for dec.More() {
+		line := lineAt(data, dec.InputOffset())
+
+		var item importCurrency
+		if err := dec.Decode(&item); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrMalformedImport, line, err)
+		}
+
+		rows = append(rows, ImportRow{
+			Line: line,
+			Currency: &model.Currency{
+				Code:                item.Code,
+				NumericCode:         item.NumericCode,
+				Description:         strings.TrimSpace(item.Description),
+				AmountDisplayFormat: item.AmountDisplayFormat,
+				HtmlEncodedSymbol:   item.HtmlEncodedSymbol,
+				Factor:              item.Factor,
+				RoundingMode:        money.RoundingMode(item.RoundingMode),
+			},
+		})
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedImport, err)
+	}
+
+	return rows, nil
+}
+
+// lineAt returns the 1-based line of the first value at or after offset,
+// skipping the whitespace and comma separating array elements
+func lineAt(data []byte, offset int64) int {
+	i := int(offset)
+	for i < len(data) && strings.ContainsRune(" \t\r\n,", rune(data[i])) {
+		i++
+	}
+	return bytes.Count(data[:i], []byte("\n")) + 1
+}
+
+// ImportCurrencies validates every row and then creates the new currencies
+// in one batch. Rows whose code already exists are skipped, or updated in
+// upsert mode. Invalid rows are reported and left out; nothing is written
+// for them.
+func (s *CurrencyService) ImportCurrencies(ctx context.Context, rows []ImportRow, upsert bool) (*ImportSummary, error) {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	summary := &ImportSummary{Rows: make([]ImportRowResult, len(rows))}
+	seen := make(map[string]int, len(rows))
+	codes := make([]string, 0, len(rows))
+
+	// Validate every row before touching the database
+	for i, row := range rows {
+		result := &summary.Rows[i]
+
+		err := row.Err
+		if err == nil {
+			err = normalizeCurrencyCode(row.Currency)
+		}
+		*result = ImportRowResult{Line: row.Line, Code: row.Currency.Code}
+		if err == nil {
+			err = s.validateNew(row.Currency)
+		}
+		if err == nil {
+			err = s.applyDefaults(ctx, row.Currency)
+		}
+		if err == nil {
+			if first, dup := seen[row.Currency.Code]; dup {
+				err = fmt.Errorf("%w: code %s already appears on line %d", apperrors.ErrDuplicateCurrency, row.Currency.Code, first)
+			}
+		}
+		if err != nil {
+			result.Status, result.Error = ImportStatusError, err.Error()
+			continue
+		}
+
+		seen[row.Currency.Code] = row.Line
+		codes = append(codes, row.Currency.Code)
+	}
+
+	existing, err := s.currencyRepo.GetByCodes(ctx, codes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing currencies: %w", err)
+	}
+	present := make(map[string]bool, len(existing))
+	for _, currency := range existing {
+		present[model.CanonicalCode(currency.Code)] = true
+	}
+
+	var creates []*model.Currency
+	var createIdx []int
+	var updates []repository.CurrencyFieldUpdate
+	var updateIdx []int
+	for i, row := range rows {
+		if summary.Rows[i].Status == ImportStatusError {
+			continue
+		}
+		code := row.Currency.Code
+		switch {
+		case !present[code]:
+			creates = append(creates, row.Currency)
+			createIdx = append(createIdx, i)
+		case upsert:
+			updates = append(updates, repository.CurrencyFieldUpdate{Code: code, Fields: importFields(row.Currency, userID)})
+			updateIdx = append(updateIdx, i)
+		default:
+			summary.Rows[i].Status = ImportStatusSkipped
+			summary.Rows[i].Error = "currency already exists"
+		}
+	}
+
+	if len(creates) > 0 {
+		if err := s.checkCurrencyLimit(ctx, len(creates)); err != nil {
+			return nil, err
+		}
+		if err := s.currencyRepo.CreateBatch(ctx, creates); err != nil {
+			var batchErr *repository.BatchError
+			if errors.As(err, &batchErr) {
+				return nil, fmt.Errorf("failed to import line %d: %w", rows[createIdx[batchErr.Index]].Line, batchErr.Err)
+			}
+			return nil, fmt.Errorf("failed to import currencies: %w", err)
+		}
+		for _, i := range createIdx {
+			summary.Rows[i].Status = ImportStatusCreated
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := s.currencyRepo.UpdateFieldsBatch(ctx, updates); err != nil {
+			var batchErr *repository.BatchError
+			if errors.As(err, &batchErr) {
+				return nil, fmt.Errorf("failed to import line %d: %w", rows[updateIdx[batchErr.Index]].Line, batchErr.Err)
+			}
+			return nil, fmt.Errorf("failed to update imported currencies: %w", err)
+		}
+		for _, i := range updateIdx {
+			summary.Rows[i].Status = ImportStatusUpdated
+		}
+	}
+
+	for _, result := range summary.Rows {
+		switch result.Status {
+		case ImportStatusCreated:
+			summary.Created++
+		case ImportStatusUpdated:
+			summary.Updated++
+		case ImportStatusSkipped:
+			summary.Skipped++
+		case ImportStatusError:
+			summary.Errored++
+		}
+	}
+	for _, i := range createIdx {
+		s.invalidateCache(ctx, rows[i].Currency.Code)
+	}
+	for _, i := range updateIdx {
+		s.invalidateCache(ctx, rows[i].Currency.Code)
+	}
+
+	return summary, nil
+}
+
+// importFields returns the columns overwritten when upserting an imported currency
+func importFields(currency *model.Currency, updatedBy uuid.UUID) map[string]interface{} {
+	return map[string]interface{}{
+		"numeric_code":          currency.NumericCode,
+		"description":           currency.Description,
+		"amount_display_format": currency.AmountDisplayFormat,
+		"html_encoded_symbol":   currency.HtmlEncodedSymbol,
+		"factor":                currency.Factor,
+		"rounding_mode":         currency.RoundingMode,
+		"updated_by":            updatedBy,
+	}
+}
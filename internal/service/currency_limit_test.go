@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLimitedTestCurrencyService returns a service capped at max currencies
+// over a repository already holding EUR and GBP
+func newLimitedTestCurrencyService(max int) (*CurrencyService, *fakeCurrencyRepo) {
+	repo := newFakeCurrencyRepo(
+		&model.Currency{Code: "EUR", Description: "Euro", IsActive: true},
+		&model.Currency{Code: "GBP", Description: "Pound Sterling", IsActive: true},
+	)
+	svc := newTestCurrencyService(repo)
+	svc.cfg.MaxCurrencies = max
+	return svc, repo
+}
+
+func newCurrency(code string) *model.Currency {
+	return &model.Currency{Code: code, Description: code + " currency"}
+}
+
+func TestCreateCurrencyUpToLimit(t *testing.T) {
+	svc, repo := newLimitedTestCurrencyService(3)
+
+	require.NoError(t, svc.CreateCurrency(userContext(), newCurrency("USD")))
+
+	err := svc.CreateCurrency(userContext(), newCurrency("JPY"))
+	require.ErrorIs(t, err, ErrCurrencyLimitReached)
+	assert.Contains(t, err.Error(), "at most 3 currencies are allowed")
+	count, _ := repo.GetCount(userContext())
+	assert.Equal(t, int64(3), count)
+}
+
+func TestCreateCurrencyBeyondLimit(t *testing.T) {
+	// Lowering the cap below the current count blocks creates, it doesn't
+	// remove anything
+	svc, repo := newLimitedTestCurrencyService(1)
+
+	assert.ErrorIs(t, svc.CreateCurrency(userContext(), newCurrency("USD")), ErrCurrencyLimitReached)
+	count, _ := repo.GetCount(userContext())
+	assert.Equal(t, int64(2), count)
+}
+
+func TestZeroLimitIsUnlimited(t *testing.T) {
+	svc, repo := newLimitedTestCurrencyService(0)
+
+	for _, code := range []string{"USD", "JPY", "CHF", "AUD"} {
+		require.NoError(t, svc.CreateCurrency(userContext(), newCurrency(code)), code)
+	}
+	count, _ := repo.GetCount(userContext())
+	assert.Equal(t, int64(6), count)
+}
+
+func TestCreateCurrenciesBatchUpToLimit(t *testing.T) {
+	svc, repo := newLimitedTestCurrencyService(4)
+
+	results, err := svc.CreateCurrenciesBatch(userContext(), []*model.Currency{newCurrency("USD"), newCurrency("JPY")})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	count, _ := repo.GetCount(userContext())
+	assert.Equal(t, int64(4), count)
+}
+
+func TestCreateCurrenciesBatchCrossingLimitCreatesNothing(t *testing.T) {
+	svc, repo := newLimitedTestCurrencyService(4)
+
+	// One more would fit, but the batch is checked as a whole
+	_, err := svc.CreateCurrenciesBatch(userContext(), []*model.Currency{newCurrency("USD"), newCurrency("JPY"), newCurrency("CHF")})
+	require.ErrorIs(t, err, ErrCurrencyLimitReached)
+	assert.Contains(t, err.Error(), "at most 4 currencies are allowed")
+
+	count, _ := repo.GetCount(userContext())
+	assert.Equal(t, int64(2), count)
+	for _, code := range []string{"USD", "JPY", "CHF"} {
+		_, err := repo.GetByCode(userContext(), code)
+		assert.Error(t, err, code)
+	}
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/auth"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/money"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrBatchFailed is returned when an atomic batch operation was rolled back
+	ErrBatchFailed = errors.New("batch operation failed")
+	// ErrDuplicateCodes is returned when a batch names the same currency more than once
+	ErrDuplicateCodes = errors.New("duplicate currency codes in batch")
+)
+
+// CurrencyPatch is a partial update of the currency identified by Code.
+// Nil fields are left unchanged; non-nil fields are written even when empty,
+// so optional fields can be cleared. The patch only applies if the currency
+// is still at Version.
+type CurrencyPatch struct {
+	Code                string
+	Version             int
+	Description         *string
+	AmountDisplayFormat *string
+	HtmlEncodedSymbol   *string
+	Factor              *int
+	RoundingMode        *money.RoundingMode
+}
+
+// BatchItemResult reports the outcome of one item of a batch operation
+type BatchItemResult struct {
+	Index   int    `json:"index"`
+	Code    string `json:"code"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// apply copies the fields present in the patch onto currency
+func (p CurrencyPatch) apply(currency *model.Currency) {
+	if p.Description != nil {
+		currency.Description = *p.Description
+	}
+	if p.AmountDisplayFormat != nil {
+		currency.AmountDisplayFormat = *p.AmountDisplayFormat
+	}
+	if p.HtmlEncodedSymbol != nil {
+		currency.HtmlEncodedSymbol = *p.HtmlEncodedSymbol
+	}
+	if p.Factor != nil {
+		currency.Factor = *p.Factor
+	}
+	if p.RoundingMode != nil {
+		currency.RoundingMode = *p.RoundingMode
+	}
+}
+
+// update returns the repository update for the patch, recording updatedBy
+// as the user who made the change
+func (p CurrencyPatch) update(updatedBy uuid.UUID) repository.CurrencyFieldUpdate {
+	return repository.CurrencyFieldUpdate{Code: p.Code, Version: p.Version, Fields: p.fields(updatedBy)}
+}
+
+// fields returns the columns present in the patch for a map-based update,
+// recording updatedBy as the user who made the change
+func (p CurrencyPatch) fields(updatedBy uuid.UUID) map[string]interface{} {
+	fields := map[string]interface{}{"updated_by": updatedBy}
+	if p.Description != nil {
+		fields["description"] = *p.Description
+	}
+	if p.AmountDisplayFormat != nil {
+		fields["amount_display_format"] = *p.AmountDisplayFormat
+	}
+	if p.HtmlEncodedSymbol != nil {
+		fields["html_encoded_symbol"] = *p.HtmlEncodedSymbol
+	}
+	if p.Factor != nil {
+		fields["factor"] = *p.Factor
+	}
+	if p.RoundingMode != nil {
+		fields["rounding_mode"] = *p.RoundingMode
+	}
+	return fields
+}
+
+// PatchCurrency applies a partial update to a single currency, writing only
+// the fields present in the patch, and returns the updated currency
+func (s *CurrencyService) PatchCurrency(ctx context.Context, patch CurrencyPatch) (*model.Currency, error) {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	existing, err := s.currencyRepo.GetByCodes(ctx, []string{patch.Code})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load currency for update: %w", err)
+	}
+	if len(existing) == 0 {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrCurrencyNotFound, patch.Code)
+	}
+	if err := s.validatePatch(existing[0], patch); err != nil {
+		return nil, err
+	}
+
+	if err := s.currencyRepo.UpdateFields(ctx, patch.update(userID)); err != nil {
+		return nil, fmt.Errorf("failed to patch currency: %w", err)
+	}
+
+	s.invalidateCache(ctx, patch.Code)
+
+	return s.currencyRepo.GetByCode(ctx, patch.Code)
+}
+
+// PatchCurrencies applies partial updates to several currencies. Every patch
+// is validated against the stored currency first. In atomic mode nothing is
+// written unless all patches succeed; otherwise each patch is applied on its
+// own and failures are reported per item.
+func (s *CurrencyService) PatchCurrencies(ctx context.Context, patches []CurrencyPatch, atomic bool) ([]BatchItemResult, error) {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	codes := make([]string, len(patches))
+	for i, patch := range patches {
+		codes[i] = patch.Code
+	}
+	// Two patches of one currency would conflict on its version
+	if err := checkDuplicateCodes(codes); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.currencyRepo.GetByCodes(ctx, codes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load currencies for batch update: %w", err)
+	}
+	byCode := make(map[string]*model.Currency, len(existing))
+	for _, currency := range existing {
+		byCode[model.CanonicalCode(currency.Code)] = currency
+	}
+
+	results := make([]BatchItemResult, len(patches))
+	failed := false
+	for i, patch := range patches {
+		results[i] = BatchItemResult{Index: i, Code: patch.Code}
+		if err := s.validatePatch(byCode[patch.Code], patch); err != nil {
+			results[i].Error = err.Error()
+			failed = true
+		}
+	}
+
+	if atomic {
+		if failed {
+			markRolledBack(results, "")
+			return results, ErrBatchFailed
+		}
+
+		updates := make([]repository.CurrencyFieldUpdate, len(patches))
+		for i, patch := range patches {
+			updates[i] = patch.update(userID)
+		}
+		if err := s.currencyRepo.UpdateFieldsBatch(ctx, updates); err != nil {
+			var batchErr *repository.BatchError
+			if !errors.As(err, &batchErr) {
+				return nil, fmt.Errorf("failed to update currencies: %w", err)
+			}
+			results[batchErr.Index].Error = batchErr.Err.Error()
+			markRolledBack(results, "")
+			return results, fmt.Errorf("%w: %v", ErrBatchFailed, err)
+		}
+
+		for i := range results {
+			results[i].Success = true
+		}
+	} else {
+		for i, patch := range patches {
+			if results[i].Error != "" {
+				continue
+			}
+			if err := s.currencyRepo.UpdateFields(ctx, patch.update(userID)); err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+			results[i].Success = true
+		}
+	}
+
+	for _, result := range results {
+		if result.Success {
+			s.invalidateCache(ctx, result.Code)
+		}
+	}
+
+	return results, nil
+}
+
+// validatePatch checks that the patched currency would still be valid
+func (s *CurrencyService) validatePatch(currency *model.Currency, patch CurrencyPatch) error {
+	if currency == nil {
+		return fmt.Errorf("%w with code %s", apperrors.ErrCurrencyNotFound, patch.Code)
+	}
+	// Fail fast; the repository re-checks the version when writing
+	if currency.Version != patch.Version {
+		return fmt.Errorf("%w: currency %s is at version %d, not %d", apperrors.ErrStaleUpdate, patch.Code, currency.Version, patch.Version)
+	}
+
+	// Unlike the text fields, the factor and rounding mode can't be cleared
+	if patch.Factor != nil && *patch.Factor <= 0 {
+		return fmt.Errorf("%w: factor must be a positive power of ten", ErrInvalidCurrency)
+	}
+	if patch.RoundingMode != nil && *patch.RoundingMode == "" {
+		return fmt.Errorf("%w: rounding mode can't be empty", ErrInvalidCurrency)
+	}
+
+	patched := *currency
+	patch.apply(&patched)
+	return s.validator.Validate(&patched)
+}
+
+// checkDuplicateCodes returns ErrDuplicateCodes naming every code that
+// appears more than once, along with the items it appears at
+func checkDuplicateCodes(codes []string) error {
+	seen := make(map[string]int, len(codes))
+	var duplicates []string
+	for i, code := range codes {
+		if first, dup := seen[code]; dup {
+			duplicates = append(duplicates, fmt.Sprintf("%s (items %d and %d)", code, first, i))
+			continue
+		}
+		seen[code] = i
+	}
+
+	if len(duplicates) > 0 {
+		return fmt.Errorf("%w: %s", ErrDuplicateCodes, strings.Join(duplicates, ", "))
+	}
+	return nil
+}
+
+// markRolledBack flags every item without its own error as not applied
+func markRolledBack(results []BatchItemResult, cause string) {
+	message := "not applied: batch rolled back"
+	if cause != "" {
+		message += ": " + cause
+	}
+	for i := range results {
+		results[i].Success = false
+		if results[i].Error == "" {
+			results[i].Error = message
+		}
+	}
+}
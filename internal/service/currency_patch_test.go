@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPatchTestRepo() *fakeCurrencyRepo {
+	return newFakeCurrencyRepo(
+		&model.Currency{Code: "USD", Description: "US Dollar", Factor: 100, Version: 1, IsActive: true},
+		&model.Currency{Code: "EUR", Description: "Euro", Factor: 100, Version: 1, IsActive: true},
+	)
+}
+
+func describe(code string, version int, description string) CurrencyPatch {
+	return CurrencyPatch{Code: code, Version: version, Description: &description}
+}
+
+func TestPatchCurrenciesRejectsDuplicateCodes(t *testing.T) {
+	repo := newPatchTestRepo()
+	svc := newTestCurrencyService(repo)
+
+	results, err := svc.PatchCurrencies(userContext(), []CurrencyPatch{
+		describe("USD", 1, "Dollar"),
+		describe("EUR", 1, "Euro area euro"),
+		describe("USD", 1, "United States dollar"),
+	}, true)
+
+	require.ErrorIs(t, err, ErrDuplicateCodes)
+	assert.Contains(t, err.Error(), "USD (items 0 and 2)")
+	assert.Nil(t, results)
+	assert.Equal(t, "US Dollar", repo.currencies["USD"].Description)
+}
+
+func TestPatchCurrenciesAtomicRollsBackInvalidBatch(t *testing.T) {
+	repo := newPatchTestRepo()
+	svc := newTestCurrencyService(repo)
+
+	results, err := svc.PatchCurrencies(userContext(), []CurrencyPatch{
+		describe("USD", 1, "Dollar"),
+		describe("EUR", 3, "Euro area euro"), // stale
+	}, true)
+
+	require.ErrorIs(t, err, ErrBatchFailed)
+	require.Len(t, results, 2)
+	assert.False(t, results[0].Success)
+	assert.Contains(t, results[0].Error, "rolled back")
+	assert.False(t, results[1].Success)
+	assert.Contains(t, results[1].Error, "version")
+	assert.Equal(t, "US Dollar", repo.currencies["USD"].Description)
+}
+
+func TestPatchCurrenciesAtomicReportsFailingItem(t *testing.T) {
+	repo := newPatchTestRepo()
+	repo.updateErrs = map[string]error{"EUR": errors.New("connection reset")}
+	svc := newTestCurrencyService(repo)
+
+	results, err := svc.PatchCurrencies(userContext(), []CurrencyPatch{
+		describe("USD", 1, "Dollar"),
+		describe("EUR", 1, "Euro area euro"),
+	}, true)
+
+	require.ErrorIs(t, err, ErrBatchFailed)
+	require.Len(t, results, 2)
+	assert.Equal(t, "not applied: batch rolled back", results[0].Error)
+	assert.Equal(t, "connection reset", results[1].Error)
+	assert.Equal(t, "US Dollar", repo.currencies["USD"].Description)
+}
+
+func TestPatchCurrenciesBestEffortAppliesValidItems(t *testing.T) {
+	repo := newPatchTestRepo()
+	svc := newTestCurrencyService(repo)
+
+	results, err := svc.PatchCurrencies(userContext(), []CurrencyPatch{
+		describe("USD", 1, "Dollar"),
+		describe("GBP", 1, "Pound"), // missing
+		describe("EUR", 3, "Euro area euro"),
+	}, false)
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Success)
+	assert.False(t, results[1].Success)
+	assert.Contains(t, results[1].Error, "not found")
+	assert.False(t, results[2].Success)
+	assert.Equal(t, "Dollar", repo.currencies["USD"].Description)
+	assert.Equal(t, 2, repo.currencies["USD"].Version)
+	assert.Equal(t, "Euro", repo.currencies["EUR"].Description)
+}
+
+func TestPatchCurrenciesAtomicAppliesValidBatch(t *testing.T) {
+	repo := newPatchTestRepo()
+	svc := newTestCurrencyService(repo)
+
+	results, err := svc.PatchCurrencies(userContext(), []CurrencyPatch{
+		describe("USD", 1, "Dollar"),
+		describe("EUR", 1, "Euro area euro"),
+	}, true)
+
+	require.NoError(t, err)
+	for _, result := range results {
+		assert.True(t, result.Success, result.Error)
+	}
+	assert.Equal(t, "Dollar", repo.currencies["USD"].Description)
+	assert.Equal(t, "Euro area euro", repo.currencies["EUR"].Description)
+}
+
+func TestPatchCurrencyClearsSymbolAndKeepsDescription(t *testing.T) {
+	repo := newFakeCurrencyRepo(&model.Currency{
+		Code: "USD", Description: "US Dollar", HtmlEncodedSymbol: "&#36;", Factor: 100, Version: 1, IsActive: true,
+	})
+	svc := newTestCurrencyService(repo)
+
+	empty := ""
+	currency, err := svc.PatchCurrency(userContext(), CurrencyPatch{Code: "USD", Version: 1, HtmlEncodedSymbol: &empty})
+
+	require.NoError(t, err)
+	assert.Equal(t, "", currency.HtmlEncodedSymbol)
+	assert.Equal(t, "US Dollar", currency.Description)
+	assert.Equal(t, 100, currency.Factor)
+	assert.Equal(t, 2, currency.Version)
+}
+
+func TestPatchCurrencyRejectsNonPositiveFactor(t *testing.T) {
+	for _, factor := range []int{0, -100} {
+		repo := newPatchTestRepo()
+		svc := newTestCurrencyService(repo)
+
+		_, err := svc.PatchCurrency(userContext(), CurrencyPatch{Code: "USD", Version: 1, Factor: &factor})
+
+		require.ErrorIs(t, err, ErrInvalidCurrency, "factor %d", factor)
+		assert.Equal(t, 100, repo.currencies["USD"].Factor)
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSearchRepo() *fakeCurrencyRepo {
+	return newFakeCurrencyRepo(
+		&model.Currency{Code: "AUD", Description: "Australian Dollar", IsActive: true},
+		&model.Currency{Code: "CAD", Description: "Canadian Dollar", IsActive: true},
+		&model.Currency{Code: "HKD", Description: "Hong Kong Dollar", IsActive: true},
+		&model.Currency{Code: "NZD", Description: "New Zealand Dollar", IsActive: true},
+		&model.Currency{Code: "USD", Description: "US Dollar", IsActive: true},
+		&model.Currency{Code: "EUR", Description: "Euro", IsActive: true},
+	)
+}
+
+func searchedCodes(currencies []*model.Currency) []string {
+	codes := make([]string, len(currencies))
+	for i, currency := range currencies {
+		codes[i] = currency.Code
+	}
+	return codes
+}
+
+func TestSearchCurrenciesTruncatesToLimit(t *testing.T) {
+	svc := newTestCurrencyService(newSearchRepo())
+	ctx := context.Background()
+	search := repository.SearchOptions{Term: "dollar"}
+
+	currencies, total, err := svc.SearchCurrencies(ctx, search, 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AUD", "CAD"}, searchedCodes(currencies))
+	assert.Equal(t, int64(5), total, "the total counts every match")
+
+	currencies, total, err = svc.SearchCurrencies(ctx, search, 2, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"USD"}, searchedCodes(currencies))
+	assert.Equal(t, int64(5), total)
+}
+
+func TestSearchCurrenciesWithoutTermFindsNothing(t *testing.T) {
+	svc := newTestCurrencyService(newSearchRepo())
+
+	currencies, total, err := svc.SearchCurrencies(context.Background(), repository.SearchOptions{}, 2, 0)
+	require.NoError(t, err)
+	assert.Empty(t, currencies)
+	assert.Zero(t, total)
+}
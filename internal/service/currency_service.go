@@ -3,29 +3,77 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/auth"
+	"github.com/Tarifsiz/go-currency-api/internal/config"
+	"github.com/Tarifsiz/go-currency-api/internal/logging"
+	"github.com/Tarifsiz/go-currency-api/internal/metrics"
 	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/money"
 	"github.com/Tarifsiz/go-currency-api/internal/repository"
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCurrencyLimitReached is returned when creating a currency would exceed MaxCurrencies
+	ErrCurrencyLimitReached = errors.New("currency limit reached")
+	// ErrInvalidCurrency is returned when currency data is well-formed but semantically invalid
+	ErrInvalidCurrency = errors.New("invalid currency")
+	// ErrMissingField is returned in strict defaults mode when a defaultable field is omitted
+	ErrMissingField = errors.New("missing required field")
+	// ErrUnauthenticated is returned when an operation needs an authenticated user and the context has none
+	ErrUnauthenticated = errors.New("no authenticated user")
 )
 
 // CurrencyServiceInterface defines the business logic for currency operations
 type CurrencyServiceInterface interface {
 	// Basic CRUD operations
 	CreateCurrency(ctx context.Context, currency *model.Currency) error
+	CreateCurrenciesBatch(ctx context.Context, currencies []*model.Currency) ([]BatchItemResult, error)
+	ImportCurrencies(ctx context.Context, rows []ImportRow, upsert bool) (*ImportSummary, error)
 	GetCurrencyByID(ctx context.Context, id uuid.UUID) (*model.Currency, error)
 	GetCurrencyByCode(ctx context.Context, code string) (*model.Currency, error)
-	GetAllCurrencies(ctx context.Context, limit, offset int) ([]*model.Currency, error)
+	GetCurrencyByNumericCode(ctx context.Context, numericCode string) (*model.Currency, error)
+	GetAllCurrencies(ctx context.Context, limit, offset int, sortField, sortOrder string, filter repository.ListFilter) ([]*model.Currency, error)
+	GetCurrenciesAfter(ctx context.Context, cursorCode string, limit int, filter repository.ListFilter) ([]*model.Currency, bool, error)
 	UpdateCurrency(ctx context.Context, currency *model.Currency) error
 	DeleteCurrency(ctx context.Context, id uuid.UUID) error
+	HardDeleteCurrency(ctx context.Context, id uuid.UUID) error
+	RestoreCurrency(ctx context.Context, code string) (*model.Currency, error)
+	DeactivateCurrency(ctx context.Context, code string) (*model.Currency, error)
+	ActivateCurrency(ctx context.Context, code string) (*model.Currency, error)
 	
 	// Business logic operations
-	SearchCurrencies(ctx context.Context, query string) ([]*model.Currency, error)
+	SearchCurrencies(ctx context.Context, search repository.SearchOptions, limit, offset int) ([]*model.Currency, int64, error)
+	FuzzySearchCurrencies(ctx context.Context, search repository.SearchOptions, limit, offset int) ([]*repository.ScoredCurrency, int64, error)
+	GetCurrenciesByCodes(ctx context.Context, codes []string) ([]*model.Currency, error)
 	GetCurrenciesByFactor(ctx context.Context, factor int) ([]*model.Currency, error)
+	GetCurrenciesWithoutRates(ctx context.Context, base string) ([]*model.Currency, error)
+	GetCurrenciesGroupedByFactor(ctx context.Context, search repository.SearchOptions) (map[int][]*model.Currency, error)
 	GetCurrencyCount(ctx context.Context) (int64, error)
+	CountCurrencies(ctx context.Context, search repository.SearchOptions, factor int) (int64, error)
+	CountFilteredCurrencies(ctx context.Context, filter repository.ListFilter) (int64, error)
+	GetCurrencyChanges(ctx context.Context, since time.Time, limit, offset int) ([]*CurrencyChange, int64, error)
+	GetCurrencyAudit(ctx context.Context, code string, limit, offset int) ([]*model.ChangeLog, int64, error)
+	PatchCurrency(ctx context.Context, patch CurrencyPatch) (*model.Currency, error)
+	
+	// Presentation
+	FormatCurrencyAmount(ctx context.Context, code string, amount int64) (*FormattedAmount, error)
+	PreviewAmountFormat(ctx context.Context, code string, amounts []int64, displayFormat string) (*FormatPreview, error)
+	PatchCurrencies(ctx context.Context, patches []CurrencyPatch, atomic bool) ([]BatchItemResult, error)
+	
+	// Cache maintenance
+	RebuildCache(ctx context.Context, hotCodes []string) (*CacheRebuildResult, error)
+	FlushCache(ctx context.Context) (int64, error)
+	ListCacheStats() ListCacheStats
 }
 
 // CurrencyService implements the CurrencyServiceInterface
@@ -33,37 +81,53 @@ type CurrencyService struct {
 	currencyRepo repository.CurrencyRepositoryInterface
 	redisClient  *redis.Client
 	cacheTimeout time.Duration
+	cfg          config.CurrencyConfig
+	cacheCfg     config.CacheConfig
+	pagination   config.PaginationConfig
+	validator    CurrencyValidator
+	clock        Clock
+	
+	listCacheHits   atomic.Int64
+	listCacheMisses atomic.Int64
+	
+	// loads collapses concurrent cache-miss loads of the same key into one
+	// database query
+	loads singleflight.Group
 }
 
 // NewCurrencyService creates a new currency service instance
-func NewCurrencyService(currencyRepo repository.CurrencyRepositoryInterface, redisClient *redis.Client) CurrencyServiceInterface {
+func NewCurrencyService(currencyRepo repository.CurrencyRepositoryInterface, redisClient *redis.Client, cfg config.CurrencyConfig, cacheCfg config.CacheConfig, pagination config.PaginationConfig, validator CurrencyValidator) CurrencyServiceInterface {
 	return &CurrencyService{
 		currencyRepo: currencyRepo,
 		redisClient:  redisClient,
-		cacheTimeout: 15 * time.Minute, // Cache currencies for 15 minutes
+		cacheTimeout: cacheCfg.TTL, // 0 disables caching
+		cfg:          cfg,
+		cacheCfg:     cacheCfg,
+		pagination:   pagination,
+		validator:    validator,
+		clock:        systemClock{},
 	}
 }
 
 // CreateCurrency creates a new currency
 func (s *CurrencyService) CreateCurrency(ctx context.Context, currency *model.Currency) error {
-	// Validate required fields
-	if currency.Code == "" {
-		return fmt.Errorf("currency code is required")
-	}
-	if currency.Description == "" {
-		return fmt.Errorf("currency description is required")
+	if err := normalizeCurrencyCode(currency); err != nil {
+		return err
 	}
 	
-	// Set default values
-	if currency.Factor == 0 {
-		currency.Factor = 100 // Default to 2 decimal places
+	// Validate required fields
+	if err := s.validateNew(currency); err != nil {
+		return err
 	}
-	if currency.AmountDisplayFormat == "" {
-		currency.AmountDisplayFormat = "###,###.##"
+	
+	// Apply defaults for omitted fields
+	if err := s.applyDefaults(ctx, currency); err != nil {
+		return err
 	}
-	if currency.CreatedBy == uuid.Nil {
-		// Set a default created_by UUID (in real app, this would come from auth context)
-		currency.CreatedBy = uuid.MustParse("1609b0e1-30c4-402c-a76e-8f5b4d6cfc24")
+	
+	// Enforce the configured currency cap
+	if err := s.checkCurrencyLimit(ctx, 1); err != nil {
+		return err
 	}
 	
 	// Create currency
@@ -77,6 +141,65 @@ func (s *CurrencyService) CreateCurrency(ctx context.Context, currency *model.Cu
 	return nil
 }
 
+// CreateCurrenciesBatch validates and creates several currencies in a single
+// transaction. If any item fails nothing is created and the returned results
+// identify the failing item by index.
+func (s *CurrencyService) CreateCurrenciesBatch(ctx context.Context, currencies []*model.Currency) ([]BatchItemResult, error) {
+	codes := make([]string, len(currencies))
+	for i, currency := range currencies {
+		codes[i] = model.CanonicalCode(currency.Code)
+	}
+	if err := checkDuplicateCodes(codes); err != nil {
+		return nil, err
+	}
+	
+	results := make([]BatchItemResult, len(currencies))
+	failed := false
+	for i, currency := range currencies {
+		results[i] = BatchItemResult{Index: i, Code: codes[i]}
+		if err := normalizeCurrencyCode(currency); err != nil {
+			results[i].Error = err.Error()
+			failed = true
+			continue
+		}
+		if err := s.validateNew(currency); err != nil {
+			results[i].Error = err.Error()
+			failed = true
+			continue
+		}
+		if err := s.applyDefaults(ctx, currency); err != nil {
+			results[i].Error = err.Error()
+			failed = true
+		}
+	}
+	if failed {
+		markRolledBack(results, "")
+		return results, ErrBatchFailed
+	}
+	
+	// Enforce the configured currency cap for the whole batch
+	if err := s.checkCurrencyLimit(ctx, len(currencies)); err != nil {
+		return nil, err
+	}
+	
+	if err := s.currencyRepo.CreateBatch(ctx, currencies); err != nil {
+		var batchErr *repository.BatchError
+		if !errors.As(err, &batchErr) {
+			return nil, fmt.Errorf("failed to create currencies: %w", err)
+		}
+		results[batchErr.Index].Error = batchErr.Err.Error()
+		markRolledBack(results, "")
+		return results, fmt.Errorf("%w: %v", ErrBatchFailed, err)
+	}
+	
+	for i, currency := range currencies {
+		results[i].Success = true
+		s.invalidateCache(ctx, currency.Code)
+	}
+	
+	return results, nil
+}
+
 // GetCurrencyByID retrieves a currency by ID
 func (s *CurrencyService) GetCurrencyByID(ctx context.Context, id uuid.UUID) (*model.Currency, error) {
 	return s.currencyRepo.GetByID(ctx, id)
@@ -84,6 +207,10 @@ func (s *CurrencyService) GetCurrencyByID(ctx context.Context, id uuid.UUID) (*m
 
 // GetCurrencyByCode retrieves a currency by code with caching
 func (s *CurrencyService) GetCurrencyByCode(ctx context.Context, code string) (*model.Currency, error) {
+	if !s.cacheEnabled() {
+		return s.currencyRepo.GetByCode(ctx, code)
+	}
+	
 	// Try to get from cache first
 	cacheKey := fmt.Sprintf("currency:code:%s", code)
 	cachedCurrency, err := s.redisClient.Get(ctx, cacheKey).Result()
@@ -92,63 +219,121 @@ func (s *CurrencyService) GetCurrencyByCode(ctx context.Context, code string) (*
 		// Cache hit - unmarshal and return
 		var currency model.Currency
 		if err := json.Unmarshal([]byte(cachedCurrency), &currency); err == nil {
+			metrics.ObserveCacheLookup("currency", true)
 			return &currency, nil
 		}
 	}
 	
-	// Cache miss - get from database
-	currency, err := s.currencyRepo.GetByCode(ctx, code)
+	// Cache miss - get from database, once for all concurrent misses
+	metrics.ObserveCacheLookup("currency", false)
+	loaded, err := s.loadOnce(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		currency, err := s.currencyRepo.GetByCode(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		
+		// Cache the result
+		s.cacheCurrency(ctx, cacheKey, currency)
+		return currency, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 	
-	// Cache the result
-	s.cacheCurrency(ctx, cacheKey, currency)
-	
-	return currency, nil
+	// Callers may modify the currency, so each gets its own copy
+	currency := *loaded.(*model.Currency)
+	return &currency, nil
 }
 
-// GetAllCurrencies retrieves all currencies with pagination and caching
-func (s *CurrencyService) GetAllCurrencies(ctx context.Context, limit, offset int) ([]*model.Currency, error) {
-	// For simplicity, only cache the first page (offset = 0) with default limit
-	if offset == 0 && limit <= 100 {
-		cacheKey := fmt.Sprintf("currencies:all:%d:%d", limit, offset)
-		cachedCurrencies, err := s.redisClient.Get(ctx, cacheKey).Result()
-		
-		if err == nil {
-			// Cache hit
-			var currencies []*model.Currency
-			if err := json.Unmarshal([]byte(cachedCurrencies), &currencies); err == nil {
-				return currencies, nil
-			}
+// GetCurrencyByNumericCode retrieves a currency by its ISO 4217 numeric code
+func (s *CurrencyService) GetCurrencyByNumericCode(ctx context.Context, numericCode string) (*model.Currency, error) {
+	return s.currencyRepo.GetByNumericCode(ctx, numericCode)
+}
+
+// GetAllCurrencies retrieves all currencies matching filter with pagination,
+// sorting and caching
+func (s *CurrencyService) GetAllCurrencies(ctx context.Context, limit, offset int, sortField, sortOrder string, filter repository.ListFilter) ([]*model.Currency, error) {
+	sortField, sortOrder = repository.NormalizeSort(sortField, sortOrder)
+	filter = s.visibleFilter(filter)
+	
+	if !s.isListPageCacheable(limit, offset) {
+		return s.currencyRepo.GetAll(ctx, limit, offset, sortField, sortOrder, filter)
+	}
+	
+	cacheKey := listCacheKey(newListQuery(limit, offset, sortField, sortOrder, filter))
+	cachedCurrencies, err := s.redisClient.Get(ctx, cacheKey).Result()
+	
+	if err == nil {
+		// Cache hit
+		var currencies []*model.Currency
+		if err := json.Unmarshal([]byte(cachedCurrencies), &currencies); err == nil {
+			s.recordListCacheHit(ctx)
+			return currencies, nil
 		}
-		
-		// Cache miss - get from database
-		currencies, err := s.currencyRepo.GetAll(ctx, limit, offset)
+	}
+	
+	// Cache miss - get from database, once for all concurrent misses
+	s.recordListCacheMiss(ctx)
+	loaded, err := s.loadOnce(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		currencies, err := s.currencyRepo.GetAll(ctx, limit, offset, sortField, sortOrder, filter)
 		if err != nil {
 			return nil, err
 		}
 		
 		// Cache the result
 		currenciesJSON, _ := json.Marshal(currencies)
-		s.redisClient.Set(ctx, cacheKey, currenciesJSON, s.cacheTimeout)
-		
+		if err := s.redisClient.Set(ctx, cacheKey, currenciesJSON, s.cacheTimeout).Err(); err != nil {
+			logging.FromContext(ctx).Warn("failed to cache currency list", "key", cacheKey, "error", err)
+		}
 		return currencies, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	shared := loaded.([]*model.Currency)
+	currencies := make([]*model.Currency, len(shared))
+	for i, currency := range shared {
+		currency := *currency
+		currencies[i] = &currency
+	}
+	return currencies, nil
+}
+
+// GetCurrenciesAfter returns one keyset page of up to limit currencies
+// matching filter whose code sorts after cursorCode, and whether more
+// currencies follow it
+func (s *CurrencyService) GetCurrenciesAfter(ctx context.Context, cursorCode string, limit int, filter repository.ListFilter) ([]*model.Currency, bool, error) {
+	// Fetch one extra row to learn whether there is a next page
+	currencies, err := s.currencyRepo.GetAllAfter(ctx, cursorCode, limit+1, s.visibleFilter(filter))
+	if err != nil {
+		return nil, false, err
 	}
 	
-	// For other pages, don't cache
-	return s.currencyRepo.GetAll(ctx, limit, offset)
+	hasMore := len(currencies) > limit
+	if hasMore {
+		currencies = currencies[:limit]
+	}
+	return currencies, hasMore, nil
 }
 
 // UpdateCurrency updates an existing currency
 func (s *CurrencyService) UpdateCurrency(ctx context.Context, currency *model.Currency) error {
+	if err := normalizeCurrencyCode(currency); err != nil {
+		return err
+	}
+	
 	// Validate required fields
-	if currency.Code == "" {
-		return fmt.Errorf("currency code is required")
+	if err := s.validator.Validate(currency); err != nil {
+		return err
 	}
-	if currency.Description == "" {
-		return fmt.Errorf("currency description is required")
+	
+	// Record who made the change
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
 	}
+	currency.UpdatedBy = &userID
 	
 	// Update currency
 	if err := s.currencyRepo.Update(ctx, currency); err != nil {
@@ -161,7 +346,7 @@ func (s *CurrencyService) UpdateCurrency(ctx context.Context, currency *model.Cu
 	return nil
 }
 
-// DeleteCurrency deletes a currency
+// DeleteCurrency soft-deletes a currency
 func (s *CurrencyService) DeleteCurrency(ctx context.Context, id uuid.UUID) error {
 	// Get currency first to get the code for cache invalidation
 	currency, err := s.currencyRepo.GetByID(ctx, id)
@@ -180,13 +365,120 @@ func (s *CurrencyService) DeleteCurrency(ctx context.Context, id uuid.UUID) erro
 	return nil
 }
 
-// SearchCurrencies searches currencies by name/description
-func (s *CurrencyService) SearchCurrencies(ctx context.Context, query string) ([]*model.Currency, error) {
-	if query == "" {
-		return []*model.Currency{}, nil
+// HardDeleteCurrency permanently deletes a currency
+func (s *CurrencyService) HardDeleteCurrency(ctx context.Context, id uuid.UUID) error {
+	// Get currency first to get the code for cache invalidation
+	currency, err := s.currencyRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get currency before deletion: %w", err)
+	}
+	
+	if err := s.currencyRepo.HardDelete(ctx, id); err != nil {
+		return fmt.Errorf("failed to hard delete currency: %w", err)
 	}
 	
-	return s.currencyRepo.SearchByName(ctx, query)
+	// Invalidate cache
+	s.invalidateCache(ctx, currency.Code)
+	
+	return nil
+}
+
+// RestoreCurrency undoes the soft deletion of the currency with the given code
+func (s *CurrencyService) RestoreCurrency(ctx context.Context, code string) (*model.Currency, error) {
+	currency, err := s.currencyRepo.GetDeletedByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	
+	// A new currency may have been created with the same code since deletion
+	active, err := s.currencyRepo.GetByCodes(ctx, []string{code})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for active currency: %w", err)
+	}
+	if len(active) > 0 {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrDuplicateCurrency, code)
+	}
+	
+	if err := s.currencyRepo.Restore(ctx, currency.ID); err != nil {
+		return nil, fmt.Errorf("failed to restore currency: %w", err)
+	}
+	currency.DeletedAt = gorm.DeletedAt{}
+	
+	// Invalidate cache so the currency reappears in listings
+	s.invalidateCache(ctx, currency.Code)
+	
+	return currency, nil
+}
+
+// SearchCurrencies searches currencies by description or code and returns
+// one page of matches along with the total number of matches
+func (s *CurrencyService) SearchCurrencies(ctx context.Context, search repository.SearchOptions, limit, offset int) ([]*model.Currency, int64, error) {
+	if search.Term == "" {
+		return []*model.Currency{}, 0, nil
+	}
+	search = s.visibleSearch(search)
+	
+	currencies, err := s.currencyRepo.SearchByName(ctx, search, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	
+	total, err := s.currencyRepo.CountByName(ctx, search)
+	if err != nil {
+		return nil, 0, err
+	}
+	
+	return currencies, total, nil
+}
+
+// FuzzySearchCurrencies finds currencies whose description is similar to
+// search.Term, best matches first, leaving out inactive currencies when
+// search.ActiveOnly is set. search.Field and search.Match don't apply. When
+// pg_trgm isn't installed it falls back to a substring search with every
+// score set to 0.
+func (s *CurrencyService) FuzzySearchCurrencies(ctx context.Context, search repository.SearchOptions, limit, offset int) ([]*repository.ScoredCurrency, int64, error) {
+	if search.Term == "" {
+		return []*repository.ScoredCurrency{}, 0, nil
+	}
+	search = s.visibleSearch(search)
+	
+	threshold := s.cfg.FuzzySearchThreshold
+	results, err := s.currencyRepo.FuzzySearchByName(ctx, search, threshold, limit, offset)
+	if errors.Is(err, repository.ErrFuzzySearchUnavailable) {
+		logging.FromContext(ctx).Warn("fuzzy search unavailable, falling back to substring search", "error", err)
+		return s.unscoredSearch(ctx, search, limit, offset)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	
+	total, err := s.currencyRepo.CountFuzzyByName(ctx, search, threshold)
+	if err != nil {
+		return nil, 0, err
+	}
+	
+	return results, total, nil
+}
+
+// unscoredSearch runs a plain substring search and wraps the results as
+// zero-score fuzzy results
+func (s *CurrencyService) unscoredSearch(ctx context.Context, search repository.SearchOptions, limit, offset int) ([]*repository.ScoredCurrency, int64, error) {
+	search.Field, search.Match = repository.SearchFieldDescription, repository.SearchMatchContains
+	currencies, total, err := s.SearchCurrencies(ctx, search, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	
+	results := make([]*repository.ScoredCurrency, len(currencies))
+	for i, currency := range currencies {
+		results[i] = &repository.ScoredCurrency{Currency: *currency}
+	}
+	return results, total, nil
+}
+
+// GetCurrenciesByCodes retrieves the currencies matching any of the given codes
+func (s *CurrencyService) GetCurrenciesByCodes(ctx context.Context, codes []string) ([]*model.Currency, error) {
+	return s.currencyRepo.GetByCodes(ctx, codes)
 }
 
 // GetCurrenciesByFactor retrieves currencies by decimal factor
@@ -194,29 +486,185 @@ func (s *CurrencyService) GetCurrenciesByFactor(ctx context.Context, factor int)
 	return s.currencyRepo.GetCurrenciesByFactor(ctx, factor)
 }
 
-// GetCurrencyCount returns total count of currencies
+// GetCurrenciesWithoutRates returns the active currencies that can't be
+// converted to or from base directly because no rate is stored for the pair,
+// e.g. to spot gaps in a provider's coverage. It returns
+// apperrors.ErrCurrencyNotFound if base doesn't exist.
+func (s *CurrencyService) GetCurrenciesWithoutRates(ctx context.Context, base string) ([]*model.Currency, error) {
+	base = model.CanonicalCode(base)
+	if _, err := s.GetCurrencyByCode(ctx, base); err != nil {
+		return nil, err
+	}
+	return s.currencyRepo.GetWithoutRates(ctx, base)
+}
+
+// GetCurrencyCount returns total count of currencies, cached until a
+// currency is created, changed or deleted
 func (s *CurrencyService) GetCurrencyCount(ctx context.Context) (int64, error) {
-	return s.currencyRepo.GetCount(ctx)
+	if !s.cacheEnabled() {
+		return s.currencyRepo.GetCount(ctx)
+	}
+	
+	if count, err := s.redisClient.Get(ctx, countCacheKey).Int64(); err == nil {
+		metrics.ObserveCacheLookup("count", true)
+		return count, nil
+	}
+	
+	// Cache miss - count in the database, once for all concurrent misses
+	metrics.ObserveCacheLookup("count", false)
+	loaded, err := s.loadOnce(ctx, countCacheKey, func(ctx context.Context) (interface{}, error) {
+		count, err := s.currencyRepo.GetCount(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.redisClient.Set(ctx, countCacheKey, count, s.cacheTimeout).Err(); err != nil {
+			logging.FromContext(ctx).Warn("failed to cache currency count", "key", countCacheKey, "error", err)
+		}
+		return count, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return loaded.(int64), nil
+}
+
+// CountCurrencies returns the number of currencies matching search and
+// factor. Without either, and with inactive and hidden currencies included,
+// it is the cached total from GetCurrencyCount.
+func (s *CurrencyService) CountCurrencies(ctx context.Context, search repository.SearchOptions, factor int) (int64, error) {
+	if search.Term == "" && factor <= 0 && !search.ActiveOnly && search.ShowHidden {
+		return s.GetCurrencyCount(ctx)
+	}
+	return s.currencyRepo.CountMatching(ctx, s.visibleSearch(search), factor)
+}
+
+// CountFilteredCurrencies returns the number of currencies matching filter
+func (s *CurrencyService) CountFilteredCurrencies(ctx context.Context, filter repository.ListFilter) (int64, error) {
+	return s.currencyRepo.CountFiltered(ctx, s.visibleFilter(filter))
+}
+
+// validateNew validates a currency about to be created. On top of the
+// configured validator, strict ISO mode rejects codes missing from the ISO
+// 4217 list. It only applies on creation so existing non-ISO currencies can
+// still be updated.
+func (s *CurrencyService) validateNew(currency *model.Currency) error {
+	if err := s.validator.Validate(currency); err != nil {
+		return err
+	}
+	if s.cfg.StrictISO && !model.IsISO4217(currency.Code) {
+		return fmt.Errorf("%w: %q is not an ISO 4217 currency code", ErrInvalidCurrency, currency.Code)
+	}
+	return nil
+}
+
+// normalizeCurrencyCode replaces the code of currency with its canonical
+// form. A code that isn't three letters is left as it is and an
+// ErrInvalidCurrency also wrapping apperrors.ErrInvalidCurrencyCode is returned.
+func normalizeCurrencyCode(currency *model.Currency) error {
+	code, err := model.NormalizeCode(currency.Code)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCurrency, err)
+	}
+	currency.Code = code
+	return nil
+}
+
+// applyDefaults fills in omitted fields of a new currency and records the
+// authenticated user as its creator. In strict mode omitted fields are an
+// error rather than silently defaulted.
+func (s *CurrencyService) applyDefaults(ctx context.Context, currency *model.Currency) error {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	currency.CreatedBy = userID
+
+	if s.cfg.StrictDefaults {
+		if currency.Factor == 0 {
+			return fmt.Errorf("%w: factor", ErrMissingField)
+		}
+		if currency.AmountDisplayFormat == "" {
+			return fmt.Errorf("%w: amount_display_format", ErrMissingField)
+		}
+	}
+
+	if currency.Factor == 0 {
+		currency.Factor = defaultFactor(currency.Code)
+	}
+	if currency.AmountDisplayFormat == "" {
+		currency.AmountDisplayFormat = "###,###.##"
+	}
+	if currency.RoundingMode == "" {
+		currency.RoundingMode = money.DefaultRoundingMode
+	}
+	return nil
+}
+
+// defaultFactor returns the factor of a currency created without one: the
+// ISO 4217 minor unit for known codes (JPY 1, USD 100, KWD 1000) and 2
+// decimal places otherwise
+func defaultFactor(code string) int {
+	places, ok := model.ISO4217MinorUnits(code)
+	if !ok {
+		return 100
+	}
+	factor := 1
+	for i := 0; i < places; i++ {
+		factor *= 10
+	}
+	return factor
+}
+
+// checkCurrencyLimit returns ErrCurrencyLimitReached if adding n currencies
+// would exceed the configured maximum
+func (s *CurrencyService) checkCurrencyLimit(ctx context.Context, n int) error {
+	if s.cfg.MaxCurrencies <= 0 {
+		return nil
+	}
+
+	count, err := s.currencyRepo.GetCount(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check currency limit: %w", err)
+	}
+
+	if count+int64(n) > int64(s.cfg.MaxCurrencies) {
+		return fmt.Errorf("%w: at most %d currencies are allowed", ErrCurrencyLimitReached, s.cfg.MaxCurrencies)
+	}
+
+	return nil
 }
 
 // Helper methods for caching
 
+// cacheEnabled reports whether Redis caching is on; a zero TTL disables it
+func (s *CurrencyService) cacheEnabled() bool {
+	return s.cacheTimeout > 0
+}
+
 func (s *CurrencyService) cacheCurrency(ctx context.Context, cacheKey string, currency *model.Currency) {
+	if !s.cacheEnabled() {
+		return
+	}
+	
 	currencyJSON, err := json.Marshal(currency)
 	if err == nil {
-		s.redisClient.Set(ctx, cacheKey, currencyJSON, s.cacheTimeout)
+		if err := s.redisClient.Set(ctx, cacheKey, currencyJSON, s.cacheTimeout).Err(); err != nil {
+			logging.FromContext(ctx).Warn("failed to cache currency", "key", cacheKey, "error", err)
+		}
 	}
 }
 
 func (s *CurrencyService) invalidateCache(ctx context.Context, currencyCode string) {
-	// Invalidate specific currency cache
+	if !s.cacheEnabled() {
+		return
+	}
+	
+	// Invalidate specific currency cache and the total count
 	cacheKey := fmt.Sprintf("currency:code:%s", currencyCode)
-	s.redisClient.Del(ctx, cacheKey)
+	s.redisClient.Del(ctx, cacheKey, countCacheKey)
 	
 	// Invalidate list cache (simple approach - delete all list caches)
-	pattern := "currencies:all:*"
-	keys, err := s.redisClient.Keys(ctx, pattern).Result()
-	if err == nil && len(keys) > 0 {
-		s.redisClient.Del(ctx, keys...)
+	if _, err := s.deleteKeysByPattern(ctx, listCachePrefix+"*"); err != nil {
+		logging.FromContext(ctx).Warn("failed to invalidate currency list cache", "error", err)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/auth"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateCurrenciesBatchRejectsDuplicateCodes(t *testing.T) {
+	svc := newTestCurrencyService(newFakeCurrencyRepo())
+
+	results, err := svc.CreateCurrenciesBatch(userContext(), []*model.Currency{
+		{Code: "USD", Description: "US Dollar"},
+		{Code: "EUR", Description: "Euro"},
+		{Code: "USD", Description: "United States dollar"},
+	})
+
+	require.ErrorIs(t, err, ErrDuplicateCodes)
+	assert.Contains(t, err.Error(), "USD (items 0 and 2)")
+	assert.NotContains(t, err.Error(), "EUR")
+	assert.Nil(t, results)
+}
+
+func TestCreateCurrencyNormalizesCode(t *testing.T) {
+	for input, want := range map[string]string{"usd": "USD", " eur\t": "EUR", "u.s.d": "USD"} {
+		repo := newFakeCurrencyRepo()
+		svc := newTestCurrencyService(repo)
+
+		currency := &model.Currency{Code: input, Description: "Test currency"}
+		require.NoError(t, svc.CreateCurrency(userContext(), currency), "%q", input)
+
+		assert.Equal(t, want, currency.Code)
+		assert.Contains(t, repo.currencies, want)
+	}
+}
+
+func TestCreateCurrencyRejectsInvalidCodes(t *testing.T) {
+	for _, input := range []string{"US", "USDT", "U$D", "u d", "123", "US1"} {
+		repo := newFakeCurrencyRepo()
+		svc := newTestCurrencyService(repo)
+
+		err := svc.CreateCurrency(userContext(), &model.Currency{Code: input, Description: "Test currency"})
+
+		assert.ErrorIs(t, err, ErrInvalidCurrency, "%q", input)
+		assert.ErrorIs(t, err, apperrors.ErrInvalidCurrencyCode, "%q", input)
+		assert.Empty(t, repo.currencies)
+	}
+}
+
+func TestUpdateCurrencyRejectsInvalidCode(t *testing.T) {
+	svc := newTestCurrencyService(newFakeCurrencyRepo())
+
+	err := svc.UpdateCurrency(userContext(), &model.Currency{Code: "US$", Description: "US Dollar", Version: 1})
+
+	assert.ErrorIs(t, err, apperrors.ErrInvalidCurrencyCode)
+}
+
+func TestCreateCurrenciesBatchReportsInvalidCodes(t *testing.T) {
+	repo := newFakeCurrencyRepo()
+	svc := newTestCurrencyService(repo)
+
+	results, err := svc.CreateCurrenciesBatch(userContext(), []*model.Currency{
+		{Code: " usd ", Description: "US Dollar"},
+		{Code: "E$R", Description: "Euro"},
+	})
+
+	require.ErrorIs(t, err, ErrBatchFailed)
+	require.Len(t, results, 2)
+	assert.Equal(t, "USD", results[0].Code)
+	assert.Contains(t, results[0].Error, "rolled back")
+	assert.Contains(t, results[1].Error, "invalid currency code")
+	assert.Empty(t, repo.currencies)
+}
+
+func TestImportCurrenciesNormalizesCodes(t *testing.T) {
+	repo := newFakeCurrencyRepo()
+	svc := newTestCurrencyService(repo)
+
+	rows, err := ParseCurrencyImport(ImportFormatCSV, strings.NewReader("code,description\nusd,US Dollar\nE$R,Euro\n"))
+	require.NoError(t, err)
+	summary, err := svc.ImportCurrencies(userContext(), rows, false)
+	require.NoError(t, err)
+
+	require.Len(t, summary.Rows, 2)
+	assert.Equal(t, "USD", summary.Rows[0].Code)
+	assert.Equal(t, ImportStatusCreated, summary.Rows[0].Status)
+	assert.Equal(t, ImportStatusError, summary.Rows[1].Status)
+	assert.Contains(t, summary.Rows[1].Error, "invalid currency code")
+	assert.Contains(t, repo.currencies, "USD")
+}
+
+func TestGetCurrenciesWithoutRates(t *testing.T) {
+	repo := newFakeCurrencyRepo(
+		&model.Currency{Code: "USD", IsActive: true},
+		&model.Currency{Code: "EUR", IsActive: true},
+		&model.Currency{Code: "GBP", IsActive: true},
+		&model.Currency{Code: "CHF", IsActive: true},
+		&model.Currency{Code: "DEM"},
+	)
+	repo.rated = map[string][]string{"USD": {"EUR"}}
+	svc := newTestCurrencyService(repo)
+
+	currencies, err := svc.GetCurrenciesWithoutRates(userContext(), "usd")
+	require.NoError(t, err)
+	var codes []string
+	for _, currency := range currencies {
+		codes = append(codes, currency.Code)
+	}
+	assert.Equal(t, []string{"CHF", "GBP"}, codes)
+
+	_, err = svc.GetCurrenciesWithoutRates(userContext(), "XAU")
+	assert.ErrorIs(t, err, apperrors.ErrCurrencyNotFound)
+}
+
+// newStrictTestCurrencyService returns a lenient currency service over repo
+// with STRICT_DEFAULTS on
+func newStrictTestCurrencyService(repo *fakeCurrencyRepo) *CurrencyService {
+	svc := newTestCurrencyService(repo)
+	svc.cfg.StrictDefaults = true
+	return svc
+}
+
+func TestCreateCurrencyDefaultsOmittedFields(t *testing.T) {
+	for code, factor := range map[string]int{"USD": 100, "JPY": 1, "KWD": 1000, "BTC": 100} {
+		repo := newFakeCurrencyRepo()
+		svc := newTestCurrencyService(repo)
+
+		currency := &model.Currency{Code: code, Description: "Test currency"}
+		require.NoError(t, svc.CreateCurrency(userContext(), currency), code)
+
+		assert.Equal(t, factor, currency.Factor, code)
+		assert.Equal(t, "###,###.##", currency.AmountDisplayFormat, code)
+		assert.Equal(t, money.DefaultRoundingMode, currency.RoundingMode, code)
+		assert.Contains(t, repo.currencies, code)
+	}
+}
+
+func TestDefaultFactorFollowsMinorUnits(t *testing.T) {
+	tests := map[string]int{
+		// Zero decimal places
+		"JPY": 1, "KRW": 1, "ISK": 1,
+		// Two decimal places
+		"USD": 100, "EUR": 100, "GBP": 100,
+		// Three decimal places
+		"KWD": 1000, "BHD": 1000, "TND": 1000,
+		// Codes off the ISO 4217 list keep two decimal places
+		"BTC": 100, "XYZ": 100,
+	}
+	for code, want := range tests {
+		assert.Equal(t, want, defaultFactor(code), code)
+	}
+}
+
+func TestCreateCurrencyDefaultsFactorForEachDecimalClass(t *testing.T) {
+	repo := newFakeCurrencyRepo()
+	svc := newTestCurrencyService(repo)
+
+	// Codes are normalized before the factor is looked up
+	for code, want := range map[string]int{"jpy": 1, "usd": 100, "kwd": 1000} {
+		currency := &model.Currency{Code: code, Description: "Test currency"}
+		require.NoError(t, svc.CreateCurrency(userContext(), currency), code)
+		assert.Equal(t, want, currency.Factor, code)
+	}
+
+	results, err := svc.CreateCurrenciesBatch(userContext(), []*model.Currency{
+		{Code: "KRW", Description: "Won"},
+		{Code: "EUR", Description: "Euro"},
+		{Code: "BHD", Description: "Bahraini Dinar"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for code, want := range map[string]int{"KRW": 1, "EUR": 100, "BHD": 1000} {
+		assert.Equal(t, want, repo.currencies[code].Factor, code)
+	}
+}
+
+func TestCreateCurrencyKeepsSuppliedFactor(t *testing.T) {
+	repo := newFakeCurrencyRepo()
+	svc := newTestCurrencyService(repo)
+
+	// A supplied factor wins over the ISO 4217 minor unit
+	for code, factor := range map[string]int{"JPY": 100, "USD": 1000, "KWD": 1} {
+		currency := &model.Currency{Code: code, Description: "Test currency", Factor: factor}
+		require.NoError(t, svc.CreateCurrency(userContext(), currency), code)
+		assert.Equal(t, factor, repo.currencies[code].Factor, code)
+	}
+}
+
+func TestCreateCurrencyStrictDefaultsRejectsOmittedFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency model.Currency
+		missing  string
+	}{
+		{"factor", model.Currency{Code: "USD", Description: "US Dollar", AmountDisplayFormat: "###,###.##"}, "factor"},
+		{"format", model.Currency{Code: "USD", Description: "US Dollar", Factor: 100}, "amount_display_format"},
+		{"both", model.Currency{Code: "USD", Description: "US Dollar"}, "factor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeCurrencyRepo()
+			svc := newStrictTestCurrencyService(repo)
+
+			currency := tt.currency
+			err := svc.CreateCurrency(userContext(), &currency)
+			require.ErrorIs(t, err, ErrMissingField)
+			assert.Contains(t, err.Error(), tt.missing)
+			assert.Empty(t, repo.currencies)
+		})
+	}
+}
+
+func TestCreateCurrencyStrictDefaultsAcceptsCompleteCurrencies(t *testing.T) {
+	repo := newFakeCurrencyRepo()
+	svc := newStrictTestCurrencyService(repo)
+
+	currency := &model.Currency{Code: "JPY", Description: "Japanese Yen", Factor: 1, AmountDisplayFormat: "###,###"}
+	require.NoError(t, svc.CreateCurrency(userContext(), currency))
+	assert.Equal(t, 1, currency.Factor)
+	assert.Equal(t, "###,###", currency.AmountDisplayFormat)
+	// The rounding mode isn't covered by strict mode
+	assert.Equal(t, money.DefaultRoundingMode, currency.RoundingMode)
+}
+
+func TestCreateCurrenciesBatchStrictDefaultsReportsOmittedFields(t *testing.T) {
+	repo := newFakeCurrencyRepo()
+	svc := newStrictTestCurrencyService(repo)
+
+	results, err := svc.CreateCurrenciesBatch(userContext(), []*model.Currency{
+		{Code: "USD", Description: "US Dollar", Factor: 100, AmountDisplayFormat: "###,###.##"},
+		{Code: "EUR", Description: "Euro", AmountDisplayFormat: "###,###.##"},
+	})
+	require.ErrorIs(t, err, ErrBatchFailed)
+	require.Len(t, results, 2)
+	assert.Contains(t, results[1].Error, "missing required field: factor")
+	assert.False(t, results[0].Success)
+	assert.Empty(t, repo.currencies)
+}
+
+func TestUpdateCurrencyRecordsActingUser(t *testing.T) {
+	creator, editor := uuid.New(), uuid.New()
+	repo := newFakeCurrencyRepo(&model.Currency{Code: "USD", Description: "US Dollar", Factor: 100, CreatedBy: creator, Version: 1})
+	svc := newTestCurrencyService(repo)
+
+	// The client can't choose who the change is recorded for
+	someoneElse := uuid.New()
+	currency := &model.Currency{Code: "USD", Description: "United States dollar", Factor: 100, CreatedBy: someoneElse, UpdatedBy: &someoneElse, Version: 1}
+	require.NoError(t, svc.UpdateCurrency(auth.WithUserID(context.Background(), editor), currency))
+
+	stored := repo.currencies["USD"]
+	require.NotNil(t, stored.UpdatedBy)
+	assert.Equal(t, editor, *stored.UpdatedBy)
+	assert.Equal(t, creator, stored.CreatedBy)
+	assert.Equal(t, "United States dollar", stored.Description)
+	assert.Equal(t, 2, stored.Version)
+}
+
+func TestUpdateCurrencyRequiresAuthenticatedUser(t *testing.T) {
+	repo := newFakeCurrencyRepo(&model.Currency{Code: "USD", Description: "US Dollar", Factor: 100, Version: 1})
+	svc := newTestCurrencyService(repo)
+
+	err := svc.UpdateCurrency(context.Background(), &model.Currency{Code: "USD", Description: "United States dollar", Factor: 100, Version: 1})
+	require.ErrorIs(t, err, ErrUnauthenticated)
+	assert.Nil(t, repo.currencies["USD"].UpdatedBy)
+	assert.Equal(t, "US Dollar", repo.currencies["USD"].Description)
+}
+
+func TestCreateCurrencyStrictISORejectsUnknownCodes(t *testing.T) {
+	repo := newFakeCurrencyRepo()
+	svc := newTestCurrencyService(repo)
+	svc.cfg.StrictISO = true
+
+	for _, code := range []string{"ZZZ", "BTC"} {
+		err := svc.CreateCurrency(userContext(), &model.Currency{Code: code, Description: "Test currency"})
+		require.ErrorIs(t, err, ErrInvalidCurrency, code)
+		assert.Contains(t, err.Error(), `"`+code+`" is not an ISO 4217 currency code`)
+	}
+	assert.Empty(t, repo.currencies)
+
+	// Codes are checked once normalized
+	require.NoError(t, svc.CreateCurrency(userContext(), &model.Currency{Code: "chf", Description: "Swiss Franc"}))
+	assert.Contains(t, repo.currencies, "CHF")
+}
+
+func TestStrictISOOnlyAppliesToNewCurrencies(t *testing.T) {
+	repo := newFakeCurrencyRepo(&model.Currency{Code: "BTC", Description: "Bitcoin", Factor: 100, Version: 1})
+	svc := newTestCurrencyService(repo)
+	svc.cfg.StrictISO = true
+
+	// Currencies created before the mode was turned on can still be edited
+	require.NoError(t, svc.UpdateCurrency(userContext(), &model.Currency{Code: "BTC", Description: "Bitcoin (BTC)", Factor: 100, Version: 1}))
+	assert.Equal(t, "Bitcoin (BTC)", repo.currencies["BTC"].Description)
+
+	// Without the mode the lenient validator accepts any three letters
+	svc.cfg.StrictISO = false
+	require.NoError(t, svc.CreateCurrency(userContext(), &model.Currency{Code: "ETH", Description: "Ether"}))
+}
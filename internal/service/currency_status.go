@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Tarifsiz/go-currency-api/internal/auth"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+)
+
+// DeactivateCurrency marks the currency with the given code as obsolete, e.g.
+// a national currency replaced by the euro. It can still be looked up by
+// code, but listings leave it out by default and it can't be converted.
+func (s *CurrencyService) DeactivateCurrency(ctx context.Context, code string) (*model.Currency, error) {
+	return s.setActive(ctx, code, false)
+}
+
+// ActivateCurrency undoes DeactivateCurrency
+func (s *CurrencyService) ActivateCurrency(ctx context.Context, code string) (*model.Currency, error) {
+	return s.setActive(ctx, code, true)
+}
+
+// setActive sets the active flag of the currency with the given code and
+// returns the updated currency. Setting the flag it already has changes
+// nothing, so the version isn't bumped.
+func (s *CurrencyService) setActive(ctx context.Context, code string, active bool) (*model.Currency, error) {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	currency, err := s.currencyRepo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if currency.IsActive == active {
+		return currency, nil
+	}
+
+	// The flag doesn't depend on any other field, so the update is unconditional
+	update := repository.CurrencyFieldUpdate{
+		Code:   currency.Code,
+		Fields: map[string]interface{}{"is_active": active, "updated_by": userID},
+	}
+	if err := s.currencyRepo.UpdateFields(ctx, update); err != nil {
+		return nil, fmt.Errorf("failed to update currency status: %w", err)
+	}
+
+	s.invalidateCache(ctx, currency.Code)
+
+	return s.currencyRepo.GetByCode(ctx, currency.Code)
+}
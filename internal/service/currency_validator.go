@@ -0,0 +1,118 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/money"
+)
+
+// Validation modes accepted by NewCurrencyValidator
+const (
+	ValidationModeISO     = "iso"
+	ValidationModeLenient = "lenient"
+)
+
+var (
+	isoCodePattern     = regexp.MustCompile(`^[A-Z]{3}$`)
+	numericCodePattern = regexp.MustCompile(`^[0-9]{3}$`)
+)
+
+// CurrencyValidator validates currency data before it is persisted.
+// Implementations return an error wrapping ErrInvalidCurrency.
+type CurrencyValidator interface {
+	Validate(currency *model.Currency) error
+}
+
+// NewCurrencyValidator returns the built-in validator chain for the given mode
+func NewCurrencyValidator(mode string) (CurrencyValidator, error) {
+	switch mode {
+	case ValidationModeISO:
+		return CompositeValidator{RequiredFieldsValidator{}, ISOCodeValidator{}}, nil
+	case ValidationModeLenient:
+		return CompositeValidator{RequiredFieldsValidator{}, LenientCodeValidator{}}, nil
+	default:
+		return nil, fmt.Errorf("unknown currency validation mode %q", mode)
+	}
+}
+
+// CompositeValidator runs validators in order and returns the first error
+type CompositeValidator []CurrencyValidator
+
+// Validate implements CurrencyValidator
+func (v CompositeValidator) Validate(currency *model.Currency) error {
+	for _, validator := range v {
+		if err := validator.Validate(currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RequiredFieldsValidator checks required fields, the decimal factor, the
+// rounding mode, the validity range and that the symbol is safe HTML.
+// A zero Factor or empty RoundingMode is allowed so that CreateCurrency can
+// apply its default.
+type RequiredFieldsValidator struct{}
+
+// Validate implements CurrencyValidator
+func (RequiredFieldsValidator) Validate(currency *model.Currency) error {
+	if currency.Code == "" {
+		return fmt.Errorf("%w: currency code is required", ErrInvalidCurrency)
+	}
+	if currency.Description == "" {
+		return fmt.Errorf("%w: currency description is required", ErrInvalidCurrency)
+	}
+	if currency.Factor != 0 && !isPowerOfTen(currency.Factor) {
+		return fmt.Errorf("%w: factor must be a positive power of ten", ErrInvalidCurrency)
+	}
+	if currency.RoundingMode != "" && !currency.RoundingMode.IsValid() {
+		return fmt.Errorf("%w: rounding mode must be one of %v", ErrInvalidCurrency, money.RoundingModes)
+	}
+	if currency.ValidFrom != nil && currency.ValidUntil != nil && !currency.ValidFrom.Before(*currency.ValidUntil) {
+		return fmt.Errorf("%w: valid_from must be before valid_until", ErrInvalidCurrency)
+	}
+	if currency.NumericCode != "" && !numericCodePattern.MatchString(currency.NumericCode) {
+		return fmt.Errorf("%w: numeric code must be exactly three digits", ErrInvalidCurrency)
+	}
+	if err := model.ValidateSymbol(currency.HtmlEncodedSymbol); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCurrency, err)
+	}
+	return nil
+}
+
+// ISOCodeValidator only accepts codes on the ISO 4217 list
+type ISOCodeValidator struct{}
+
+// Validate implements CurrencyValidator
+func (ISOCodeValidator) Validate(currency *model.Currency) error {
+	if !model.IsISO4217(currency.Code) {
+		return fmt.Errorf("%w: code %q is not an ISO 4217 currency code", ErrInvalidCurrency, currency.Code)
+	}
+	return nil
+}
+
+// LenientCodeValidator accepts any three uppercase letters, for crypto or
+// internal currencies that aren't on the ISO 4217 list. It doesn't allow
+// digits: model.NormalizeCode only addresses three letter codes, and that
+// rule wins over validation modes so every stored code stays addressable.
+type LenientCodeValidator struct{}
+
+// Validate implements CurrencyValidator
+func (LenientCodeValidator) Validate(currency *model.Currency) error {
+	if !isoCodePattern.MatchString(currency.Code) {
+		return fmt.Errorf("%w: code %q must be three uppercase letters", ErrInvalidCurrency, currency.Code)
+	}
+	return nil
+}
+
+func isPowerOfTen(n int) bool {
+	if n < 1 {
+		return false
+	}
+	for n%10 == 0 {
+		n /= 10
+	}
+	return n == 1
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeValidatorsByMode(t *testing.T) {
+	tests := []struct {
+		mode string
+		code string
+		ok   bool
+	}{
+		{ValidationModeISO, "USD", true},
+		{ValidationModeISO, "XAU", true},
+		{ValidationModeISO, "BTC", false},
+		{ValidationModeISO, "usd", false},
+		{ValidationModeLenient, "USD", true},
+		{ValidationModeLenient, "BTC", true},
+		// Digits aren't allowed in any mode, as NormalizeCode couldn't address them
+		{ValidationModeLenient, "US1", false},
+		{ValidationModeLenient, "123", false},
+		{ValidationModeLenient, "usd", false},
+	}
+
+	for _, tt := range tests {
+		validator, err := NewCurrencyValidator(tt.mode)
+		if !assert.NoError(t, err, tt.mode) {
+			continue
+		}
+		err = validator.Validate(&model.Currency{Code: tt.code, Description: "Test currency"})
+		if tt.ok {
+			assert.NoError(t, err, "%s %s", tt.mode, tt.code)
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidCurrency, "%s %s", tt.mode, tt.code)
+		}
+	}
+}
+
+func TestRequiredFieldsValidator(t *testing.T) {
+	from := time.Date(2002, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := from.AddDate(10, 0, 0)
+	valid := func() *model.Currency {
+		return &model.Currency{Code: "EUR", Description: "Euro", Factor: 100, RoundingMode: money.RoundHalfUp, NumericCode: "978", HtmlEncodedSymbol: "&euro;", ValidFrom: &from, ValidUntil: &until}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*model.Currency)
+		want   string
+	}{
+		{"valid", func(*model.Currency) {}, ""},
+		// CreateCurrency fills in a default factor and rounding mode
+		{"default factor and rounding mode", func(c *model.Currency) { c.Factor, c.RoundingMode = 0, "" }, ""},
+		{"missing code", func(c *model.Currency) { c.Code = "" }, "code is required"},
+		{"missing description", func(c *model.Currency) { c.Description = "" }, "description is required"},
+		{"factor not a power of ten", func(c *model.Currency) { c.Factor = 7 }, "power of ten"},
+		{"negative factor", func(c *model.Currency) { c.Factor = -100 }, "power of ten"},
+		{"unknown rounding mode", func(c *model.Currency) { c.RoundingMode = "ceiling" }, "rounding mode"},
+		{"empty validity range", func(c *model.Currency) { c.ValidUntil = &from }, "valid_from must be before valid_until"},
+		{"open validity range", func(c *model.Currency) { c.ValidUntil = nil }, ""},
+		{"numeric code too short", func(c *model.Currency) { c.NumericCode = "97" }, "numeric code"},
+		{"unsafe symbol", func(c *model.Currency) { c.HtmlEncodedSymbol = "<b>€</b>" }, "symbol"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currency := valid()
+			tt.modify(currency)
+			err := RequiredFieldsValidator{}.Validate(currency)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidCurrency)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestCodeValidatorsIgnoreOtherFields(t *testing.T) {
+	// Code validators only look at the code; missing fields are left to
+	// RequiredFieldsValidator
+	assert.NoError(t, ISOCodeValidator{}.Validate(&model.Currency{Code: "USD", Factor: 7}))
+	assert.NoError(t, LenientCodeValidator{}.Validate(&model.Currency{Code: "BTC", Factor: 7}))
+	assert.ErrorIs(t, ISOCodeValidator{}.Validate(&model.Currency{Code: "BTC", Description: "Bitcoin"}), ErrInvalidCurrency)
+}
+
+// recordingValidator records that it ran and returns err
+type recordingValidator struct {
+	name string
+	err  error
+	ran  *[]string
+}
+
+func (v recordingValidator) Validate(*model.Currency) error {
+	*v.ran = append(*v.ran, v.name)
+	return v.err
+}
+
+func TestCompositeValidatorChaining(t *testing.T) {
+	first, second := errors.New("first"), errors.New("second")
+
+	tests := []struct {
+		name    string
+		errs    []error
+		wantErr error
+		wantRan []string
+	}{
+		{"all pass", []error{nil, nil, nil}, nil, []string{"a", "b", "c"}},
+		{"stops at the first error", []error{nil, first, second}, first, []string{"a", "b"}},
+		{"first validator fails", []error{first, nil, nil}, first, []string{"a"}},
+		{"last validator fails", []error{nil, nil, second}, second, []string{"a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			composite := CompositeValidator{}
+			for i, err := range tt.errs {
+				composite = append(composite, recordingValidator{name: string(rune('a' + i)), err: err, ran: &ran})
+			}
+
+			err := composite.Validate(&model.Currency{Code: "USD"})
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantRan, ran)
+		})
+	}
+
+	assert.NoError(t, CompositeValidator{}.Validate(&model.Currency{}), "an empty chain accepts everything")
+}
+
+func TestNewCurrencyValidatorChecksRequiredFieldsBeforeCode(t *testing.T) {
+	for _, mode := range []string{ValidationModeISO, ValidationModeLenient} {
+		validator, err := NewCurrencyValidator(mode)
+		require.NoError(t, err)
+
+		// An invalid code with a missing description reports the description
+		err = validator.Validate(&model.Currency{Code: "12"})
+		assert.ErrorIs(t, err, ErrInvalidCurrency, mode)
+		assert.ErrorContains(t, err, "description is required", mode)
+	}
+
+	_, err := NewCurrencyValidator("strict")
+	assert.Error(t, err)
+}
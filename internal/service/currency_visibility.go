@@ -0,0 +1,38 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+)
+
+// visibilityResolution is how finely listings follow visible_after. The
+// time they are listed at is rounded down to it, so that list pages stay
+// cacheable within it; a soft-launched currency appears at most this late.
+const visibilityResolution = time.Minute
+
+// visibleAt returns the time listings are shown at: now, rounded down to
+// visibilityResolution
+func (s *CurrencyService) visibleAt() *time.Time {
+	at := s.clock.Now().UTC().Truncate(visibilityResolution)
+	return &at
+}
+
+// visibleFilter leaves soft-launched currencies that aren't visible yet out
+// of filter, unless it asks to show hidden currencies
+func (s *CurrencyService) visibleFilter(filter repository.ListFilter) repository.ListFilter {
+	filter.VisibleAt = nil
+	if !filter.ShowHidden {
+		filter.VisibleAt = s.visibleAt()
+	}
+	return filter
+}
+
+// visibleSearch is visibleFilter for search options
+func (s *CurrencyService) visibleSearch(search repository.SearchOptions) repository.SearchOptions {
+	search.VisibleAt = nil
+	if !search.ShowHidden {
+		search.VisibleAt = s.visibleAt()
+	}
+	return search
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// launch is when the soft-launched currency of these tests becomes visible
+var launch = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newSoftLaunchRepo(visibleAfter time.Time) *fakeCurrencyRepo {
+	return newFakeCurrencyRepo(
+		&model.Currency{Code: "EUR", Description: "Euro", Factor: 100, IsActive: true},
+		&model.Currency{Code: "USD", Description: "US Dollar", Factor: 100, IsActive: true},
+		&model.Currency{Code: "XTS", Description: "Test Dollar", Factor: 100, IsActive: true, VisibleAfter: &visibleAfter},
+	)
+}
+
+func listedCodes(t *testing.T, svc *CurrencyService, filter repository.ListFilter) []string {
+	t.Helper()
+	currencies, err := svc.GetAllCurrencies(context.Background(), 50, 0, "code", "asc", filter)
+	require.NoError(t, err)
+	codes := make([]string, len(currencies))
+	for i, currency := range currencies {
+		codes[i] = currency.Code
+	}
+	return codes
+}
+
+func TestSoftLaunchedCurrencyListedFromVisibleAfter(t *testing.T) {
+	svc := newTestCurrencyService(newSoftLaunchRepo(launch))
+	clock := &fakeClock{now: launch.Add(-time.Second)}
+	svc.clock = clock
+	ctx := context.Background()
+	filter := repository.ListFilter{ActiveOnly: true}
+
+	assert.Equal(t, []string{"EUR", "USD"}, listedCodes(t, svc, filter))
+	count, err := svc.CountFilteredCurrencies(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// It is still stored, and listed on request
+	_, err = svc.GetCurrencyByCode(ctx, "XTS")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EUR", "USD", "XTS"}, listedCodes(t, svc, repository.ListFilter{ActiveOnly: true, ShowHidden: true}))
+
+	// visible_after itself is the first instant it is listed
+	clock.now = launch
+	assert.Equal(t, []string{"EUR", "USD", "XTS"}, listedCodes(t, svc, filter))
+	count, err = svc.CountFilteredCurrencies(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestSoftLaunchIsResolvedToTheMinute(t *testing.T) {
+	svc := newTestCurrencyService(newSoftLaunchRepo(launch.Add(30 * time.Second)))
+	clock := &fakeClock{now: launch.Add(45 * time.Second)}
+	svc.clock = clock
+	filter := repository.ListFilter{ActiveOnly: true}
+
+	// Listings are resolved to the start of the minute, which is still before
+	// visible_after, so the currency shows up late rather than early
+	assert.Equal(t, []string{"EUR", "USD"}, listedCodes(t, svc, filter))
+
+	clock.now = launch.Add(time.Minute)
+	assert.Equal(t, []string{"EUR", "USD", "XTS"}, listedCodes(t, svc, filter))
+}
+
+func TestCachedListingsFollowVisibleAfter(t *testing.T) {
+	repo := newSoftLaunchRepo(launch)
+	svc, _ := newCachedTestCurrencyService(t, repo)
+	clock := &fakeClock{now: launch.Add(-time.Minute)}
+	svc.clock = clock
+	filter := repository.ListFilter{ActiveOnly: true}
+
+	assert.Equal(t, []string{"EUR", "USD"}, listedCodes(t, svc, filter))
+	assert.Equal(t, []string{"EUR", "USD"}, listedCodes(t, svc, filter))
+	assert.Equal(t, 1, repo.GetAllCalls(), "second listing within the minute wasn't cached")
+
+	// Nothing is written when visible_after passes, so the cached page
+	// mustn't outlive it
+	clock.now = launch
+	assert.Equal(t, []string{"EUR", "USD", "XTS"}, listedCodes(t, svc, filter))
+}
+
+func TestSoftLaunchedCurrencyLeftOutOfSearchesAndCounts(t *testing.T) {
+	svc := newTestCurrencyService(newSoftLaunchRepo(launch))
+	clock := &fakeClock{now: launch.Add(-time.Second)}
+	svc.clock = clock
+	ctx := context.Background()
+
+	count, err := svc.CountCurrencies(ctx, repository.SearchOptions{Term: "dollar"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	count, err = svc.CountCurrencies(ctx, repository.SearchOptions{}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	count, err = svc.CountCurrencies(ctx, repository.SearchOptions{Term: "dollar", ShowHidden: true}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	groups, err := svc.GetCurrenciesGroupedByFactor(ctx, repository.SearchOptions{})
+	require.NoError(t, err)
+	assert.Len(t, groups[100], 2)
+
+	clock.now = launch
+	count, err = svc.CountCurrencies(ctx, repository.SearchOptions{Term: "dollar"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
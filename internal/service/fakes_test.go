@@ -0,0 +1,589 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/auth"
+	"github.com/Tarifsiz/go-currency-api/internal/config"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/money"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// testPagination matches the default page sizes of the handlers
+var testPagination = config.PaginationConfig{DefaultLimit: 50, MaxLimit: 100}
+
+// newTestCurrencyService returns a lenient currency service over repo with
+// Redis caching disabled
+func newTestCurrencyService(repo repository.CurrencyRepositoryInterface) *CurrencyService {
+	validator, err := NewCurrencyValidator(ValidationModeLenient)
+	if err != nil {
+		panic(err)
+	}
+	return NewCurrencyService(repo, nil, config.CurrencyConfig{ValidationMode: ValidationModeLenient}, config.CacheConfig{}, testPagination, validator).(*CurrencyService)
+}
+
+// newCachedTestCurrencyService returns a lenient currency service over repo
+// caching in a fresh miniredis server
+func newCachedTestCurrencyService(t *testing.T, repo repository.CurrencyRepositoryInterface) (*CurrencyService, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	svc := newTestCurrencyService(repo)
+	svc.redisClient = client
+	svc.cacheCfg = config.CacheConfig{TTL: 15 * time.Minute, MaxListPages: 10}
+	svc.cacheTimeout = svc.cacheCfg.TTL
+	return svc, server
+}
+
+// fakeClock is a Clock stopped at now until a test moves it
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// userContext returns a context carrying an authenticated user
+func userContext() context.Context {
+	return auth.WithUserID(context.Background(), uuid.New())
+}
+
+// fakeCurrencyRepo is an in-memory CurrencyRepositoryInterface keyed on the
+// upper-cased code. Methods a test doesn't need are left to the embedded nil
+// interface, so calling one panics.
+type fakeCurrencyRepo struct {
+	repository.CurrencyRepositoryInterface
+
+	mu             sync.Mutex
+	currencies     map[string]*model.Currency
+	getByCodeCalls int
+	getAllCalls    int
+
+	// updateErrs makes UpdateFields and UpdateFieldsBatch fail for a code
+	updateErrs map[string]error
+
+	// rated lists the codes with a rate against each base, for GetWithoutRates
+	rated map[string][]string
+
+	// changeLog is returned by GetChangeLog for matching codes
+	changeLog []*model.ChangeLog
+}
+
+func newFakeCurrencyRepo(currencies ...*model.Currency) *fakeCurrencyRepo {
+	repo := &fakeCurrencyRepo{currencies: make(map[string]*model.Currency)}
+	for _, currency := range currencies {
+		repo.currencies[strings.ToUpper(currency.Code)] = currency
+	}
+	return repo
+}
+
+func (r *fakeCurrencyRepo) GetByCode(ctx context.Context, code string) (*model.Currency, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.getByCodeCalls++
+	currency, ok := r.currencies[strings.ToUpper(code)]
+	if !ok {
+		return nil, apperrors.ErrCurrencyNotFound
+	}
+	copied := *currency
+	return &copied, nil
+}
+
+func (r *fakeCurrencyRepo) Create(ctx context.Context, currency *model.Currency) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := strings.ToUpper(currency.Code)
+	if _, ok := r.currencies[key]; ok {
+		return fmt.Errorf("%w: %s", apperrors.ErrDuplicateCurrency, currency.Code)
+	}
+	copied := *currency
+	r.currencies[key] = &copied
+	return nil
+}
+
+// CreateBatch creates currencies in order. Unlike the real repository it
+// doesn't roll back the currencies created before a failing one.
+func (r *fakeCurrencyRepo) CreateBatch(ctx context.Context, currencies []*model.Currency) error {
+	for i, currency := range currencies {
+		if err := r.Create(ctx, currency); err != nil {
+			return fmt.Errorf("failed to create currencies in batch: %w", &repository.BatchError{Index: i, Code: currency.Code, Err: err})
+		}
+	}
+	return nil
+}
+
+func (r *fakeCurrencyRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Currency, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, currency := range r.currencies {
+		if currency.ID == id {
+			copied := *currency
+			return &copied, nil
+		}
+	}
+	return nil, apperrors.ErrCurrencyNotFound
+}
+
+func (r *fakeCurrencyRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, currency := range r.currencies {
+		if currency.ID == id {
+			delete(r.currencies, key)
+			return nil
+		}
+	}
+	return apperrors.ErrCurrencyNotFound
+}
+
+func (r *fakeCurrencyRepo) GetCount(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.currencies)), nil
+}
+
+func (r *fakeCurrencyRepo) CountFiltered(ctx context.Context, filter repository.ListFilter) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, currency := range r.currencies {
+		if (!filter.ActiveOnly || currency.IsActive) && !hiddenAt(currency, filter.VisibleAt) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CountMatching supports factor, ActiveOnly and VisibleAt; a search term matches
+// descriptions containing it
+func (r *fakeCurrencyRepo) CountMatching(ctx context.Context, search repository.SearchOptions, factor int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, currency := range r.currencies {
+		if search.ActiveOnly && !currency.IsActive || hiddenAt(currency, search.VisibleAt) {
+			continue
+		}
+		if factor > 0 && currency.Factor != factor {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(currency.Description), strings.ToLower(search.Term)) {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// SearchByName returns a page of the currencies whose description or code
+// matches search as likePattern would, ordered by code
+func (r *fakeCurrencyRepo) SearchByName(ctx context.Context, search repository.SearchOptions, limit, offset int) ([]*model.Currency, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	found := r.searched(search)
+	if offset > len(found) {
+		offset = len(found)
+	}
+	found = found[offset:]
+	if limit > 0 && limit < len(found) {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func (r *fakeCurrencyRepo) CountByName(ctx context.Context, search repository.SearchOptions) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.searched(search))), nil
+}
+
+// searched returns copies of the currencies matching search, ordered by code
+func (r *fakeCurrencyRepo) searched(search repository.SearchOptions) []*model.Currency {
+	term := strings.ToLower(search.Term)
+	var found []*model.Currency
+	for _, currency := range r.currencies {
+		if search.ActiveOnly && !currency.IsActive || hiddenAt(currency, search.VisibleAt) {
+			continue
+		}
+		value := strings.ToLower(currency.Description)
+		if search.Field == repository.SearchFieldCode {
+			value = strings.ToLower(currency.Code)
+		}
+		matched := strings.Contains(value, term)
+		switch search.Match {
+		case repository.SearchMatchPrefix:
+			matched = strings.HasPrefix(value, term)
+		case repository.SearchMatchExact:
+			matched = value == term
+		}
+		if matched {
+			copied := *currency
+			found = append(found, &copied)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Code < found[j].Code })
+	return found
+}
+
+func (r *fakeCurrencyRepo) GetCountsByFactor(ctx context.Context) (map[int]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[int]int64)
+	for _, currency := range r.currencies {
+		counts[currency.Factor]++
+	}
+	return counts, nil
+}
+
+func (r *fakeCurrencyRepo) GetByCodes(ctx context.Context, codes []string) ([]*model.Currency, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []*model.Currency
+	for _, code := range codes {
+		if currency, ok := r.currencies[strings.ToUpper(code)]; ok {
+			copied := *currency
+			found = append(found, &copied)
+		}
+	}
+	return found, nil
+}
+
+// GetWithoutRates returns the active currencies other than base that
+// aren't listed in rated[base], ordered by code
+func (r *fakeCurrencyRepo) GetWithoutRates(ctx context.Context, base string) ([]*model.Currency, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rated := map[string]bool{base: true}
+	for _, code := range r.rated[base] {
+		rated[code] = true
+	}
+	var found []*model.Currency
+	for code, currency := range r.currencies {
+		if currency.IsActive && !rated[code] {
+			copied := *currency
+			found = append(found, &copied)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Code < found[j].Code })
+	return found, nil
+}
+
+func (r *fakeCurrencyRepo) GetChangeLog(ctx context.Context, code string, limit, offset int) ([]*model.ChangeLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var changes []*model.ChangeLog
+	for _, change := range r.changeLog {
+		if strings.EqualFold(change.CurrencyCode, code) {
+			changes = append(changes, change)
+		}
+	}
+	if offset >= len(changes) {
+		return []*model.ChangeLog{}, nil
+	}
+	changes = changes[offset:]
+	if limit > 0 && limit < len(changes) {
+		changes = changes[:limit]
+	}
+	return changes, nil
+}
+
+func (r *fakeCurrencyRepo) CountChangeLog(ctx context.Context, code string) (int64, error) {
+	changes, err := r.GetChangeLog(ctx, code, 0, 0)
+	return int64(len(changes)), err
+}
+
+// Update replaces the columns the real repository writes on a full update
+// if the stored currency is still at currency.Version
+func (r *fakeCurrencyRepo) Update(ctx context.Context, currency *model.Currency) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.currencies[strings.ToUpper(currency.Code)]
+	if !ok {
+		return fmt.Errorf("%w with code %s", apperrors.ErrCurrencyNotFound, currency.Code)
+	}
+	if stored.Version != currency.Version {
+		return fmt.Errorf("%w: currency %s is no longer at version %d", apperrors.ErrStaleUpdate, currency.Code, currency.Version)
+	}
+
+	updated := *stored
+	updated.NumericCode = currency.NumericCode
+	updated.Description = currency.Description
+	updated.AmountDisplayFormat = currency.AmountDisplayFormat
+	updated.HtmlEncodedSymbol = currency.HtmlEncodedSymbol
+	updated.Factor = currency.Factor
+	updated.RoundingMode = currency.RoundingMode
+	updated.ValidFrom, updated.ValidUntil, updated.VisibleAfter = currency.ValidFrom, currency.ValidUntil, currency.VisibleAfter
+	updated.UpdatedBy = currency.UpdatedBy
+	updated.Version++
+	currency.Version = updated.Version
+	r.currencies[strings.ToUpper(currency.Code)] = &updated
+	return nil
+}
+
+func (r *fakeCurrencyRepo) UpdateFields(ctx context.Context, update repository.CurrencyFieldUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	updated, err := r.updated(update)
+	if err != nil {
+		return err
+	}
+	r.currencies[strings.ToUpper(update.Code)] = updated
+	return nil
+}
+
+func (r *fakeCurrencyRepo) UpdateFieldsBatch(ctx context.Context, updates []repository.CurrencyFieldUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Nothing is stored until every update has succeeded
+	staged := make([]*model.Currency, len(updates))
+	for i, update := range updates {
+		updated, err := r.updated(update)
+		if err != nil {
+			return fmt.Errorf("failed to update currencies in batch: %w", &repository.BatchError{Index: i, Code: update.Code, Err: err})
+		}
+		staged[i] = updated
+	}
+	for _, updated := range staged {
+		r.currencies[strings.ToUpper(updated.Code)] = updated
+	}
+	return nil
+}
+
+// updated returns a copy of the stored currency with update applied
+func (r *fakeCurrencyRepo) updated(update repository.CurrencyFieldUpdate) (*model.Currency, error) {
+	if err := r.updateErrs[update.Code]; err != nil {
+		return nil, err
+	}
+	stored, ok := r.currencies[strings.ToUpper(update.Code)]
+	if !ok {
+		return nil, fmt.Errorf("%w with code %s", apperrors.ErrCurrencyNotFound, update.Code)
+	}
+	if update.Version > 0 && stored.Version != update.Version {
+		return nil, fmt.Errorf("%w: currency %s is no longer at version %d", apperrors.ErrStaleUpdate, update.Code, update.Version)
+	}
+
+	currency := *stored
+	for column, value := range update.Fields {
+		switch column {
+		case "description":
+			currency.Description = value.(string)
+		case "amount_display_format":
+			currency.AmountDisplayFormat = value.(string)
+		case "html_encoded_symbol":
+			currency.HtmlEncodedSymbol = value.(string)
+		case "factor":
+			currency.Factor = value.(int)
+		case "rounding_mode":
+			currency.RoundingMode = value.(money.RoundingMode)
+		case "is_active":
+			currency.IsActive = value.(bool)
+		}
+	}
+	currency.Version++
+	return &currency, nil
+}
+
+// GetAll returns the currencies ordered by code, ignoring the other sort
+// fields and every filter but ActiveOnly and VisibleAt
+func (r *fakeCurrencyRepo) GetAll(ctx context.Context, limit, offset int, sortField, sortOrder string, filter repository.ListFilter) ([]*model.Currency, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.getAllCalls++
+
+	currencies := make([]*model.Currency, 0, len(r.currencies))
+	for _, currency := range r.currencies {
+		if filter.ActiveOnly && !currency.IsActive || hiddenAt(currency, filter.VisibleAt) {
+			continue
+		}
+		copied := *currency
+		currencies = append(currencies, &copied)
+	}
+	sort.Slice(currencies, func(i, j int) bool {
+		if sortOrder == "desc" {
+			return currencies[i].Code > currencies[j].Code
+		}
+		return currencies[i].Code < currencies[j].Code
+	})
+
+	if offset >= len(currencies) {
+		return []*model.Currency{}, nil
+	}
+	currencies = currencies[offset:]
+	if limit > 0 && limit < len(currencies) {
+		currencies = currencies[:limit]
+	}
+	return currencies, nil
+}
+
+// hiddenAt reports whether currency is soft-launched after at, as the
+// repository's visible_after filter decides
+func hiddenAt(currency *model.Currency, at *time.Time) bool {
+	return at != nil && currency.VisibleAfter != nil && currency.VisibleAfter.After(*at)
+}
+
+// GetAllCalls returns how many times GetAll was called
+func (r *fakeCurrencyRepo) GetAllCalls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.getAllCalls
+}
+
+// GetByCodeCalls returns how many times GetByCode was called
+func (r *fakeCurrencyRepo) GetByCodeCalls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.getByCodeCalls
+}
+
+// fakeRateRepo is an ExchangeRateRepositoryInterface reporting fixed pair
+// statistics and recording the rates stored through it
+type fakeRateRepo struct {
+	repository.ExchangeRateRepositoryInterface
+
+	pairs   int64
+	stalest *time.Time
+
+	mu        sync.Mutex
+	stored    []*model.ExchangeRate
+	createErr error // returned by CreateBatch and UpsertBatch when set
+	latestErr error // returned by GetLatest when set
+	fetches   int   // calls to GetLatestAmong
+}
+
+func (r *fakeRateRepo) Create(ctx context.Context, rate *model.ExchangeRate) error {
+	return r.CreateBatch(ctx, []*model.ExchangeRate{rate})
+}
+
+// preferredRate reports whether a ranks before b for the same pair
+func preferredRate(a, b *model.ExchangeRate) bool {
+	if a.Pinned != b.Pinned {
+		return a.Pinned
+	}
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return a.Timestamp.After(b.Timestamp)
+}
+
+// Unpin removes the pinned rates stored for the pair
+func (r *fakeRateRepo) Unpin(ctx context.Context, fromCode, toCode string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.stored[:0]
+	for _, rate := range r.stored {
+		if !(rate.Pinned && rate.FromCode == fromCode && rate.ToCode == toCode) {
+			kept = append(kept, rate)
+		}
+	}
+	removed := int64(len(r.stored) - len(kept))
+	r.stored = kept
+	return removed, nil
+}
+
+func (r *fakeRateRepo) CreateBatch(ctx context.Context, rates []*model.ExchangeRate) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stored = append(r.stored, rates...)
+	return nil
+}
+
+// UpsertBatch replaces stored rates with the same pair and timestamp and
+// appends the rest
+func (r *fakeRateRepo) UpsertBatch(ctx context.Context, rates []*model.ExchangeRate) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+next:
+	for _, rate := range rates {
+		for i, stored := range r.stored {
+			if stored.FromCode == rate.FromCode && stored.ToCode == rate.ToCode && stored.Timestamp.Equal(rate.Timestamp) {
+				r.stored[i] = rate
+				continue next
+			}
+		}
+		r.stored = append(r.stored, rate)
+	}
+	return nil
+}
+
+// Stored returns the rates stored so far
+func (r *fakeRateRepo) Stored() []*model.ExchangeRate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*model.ExchangeRate(nil), r.stored...)
+}
+
+// GetLatestAmong returns the preferred stored rate of every pair between
+// two of codes: pinned before unpinned, then by priority and recency
+func (r *fakeRateRepo) GetLatestAmong(ctx context.Context, codes []string) ([]*model.ExchangeRate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fetches++
+
+	among := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		among[code] = true
+	}
+	latest := make(map[[2]string]*model.ExchangeRate)
+	for _, rate := range r.stored {
+		if !among[rate.FromCode] || !among[rate.ToCode] {
+			continue
+		}
+		key := [2]string{rate.FromCode, rate.ToCode}
+		if latest[key] == nil || preferredRate(rate, latest[key]) {
+			latest[key] = rate
+		}
+	}
+
+	rates := make([]*model.ExchangeRate, 0, len(latest))
+	for _, rate := range latest {
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// GetLatest returns the preferred stored rate for the pair
+func (r *fakeRateRepo) GetLatest(ctx context.Context, fromCode, toCode string) (*model.ExchangeRate, error) {
+	if r.latestErr != nil {
+		return nil, r.latestErr
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *model.ExchangeRate
+	for _, rate := range r.stored {
+		if rate.FromCode == fromCode && rate.ToCode == toCode && (latest == nil || preferredRate(rate, latest)) {
+			latest = rate
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("%w for %s/%s", apperrors.ErrExchangeRateNotFound, fromCode, toCode)
+	}
+	return latest, nil
+}
+
+func (r *fakeRateRepo) CountPairs(ctx context.Context, fromCode string) (int64, error) {
+	return r.pairs, nil
+}
+
+func (r *fakeRateRepo) GetStalestTimestamp(ctx context.Context) (*time.Time, error) {
+	return r.stalest, nil
+}
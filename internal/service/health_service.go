@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/database"
+	"github.com/Tarifsiz/go-currency-api/internal/logging"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// healthCheckTimeout bounds how long a readiness check waits on each dependency
+const healthCheckTimeout = 2 * time.Second
+
+// Health statuses reported for the service and for each dependency
+const (
+	HealthStatusUp       = "up"
+	HealthStatusDown     = "down"
+	HealthStatusDegraded = "degraded"
+)
+
+// HealthServiceInterface defines dependency health checks
+type HealthServiceInterface interface {
+	CheckReadiness(ctx context.Context) *HealthReport
+}
+
+// HealthReport is the result of checking every dependency. Status is down
+// when a critical dependency is down, and degraded when only a non-critical
+// one is.
+type HealthReport struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyHealth `json:"dependencies"`
+	Timestamp    model.Timestamp             `json:"timestamp"`
+}
+
+// DependencyHealth reports the status of a single dependency
+type DependencyHealth struct {
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// HealthService implements the HealthServiceInterface
+type HealthService struct {
+	db          *gorm.DB
+	redisClient *redis.Client
+}
+
+// NewHealthService creates a new health service instance
+func NewHealthService(db *gorm.DB, redisClient *redis.Client) HealthServiceInterface {
+	return &HealthService{
+		db:          db,
+		redisClient: redisClient,
+	}
+}
+
+// CheckReadiness pings Postgres and Redis. Postgres is critical; Redis is not,
+// since the cache and rate limiter fall back gracefully when it is down.
+func (s *HealthService) CheckReadiness(ctx context.Context) *HealthReport {
+	report := &HealthReport{
+		Status: HealthStatusUp,
+		Dependencies: map[string]DependencyHealth{
+			"database": checkDependency(ctx, "database", true, func(ctx context.Context) error {
+				return database.Ping(ctx, s.db)
+			}),
+			"redis": checkDependency(ctx, "redis", false, func(ctx context.Context) error {
+				return s.redisClient.Ping(ctx).Err()
+			}),
+		},
+		Timestamp: model.Now(),
+	}
+
+	for _, dep := range report.Dependencies {
+		if dep.Status == HealthStatusUp {
+			continue
+		}
+		if dep.Critical {
+			report.Status = HealthStatusDown
+			break
+		}
+		report.Status = HealthStatusDegraded
+	}
+
+	return report
+}
+
+// checkDependency runs ping with healthCheckTimeout, reports the outcome
+// and logs the failure. The error itself isn't returned because health
+// endpoints are unauthenticated and it may reveal connection details.
+func checkDependency(ctx context.Context, name string, critical bool, ping func(context.Context) error) DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := ping(ctx)
+	health := DependencyHealth{
+		Status:    HealthStatusUp,
+		Critical:  critical,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		health.Status = HealthStatusDown
+		logging.FromContext(ctx).Warn("health check failed", "dependency", name, "error", err)
+	}
+	return health
+}
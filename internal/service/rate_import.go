@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// ImportStatusImported is the status of a rate row that was stored
+const ImportStatusImported = "imported"
+
+const (
+	// maxRateIntegerDigits is how many integer digits the exchange_rates.rate
+	// column, numeric(24,12), holds
+	maxRateIntegerDigits = 12
+	// maxRateSourceLength is the length of the exchange_rates.source column
+	maxRateSourceLength = 50
+)
+
+// ErrInvalidRate is returned for an imported rate row that is well-formed
+// but can't be stored, e.g. a non-positive rate or an unknown currency
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// rateImportColumns are the columns a rate import CSV must have
+var rateImportColumns = []string{"base", "quote", "rate", "as_of", "source"}
+
+// RateImportRow is one parsed row of a rate import file. Err is set when
+// the row itself is unusable, e.g. a rate that isn't a number, without the
+// file being malformed.
+type RateImportRow struct {
+	Line int
+	Rate *model.ExchangeRate
+	Err  error
+}
+
+// RateImportRowResult reports what happened to one row of a rate import
+type RateImportRowResult struct {
+	Line   int    `json:"line"`
+	Base   string `json:"base"`
+	Quote  string `json:"quote"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RateImportSummary reports the outcome of a rate import
+type RateImportSummary struct {
+	Imported int                   `json:"imported"`
+	Errored  int                   `json:"errored"`
+	Rows     []RateImportRowResult `json:"rows"`
+}
+
+// ParseRateImport parses a rate import CSV with a header row naming the
+// base, quote, rate, as_of and source columns, in any order. as_of is a
+// YYYY-MM-DD date, read as midnight UTC, or an RFC 3339 timestamp.
+func ParseRateImport(r io.Reader) ([]RateImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read CSV header: %v", ErrMalformedImport, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		columns[name] = i
+	}
+	if len(columns) != len(rateImportColumns) {
+		return nil, fmt.Errorf("%w: CSV columns must be %s", ErrMalformedImport, strings.Join(rateImportColumns, ","))
+	}
+	for _, name := range rateImportColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing required CSV column %q", ErrMalformedImport, name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		return strings.TrimSpace(record[columns[name]])
+	}
+
+	var rows []RateImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Covers wrong field counts and broken quoting
+			return nil, fmt.Errorf("%w: %v", ErrMalformedImport, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := RateImportRow{
+			Line: line,
+			Rate: &model.ExchangeRate{
+				FromCode: field(record, "base"),
+				ToCode:   field(record, "quote"),
+				Source:   field(record, "source"),
+			},
+		}
+		if rate := field(record, "rate"); rate != "" {
+			if row.Rate.Rate, err = decimal.NewFromString(rate); err != nil {
+				row.Err = fmt.Errorf("%w: rate %q is not a number", ErrInvalidRate, rate)
+			}
+		}
+		if row.Err == nil {
+			if row.Rate.Timestamp, err = parseRateTime(field(record, "as_of")); err != nil {
+				row.Err = fmt.Errorf("%w: %v", ErrInvalidRate, err)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseRateTime parses the as_of column of a rate import
+func parseRateTime(raw string) (time.Time, error) {
+	if day, err := time.Parse(time.DateOnly, raw); err == nil {
+		return day, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("as_of %q must be YYYY-MM-DD or an RFC 3339 timestamp", raw)
+	}
+	return t.UTC(), nil
+}
+
+// ImportRates validates every row and then upserts the valid ones in one
+// batch, replacing any rate already stored for the same pair and as_of
+// time. Invalid rows are reported and left out; nothing is written for them.
+func (s *RateService) ImportRates(ctx context.Context, rows []RateImportRow) (*RateImportSummary, error) {
+	summary := &RateImportSummary{Rows: make([]RateImportRowResult, len(rows))}
+
+	// Validate the rows on their own before looking up their currencies
+	codes := make([]string, 0, 2*len(rows))
+	for i, row := range rows {
+		result := &summary.Rows[i]
+		*result = RateImportRowResult{Line: row.Line, Base: row.Rate.FromCode, Quote: row.Rate.ToCode}
+
+		err := row.Err
+		if err == nil {
+			err = normalizeRate(row.Rate)
+		}
+		if err != nil {
+			result.Status, result.Error = ImportStatusError, err.Error()
+			continue
+		}
+		result.Base, result.Quote = row.Rate.FromCode, row.Rate.ToCode
+		codes = append(codes, row.Rate.FromCode, row.Rate.ToCode)
+	}
+
+	existing, err := s.currencyRepo.GetByCodes(ctx, codes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check imported currencies: %w", err)
+	}
+	known := make(map[string]bool, len(existing))
+	for _, currency := range existing {
+		known[model.CanonicalCode(currency.Code)] = true
+	}
+
+	type quote struct {
+		base, quote string
+		at          int64
+	}
+	seen := make(map[quote]int, len(rows))
+	var rates []*model.ExchangeRate
+	var rateIdx []int
+	for i, row := range rows {
+		result := &summary.Rows[i]
+		if result.Status == ImportStatusError {
+			continue
+		}
+
+		var err error
+		key := quote{row.Rate.FromCode, row.Rate.ToCode, row.Rate.Timestamp.UnixMicro()}
+		switch first, dup := seen[key]; {
+		case !known[row.Rate.FromCode]:
+			err = fmt.Errorf("%w: unknown currency %s", ErrInvalidRate, row.Rate.FromCode)
+		case !known[row.Rate.ToCode]:
+			err = fmt.Errorf("%w: unknown currency %s", ErrInvalidRate, row.Rate.ToCode)
+		case dup:
+			err = fmt.Errorf("%w: %s/%s as of the same time already appears on line %d", ErrInvalidRate, row.Rate.FromCode, row.Rate.ToCode, first)
+		}
+		if err != nil {
+			result.Status, result.Error = ImportStatusError, err.Error()
+			continue
+		}
+
+		seen[key] = row.Line
+		rates = append(rates, row.Rate)
+		rateIdx = append(rateIdx, i)
+	}
+
+	if err := s.rateRepo.UpsertBatch(ctx, rates); err != nil {
+		return nil, fmt.Errorf("failed to import exchange rates: %w", err)
+	}
+	for _, i := range rateIdx {
+		summary.Rows[i].Status = ImportStatusImported
+	}
+
+	summary.Imported = len(rateIdx)
+	summary.Errored = len(rows) - len(rateIdx)
+	return summary, nil
+}
+
+// normalizeRate canonicalizes the codes of an imported rate and checks what
+// can be checked without the database
+func normalizeRate(rate *model.ExchangeRate) error {
+	var err error
+	if rate.FromCode, err = model.NormalizeCode(rate.FromCode); err != nil {
+		return fmt.Errorf("%w: base: %v", ErrInvalidRate, err)
+	}
+	if rate.ToCode, err = model.NormalizeCode(rate.ToCode); err != nil {
+		return fmt.Errorf("%w: quote: %v", ErrInvalidRate, err)
+	}
+	if rate.FromCode == rate.ToCode {
+		return fmt.Errorf("%w: base and quote are both %s", ErrInvalidRate, rate.FromCode)
+	}
+	if !rate.Rate.IsPositive() {
+		return fmt.Errorf("%w: rate must be greater than zero", ErrInvalidRate)
+	}
+	if digits := rate.Rate.Truncate(0).String(); len(digits) > maxRateIntegerDigits {
+		return fmt.Errorf("%w: rate must have at most %d integer digits", ErrInvalidRate, maxRateIntegerDigits)
+	}
+	if rate.Source == "" {
+		return fmt.Errorf("%w: source can't be empty", ErrInvalidRate)
+	}
+	if len(rate.Source) > maxRateSourceLength {
+		return fmt.Errorf("%w: source must be at most %d characters", ErrInvalidRate, maxRateSourceLength)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateImportService(rateRepo *fakeRateRepo) *RateService {
+	currencyRepo := newFakeCurrencyRepo(
+		&model.Currency{Code: "USD", Factor: 100, IsActive: true},
+		&model.Currency{Code: "EUR", Factor: 100, IsActive: true},
+		&model.Currency{Code: "JPY", Factor: 1, IsActive: true},
+	)
+	return NewRateService(rateRepo, currencyRepo).(*RateService)
+}
+
+func importRates(t *testing.T, svc *RateService, csv string) *RateImportSummary {
+	t.Helper()
+	rows, err := ParseRateImport(strings.NewReader(csv))
+	require.NoError(t, err)
+	summary, err := svc.ImportRates(context.Background(), rows)
+	require.NoError(t, err)
+	return summary
+}
+
+func TestImportRatesStoresValidRows(t *testing.T) {
+	rateRepo := &fakeRateRepo{}
+	svc := newRateImportService(rateRepo)
+
+	summary := importRates(t, svc, "base,quote,rate,as_of,source\n"+
+		"usd,EUR,0.92,2024-01-15,ecb\n"+
+		"EUR,JPY,161.5,2024-01-15T12:00:00+01:00,manual\n")
+
+	assert.Equal(t, 2, summary.Imported)
+	assert.Equal(t, 0, summary.Errored)
+	assert.Equal(t, []RateImportRowResult{
+		{Line: 2, Base: "USD", Quote: "EUR", Status: ImportStatusImported},
+		{Line: 3, Base: "EUR", Quote: "JPY", Status: ImportStatusImported},
+	}, summary.Rows)
+
+	stored := rateRepo.Stored()
+	require.Len(t, stored, 2)
+	assert.Equal(t, "USD", stored[0].FromCode)
+	assert.True(t, decimal.RequireFromString("0.92").Equal(stored[0].Rate))
+	assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), stored[0].Timestamp)
+	assert.Equal(t, "ecb", stored[0].Source)
+	assert.Equal(t, time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC), stored[1].Timestamp)
+	assert.Equal(t, "manual", stored[1].Source)
+}
+
+func TestImportRatesReplacesRateAtTheSameTime(t *testing.T) {
+	rateRepo := &fakeRateRepo{}
+	svc := newRateImportService(rateRepo)
+
+	importRates(t, svc, "base,quote,rate,as_of,source\nUSD,EUR,0.92,2024-01-15,ecb\n")
+	summary := importRates(t, svc, "source,as_of,rate,quote,base\nmanual,2024-01-15,0.93,EUR,USD\n")
+
+	assert.Equal(t, 1, summary.Imported)
+	stored := rateRepo.Stored()
+	require.Len(t, stored, 1)
+	assert.True(t, decimal.RequireFromString("0.93").Equal(stored[0].Rate))
+	assert.Equal(t, "manual", stored[0].Source)
+}
+
+func TestImportRatesReportsUnknownCurrencies(t *testing.T) {
+	rateRepo := &fakeRateRepo{}
+	svc := newRateImportService(rateRepo)
+
+	summary := importRates(t, svc, "base,quote,rate,as_of,source\n"+
+		"USD,XYZ,1.5,2024-01-15,ecb\n"+
+		"ABC,EUR,1.5,2024-01-15,ecb\n"+
+		"USD,EUR,0.92,2024-01-15,ecb\n")
+
+	assert.Equal(t, 1, summary.Imported)
+	assert.Equal(t, 2, summary.Errored)
+	assert.Equal(t, ImportStatusError, summary.Rows[0].Status)
+	assert.Contains(t, summary.Rows[0].Error, "unknown currency XYZ")
+	assert.Equal(t, ImportStatusError, summary.Rows[1].Status)
+	assert.Contains(t, summary.Rows[1].Error, "unknown currency ABC")
+	assert.Equal(t, ImportStatusImported, summary.Rows[2].Status)
+	require.Len(t, rateRepo.Stored(), 1)
+}
+
+func TestImportRatesReportsBadRows(t *testing.T) {
+	tests := []struct {
+		row  string
+		want string
+	}{
+		{"USD,EUR,0,2024-01-15,ecb", "rate must be greater than zero"},
+		{"USD,EUR,-1.5,2024-01-15,ecb", "rate must be greater than zero"},
+		{"USD,EUR,,2024-01-15,ecb", "rate must be greater than zero"},
+		{"USD,EUR,abc,2024-01-15,ecb", `rate "abc" is not a number`},
+		{"USD,EUR,1e13,2024-01-15,ecb", "at most 12 integer digits"},
+		{"USD,EUR,0.92,15/01/2024,ecb", `as_of "15/01/2024" must be YYYY-MM-DD or an RFC 3339 timestamp`},
+		{"USD,EUR,0.92,,ecb", "must be YYYY-MM-DD"},
+		{"USD,USD,1,2024-01-15,ecb", "base and quote are both USD"},
+		{"US,EUR,0.92,2024-01-15,ecb", "base"},
+		{"USD,EUR,0.92,2024-01-15,", "source can't be empty"},
+	}
+
+	for _, tt := range tests {
+		rateRepo := &fakeRateRepo{}
+		svc := newRateImportService(rateRepo)
+
+		summary := importRates(t, svc, "base,quote,rate,as_of,source\n"+tt.row+"\n")
+
+		assert.Equal(t, 0, summary.Imported, tt.row)
+		assert.Equal(t, 1, summary.Errored, tt.row)
+		require.Len(t, summary.Rows, 1, tt.row)
+		assert.Equal(t, ImportStatusError, summary.Rows[0].Status, tt.row)
+		assert.Contains(t, summary.Rows[0].Error, tt.want, tt.row)
+		assert.Empty(t, rateRepo.Stored(), tt.row)
+	}
+}
+
+func TestImportRatesReportsDuplicateRows(t *testing.T) {
+	svc := newRateImportService(&fakeRateRepo{})
+
+	summary := importRates(t, svc, "base,quote,rate,as_of,source\n"+
+		"USD,EUR,0.92,2024-01-15,ecb\n"+
+		"USD,EUR,0.93,2024-01-15T00:00:00Z,manual\n")
+
+	assert.Equal(t, 1, summary.Imported)
+	assert.Contains(t, summary.Rows[1].Error, "already appears on line 2")
+}
+
+func TestParseRateImportRejectsMalformedFiles(t *testing.T) {
+	for _, csv := range []string{
+		"",
+		"base,quote,rate,as_of\nUSD,EUR,0.92,2024-01-15\n",
+		"base,quote,rate,as_of,source,extra\n",
+		"base,quote,rate,as_of,base\n",
+		"base,quote,rate,as_of,source\nUSD,EUR,0.92\n",
+	} {
+		_, err := ParseRateImport(strings.NewReader(csv))
+		assert.ErrorIs(t, err, ErrMalformedImport, csv)
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// RateMatrix holds the conversion rates between every two of a set of
+// currencies. Rows[i][j] converts Codes[i] into Codes[j].
+type RateMatrix struct {
+	Codes   []string           `json:"codes"`
+	Rows    [][]RateMatrixCell `json:"rows"`
+	Missing int                `json:"missing"` // Number of cells without a rate
+}
+
+// RateMatrixCell is one rate of a RateMatrix. A cell whose pair has neither
+// a rate of its own nor a cross rate through the pivot currency is Missing
+// and has no rate; cells converting a currency into itself have a rate of 1
+// and no timestamp.
+type RateMatrixCell struct {
+	From          string           `json:"from"`
+	To            string           `json:"to"`
+	Rate          *decimal.Decimal `json:"rate"`
+	RateTimestamp *model.Timestamp `json:"rate_timestamp"`
+	Via           string           `json:"via,omitempty"` // Pivot currency of a cross rate; empty for a direct rate
+	Missing       bool             `json:"missing"`
+}
+
+// ratePair identifies the rates converting from one currency into another
+type ratePair struct {
+	from, to string
+}
+
+// RateMatrix returns the rates between every two of codes, in the order
+// given, with duplicates dropped. Rates are found the way ConvertAmount finds
+// them, directly, inverted or through the pivot currency, but from a single
+// fetch of the latest rates among codes and the pivot. Unknown and inactive
+// currencies are rejected as they are by ConvertAmount.
+func (s *ConversionService) RateMatrix(ctx context.Context, codes []string) (*RateMatrix, error) {
+	unique := make([]string, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		code = model.CanonicalCode(code)
+		if !seen[code] {
+			seen[code] = true
+			unique = append(unique, code)
+		}
+	}
+
+	currencies, err := s.currencyRepo.GetByCodes(ctx, unique)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load currencies: %w", err)
+	}
+	byCode := make(map[string]*model.Currency, len(currencies))
+	for _, currency := range currencies {
+		byCode[model.CanonicalCode(currency.Code)] = currency
+	}
+	for _, code := range unique {
+		if byCode[code] == nil {
+			return nil, fmt.Errorf("%w: %s", apperrors.ErrCurrencyNotFound, code)
+		}
+		if !byCode[code].IsActive {
+			return nil, fmt.Errorf("%w: %s", apperrors.ErrCurrencyInactive, code)
+		}
+	}
+
+	fetch := unique
+	if s.pivot != "" && !seen[s.pivot] {
+		fetch = append(append([]string(nil), unique...), s.pivot)
+	}
+	rates, err := s.rateRepo.GetLatestAmong(ctx, fetch)
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[ratePair]*model.ExchangeRate, len(rates))
+	for _, rate := range rates {
+		latest[ratePair{model.CanonicalCode(rate.FromCode), model.CanonicalCode(rate.ToCode)}] = rate
+	}
+
+	matrix := &RateMatrix{Codes: unique, Rows: make([][]RateMatrixCell, len(unique))}
+	for i, from := range unique {
+		matrix.Rows[i] = make([]RateMatrixCell, len(unique))
+		for j, to := range unique {
+			cell := RateMatrixCell{From: from, To: to}
+			if from == to {
+				one := decimal.NewFromInt(1)
+				cell.Rate = &one
+			} else if rate := s.matrixRate(latest, from, to); rate != nil {
+				cell.Rate = &rate.rate
+				cell.RateTimestamp = model.NewNullableTimestamp(&rate.timestamp)
+				cell.Via = rate.via
+			} else {
+				cell.Missing = true
+				matrix.Missing++
+			}
+			matrix.Rows[i][j] = cell
+		}
+	}
+
+	return matrix, nil
+}
+
+// matrixRate finds the rate for converting from into to among latest as
+// findRate does, returning nil when there is none
+func (s *ConversionService) matrixRate(latest map[ratePair]*model.ExchangeRate, from, to string) *quotedRate {
+	if direct := latestPairRate(latest, from, to); direct != nil {
+		return direct
+	}
+	if s.pivot == "" || from == s.pivot || to == s.pivot {
+		return nil
+	}
+
+	toPivot := latestPairRate(latest, from, s.pivot)
+	fromPivot := latestPairRate(latest, s.pivot, to)
+	if toPivot == nil || fromPivot == nil {
+		return nil
+	}
+
+	cross := &quotedRate{
+		rate:      toPivot.rate.Mul(fromPivot.rate),
+		timestamp: toPivot.timestamp,
+		via:       s.pivot,
+	}
+	if fromPivot.timestamp.Before(cross.timestamp) {
+		cross.timestamp = fromPivot.timestamp
+	}
+	return cross
+}
+
+// latestPairRate returns the rate for from→to among latest, or the inverse
+// of the to→from rate as pairRate does, or nil when neither is there
+func latestPairRate(latest map[ratePair]*model.ExchangeRate, from, to string) *quotedRate {
+	if rate := latest[ratePair{from, to}]; rate != nil {
+		return &quotedRate{rate: rate.Rate, timestamp: rate.Timestamp}
+	}
+	if reverse := latest[ratePair{to, from}]; reverse != nil && !reverse.Rate.IsZero() {
+		return &quotedRate{rate: decimal.NewFromInt(1).Div(reverse.Rate), timestamp: reverse.Timestamp}
+	}
+	return nil
+}
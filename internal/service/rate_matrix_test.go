@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+)
+
+func newMatrixCurrencyRepo() *fakeCurrencyRepo {
+	return newFakeCurrencyRepo(
+		&model.Currency{Code: "USD", Factor: 100, IsActive: true},
+		&model.Currency{Code: "EUR", Factor: 100, IsActive: true},
+		&model.Currency{Code: "GBP", Factor: 100, IsActive: true},
+		&model.Currency{Code: "DEM", Factor: 100},
+	)
+}
+
+func assertRate(t *testing.T, want string, cell RateMatrixCell) {
+	t.Helper()
+	require.NotNil(t, cell.Rate, "%s/%s", cell.From, cell.To)
+	assert.True(t, decimal.RequireFromString(want).Equal(*cell.Rate), "%s/%s: got %s", cell.From, cell.To, cell.Rate)
+	assert.False(t, cell.Missing, "%s/%s", cell.From, cell.To)
+}
+
+func TestRateMatrixMarksMissingPairs(t *testing.T) {
+	older := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	rates := &fakeRateRepo{stored: []*model.ExchangeRate{
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.8"), Timestamp: older},
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.5"), Timestamp: newer},
+		{FromCode: "EUR", ToCode: "GBP", Rate: decimal.RequireFromString("0.25"), Timestamp: newer},
+	}}
+	svc := NewConversionService(newMatrixCurrencyRepo(), rates, "")
+
+	matrix, err := svc.RateMatrix(context.Background(), []string{"USD", "EUR", "GBP"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, rates.fetches)
+	assert.Equal(t, []string{"USD", "EUR", "GBP"}, matrix.Codes)
+	require.Len(t, matrix.Rows, 3)
+	for i, row := range matrix.Rows {
+		require.Len(t, row, 3)
+		assertRate(t, "1", row[i])
+		assert.Nil(t, row[i].RateTimestamp)
+	}
+
+	usd, eur, gbp := matrix.Rows[0], matrix.Rows[1], matrix.Rows[2]
+	assertRate(t, "0.5", usd[1])
+	assert.True(t, newer.Equal(usd[1].RateTimestamp.Time))
+	assertRate(t, "2", eur[0])
+	assertRate(t, "0.25", eur[2])
+	assertRate(t, "4", gbp[1])
+
+	// Without a pivot nothing connects USD and GBP
+	for _, cell := range []RateMatrixCell{usd[2], gbp[0]} {
+		assert.True(t, cell.Missing, "%s/%s", cell.From, cell.To)
+		assert.Nil(t, cell.Rate)
+		assert.Nil(t, cell.RateTimestamp)
+	}
+	assert.Equal(t, 2, matrix.Missing)
+}
+
+func TestRateMatrixComputesCrossRatesThroughPivot(t *testing.T) {
+	older := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	rates := &fakeRateRepo{stored: []*model.ExchangeRate{
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.5"), Timestamp: older.Add(time.Hour)},
+		{FromCode: "USD", ToCode: "GBP", Rate: decimal.RequireFromString("0.25"), Timestamp: older},
+	}}
+	svc := NewConversionService(newMatrixCurrencyRepo(), rates, "USD")
+
+	// The pivot is fetched along with the requested codes
+	matrix, err := svc.RateMatrix(context.Background(), []string{"eur", "GBP", "EUR"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, rates.fetches)
+	assert.Equal(t, []string{"EUR", "GBP"}, matrix.Codes)
+	assert.Zero(t, matrix.Missing)
+
+	eurGBP := matrix.Rows[0][1]
+	assertRate(t, "0.5", eurGBP)
+	assert.Equal(t, "USD", eurGBP.Via)
+	assert.True(t, older.Equal(eurGBP.RateTimestamp.Time), "a cross rate is as old as its older leg")
+	assertRate(t, "2", matrix.Rows[1][0])
+}
+
+func TestRateMatrixRejectsUnknownAndInactiveCurrencies(t *testing.T) {
+	svc := NewConversionService(newMatrixCurrencyRepo(), &fakeRateRepo{}, "USD")
+
+	_, err := svc.RateMatrix(context.Background(), []string{"USD", "XYZ"})
+	assert.ErrorIs(t, err, apperrors.ErrCurrencyNotFound)
+
+	_, err = svc.RateMatrix(context.Background(), []string{"USD", "DEM"})
+	assert.ErrorIs(t, err, apperrors.ErrCurrencyInactive)
+}
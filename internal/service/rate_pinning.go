@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// PinRate stores a manual rate for converting from into to that overrides
+// the pair's provider rates, however recent, until it is unpinned. When a
+// pair has several pinned rates the one with the highest priority is used,
+// and the latest of those with the same priority. Only that direction is
+// pinned; the reverse pair keeps its own rates.
+func (s *RateService) PinRate(ctx context.Context, from, to string, rate decimal.Decimal, priority int) (*model.ExchangeRate, error) {
+	pinned := &model.ExchangeRate{
+		FromCode:  from,
+		ToCode:    to,
+		Rate:      rate,
+		Timestamp: time.Now().UTC(),
+		Source:    model.RateSourceManual,
+		Pinned:    true,
+		Priority:  priority,
+	}
+	if err := normalizeRate(pinned); err != nil {
+		return nil, err
+	}
+
+	currencies, err := s.currencyRepo.GetByCodes(ctx, []string{pinned.FromCode, pinned.ToCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load currencies: %w", err)
+	}
+	known := make(map[string]bool, len(currencies))
+	for _, currency := range currencies {
+		known[model.CanonicalCode(currency.Code)] = true
+	}
+	for _, code := range []string{pinned.FromCode, pinned.ToCode} {
+		if !known[code] {
+			return nil, fmt.Errorf("%w: %s", apperrors.ErrCurrencyNotFound, code)
+		}
+	}
+
+	if err := s.rateRepo.Create(ctx, pinned); err != nil {
+		return nil, err
+	}
+	return pinned, nil
+}
+
+// UnpinRate removes every pinned rate for converting from into to, so the
+// pair goes back to its provider rates. It returns ErrExchangeRateNotFound
+// when the pair has no pinned rate.
+func (s *RateService) UnpinRate(ctx context.Context, from, to string) error {
+	removed, err := s.rateRepo.Unpin(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return fmt.Errorf("%w: no pinned rate for %s/%s", apperrors.ErrExchangeRateNotFound, from, to)
+	}
+	return nil
+}
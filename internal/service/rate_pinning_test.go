@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tarifsiz/go-currency-api/internal/apperrors"
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+)
+
+func TestPinRateStoresPinnedManualRate(t *testing.T) {
+	rateRepo := &fakeRateRepo{}
+	svc := newRateImportService(rateRepo)
+
+	before := time.Now().UTC()
+	pinned, err := svc.PinRate(context.Background(), "usd", "EUR", decimal.RequireFromString("0.9"), 5)
+	require.NoError(t, err)
+
+	stored := rateRepo.Stored()
+	require.Len(t, stored, 1)
+	assert.Same(t, pinned, stored[0])
+	assert.Equal(t, "USD", pinned.FromCode)
+	assert.Equal(t, "EUR", pinned.ToCode)
+	assert.True(t, pinned.Pinned)
+	assert.Equal(t, 5, pinned.Priority)
+	assert.Equal(t, model.RateSourceManual, pinned.Source)
+	assert.False(t, pinned.Timestamp.Before(before))
+}
+
+func TestPinRateRejectsInvalidRates(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		rate     string
+		wantErr  error
+	}{
+		{"zero rate", "USD", "EUR", "0", ErrInvalidRate},
+		{"negative rate", "USD", "EUR", "-1", ErrInvalidRate},
+		{"same currency", "USD", "USD", "1", ErrInvalidRate},
+		{"unknown currency", "USD", "GBP", "0.8", apperrors.ErrCurrencyNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rateRepo := &fakeRateRepo{}
+			svc := newRateImportService(rateRepo)
+
+			_, err := svc.PinRate(context.Background(), tt.from, tt.to, decimal.RequireFromString(tt.rate), 0)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Empty(t, rateRepo.Stored())
+		})
+	}
+}
+
+func TestUnpinRateKeepsProviderRates(t *testing.T) {
+	at := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	provider := &model.ExchangeRate{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.92"), Timestamp: at, Source: model.RateSourceProvider}
+	rateRepo := &fakeRateRepo{stored: []*model.ExchangeRate{
+		provider,
+		{FromCode: "USD", ToCode: "EUR", Rate: decimal.RequireFromString("0.9"), Timestamp: at.Add(-time.Hour), Source: model.RateSourceManual, Pinned: true},
+		{FromCode: "EUR", ToCode: "USD", Rate: decimal.RequireFromString("1.1"), Timestamp: at, Source: model.RateSourceManual, Pinned: true},
+	}}
+	svc := newRateImportService(rateRepo)
+
+	require.NoError(t, svc.UnpinRate(context.Background(), "USD", "EUR"))
+	stored := rateRepo.Stored()
+	require.Len(t, stored, 2)
+	assert.Same(t, provider, stored[0])
+	assert.Equal(t, "EUR", stored[1].FromCode, "the reverse pair keeps its pin")
+
+	err := svc.UnpinRate(context.Background(), "USD", "EUR")
+	assert.ErrorIs(t, err, apperrors.ErrExchangeRateNotFound)
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/rates"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+)
+
+// RateRefresherInterface defines on-demand exchange rate refreshes
+type RateRefresherInterface interface {
+	RefreshAll(ctx context.Context) []RateRefreshResult
+	ProviderStatus() RateProviderStatus
+}
+
+// RateRefreshResult reports the outcome of refreshing one base currency
+type RateRefreshResult struct {
+	Base   string `json:"base"`
+	Stored int    `json:"stored"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RateProviderStatus reports how the rate provider has been doing. While
+// it keeps failing, refreshes are retried less and less often, up to the
+// configured maximum backoff; LastSuccessAt is null until a fetch succeeds.
+type RateProviderStatus struct {
+	LastSuccessAt       *model.Timestamp `json:"last_success_at"`
+	ConsecutiveFailures int              `json:"consecutive_failures"`
+	RetryIntervalSec    int64            `json:"retry_interval_seconds"`
+}
+
+// errRateFetch marks refresh errors caused by the provider rather than by
+// storing its rates
+var errRateFetch = errors.New("failed to fetch rates")
+
+// RateRefresher periodically fetches exchange rates from a RateProvider and
+// stores them as new snapshots. The provider is injected so it can be
+// swapped, e.g. for a fake in tests.
+type RateRefresher struct {
+	provider rates.RateProvider
+	rateRepo repository.ExchangeRateRepositoryInterface
+	bases    []string
+	interval time.Duration
+	timeout  time.Duration
+	logger   *slog.Logger
+
+	// Backoff state, see ProviderStatus
+	maxBackoff  time.Duration
+	mu          sync.Mutex
+	lastSuccess time.Time
+	failures    int
+}
+
+// NewRateRefresher creates a refresher that fetches rates for every base
+// currency once per interval, giving each refresh at most timeout. While the
+// provider keeps failing the interval doubles with every failed refresh, up
+// to maxBackoff.
+func NewRateRefresher(provider rates.RateProvider, rateRepo repository.ExchangeRateRepositoryInterface, bases []string, interval, timeout, maxBackoff time.Duration, logger *slog.Logger) *RateRefresher {
+	normalized := make([]string, len(bases))
+	for i, base := range bases {
+		normalized[i] = strings.ToUpper(base)
+	}
+
+	if maxBackoff < interval {
+		maxBackoff = interval
+	}
+
+	return &RateRefresher{
+		provider:   provider,
+		rateRepo:   rateRepo,
+		bases:      normalized,
+		interval:   interval,
+		timeout:    timeout,
+		logger:     logger,
+		maxBackoff: maxBackoff,
+	}
+}
+
+// Run refreshes rates immediately and then on every interval until ctx is
+// cancelled. A failed refresh is logged and retried after the backoff
+// reported by ProviderStatus; it never stops the loop.
+func (r *RateRefresher) Run(ctx context.Context) {
+	for {
+		r.RefreshAll(ctx)
+
+		timer := time.NewTimer(r.retryInterval())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// RefreshAll refreshes every base currency independently, so one failing
+// base doesn't prevent the others from updating, and logs each outcome. The
+// provider counts as failing when no base could be fetched.
+func (r *RateRefresher) RefreshAll(ctx context.Context) []RateRefreshResult {
+	results := make([]RateRefreshResult, 0, len(r.bases))
+	fetched, fetchFailed := false, false
+	for _, base := range r.bases {
+		if ctx.Err() != nil {
+			break
+		}
+		result := RateRefreshResult{Base: base}
+		stored, err := r.Refresh(ctx, base)
+		if errors.Is(err, errRateFetch) {
+			fetchFailed = true
+		} else {
+			fetched = true
+		}
+		if err != nil {
+			result.Error = err.Error()
+			r.logger.Error("exchange rate refresh failed", "base", base, "error", err)
+		} else {
+			result.Stored = stored
+			r.logger.Info("exchange rates refreshed", "base", base, "rates", stored)
+		}
+		results = append(results, result)
+	}
+
+	// A fetch cut short by cancellation says nothing about the provider
+	if fetched || (fetchFailed && ctx.Err() == nil) {
+		r.recordFetch(fetched)
+	}
+	return results
+}
+
+// ProviderStatus reports when rates were last fetched successfully, how
+// many refreshes in a row have failed since and how long the next retry
+// waits
+func (r *RateRefresher) ProviderStatus() RateProviderStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := RateProviderStatus{
+		ConsecutiveFailures: r.failures,
+		RetryIntervalSec:    int64(r.backoff().Seconds()),
+	}
+	if !r.lastSuccess.IsZero() {
+		at := model.NewTimestamp(r.lastSuccess)
+		status.LastSuccessAt = &at
+	}
+	return status
+}
+
+// recordFetch updates the backoff state after a refresh: a success resets
+// it, a failure lengthens the retry interval
+func (r *RateRefresher) recordFetch(succeeded bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if succeeded {
+		r.lastSuccess = time.Now().UTC()
+		r.failures = 0
+		return
+	}
+	r.failures++
+	r.logger.Warn("exchange rate provider failing, backing off", "consecutive_failures", r.failures, "retry_in", r.backoff())
+}
+
+// retryInterval returns how long to wait before the next refresh
+func (r *RateRefresher) retryInterval() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.backoff()
+}
+
+// backoff doubles the interval for every consecutive failure, up to
+// maxBackoff. r.mu must be held.
+func (r *RateRefresher) backoff() time.Duration {
+	delay := r.interval
+	for i := 0; i < r.failures && delay < r.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.maxBackoff {
+		delay = r.maxBackoff
+	}
+	return delay
+}
+
+// Refresh fetches and stores the rates for a single base currency and
+// returns the number of rates stored
+func (r *RateRefresher) Refresh(ctx context.Context, base string) (stored int, err error) {
+	// A misbehaving provider must not take the refresh loop down with it
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w: rate provider panicked: %v", errRateFetch, p)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	quotes, err := r.provider.FetchRates(ctx, base)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", errRateFetch, err)
+	}
+
+	now := time.Now().UTC()
+	snapshots := make([]*model.ExchangeRate, 0, len(quotes))
+	for code, rate := range quotes {
+		code = strings.ToUpper(code)
+		// Skip entries the exchange_rates table would reject
+		if code == base || !isoCodePattern.MatchString(code) || !rate.IsPositive() {
+			continue
+		}
+		snapshots = append(snapshots, &model.ExchangeRate{
+			FromCode:  base,
+			ToCode:    code,
+			Rate:      rate,
+			Timestamp: now,
+			Source:    model.RateSourceProvider,
+		})
+	}
+
+	if err := r.rateRepo.CreateBatch(ctx, snapshots); err != nil {
+		return 0, err
+	}
+	return len(snapshots), nil
+}
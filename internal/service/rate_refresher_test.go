@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRateProvider returns quotes, or err when it's set, counting fetches.
+// A blocking provider waits for its context instead.
+type fakeRateProvider struct {
+	quotes   map[string]decimal.Decimal
+	err      error
+	block    bool
+	fetches  atomic.Int32
+	fetching chan struct{} // receives once per fetch when set
+}
+
+func (p *fakeRateProvider) FetchRates(ctx context.Context, base string) (map[string]decimal.Decimal, error) {
+	p.fetches.Add(1)
+	if p.fetching != nil {
+		p.fetching <- struct{}{}
+	}
+	if p.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return p.quotes, p.err
+}
+
+// newTestRefresher returns a refresher backing off up to 8 intervals
+func newTestRefresher(provider *fakeRateProvider, repo *fakeRateRepo, interval time.Duration, bases ...string) *RateRefresher {
+	return NewRateRefresher(provider, repo, bases, interval, time.Second, 8*interval, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// runRefresher starts r.Run and returns a channel closed when it returns
+func runRefresher(ctx context.Context, r *RateRefresher) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+	return done
+}
+
+func requireStops(t *testing.T, done <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("refresher didn't stop after its context was cancelled")
+	}
+}
+
+// requireGoroutinesExit waits for the number of goroutines to drop back to
+// baseline. assert.Eventually can't be used as it runs goroutines itself.
+func requireGoroutinesExit(t *testing.T, baseline int) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > baseline; {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines leaked", runtime.NumGoroutine()-baseline)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRateRefresherStopsWhenCancelledBetweenTicks(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	provider := &fakeRateProvider{quotes: map[string]decimal.Decimal{"EUR": decimal.RequireFromString("0.9")}, fetching: make(chan struct{}, 10)}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := runRefresher(ctx, newTestRefresher(provider, &fakeRateRepo{}, time.Hour, "USD"))
+	<-provider.fetching
+	cancel()
+
+	requireStops(t, done)
+	assert.Equal(t, int32(1), provider.fetches.Load())
+	requireGoroutinesExit(t, baseline)
+}
+
+func TestRateRefresherStopsWhenCancelledMidFetch(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	provider := &fakeRateProvider{block: true, fetching: make(chan struct{}, 10)}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := runRefresher(ctx, newTestRefresher(provider, &fakeRateRepo{}, time.Hour, "USD", "EUR", "GBP"))
+	<-provider.fetching
+	cancel()
+
+	requireStops(t, done)
+	// The remaining bases are skipped once the context is cancelled
+	assert.Equal(t, int32(1), provider.fetches.Load())
+	requireGoroutinesExit(t, baseline)
+}
+
+func TestRateRefresherRetriesFailures(t *testing.T) {
+	provider := &fakeRateProvider{err: errors.New("provider down"), fetching: make(chan struct{}, 10)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := runRefresher(ctx, newTestRefresher(provider, &fakeRateRepo{}, time.Millisecond, "USD"))
+	// Failures don't stop the loop, they only delay the next refresh
+	for i := 0; i < 3; i++ {
+		<-provider.fetching
+	}
+	cancel()
+	requireStops(t, done)
+}
+
+func TestRefreshAllBacksOffWhileProviderFails(t *testing.T) {
+	provider := &fakeRateProvider{err: errors.New("provider down")}
+	refresher := newTestRefresher(provider, &fakeRateRepo{}, time.Minute, "USD", "EUR")
+
+	status := refresher.ProviderStatus()
+	assert.Zero(t, status.ConsecutiveFailures)
+	assert.Equal(t, int64(60), status.RetryIntervalSec)
+
+	// Each failed refresh doubles the interval, up to the 8 minute cap
+	for i, want := range []time.Duration{2, 4, 8, 8, 8} {
+		refresher.RefreshAll(context.Background())
+
+		status := refresher.ProviderStatus()
+		assert.Equal(t, i+1, status.ConsecutiveFailures)
+		assert.Equal(t, int64((want * time.Minute).Seconds()), status.RetryIntervalSec, "after %d failures", i+1)
+		assert.Nil(t, status.LastSuccessAt)
+	}
+
+	// Recovery resets the backoff
+	provider.err = nil
+	provider.quotes = map[string]decimal.Decimal{"GBP": decimal.RequireFromString("0.8")}
+	before := time.Now()
+	refresher.RefreshAll(context.Background())
+
+	status = refresher.ProviderStatus()
+	assert.Zero(t, status.ConsecutiveFailures)
+	assert.Equal(t, int64(60), status.RetryIntervalSec)
+	require.NotNil(t, status.LastSuccessAt)
+	assert.False(t, status.LastSuccessAt.Before(before.Truncate(time.Microsecond)))
+
+	// A later failure starts over from the first step
+	provider.err = errors.New("provider down again")
+	refresher.RefreshAll(context.Background())
+	status = refresher.ProviderStatus()
+	assert.Equal(t, 1, status.ConsecutiveFailures)
+	assert.Equal(t, int64(120), status.RetryIntervalSec)
+	assert.NotNil(t, status.LastSuccessAt)
+}
+
+func TestRefreshAllDoesntBackOffForStoreFailures(t *testing.T) {
+	provider := &fakeRateProvider{quotes: map[string]decimal.Decimal{"EUR": decimal.RequireFromString("0.9")}}
+	refresher := newTestRefresher(provider, &fakeRateRepo{createErr: errors.New("database down")}, time.Minute, "USD")
+
+	results := refresher.RefreshAll(context.Background())
+
+	require.Len(t, results, 1)
+	assert.Contains(t, results[0].Error, "database down")
+	// The provider answered, so it isn't backed off
+	status := refresher.ProviderStatus()
+	assert.Zero(t, status.ConsecutiveFailures)
+	assert.NotNil(t, status.LastSuccessAt)
+}
+
+func TestRefreshAllCancelledDoesntCountAsFailure(t *testing.T) {
+	provider := &fakeRateProvider{block: true, fetching: make(chan struct{}, 1)}
+	refresher := newTestRefresher(provider, &fakeRateRepo{}, time.Minute, "USD")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		<-provider.fetching
+		cancel()
+	}()
+	refresher.RefreshAll(ctx)
+
+	assert.Zero(t, refresher.ProviderStatus().ConsecutiveFailures)
+}
+
+func TestRefreshStoresValidQuotes(t *testing.T) {
+	provider := &fakeRateProvider{quotes: map[string]decimal.Decimal{
+		"eur": decimal.RequireFromString("0.9"),
+		"GBP": decimal.RequireFromString("0.8"),
+		"USD": decimal.NewFromInt(1),  // the base itself
+		"XX":  decimal.NewFromInt(1),  // not a code
+		"JPY": decimal.NewFromInt(0),  // not a rate
+		"CHF": decimal.NewFromInt(-1), // not a rate
+	}}
+	repo := &fakeRateRepo{}
+
+	stored, err := newTestRefresher(provider, repo, time.Hour, "usd").Refresh(context.Background(), "USD")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored)
+	pairs := map[string]string{}
+	for _, rate := range repo.Stored() {
+		pairs[rate.FromCode+"/"+rate.ToCode] = rate.Rate.String()
+	}
+	assert.Equal(t, map[string]string{"USD/EUR": "0.9", "USD/GBP": "0.8"}, pairs)
+}
+
+func TestRefreshAllReportsEachBase(t *testing.T) {
+	provider := &fakeRateProvider{err: errors.New("provider down")}
+
+	results := newTestRefresher(provider, &fakeRateRepo{}, time.Hour, "usd", "eur").RefreshAll(context.Background())
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "USD", results[0].Base)
+	assert.Equal(t, "EUR", results[1].Base)
+	for _, result := range results {
+		assert.Contains(t, result.Error, "provider down")
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+	"github.com/shopspring/decimal"
+)
+
+// RateServiceInterface defines exchange rate lookups
+type RateServiceInterface interface {
+	GetRateAt(ctx context.Context, from, to string, at time.Time) (*model.ExchangeRate, error)
+	ListPairs(ctx context.Context, base string, limit, offset int) ([]*repository.RatePair, int64, error)
+	ImportRates(ctx context.Context, rows []RateImportRow) (*RateImportSummary, error)
+	PinRate(ctx context.Context, from, to string, rate decimal.Decimal, priority int) (*model.ExchangeRate, error)
+	UnpinRate(ctx context.Context, from, to string) error
+}
+
+// RateService implements the RateServiceInterface
+type RateService struct {
+	rateRepo     repository.ExchangeRateRepositoryInterface
+	currencyRepo repository.CurrencyRepositoryInterface
+}
+
+// NewRateService creates a new rate service instance
+func NewRateService(rateRepo repository.ExchangeRateRepositoryInterface, currencyRepo repository.CurrencyRepositoryInterface) RateServiceInterface {
+	return &RateService{
+		rateRepo:     rateRepo,
+		currencyRepo: currencyRepo,
+	}
+}
+
+// GetRateAt returns the rate for converting from into to that was in effect
+// at the given time, i.e. the most recent snapshot quoted on or before it
+func (s *RateService) GetRateAt(ctx context.Context, from, to string, at time.Time) (*model.ExchangeRate, error) {
+	return s.rateRepo.GetRateAt(ctx, from, to, at.UTC())
+}
+
+// ListPairs returns one page of the currency pairs that can be converted,
+// i.e. have at least one stored rate, along with the total number of pairs.
+// A non-empty base restricts them to pairs converting from base.
+func (s *RateService) ListPairs(ctx context.Context, base string, limit, offset int) ([]*repository.RatePair, int64, error) {
+	pairs, err := s.rateRepo.GetPairs(ctx, base, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.rateRepo.CountPairs(ctx, base)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return pairs, total, nil
+}
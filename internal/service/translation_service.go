@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+	"github.com/Tarifsiz/go-currency-api/internal/repository"
+)
+
+// TranslationServiceInterface defines localization of currency descriptions
+type TranslationServiceInterface interface {
+	Localize(ctx context.Context, currencies []*model.Currency, locales []string) (map[string]*model.CurrencyTranslation, error)
+}
+
+// TranslationService implements the TranslationServiceInterface
+type TranslationService struct {
+	translationRepo repository.TranslationRepositoryInterface
+}
+
+// NewTranslationService creates a new translation service instance
+func NewTranslationService(translationRepo repository.TranslationRepositoryInterface) TranslationServiceInterface {
+	return &TranslationService{
+		translationRepo: translationRepo,
+	}
+}
+
+// Localize replaces the description of each currency with its translation
+// into the most preferred of locales that has one, and returns the applied
+// translations keyed by currency code. Currencies without a translation keep
+// their default description. The currencies are modified in place, so they
+// must not be shared with the cache.
+func (s *TranslationService) Localize(ctx context.Context, currencies []*model.Currency, locales []string) (map[string]*model.CurrencyTranslation, error) {
+	preferred := expandLocales(locales)
+	if len(currencies) == 0 || len(preferred) == 0 {
+		return nil, nil
+	}
+
+	codes := make([]string, len(currencies))
+	for i, currency := range currencies {
+		codes[i] = currency.Code
+	}
+
+	translations, err := s.translationRepo.GetByCodes(ctx, codes, preferred)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := make(map[string]int, len(preferred))
+	for i, locale := range preferred {
+		rank[locale] = i
+	}
+	best := make(map[string]*model.CurrencyTranslation)
+	for _, t := range translations {
+		if current, ok := best[t.CurrencyCode]; !ok || rank[t.Locale] < rank[current.Locale] {
+			best[t.CurrencyCode] = t
+		}
+	}
+
+	for _, currency := range currencies {
+		if t, ok := best[currency.Code]; ok {
+			currency.Description = t.Description
+		}
+	}
+	return best, nil
+}
+
+// expandLocales lower-cases locales and adds the base language after each
+// regional tag that isn't followed by it, so "fr-CA" falls back to "fr"
+// before the next preference. Duplicates are dropped.
+func expandLocales(locales []string) []string {
+	var expanded []string
+	seen := make(map[string]bool)
+	add := func(locale string) {
+		if locale != "" && !seen[locale] {
+			seen[locale] = true
+			expanded = append(expanded, locale)
+		}
+	}
+
+	for _, locale := range locales {
+		locale = strings.ToLower(strings.TrimSpace(locale))
+		add(locale)
+		if base, _, ok := strings.Cut(locale, "-"); ok {
+			add(base)
+		}
+	}
+	return expanded
+}
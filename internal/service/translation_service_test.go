@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tarifsiz/go-currency-api/internal/model"
+)
+
+// fakeTranslationRepo holds translations and records the locales it was
+// asked for
+type fakeTranslationRepo struct {
+	translations []*model.CurrencyTranslation
+	locales      [][]string
+}
+
+func (r *fakeTranslationRepo) GetByCodes(ctx context.Context, codes, locales []string) ([]*model.CurrencyTranslation, error) {
+	r.locales = append(r.locales, locales)
+	wanted := make(map[string]bool, len(codes)+len(locales))
+	for _, value := range append(append([]string(nil), codes...), locales...) {
+		wanted[value] = true
+	}
+	var found []*model.CurrencyTranslation
+	for _, t := range r.translations {
+		if wanted[t.CurrencyCode] && wanted[t.Locale] {
+			found = append(found, t)
+		}
+	}
+	return found, nil
+}
+
+func newTranslationTestRepo() *fakeTranslationRepo {
+	return &fakeTranslationRepo{translations: []*model.CurrencyTranslation{
+		{CurrencyCode: "USD", Locale: "fr", Description: "Dollar américain"},
+		{CurrencyCode: "USD", Locale: "fr-ca", Description: "Dollar des États-Unis"},
+		{CurrencyCode: "USD", Locale: "de", Description: "US-Dollar"},
+		{CurrencyCode: "EUR", Locale: "fr", Description: "Euro"},
+	}}
+}
+
+func localizeCurrencies(t *testing.T, svc TranslationServiceInterface, locales ...string) (map[string]string, map[string]*model.CurrencyTranslation) {
+	t.Helper()
+	currencies := []*model.Currency{
+		{Code: "USD", Description: "US Dollar"},
+		{Code: "EUR", Description: "Euro Member Countries"},
+		{Code: "JPY", Description: "Japanese Yen"},
+	}
+	translations, err := svc.Localize(context.Background(), currencies, locales)
+	require.NoError(t, err)
+
+	descriptions := make(map[string]string, len(currencies))
+	for _, currency := range currencies {
+		descriptions[currency.Code] = currency.Description
+	}
+	return descriptions, translations
+}
+
+func TestLocalizeUsesTranslationForLocale(t *testing.T) {
+	svc := NewTranslationService(newTranslationTestRepo())
+
+	descriptions, translations := localizeCurrencies(t, svc, "FR")
+	assert.Equal(t, map[string]string{"USD": "Dollar américain", "EUR": "Euro", "JPY": "Japanese Yen"}, descriptions)
+	require.Len(t, translations, 2)
+	assert.Equal(t, "fr", translations["USD"].Locale)
+
+	// A regional translation beats its language's
+	descriptions, translations = localizeCurrencies(t, svc, "fr-CA")
+	assert.Equal(t, "Dollar des États-Unis", descriptions["USD"])
+	assert.Equal(t, "fr-ca", translations["USD"].Locale)
+	assert.Equal(t, "Euro", descriptions["EUR"])
+	assert.Equal(t, "fr", translations["EUR"].Locale)
+}
+
+func TestLocalizeFallsBackThroughPreferences(t *testing.T) {
+	repo := newTranslationTestRepo()
+	svc := NewTranslationService(repo)
+
+	// fr-BE has no translation of its own, so fr applies before de
+	descriptions, _ := localizeCurrencies(t, svc, "fr-BE", "de")
+	assert.Equal(t, "Dollar américain", descriptions["USD"])
+	assert.Equal(t, []string{"fr-be", "fr", "de"}, repo.locales[0])
+
+	descriptions, _ = localizeCurrencies(t, svc, "it", "de", "fr")
+	assert.Equal(t, "US-Dollar", descriptions["USD"])
+	assert.Equal(t, "Euro", descriptions["EUR"])
+}
+
+func TestLocalizeKeepsDefaultsForUnknownLocale(t *testing.T) {
+	repo := newTranslationTestRepo()
+	svc := NewTranslationService(repo)
+
+	descriptions, translations := localizeCurrencies(t, svc, "sw")
+	assert.Equal(t, map[string]string{"USD": "US Dollar", "EUR": "Euro Member Countries", "JPY": "Japanese Yen"}, descriptions)
+	assert.Empty(t, translations)
+
+	// Without any locale the repository isn't asked
+	repo.locales = nil
+	descriptions, _ = localizeCurrencies(t, svc)
+	assert.Equal(t, "US Dollar", descriptions["USD"])
+	assert.Empty(t, repo.locales)
+}
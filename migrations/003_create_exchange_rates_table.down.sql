@@ -0,0 +1,2 @@
+-- Drop exchange rates table
+DROP TABLE IF EXISTS exchange_rates;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- Create exchange rates table
+CREATE TABLE exchange_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    from_code VARCHAR(3) NOT NULL,
+    to_code VARCHAR(3) NOT NULL,
+    rate NUMERIC(24, 12) NOT NULL CHECK (rate > 0),
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX idx_exchange_rates_pair ON exchange_rates(from_code, to_code, timestamp DESC);
+CREATE INDEX idx_exchange_rates_timestamp ON exchange_rates(timestamp);
+
+-- Add comments
+COMMENT ON TABLE exchange_rates IS 'Exchange rate snapshots between two currencies';
+COMMENT ON COLUMN exchange_rates.rate IS 'Units of to_code received for one unit of from_code';
+COMMENT ON COLUMN exchange_rates.timestamp IS 'Time at which the rate was quoted';
@@ -0,0 +1,3 @@
+-- Remove ISO 4217 numeric code
+DROP INDEX IF EXISTS idx_currencies_numeric_code;
+ALTER TABLE currencies DROP COLUMN IF EXISTS numeric_code;
\ No newline at end of file
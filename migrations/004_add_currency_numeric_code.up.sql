@@ -0,0 +1,72 @@
+-- Add ISO 4217 numeric code; existing rows default to '' (unknown)
+ALTER TABLE currencies ADD COLUMN numeric_code VARCHAR(3) NOT NULL DEFAULT '';
+
+-- Numeric codes must be unique when known
+CREATE UNIQUE INDEX idx_currencies_numeric_code ON currencies(numeric_code) WHERE numeric_code <> '';
+
+-- Backfill numeric codes for known currencies (CNH has no ISO numeric code)
+UPDATE currencies AS c SET numeric_code = v.numeric_code
+FROM (VALUES
+('AED', '784'),
+('MAD', '504'),
+('MUR', '480'),
+('XCD', '951'),
+('CLP', '152'),
+('ZAR', '710'),
+('SEK', '752'),
+('KES', '404'),
+('CAD', '124'),
+('GBP', '826'),
+('OMR', '512'),
+('RON', '946'),
+('NOK', '578'),
+('SAR', '682'),
+('JPY', '392'),
+('DKK', '208'),
+('HUF', '348'),
+('IDR', '360'),
+('KWD', '414'),
+('TWD', '901'),
+('TTD', '780'),
+('QAR', '634'),
+('MYR', '458'),
+('HKD', '344'),
+('USD', '840'),
+('CNY', '156'),
+('BBD', '052'),
+('ZMW', '967'),
+('PLN', '985'),
+('CHF', '756'),
+('XOF', '952'),
+('BWP', '072'),
+('BHD', '048'),
+('KZT', '398'),
+('EGP', '818'),
+('ISK', '352'),
+('MWK', '454'),
+('HRK', '191'),
+('NGN', '566'),
+('AUD', '036'),
+('RUB', '643'),
+('JOD', '400'),
+('FJD', '242'),
+('THB', '764'),
+('MXN', '484'),
+('KRW', '410'),
+('NZD', '554'),
+('LKR', '144'),
+('EUR', '978'),
+('CZK', '203'),
+('BGN', '975'),
+('UGX', '800'),
+('SGD', '702'),
+('INR', '356'),
+('GHS', '936'),
+('PHP', '608'),
+('ILS', '376'),
+('TRY', '949'),
+('BRL', '986')
+) AS v(code, numeric_code)
+WHERE c.code = v.code;
+
+COMMENT ON COLUMN currencies.numeric_code IS 'ISO 4217 numeric currency code, empty if unknown';
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- Permanently remove soft-deleted currencies so the unique constraints can be restored
+DELETE FROM currencies WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_currencies_numeric_code;
+CREATE UNIQUE INDEX idx_currencies_numeric_code ON currencies(numeric_code) WHERE numeric_code <> '';
+
+DROP INDEX IF EXISTS idx_currencies_code_active;
+ALTER TABLE currencies ADD CONSTRAINT currencies_code_key UNIQUE (code);
+
+DROP INDEX IF EXISTS idx_currencies_deleted_at;
+ALTER TABLE currencies DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,13 @@
+-- Add soft delete support
+ALTER TABLE currencies ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX idx_currencies_deleted_at ON currencies(deleted_at);
+
+-- Codes only need to be unique among non-deleted currencies, so a deleted
+-- code can be created again
+ALTER TABLE currencies DROP CONSTRAINT IF EXISTS currencies_code_key;
+CREATE UNIQUE INDEX idx_currencies_code_active ON currencies(code) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_currencies_numeric_code;
+CREATE UNIQUE INDEX idx_currencies_numeric_code ON currencies(numeric_code) WHERE numeric_code <> '' AND deleted_at IS NULL;
+
+COMMENT ON COLUMN currencies.deleted_at IS 'Soft delete timestamp, NULL for live currencies';
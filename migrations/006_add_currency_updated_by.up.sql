@@ -0,0 +1,4 @@
+-- Track who last modified a currency
+ALTER TABLE currencies ADD COLUMN updated_by UUID;
+
+COMMENT ON COLUMN currencies.updated_by IS 'User who last updated the currency, NULL if never updated';
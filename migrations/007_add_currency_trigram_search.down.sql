@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_currencies_description_trgm;
+
+-- The extension is left installed as other schemas may depend on it
@@ -0,0 +1,4 @@
+-- Fuzzy (trigram) search on currency descriptions
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_currencies_description_trgm ON currencies USING GIN (description gin_trgm_ops);
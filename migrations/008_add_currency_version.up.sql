@@ -0,0 +1,5 @@
+-- Optimistic locking: every update bumps the version and is conditional on
+-- the version the client last read
+ALTER TABLE currencies ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN currencies.version IS 'Incremented on every update; writes must supply the current value';
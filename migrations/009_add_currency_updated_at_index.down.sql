@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_currencies_updated_at;
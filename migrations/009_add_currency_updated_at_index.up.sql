@@ -0,0 +1,2 @@
+-- Supports change feeds that scan currencies modified after a point in time
+CREATE INDEX IF NOT EXISTS idx_currencies_updated_at ON currencies(updated_at);
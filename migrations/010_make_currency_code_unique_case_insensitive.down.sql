@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_currencies_code_active;
+CREATE UNIQUE INDEX idx_currencies_code_active ON currencies(code) WHERE deleted_at IS NULL;
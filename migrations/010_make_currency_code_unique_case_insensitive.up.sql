@@ -0,0 +1,4 @@
+-- Codes are unique regardless of case, so "usd" can't be stored next to
+-- "USD" if code normalization is ever bypassed
+DROP INDEX IF EXISTS idx_currencies_code_active;
+CREATE UNIQUE INDEX idx_currencies_code_active ON currencies(UPPER(code)) WHERE deleted_at IS NULL;
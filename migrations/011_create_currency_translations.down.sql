@@ -0,0 +1,2 @@
+-- Drop currency translations table
+DROP TABLE IF EXISTS currency_translations;
@@ -0,0 +1,18 @@
+-- Create currency_translations table. Rows are keyed by code rather than
+-- currency id so a translation survives a currency being deleted and restored;
+-- there is no foreign key because codes are only unique among active currencies.
+CREATE TABLE currency_translations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    currency_code VARCHAR(3) NOT NULL,
+    locale VARCHAR(35) NOT NULL CHECK (locale = LOWER(locale)),
+    description VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_currency_translations_code_locale ON currency_translations(currency_code, locale);
+
+-- Add comments
+COMMENT ON TABLE currency_translations IS 'Localized currency descriptions';
+COMMENT ON COLUMN currency_translations.locale IS 'Lower-case BCP 47 language tag, e.g. fr or fr-ca';
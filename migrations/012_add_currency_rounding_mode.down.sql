@@ -0,0 +1,2 @@
+-- Remove currency rounding mode
+ALTER TABLE currencies DROP COLUMN IF EXISTS rounding_mode;
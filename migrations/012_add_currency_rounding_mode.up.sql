@@ -0,0 +1,6 @@
+-- Add the rounding mode used when converting into a currency; existing rows
+-- default to banker's rounding
+ALTER TABLE currencies ADD COLUMN rounding_mode VARCHAR(10) NOT NULL DEFAULT 'half_even'
+    CHECK (rounding_mode IN ('half_up', 'half_even', 'down', 'up'));
+
+COMMENT ON COLUMN currencies.rounding_mode IS 'Rounding to the minor unit: half_up, half_even, down or up';
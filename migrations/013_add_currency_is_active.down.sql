@@ -0,0 +1,2 @@
+-- Remove currency active flag
+ALTER TABLE currencies DROP COLUMN IF EXISTS is_active;
@@ -0,0 +1,5 @@
+-- Obsolete currencies (e.g. pre-euro national currencies) are kept but
+-- flagged inactive; existing rows stay active
+ALTER TABLE currencies ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT TRUE;
+
+COMMENT ON COLUMN currencies.is_active IS 'False for obsolete currencies; hidden from listings by default and can''t be converted';
@@ -0,0 +1,4 @@
+-- Remove currency validity range
+ALTER TABLE currencies DROP CONSTRAINT IF EXISTS chk_currencies_validity;
+ALTER TABLE currencies DROP COLUMN IF EXISTS valid_until;
+ALTER TABLE currencies DROP COLUMN IF EXISTS valid_from;
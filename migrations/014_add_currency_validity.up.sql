@@ -0,0 +1,10 @@
+-- Currencies are valid from their introduction until their demonetization.
+-- Either bound may be unknown or open; existing rows are unbounded.
+ALTER TABLE currencies ADD COLUMN valid_from TIMESTAMP WITH TIME ZONE;
+ALTER TABLE currencies ADD COLUMN valid_until TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE currencies ADD CONSTRAINT chk_currencies_validity
+    CHECK (valid_from IS NULL OR valid_until IS NULL OR valid_from < valid_until);
+
+COMMENT ON COLUMN currencies.valid_from IS 'First instant the currency is valid, NULL for no lower bound';
+COMMENT ON COLUMN currencies.valid_until IS 'First instant the currency is no longer valid, NULL for no upper bound';
@@ -0,0 +1,2 @@
+-- Drop currency change log table
+DROP TABLE IF EXISTS currency_change_log;
@@ -0,0 +1,28 @@
+-- Create currency_change_log table, the audit trail of every create, update,
+-- delete, restore and purge of a currency. Entries keep the code as well as
+-- the currency id and have no foreign key, so the history of a code survives
+-- the currency being hard deleted.
+CREATE TABLE currency_change_log (
+    id BIGSERIAL PRIMARY KEY,
+    currency_id UUID NOT NULL,
+    currency_code VARCHAR(3) NOT NULL,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('created', 'updated', 'deleted', 'restored', 'purged')),
+    version INTEGER NOT NULL,
+    changed_by UUID,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX idx_currency_change_log_code ON currency_change_log(UPPER(currency_code), id);
+
+-- Existing currencies start their history with their creation, and deletion
+-- if they are deleted; who deleted them isn't known
+INSERT INTO currency_change_log (currency_id, currency_code, action, version, changed_by, changed_at)
+SELECT id, code, 'created', 1, created_by, COALESCE(created_at, NOW()) FROM currencies;
+INSERT INTO currency_change_log (currency_id, currency_code, action, version, changed_by, changed_at)
+SELECT id, code, 'deleted', version, NULL, deleted_at FROM currencies WHERE deleted_at IS NOT NULL;
+
+-- Add comments
+COMMENT ON TABLE currency_change_log IS 'Audit trail of changes to currencies';
+COMMENT ON COLUMN currency_change_log.version IS 'Version of the currency after the change';
+COMMENT ON COLUMN currency_change_log.changed_by IS 'User who made the change, NULL if unknown';
@@ -0,0 +1,3 @@
+-- Remove exchange rate source
+DROP INDEX IF EXISTS idx_exchange_rates_pair_quoted;
+ALTER TABLE exchange_rates DROP COLUMN IF EXISTS source;
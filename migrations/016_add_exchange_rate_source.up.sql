@@ -0,0 +1,15 @@
+-- Rates are either fetched from the provider or imported by an operator.
+-- Existing rows all came from the provider.
+ALTER TABLE exchange_rates ADD COLUMN source VARCHAR(50) NOT NULL DEFAULT 'provider';
+
+-- Imports upsert on the pair and quote time, so there may be only one rate
+-- per pair at any instant; keep the most recently stored of any duplicates
+DELETE FROM exchange_rates a USING exchange_rates b
+WHERE a.from_code = b.from_code
+  AND a.to_code = b.to_code
+  AND a.timestamp = b.timestamp
+  AND (a.created_at, a.id) < (b.created_at, b.id);
+
+CREATE UNIQUE INDEX idx_exchange_rates_pair_quoted ON exchange_rates(from_code, to_code, timestamp);
+
+COMMENT ON COLUMN exchange_rates.source IS 'Where the rate came from, e.g. provider or the source column of an import';
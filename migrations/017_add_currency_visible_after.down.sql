@@ -0,0 +1,2 @@
+-- Remove currency soft launch time
+ALTER TABLE currencies DROP COLUMN IF EXISTS visible_after;
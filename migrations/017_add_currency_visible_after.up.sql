@@ -0,0 +1,5 @@
+-- Soft launch: a currency with a future visible_after is stored and can be
+-- looked up by code, but is left out of listings until that time
+ALTER TABLE currencies ADD COLUMN visible_after TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN currencies.visible_after IS 'First instant the currency is listed, NULL to list it right away';
@@ -0,0 +1,2 @@
+-- Remove partial index on active currencies
+DROP INDEX IF EXISTS idx_currencies_active_code;
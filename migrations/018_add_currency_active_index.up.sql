@@ -0,0 +1,5 @@
+-- Most listings and searches only return live, active currencies in code
+-- order. A partial index over just those rows stays small as obsolete and
+-- deleted currencies accumulate, and serves the ORDER BY code LIMIT n of
+-- the default listing without a sort.
+CREATE INDEX IF NOT EXISTS idx_currencies_active_code ON currencies(code) WHERE is_active = true AND deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- Remove exchange rate pinning
+DROP INDEX IF EXISTS idx_exchange_rates_pair_preferred;
+ALTER TABLE exchange_rates DROP COLUMN IF EXISTS priority;
+ALTER TABLE exchange_rates DROP COLUMN IF EXISTS pinned;
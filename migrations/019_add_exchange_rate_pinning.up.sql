@@ -0,0 +1,11 @@
+-- Operators can pin a manual rate for a pair, e.g. a contractual fixed rate.
+-- Pinned rates take precedence over every provider rate for the pair,
+-- however recent, and among themselves the highest priority wins.
+ALTER TABLE exchange_rates ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE exchange_rates ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+
+-- Latest-rate lookups order a pair's rates by these columns
+CREATE INDEX idx_exchange_rates_pair_preferred ON exchange_rates(from_code, to_code, pinned DESC, priority DESC, timestamp DESC);
+
+COMMENT ON COLUMN exchange_rates.pinned IS 'Set for manual rates that override provider rates for the pair';
+COMMENT ON COLUMN exchange_rates.priority IS 'Rank among the pinned rates of a pair; the highest wins';
@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL migrations so they ship in the
+// binary and can be applied on startup.
+package migrations
+
+import "embed"
+
+// FS holds the *.up.sql and *.down.sql migration files
+//
+//go:embed *.sql
+var FS embed.FS